@@ -0,0 +1,232 @@
+package audit
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/mhrlife/goai-kit/internal/callback"
+)
+
+// Record is a single entry of the audit log. Records form a hash chain: each signature
+// covers the record including the signature of the previous one, so removing, reordering
+// or editing records is detected by Verify.
+type Record struct {
+	Seq      int64                  `json:"seq"`
+	Time     time.Time              `json:"time"`
+	Event    string                 `json:"event"`
+	RunID    string                 `json:"run_id,omitempty"`
+	ParentID string                 `json:"parent_run_id,omitempty"`
+	Agent    string                 `json:"agent,omitempty"`
+	User     string                 `json:"user_id,omitempty"`
+	Metadata map[string]string      `json:"metadata,omitempty"`
+	Tags     []string               `json:"tags,omitempty"`
+	Data     map[string]interface{} `json:"data,omitempty"`
+	Prev     string                 `json:"prev"`
+	Sig      string                 `json:"sig"`
+}
+
+// Config configures the audit callback
+type Config struct {
+	// Writer receives one JSON record per line (required). It should be append-only.
+	Writer io.Writer
+
+	// Key signs the records with HMAC-SHA256 (required)
+	Key []byte
+
+	// Now overrides the clock (optional, defaults to time.Now)
+	Now func() time.Time
+
+	// OnError is called with every record that couldn't be signed or written, e.g. to alert
+	// on a full disk (optional). The failures are available from Callback.Err as well.
+	OnError func(err error)
+}
+
+// Callback writes a signed, append-only audit trail of agent runs: who started which agent
+// with which tools, and every tool call with its arguments and outcome
+type Callback struct {
+	callback.BaseCallback
+
+	mu     sync.Mutex
+	writer io.Writer
+	key    []byte
+	now    func() time.Time
+	report func(err error)
+	seq    int64
+	prev   string
+	err    error
+}
+
+// NewCallback creates an audit callback
+func NewCallback(config Config) *Callback {
+	if config.Writer == nil || len(config.Key) == 0 {
+		panic("Writer and Key are required")
+	}
+
+	now := config.Now
+	if now == nil {
+		now = time.Now
+	}
+
+	return &Callback{
+		writer: config.Writer,
+		key:    config.Key,
+		now:    now,
+		report: config.OnError,
+	}
+}
+
+// Err returns the first error that lost an audit record, nil if every record was written
+func (c *Callback) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.err
+}
+
+func (c *Callback) Name() string {
+	return "AuditCallback"
+}
+
+// OnRunStart records who invoked which agent, with which model and tools
+func (c *Callback) OnRunStart(ctx map[string]interface{}) {
+	c.write("run_start", ctx, map[string]interface{}{
+		"model": ctx["model"],
+		"tools": ctx["tools"],
+		"input": ctx["input"],
+	})
+}
+
+// OnRunEnd records the successful completion of a run
+func (c *Callback) OnRunEnd(ctx map[string]interface{}) {
+	c.write("run_end", ctx, map[string]interface{}{
+		"total_iterations": ctx["total_iterations"],
+	})
+}
+
+// OnToolCallEnd records a tool call, the side effect an agent had on the outside world
+func (c *Callback) OnToolCallEnd(ctx map[string]interface{}) {
+	data := map[string]interface{}{
+		"tool_name":    ctx["tool_name"],
+		"tool_call_id": ctx["tool_call_id"],
+		"arguments":    ctx["arguments"],
+	}
+	if errMsg, ok := ctx["error"]; ok {
+		data["error"] = errMsg
+	}
+	c.write("tool_call", ctx, data)
+}
+
+// OnError records failures of a run
+func (c *Callback) OnError(ctx map[string]interface{}) {
+	c.write("error", ctx, map[string]interface{}{
+		"error": ctx["error"],
+		"stage": ctx["stage"],
+	})
+}
+
+// write signs and appends a record. Failures can't be returned through the callback
+// interface and must not crash the run, so the record is dropped and the failure reported to
+// the error handler; the chain continues from the last record written.
+func (c *Callback) write(event string, ctx map[string]interface{}, data map[string]interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	record := Record{
+		Seq:   c.seq + 1,
+		Time:  c.now().UTC(),
+		Event: event,
+		Data:  data,
+		Prev:  c.prev,
+	}
+	record.RunID, _ = ctx["run_id"].(string)
+	record.ParentID, _ = ctx["parent_run_id"].(string)
+	record.Agent, _ = ctx["agent_name"].(string)
+	record.User, _ = ctx["user_id"].(string)
+	record.Metadata, _ = ctx["metadata"].(map[string]string)
+	record.Tags, _ = ctx["tags"].([]string)
+
+	sig, err := sign(c.key, record)
+	if err != nil {
+		c.fail(fmt.Errorf("audit: failed to sign %s record: %w", event, err))
+		return
+	}
+	record.Sig = sig
+
+	line, err := json.Marshal(record)
+	if err != nil {
+		c.fail(fmt.Errorf("audit: failed to marshal %s record: %w", event, err))
+		return
+	}
+	if _, err := c.writer.Write(append(line, '\n')); err != nil {
+		c.fail(fmt.Errorf("audit: failed to write %s record: %w", event, err))
+		return
+	}
+
+	c.seq = record.Seq
+	c.prev = sig
+}
+
+// fail records the error of a lost record and hands it to the error handler
+func (c *Callback) fail(err error) {
+	if c.err == nil {
+		c.err = err
+	}
+	if c.report != nil {
+		c.report(err)
+	}
+}
+
+// sign computes the HMAC of the record with an empty signature field
+func sign(key []byte, record Record) (string, error) {
+	record.Sig = ""
+	payload, err := json.Marshal(record)
+	if err != nil {
+		return "", err
+	}
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// Verify reads an audit log and checks every signature and the integrity of the chain
+func Verify(r io.Reader, key []byte) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	prev := ""
+	var seq int64
+	for scanner.Scan() {
+		var record Record
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return fmt.Errorf("record %d is not valid JSON: %w", seq+1, err)
+		}
+
+		seq++
+		if record.Seq != seq {
+			return fmt.Errorf("record %d has sequence %d", seq, record.Seq)
+		}
+		if record.Prev != prev {
+			return fmt.Errorf("record %d breaks the chain", seq)
+		}
+
+		expected, err := sign(key, record)
+		if err != nil {
+			return err
+		}
+		if !hmac.Equal([]byte(expected), []byte(record.Sig)) {
+			return fmt.Errorf("record %d has an invalid signature", seq)
+		}
+
+		prev = record.Sig
+	}
+
+	return scanner.Err()
+}
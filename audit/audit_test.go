@@ -0,0 +1,74 @@
+package audit
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerify(t *testing.T) {
+	key := []byte("secret")
+	var log bytes.Buffer
+
+	cb := NewCallback(Config{Writer: &log, Key: key})
+	cb.OnRunStart(map[string]interface{}{"run_id": "r1", "user_id": "alice", "model": "gpt-4o"})
+	cb.OnToolCallEnd(map[string]interface{}{
+		"run_id":        "t1",
+		"parent_run_id": "r1",
+		"tool_name":     "send_email",
+		"arguments":     map[string]interface{}{"to": "bob@example.com"},
+	})
+	cb.OnRunEnd(map[string]interface{}{"run_id": "r1", "total_iterations": 2})
+
+	require.NoError(t, Verify(bytes.NewReader(log.Bytes()), key))
+	require.Error(t, Verify(bytes.NewReader(log.Bytes()), []byte("wrong")))
+
+	// Tampering with a record is detected
+	tampered := strings.Replace(log.String(), "bob@example.com", "eve@example.com", 1)
+	require.Error(t, Verify(strings.NewReader(tampered), key))
+
+	// Dropping a record breaks the chain
+	lines := strings.SplitAfter(log.String(), "\n")
+	require.Error(t, Verify(strings.NewReader(lines[0]+lines[2]), key))
+}
+
+// flakyWriter fails the writes while broken is set
+type flakyWriter struct {
+	bytes.Buffer
+	broken bool
+}
+
+func (w *flakyWriter) Write(p []byte) (int, error) {
+	if w.broken {
+		return 0, errors.New("no space left on device")
+	}
+	return w.Buffer.Write(p)
+}
+
+func TestWriteFailures(t *testing.T) {
+	key := []byte("secret")
+	writer := &flakyWriter{}
+	var reported []error
+
+	cb := NewCallback(Config{Writer: writer, Key: key, OnError: func(err error) { reported = append(reported, err) }})
+	cb.OnRunStart(map[string]interface{}{"run_id": "r1"})
+
+	// Failures are reported instead of crashing the run
+	writer.broken = true
+	require.NotPanics(t, func() { cb.OnRunEnd(map[string]interface{}{"run_id": "r1"}) })
+	writer.broken = false
+	cb.OnToolCallEnd(map[string]interface{}{"run_id": "r1", "arguments": map[string]interface{}{"bad": make(chan int)}})
+
+	require.Len(t, reported, 2)
+	require.ErrorContains(t, reported[0], "no space left on device")
+	require.ErrorContains(t, reported[1], "failed to sign tool_call record")
+	require.Equal(t, reported[0], cb.Err())
+
+	// The chain continues from the last record written
+	cb.OnRunEnd(map[string]interface{}{"run_id": "r1"})
+	require.NoError(t, Verify(bytes.NewReader(writer.Bytes()), key))
+	require.Equal(t, 2, strings.Count(writer.String(), "\n"))
+}
@@ -1,15 +1,23 @@
 package callback
 
+import (
+	"context"
+	"time"
+)
+
 // AgentCallback defines the interface for agent lifecycle callbacks
 // Similar to LangChain's callback system for observability and tracing
+// Every context also contains the run-level attributes (agent_name, user_id, metadata, tags) when they are set
+// Payload fields (see PayloadFields) have already been passed through the run's Redactor, if any
 type AgentCallback interface {
 	Name() string
 	// OnRunStart is called when the agent starts execution
-	// Context contains: model, input, has_output_class, run_id, parent_run_id
+	// Context contains: model, input, has_output_class, tools, run_id, parent_run_id
 	OnRunStart(ctx map[string]interface{})
 
 	// OnRunEnd is called when the agent completes execution
-	// Context contains: output, total_iterations, run_id, parent_run_id
+	// Context contains: output, total_iterations, usage (aggregated over all generations), cached_tokens,
+	// reasoning_tokens, run_id, parent_run_id
 	OnRunEnd(ctx map[string]interface{})
 
 	// OnGenerationStart is called before each LLM API call
@@ -17,7 +25,9 @@ type AgentCallback interface {
 	OnGenerationStart(ctx map[string]interface{})
 
 	// OnGenerationEnd is called after each LLM API call
-	// Context contains: finish_reason, content, tool_calls, usage, run_id, parent_run_id
+	// Context contains: finish_reason, content, tool_calls, usage, cached_tokens, reasoning_tokens, model,
+	// latency_ms, request_id, processing_ms, service_tier, rate_limits (*RateLimits), citations ([]Citation)
+	// and reasoning_content when reported by the provider, run_id, parent_run_id
 	OnGenerationEnd(ctx map[string]interface{})
 
 	// OnToolCallStart is called before tool execution
@@ -29,10 +39,105 @@ type AgentCallback interface {
 	OnToolCallEnd(ctx map[string]interface{})
 
 	// OnError is called when an error occurs
-	// Context contains: error, stage (run/generation/tool), run_id, parent_run_id
+	// Context contains: error, stage (run/quota/input_guard/generation/tool/reflection/output_guard/cancel),
+	// error_class, retryable, run_id, parent_run_id
 	OnError(ctx map[string]interface{})
 }
 
+// GenerationInfo describes the request behind a generation
+type GenerationInfo struct {
+	// Model that produced the generation, which differs from the agent's model after a fallback
+	Model string
+
+	// Latency is the wall-clock duration of the generation, retries included
+	Latency time.Duration
+
+	// RequestID is the provider's ID of the request (x-request-id header)
+	RequestID string
+
+	// ProcessingTime is the time the provider reports spending on the request (openai-processing-ms header)
+	ProcessingTime time.Duration
+
+	// RateLimits reported with the response, nil when the provider sent none
+	RateLimits *RateLimits
+
+	// ServiceTier that processed the request, when reported by the provider
+	ServiceTier string
+}
+
+// Citation is a source the provider cited in a generation, e.g. from web or file search
+type Citation struct {
+	// Type is the provider's annotation type, e.g. url_citation or file_citation
+	Type string `json:"type"`
+
+	URL      string `json:"url,omitempty"`
+	Title    string `json:"title,omitempty"`
+	FileID   string `json:"file_id,omitempty"`
+	Filename string `json:"filename,omitempty"`
+
+	// StartIndex and EndIndex delimit the cited text in the content, when reported
+	StartIndex int `json:"start_index,omitempty"`
+	EndIndex   int `json:"end_index,omitempty"`
+}
+
+// RateLimits is the provider's rate limit state from the x-ratelimit-* response headers.
+// Values the provider didn't report are zero.
+type RateLimits struct {
+	LimitRequests     int64
+	RemainingRequests int64
+	ResetRequests     time.Duration // until the request limit resets
+
+	LimitTokens     int64
+	RemainingTokens int64
+	ResetTokens     time.Duration // until the token limit resets
+
+	// ObservedAt is when the response carrying the headers was received
+	ObservedAt time.Time
+}
+
+// The callbacks below are optional: the Manager calls them on the callbacks implementing them,
+// so callbacks written against AgentCallback keep compiling as hooks are added. BaseCallback
+// implements all of them.
+
+// HistoryTrimCallback is implemented by callbacks observing OnHistoryTrim.
+// OnHistoryTrim is called when a trim strategy shortened the message history
+// Context contains: strategy, messages_before, messages_after, run_id, parent_run_id
+type HistoryTrimCallback interface {
+	OnHistoryTrim(ctx map[string]interface{})
+}
+
+// RetryCallback is implemented by callbacks observing OnRetry.
+// OnRetry is called when a failed step is retried, possibly with a fallback model
+// Context contains: stage (generation/output_guard/reflection/empty_response/context_overflow/refusal/
+// key_failover/tool/tool_retry), error, error_class, attempt (the failed attempt, starting at 1), backoff_ms, model
+// (used by the next attempt), run_id, parent_run_id
+type RetryCallback interface {
+	OnRetry(ctx map[string]interface{})
+}
+
+// ProgressCallback is implemented by callbacks observing OnProgress.
+// OnProgress is called at the start of every iteration and when a tool reports progress
+// Context contains: step, total_steps, percent (0-100), message, tool_name (for tool progress),
+// run_id, parent_run_id
+type ProgressCallback interface {
+	OnProgress(ctx map[string]interface{})
+}
+
+// OutputFieldCallback is implemented by callbacks observing OnOutputField.
+// OnOutputField is called in streamed runs when a top-level field of the structured output is
+// complete, before the whole object arrived. A retried generation reports its fields again.
+// Context contains: field, value (json.RawMessage), run_id, parent_run_id
+type OutputFieldCallback interface {
+	OnOutputField(ctx map[string]interface{})
+}
+
+// ContextAwareCallback is implemented by callbacks that need the context.Context of the run,
+// e.g. to make their spans children of the incoming request's span. SetRunContext is called
+// right before OnRunStart.
+type ContextAwareCallback interface {
+	SetRunContext(ctx context.Context)
+}
+
 // BaseCallback provides empty implementations for all callback methods
 // Embed this in your callback to only override methods you need
 type BaseCallback struct{}
@@ -43,4 +148,8 @@ func (b *BaseCallback) OnGenerationStart(ctx map[string]interface{}) {}
 func (b *BaseCallback) OnGenerationEnd(ctx map[string]interface{})   {}
 func (b *BaseCallback) OnToolCallStart(ctx map[string]interface{})   {}
 func (b *BaseCallback) OnToolCallEnd(ctx map[string]interface{})     {}
+func (b *BaseCallback) OnHistoryTrim(ctx map[string]interface{})     {}
+func (b *BaseCallback) OnRetry(ctx map[string]interface{})           {}
+func (b *BaseCallback) OnProgress(ctx map[string]interface{})        {}
+func (b *BaseCallback) OnOutputField(ctx map[string]interface{})     {}
 func (b *BaseCallback) OnError(ctx map[string]interface{})           {}
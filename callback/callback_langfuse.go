@@ -27,10 +27,17 @@ type LangfuseCallback struct {
 	// Context management - mimicking Python/PHP's attach/detach pattern
 	traceContext    context.Context
 	rootSpanContext context.Context
+	parentContext   context.Context
+	traceStarted    bool
 
 	// Configuration
-	serviceName string
-	traceID     string
+	serviceName    string
+	traceID        string
+	maxPayloadSize int
+	messageEvents  bool
+	maxMessageSize int
+	media          MediaUploader
+	mediaRefs      map[[32]byte]string // sha256 of uploaded files -> media reference
 }
 
 // LangfuseCallbackConfig configures the Langfuse callback with OTEL
@@ -44,8 +51,26 @@ type LangfuseCallbackConfig struct {
 	// TraceID allows reusing an existing trace (optional)
 	TraceID string
 
-	// ParentContext allows creating child callbacks (optional)
+	// ParentContext allows creating child callbacks (optional). Without it, the trace becomes
+	// a child of the span carried by the context passed to Invoke, if any
 	ParentContext context.Context
+
+	// MaxPayloadSize truncates input and output attributes to this many bytes, keeping spans
+	// within the collector's attribute limits (optional, unlimited by default)
+	MaxPayloadSize int
+
+	// MessageEvents records the messages of a generation as one span event each instead of a
+	// single input attribute, which then only holds the last message (optional)
+	MessageEvents bool
+
+	// MaxMessageSize truncates the content of each message event to this many bytes
+	// (optional, defaults to MaxPayloadSize)
+	MaxMessageSize int
+
+	// Media uploads the files of inputs and outputs, e.g. images and PDFs of kit.File, and
+	// references them in place of their base64 data URIs, e.g. NewLangfuseMedia. Without it the
+	// data URIs are replaced with a description of the file (optional)
+	Media MediaUploader
 }
 
 // NewLangfuseCallback creates a new Langfuse callback handler using OTEL
@@ -60,18 +85,45 @@ func NewLangfuseCallback(config LangfuseCallbackConfig) *LangfuseCallback {
 	}
 
 	lc := &LangfuseCallback{
-		tracer:      config.Tracer,
-		serviceName: serviceName,
-		traceID:     config.TraceID,
-		toolSpans:   make(map[string]trace.Span),
-	}
+		tracer:        config.Tracer,
+		serviceName:   serviceName,
+		traceID:       config.TraceID,
+		toolSpans:     make(map[string]trace.Span),
+		parentContext: config.ParentContext,
 
-	// Initialize trace span
-	lc.initializeTrace(config.TraceID, config.ParentContext)
+		maxPayloadSize: config.MaxPayloadSize,
+		messageEvents:  config.MessageEvents,
+		maxMessageSize: config.MaxMessageSize,
+		media:          config.Media,
+		mediaRefs:      make(map[[32]byte]string),
+	}
+	if lc.maxMessageSize == 0 {
+		lc.maxMessageSize = config.MaxPayloadSize
+	}
 
 	return lc
 }
 
+// SetRunContext adopts the span of the run's context as the parent of the trace, unless a
+// ParentContext was configured or the trace has already started
+func (lc *LangfuseCallback) SetRunContext(ctx context.Context) {
+	if lc.traceStarted || lc.parentContext != nil {
+		return
+	}
+	if trace.SpanContextFromContext(ctx).IsValid() {
+		lc.parentContext = ctx
+	}
+}
+
+// startTrace creates the trace span on first use, so the run's context can still provide the parent
+func (lc *LangfuseCallback) startTrace() {
+	if lc.traceStarted {
+		return
+	}
+	lc.traceStarted = true
+	lc.initializeTrace(lc.traceID, lc.parentContext)
+}
+
 // initializeTrace creates the trace span for this execution
 // Mimics Python's context attachment pattern
 func (lc *LangfuseCallback) initializeTrace(traceID string, parentContext context.Context) {
@@ -102,6 +154,8 @@ func (lc *LangfuseCallback) Name() string {
 
 // OnRunStart creates a root span for the agent run
 func (lc *LangfuseCallback) OnRunStart(ctx map[string]interface{}) {
+	lc.startTrace()
+
 	runID := ctx["run_id"].(string)
 	parentRunID := lc.getParentRunID(ctx)
 
@@ -122,10 +176,7 @@ func (lc *LangfuseCallback) OnRunStart(ctx map[string]interface{}) {
 		}
 
 		if input := ctx["input"]; input != nil {
-			inputJSON, _ := json.Marshal(input)
-			lc.rootSpan.SetAttributes(
-				attribute.String("langfuse.observation.input", string(inputJSON)),
-			)
+			lc.rootSpan.SetAttributes(lc.payload("langfuse.observation.input", input))
 		}
 
 		if hasOutputClass, ok := ctx["has_output_class"].(bool); ok && hasOutputClass {
@@ -135,6 +186,17 @@ func (lc *LangfuseCallback) OnRunStart(ctx map[string]interface{}) {
 		}
 
 		lc.rootSpan.SetAttributes(attribute.String("run_id", runID))
+		if userID, ok := ctx["user_id"].(string); ok && userID != "" {
+			lc.rootSpan.SetAttributes(attribute.String("langfuse.user.id", userID))
+			if lc.traceSpan != nil {
+				lc.traceSpan.SetAttributes(attribute.String("langfuse.user.id", userID))
+			}
+		}
+		lc.rootSpan.SetAttributes(runAttributes(ctx, "langfuse.trace")...)
+		lc.rootSpan.SetAttributes(runAttributes(ctx, "langfuse.observation")...)
+		if lc.traceSpan != nil {
+			lc.traceSpan.SetAttributes(runAttributes(ctx, "langfuse.trace")...)
+		}
 	}
 }
 
@@ -146,10 +208,7 @@ func (lc *LangfuseCallback) OnRunEnd(ctx map[string]interface{}) {
 
 	// Set output
 	if output := ctx["output"]; output != nil {
-		outputJSON, _ := json.Marshal(output)
-		lc.rootSpan.SetAttributes(
-			attribute.String("langfuse.observation.output", string(outputJSON)),
-		)
+		lc.rootSpan.SetAttributes(lc.payload("langfuse.observation.output", output))
 	}
 
 	// Set total iterations
@@ -159,6 +218,17 @@ func (lc *LangfuseCallback) OnRunEnd(ctx map[string]interface{}) {
 		)
 	}
 
+	// Set usage aggregated over all generations
+	if u, ok := ctx["usage"].(*openai.CompletionUsage); ok && u != nil {
+		lc.rootSpan.SetAttributes(
+			attribute.Int64("total_usage.prompt_tokens", u.PromptTokens),
+			attribute.Int64("total_usage.completion_tokens", u.CompletionTokens),
+			attribute.Int64("total_usage.total_tokens", u.TotalTokens),
+			attribute.Int64("total_usage.cached_tokens", u.PromptTokensDetails.CachedTokens),
+			attribute.Int64("total_usage.reasoning_tokens", u.CompletionTokensDetails.ReasoningTokens),
+		)
+	}
+
 	lc.rootSpan.SetStatus(codes.Ok, "")
 	lc.rootSpan.End()
 
@@ -185,6 +255,8 @@ func (lc *LangfuseCallback) OnGenerationStart(ctx map[string]interface{}) {
 	lc.currentGenerationSpan = span
 	_ = spanCtx // We don't need to store this as we're not creating nested children
 
+	span.SetAttributes(runAttributes(ctx, "langfuse.observation")...)
+
 	// Set attributes
 	if model, ok := ctx["model"].(string); ok {
 		span.SetAttributes(
@@ -198,10 +270,7 @@ func (lc *LangfuseCallback) OnGenerationStart(ctx map[string]interface{}) {
 	}
 
 	if messages := ctx["messages"]; messages != nil {
-		messagesJSON, _ := json.Marshal(messages)
-		span.SetAttributes(
-			attribute.String("langfuse.observation.input", string(messagesJSON)),
-		)
+		lc.messagesPayload(span, messages)
 	}
 }
 
@@ -218,6 +287,26 @@ func (lc *LangfuseCallback) OnGenerationEnd(ctx map[string]interface{}) {
 		)
 	}
 
+	// Set request details for debugging slow calls
+	if model, ok := ctx["model"].(string); ok && model != "" {
+		lc.currentGenerationSpan.SetAttributes(attribute.String("gen_ai.response.model", model))
+	}
+	if latency, ok := ctx["latency_ms"].(int64); ok {
+		lc.currentGenerationSpan.SetAttributes(attribute.Int64("latency_ms", latency))
+	}
+	if requestID, ok := ctx["request_id"].(string); ok {
+		lc.currentGenerationSpan.SetAttributes(attribute.String("request_id", requestID))
+	}
+	if processing, ok := ctx["processing_ms"].(int64); ok {
+		lc.currentGenerationSpan.SetAttributes(attribute.Int64("processing_ms", processing))
+	}
+	if limits, ok := ctx["rate_limits"].(*RateLimits); ok && limits != nil {
+		lc.currentGenerationSpan.SetAttributes(
+			attribute.Int64("ratelimit.remaining_requests", limits.RemainingRequests),
+			attribute.Int64("ratelimit.remaining_tokens", limits.RemainingTokens),
+		)
+	}
+
 	// Build complete output including tool calls if present
 	output := make(map[string]interface{})
 
@@ -248,11 +337,15 @@ func (lc *LangfuseCallback) OnGenerationEnd(ctx map[string]interface{}) {
 		}
 	}
 
+	if citations, ok := ctx["citations"].([]Citation); ok && len(citations) > 0 {
+		output["citations"] = citations
+	}
+	if reasoning, ok := ctx["reasoning_content"].(string); ok && reasoning != "" {
+		output["reasoning_content"] = reasoning
+	}
+
 	// Set output
-	outputJSON, _ := json.Marshal(output)
-	lc.currentGenerationSpan.SetAttributes(
-		attribute.String("langfuse.observation.output", string(outputJSON)),
-	)
+	lc.currentGenerationSpan.SetAttributes(lc.payload("langfuse.observation.output", output))
 
 	// Add usage information if available
 	if usage := ctx["usage"]; usage != nil {
@@ -262,6 +355,13 @@ func (lc *LangfuseCallback) OnGenerationEnd(ctx map[string]interface{}) {
 				"completion_tokens": int(u.CompletionTokens),
 				"total_tokens":      int(u.TotalTokens),
 			}
+			// Langfuse prices these keys separately from the plain input/output tokens
+			if cached := u.PromptTokensDetails.CachedTokens; cached > 0 {
+				usageDetails["input_cached_tokens"] = int(cached)
+			}
+			if reasoning := u.CompletionTokensDetails.ReasoningTokens; reasoning > 0 {
+				usageDetails["output_reasoning_tokens"] = int(reasoning)
+			}
 			usageJSON, _ := json.Marshal(usageDetails)
 			lc.currentGenerationSpan.SetAttributes(
 				attribute.String("langfuse.observation.usage_details", string(usageJSON)),
@@ -294,12 +394,10 @@ func (lc *LangfuseCallback) OnToolCallStart(ctx map[string]interface{}) {
 		attribute.String("tool.name", toolName),
 		attribute.String("tool_call_id", toolCallID),
 	)
+	toolSpan.SetAttributes(runAttributes(ctx, "langfuse.observation")...)
 
 	if arguments := ctx["arguments"]; arguments != nil {
-		argsJSON, _ := json.Marshal(arguments)
-		toolSpan.SetAttributes(
-			attribute.String("langfuse.observation.input", string(argsJSON)),
-		)
+		toolSpan.SetAttributes(lc.payload("langfuse.observation.input", arguments))
 	}
 
 	lc.toolSpans[toolCallID] = toolSpan
@@ -319,10 +417,7 @@ func (lc *LangfuseCallback) OnToolCallEnd(ctx map[string]interface{}) {
 
 	// Set output
 	if result := ctx["result"]; result != nil {
-		resultJSON, _ := json.Marshal(result)
-		toolSpan.SetAttributes(
-			attribute.String("langfuse.observation.output", string(resultJSON)),
-		)
+		toolSpan.SetAttributes(lc.payload("langfuse.observation.output", result))
 	}
 
 	// Check for error
@@ -338,11 +433,49 @@ func (lc *LangfuseCallback) OnToolCallEnd(ctx map[string]interface{}) {
 	delete(lc.toolSpans, toolCallID)
 }
 
-// OnError handles errors by ending all open spans
+// OnRetry records the retry as an event on the root span
+func (lc *LangfuseCallback) OnRetry(ctx map[string]interface{}) {
+	if lc.rootSpan == nil {
+		return
+	}
+
+	stage, _ := ctx["stage"].(string)
+	errMsg, _ := ctx["error"].(string)
+	errorClass, _ := ctx["error_class"].(string)
+	attempt, _ := ctx["attempt"].(int)
+	backoff, _ := ctx["backoff_ms"].(int64)
+	model, _ := ctx["model"].(string)
+
+	lc.rootSpan.AddEvent("retry", trace.WithAttributes(
+		attribute.String("stage", stage),
+		attribute.String("error", errMsg),
+		attribute.String("error_class", errorClass),
+		attribute.Int("attempt", attempt),
+		attribute.Int64("backoff_ms", backoff),
+		attribute.String("model", model),
+	))
+}
+
+// OnError handles errors by ending all open spans, tagged with the error class; the spans
+// of a cancelled run are marked cancelled
 func (lc *LangfuseCallback) OnError(ctx map[string]interface{}) {
 	errMsg, _ := ctx["error"].(string)
 	err := fmt.Errorf("%s", errMsg)
 
+	stage, _ := ctx["stage"].(string)
+	errorClass, _ := ctx["error_class"].(string)
+	for _, span := range []trace.Span{lc.rootSpan, lc.traceSpan} {
+		if span == nil {
+			continue
+		}
+		if stage == "cancel" {
+			span.SetAttributes(attribute.Bool("cancelled", true))
+		}
+		if errorClass != "" {
+			span.SetAttributes(attribute.String("error_class", errorClass))
+		}
+	}
+
 	// End current generation span with error
 	if lc.currentGenerationSpan != nil {
 		lc.currentGenerationSpan.RecordError(err)
@@ -378,6 +511,20 @@ func (lc *LangfuseCallback) OnError(ctx map[string]interface{}) {
 
 // Helper methods
 
+// runAttributes converts the run metadata and tags into span attributes under prefix
+func runAttributes(ctx map[string]interface{}, prefix string) []attribute.KeyValue {
+	var attrs []attribute.KeyValue
+	if metadata, ok := ctx["metadata"].(map[string]string); ok {
+		for key, value := range metadata {
+			attrs = append(attrs, attribute.String(prefix+".metadata."+key, value))
+		}
+	}
+	if tags, ok := ctx["tags"].([]string); ok && len(tags) > 0 {
+		attrs = append(attrs, attribute.StringSlice(prefix+".tags", tags))
+	}
+	return attrs
+}
+
 // getParentRunID extracts parent_run_id from context
 func (lc *LangfuseCallback) getParentRunID(ctx map[string]interface{}) string {
 	if parentID, exists := ctx["parent_run_id"]; exists && parentID != nil {
@@ -386,18 +533,22 @@ func (lc *LangfuseCallback) getParentRunID(ctx map[string]interface{}) string {
 	return ""
 }
 
-// GetTraceContext returns the current trace context for creating child callbacks
+// GetTraceContext returns the current trace context for creating child callbacks.
+// Calling it before the run starts the trace without the run context's parent span.
 func (lc *LangfuseCallback) GetTraceContext() context.Context {
+	lc.startTrace()
 	return lc.traceContext
 }
 
 // GetTraceID returns the current trace ID
 func (lc *LangfuseCallback) GetTraceID() string {
+	lc.startTrace()
 	return lc.traceID
 }
 
 // GetTraceURL returns the URL to view the trace in Langfuse
 func (lc *LangfuseCallback) GetTraceURL(langfuseHost string) string {
+	lc.startTrace()
 	if lc.traceID == "" {
 		return ""
 	}
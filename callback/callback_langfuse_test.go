@@ -0,0 +1,74 @@
+package callback
+
+import (
+	"context"
+	"testing"
+
+	"github.com/openai/openai-go"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestLangfuseCallbackUsesRunContextSpan(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	tracer := provider.Tracer("test")
+
+	requestCtx, requestSpan := tracer.Start(context.Background(), "http.request")
+
+	lc := NewLangfuseCallback(LangfuseCallbackConfig{Tracer: tracer})
+	manager := NewManager([]AgentCallback{lc}, nil)
+	manager.SetContext(requestCtx)
+	manager.OnRunStart("gpt-4o", "hi", false, nil)
+	manager.OnRunEnd("hello", 1, nil)
+	requestSpan.End()
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 3)
+
+	parents := make(map[string]string)
+	for _, span := range spans {
+		parents[span.Name] = span.Parent.SpanID().String()
+		require.Equal(t, requestSpan.SpanContext().TraceID(), span.SpanContext.TraceID())
+	}
+	require.Equal(t, requestSpan.SpanContext().SpanID().String(), parents["trace"])
+	require.Equal(t, requestSpan.SpanContext().TraceID().String(), lc.GetTraceID())
+}
+
+func TestLangfuseCallbackMessageEvents(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	lc := NewLangfuseCallback(LangfuseCallbackConfig{
+		Tracer:         provider.Tracer("test"),
+		ParentContext:  context.Background(),
+		MaxPayloadSize: 64,
+		MessageEvents:  true,
+		MaxMessageSize: 10,
+	})
+	manager := NewManager([]AgentCallback{lc}, nil)
+	manager.OnRunStart("gpt-4o", "hi", false, nil)
+	manager.OnGenerationStart(1, []openai.ChatCompletionMessageParamUnion{
+		openai.SystemMessage("You are a helpful assistant"),
+		openai.UserMessage("hi"),
+	}, "gpt-4o")
+	manager.OnGenerationEnd("stop", "hello", "", nil, nil, &openai.CompletionUsage{}, GenerationInfo{})
+	manager.OnRunEnd("hello", 1, nil)
+
+	var generation tracetest.SpanStub
+	for _, span := range exporter.GetSpans() {
+		if span.Name == "llm.generation" {
+			generation = span
+		}
+	}
+	require.Len(t, generation.Events, 2)
+	require.Equal(t, "gen_ai.system.message", generation.Events[0].Name)
+	require.Contains(t, generation.Events[0].Attributes, attribute.String("content", "You are a …[truncated 17 bytes]"))
+	require.Equal(t, "gen_ai.user.message", generation.Events[1].Name)
+	require.Contains(t, generation.Events[1].Attributes, attribute.String("content", "hi"))
+
+	require.Contains(t, generation.Attributes, attribute.Int("gen_ai.request.message_count", 2))
+	require.Contains(t, generation.Attributes, attribute.String("langfuse.observation.input", `[{"content":"hi","role":"user"}]`))
+}
@@ -0,0 +1,241 @@
+package callback
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// ChangeKind is how a message differs between two requests
+type ChangeKind string
+
+const (
+	ChangeAdded   ChangeKind = "added"
+	ChangeRemoved ChangeKind = "removed"
+	ChangeChanged ChangeKind = "changed"
+)
+
+// HistoryChange is a message added, removed or changed between two requests
+type HistoryChange struct {
+	Kind ChangeKind `json:"kind"`
+
+	// Index of the message in the later request, or in the earlier one for removed messages
+	Index int `json:"index"`
+
+	Role       string `json:"role"`
+	ToolCallID string `json:"tool_call_id,omitempty"`
+
+	// Before and After are the message as JSON in the earlier and the later request
+	Before json.RawMessage `json:"before,omitempty"`
+	After  json.RawMessage `json:"after,omitempty"`
+}
+
+// HistoryDiff is what changed in the messages of a run between two requests
+type HistoryDiff struct {
+	// MessagesBefore and MessagesAfter are the number of messages of the two requests
+	MessagesBefore int `json:"messages_before"`
+	MessagesAfter  int `json:"messages_after"`
+
+	// Changes in the order of the later request, removed messages where they used to be
+	Changes []HistoryChange `json:"changes"`
+}
+
+// Truncated reports whether messages were dropped or rewritten, e.g. by a trim strategy or a
+// message transformer, rather than only appended
+func (d HistoryDiff) Truncated() bool {
+	for _, change := range d.Changes {
+		if change.Kind != ChangeAdded {
+			return true
+		}
+	}
+	return false
+}
+
+// String renders the diff one change per line, prefixed with +, - or ~
+func (d HistoryDiff) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d -> %d messages\n", d.MessagesBefore, d.MessagesAfter)
+	for _, change := range d.Changes {
+		symbol := map[ChangeKind]string{ChangeAdded: "+", ChangeRemoved: "-", ChangeChanged: "~"}[change.Kind]
+		role := change.Role
+		if change.ToolCallID != "" {
+			role += "(" + change.ToolCallID + ")"
+		}
+
+		message := change.After
+		if change.Kind == ChangeRemoved {
+			message = change.Before
+		}
+		fmt.Fprintf(&b, "%s [%d] %s: %s\n", symbol, change.Index, role, previewMessage(message))
+	}
+	return b.String()
+}
+
+// DiffHistory computes the changes from the messages of one request to those of a later one.
+// The messages may be of any type encoding to a JSON array of chat messages, such as
+// []openai.ChatCompletionMessageParamUnion or the redacted messages of OnGenerationStart.
+func DiffHistory(before, after interface{}) HistoryDiff {
+	from, to := rawMessages(before), rawMessages(after)
+	diff := HistoryDiff{MessagesBefore: len(from), MessagesAfter: len(to), Changes: []HistoryChange{}}
+
+	// Longest common subsequence of the two lists, common[i][j] being that of from[i:] and to[j:]
+	common := make([][]int, len(from)+1)
+	for i := range common {
+		common[i] = make([]int, len(to)+1)
+	}
+	for i := len(from) - 1; i >= 0; i-- {
+		for j := len(to) - 1; j >= 0; j-- {
+			if bytes.Equal(from[i], to[j]) {
+				common[i][j] = common[i+1][j+1] + 1
+			} else {
+				common[i][j] = max(common[i+1][j], common[i][j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(from) || j < len(to) {
+		switch {
+		case i < len(from) && j < len(to) && bytes.Equal(from[i], to[j]):
+			i++
+			j++
+		case i < len(from) && (j == len(to) || common[i+1][j] >= common[i][j+1]):
+			diff.add(historyChange(ChangeRemoved, i, from[i], nil))
+			i++
+		default:
+			diff.add(historyChange(ChangeAdded, j, nil, to[j]))
+			j++
+		}
+	}
+	return diff
+}
+
+// add appends a change, merging a message removed and added back in the same place with the
+// same role into a changed message
+func (d *HistoryDiff) add(change HistoryChange) {
+	if n := len(d.Changes); n > 0 && change.Kind == ChangeAdded {
+		last := &d.Changes[n-1]
+		if last.Kind == ChangeRemoved && last.Role == change.Role && last.ToolCallID == change.ToolCallID {
+			last.Kind = ChangeChanged
+			last.Index = change.Index
+			last.After = change.After
+			return
+		}
+	}
+	d.Changes = append(d.Changes, change)
+}
+
+func historyChange(kind ChangeKind, index int, before, after json.RawMessage) HistoryChange {
+	message := after
+	if message == nil {
+		message = before
+	}
+
+	var fields struct {
+		Role       string `json:"role"`
+		ToolCallID string `json:"tool_call_id"`
+	}
+	_ = json.Unmarshal(message, &fields)
+	return HistoryChange{
+		Kind:       kind,
+		Index:      index,
+		Role:       fields.Role,
+		ToolCallID: fields.ToolCallID,
+		Before:     before,
+		After:      after,
+	}
+}
+
+// rawMessages encodes the messages as a list of JSON messages
+func rawMessages(messages interface{}) []json.RawMessage {
+	if messages == nil {
+		return nil
+	}
+	data, _ := json.Marshal(messages)
+	var raw []json.RawMessage
+	_ = json.Unmarshal(data, &raw)
+	return raw
+}
+
+// previewMessage returns the beginning of the content of a message, or of its tool calls
+func previewMessage(message json.RawMessage) string {
+	var fields struct {
+		Content   json.RawMessage `json:"content"`
+		ToolCalls json.RawMessage `json:"tool_calls"`
+	}
+	_ = json.Unmarshal(message, &fields)
+
+	preview := string(fields.Content)
+	var text string
+	if json.Unmarshal(fields.Content, &text) == nil {
+		preview = text
+	}
+	if preview == "" || preview == "null" {
+		preview = string(fields.ToolCalls)
+	}
+
+	preview = strings.Join(strings.Fields(preview), " ")
+	if runes := []rune(preview); len(runes) > 80 {
+		preview = string(runes[:80]) + "..."
+	}
+	return preview
+}
+
+// HistoryDiffCallback reports what changed in the messages sent to the model between the
+// generations of every run, to explain why the model behaved differently on a later
+// iteration. It is safe to share across agents and concurrent runs.
+type HistoryDiffCallback struct {
+	BaseCallback
+
+	report func(runID string, iteration int, diff HistoryDiff)
+
+	mu   sync.Mutex
+	last map[string][]json.RawMessage // run_id -> messages of the last generation
+}
+
+// NewHistoryDiffCallback creates a callback calling report with the diff of every generation
+// from the previous generation of the same run
+func NewHistoryDiffCallback(report func(runID string, iteration int, diff HistoryDiff)) *HistoryDiffCallback {
+	return &HistoryDiffCallback{
+		report: report,
+		last:   make(map[string][]json.RawMessage),
+	}
+}
+
+func (h *HistoryDiffCallback) Name() string { return "history_diff" }
+
+func (h *HistoryDiffCallback) OnGenerationStart(ctx map[string]interface{}) {
+	runID, _ := ctx["run_id"].(string)
+	iteration, _ := ctx["iteration"].(int)
+	messages := rawMessages(ctx["messages"])
+
+	h.mu.Lock()
+	previous, ok := h.last[runID]
+	h.last[runID] = messages
+	h.mu.Unlock()
+
+	if ok {
+		h.report(runID, iteration, DiffHistory(previous, messages))
+	}
+}
+
+func (h *HistoryDiffCallback) OnRunEnd(ctx map[string]interface{}) {
+	h.forget(ctx)
+}
+
+func (h *HistoryDiffCallback) OnError(ctx map[string]interface{}) {
+	if stage, _ := ctx["stage"].(string); terminalStages[stage] {
+		h.forget(ctx)
+	}
+}
+
+// forget drops the messages of a finished run
+func (h *HistoryDiffCallback) forget(ctx map[string]interface{}) {
+	runID, _ := ctx["run_id"].(string)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.last, runID)
+}
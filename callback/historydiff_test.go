@@ -0,0 +1,59 @@
+package callback
+
+import (
+	"testing"
+
+	"github.com/openai/openai-go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffHistory(t *testing.T) {
+	toolCall := openai.ChatCompletionMessageParamUnion{OfAssistant: &openai.ChatCompletionAssistantMessageParam{
+		ToolCalls: []openai.ChatCompletionMessageToolCallParam{{
+			ID:       "call_1",
+			Function: openai.ChatCompletionMessageToolCallFunctionParam{Name: "lookup", Arguments: `{"q":"go"}`},
+		}},
+	}}
+	first := []openai.ChatCompletionMessageParamUnion{openai.SystemMessage("Be brief."), openai.UserMessage("hi")}
+	second := append(first[:2:2], toolCall, openai.ToolMessage("found a long result", "call_1"))
+
+	// Appended tool results
+	diff := DiffHistory(first, second)
+	require.Equal(t, 2, diff.MessagesBefore)
+	require.Equal(t, 4, diff.MessagesAfter)
+	require.False(t, diff.Truncated())
+	require.Len(t, diff.Changes, 2)
+	require.Equal(t, HistoryChange{Kind: ChangeAdded, Index: 2, Role: "assistant", After: diff.Changes[0].After}, diff.Changes[0])
+	require.Equal(t, "call_1", diff.Changes[1].ToolCallID)
+
+	// Trimmed messages and a shortened tool result
+	third := []openai.ChatCompletionMessageParamUnion{
+		openai.SystemMessage("Be brief."),
+		openai.ToolMessage("found...", "call_1"),
+		openai.UserMessage("thanks"),
+	}
+	diff = DiffHistory(second, third)
+	require.True(t, diff.Truncated())
+	kinds := make([]ChangeKind, 0, len(diff.Changes))
+	for _, change := range diff.Changes {
+		kinds = append(kinds, change.Kind)
+	}
+	require.Equal(t, []ChangeKind{ChangeRemoved, ChangeRemoved, ChangeChanged, ChangeAdded}, kinds)
+	require.Equal(t, 1, diff.Changes[2].Index)
+	rendered := diff.String()
+	require.Contains(t, rendered, "4 -> 3 messages\n- [1] user: hi\n- [2] assistant: [{")
+	require.Contains(t, rendered, "\n~ [1] tool(call_1): found...\n+ [2] user: thanks\n")
+
+	// The callback reports the diff of every generation from the previous one of the run
+	var diffs []HistoryDiff
+	manager := NewManager([]AgentCallback{NewHistoryDiffCallback(func(runID string, iteration int, diff HistoryDiff) {
+		require.Equal(t, 2, iteration)
+		diffs = append(diffs, diff)
+	})}, nil)
+	manager.OnRunStart("gpt-4o", "hi", false, nil)
+	manager.OnGenerationStart(1, first, "gpt-4o")
+	manager.OnGenerationStart(2, second, "gpt-4o")
+	manager.OnRunEnd("found", 2, &openai.CompletionUsage{})
+	require.Len(t, diffs, 1)
+	require.Len(t, diffs[0].Changes, 2)
+}
@@ -0,0 +1,188 @@
+package callback
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// dataURIPattern matches the base64 data URIs of files, e.g. those of kit.File, in JSON payloads
+var dataURIPattern = regexp.MustCompile(`data:([a-zA-Z0-9.+-]+/[a-zA-Z0-9.+-]+);base64,([A-Za-z0-9+/]+=*)`)
+
+// Media is a file of a traced payload
+type Media struct {
+	TraceID     string
+	ContentType string
+	Data        []byte
+
+	// Field of the observation holding the file: input, output or metadata
+	Field string
+}
+
+// MediaUploader stores the files of traced payloads outside the spans and returns the reference
+// replacing their data URI
+type MediaUploader interface {
+	Upload(ctx context.Context, media Media) (string, error)
+}
+
+// LangfuseMedia uploads files through the media API of Langfuse, which renders the references
+// it returns in place of the files in the trace's input and output
+type LangfuseMedia struct {
+	host       string
+	publicKey  string
+	secretKey  string
+	httpClient *http.Client
+}
+
+// NewLangfuseMedia creates a media uploader for the Langfuse project of the keys. Host is the
+// Langfuse host, e.g. "cloud.langfuse.com", "https://" being assumed when it has no scheme.
+func NewLangfuseMedia(host, publicKey, secretKey string) *LangfuseMedia {
+	if !strings.Contains(host, "://") {
+		host = "https://" + host
+	}
+	return &LangfuseMedia{
+		host:       strings.TrimSuffix(host, "/"),
+		publicKey:  publicKey,
+		secretKey:  secretKey,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Upload registers the file with Langfuse, uploads it unless Langfuse already has it and
+// returns its media reference
+func (m *LangfuseMedia) Upload(ctx context.Context, media Media) (string, error) {
+	sum := sha256.Sum256(media.Data)
+	hash := base64.StdEncoding.EncodeToString(sum[:])
+
+	var created struct {
+		MediaID   string `json:"mediaId"`
+		UploadURL string `json:"uploadUrl"`
+	}
+	err := m.call(ctx, http.MethodPost, "/api/public/media", map[string]interface{}{
+		"traceId":       media.TraceID,
+		"contentType":   media.ContentType,
+		"contentLength": len(media.Data),
+		"sha256Hash":    hash,
+		"field":         media.Field,
+	}, &created)
+	if err != nil {
+		return "", fmt.Errorf("failed to create media: %w", err)
+	}
+
+	// Without an upload URL Langfuse already stores the same file
+	if created.UploadURL != "" {
+		if err := m.upload(ctx, created.MediaID, created.UploadURL, media.ContentType, hash, media.Data); err != nil {
+			return "", err
+		}
+	}
+
+	return fmt.Sprintf("@@@langfuseMedia:type=%s|id=%s|source=base64_data_uri@@@", media.ContentType, created.MediaID), nil
+}
+
+// upload puts the file to its upload URL and reports the outcome back to Langfuse
+func (m *LangfuseMedia) upload(ctx context.Context, mediaID, uploadURL, contentType, hash string, data []byte) error {
+	startedAt := time.Now()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, uploadURL, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create upload request: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("x-amz-checksum-sha256", hash)
+
+	status := map[string]interface{}{}
+	resp, uploadErr := m.httpClient.Do(req)
+	if uploadErr == nil {
+		_ = resp.Body.Close()
+		status["uploadHttpStatus"] = resp.StatusCode
+		if resp.StatusCode >= 300 {
+			uploadErr = fmt.Errorf("upload returned status %d", resp.StatusCode)
+			status["uploadHttpError"] = uploadErr.Error()
+		}
+	} else {
+		status["uploadHttpStatus"] = 0
+		status["uploadHttpError"] = uploadErr.Error()
+	}
+	status["uploadedAt"] = time.Now().UTC().Format(time.RFC3339Nano)
+	status["uploadTimeMs"] = time.Since(startedAt).Milliseconds()
+
+	if err := m.call(ctx, http.MethodPatch, "/api/public/media/"+mediaID, status, nil); err != nil {
+		return fmt.Errorf("failed to report media upload: %w", err)
+	}
+	if uploadErr != nil {
+		return fmt.Errorf("failed to upload media: %w", uploadErr)
+	}
+	return nil
+}
+
+// call sends a JSON request to the public API of Langfuse, decoding the response into out
+func (m *LangfuseMedia) call(ctx context.Context, method, path string, body, out interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, m.host+path, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(m.publicKey, m.secretKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := m.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("langfuse returned status %d", resp.StatusCode)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// externalizeMedia replaces the data URIs in a JSON payload of the field with references to
+// the uploaded files or, without an uploader or when the upload fails, with a description of
+// the file, so spans don't carry the files themselves
+func (lc *LangfuseCallback) externalizeMedia(data []byte, field string) []byte {
+	if !bytes.Contains(data, []byte(";base64,")) {
+		return data
+	}
+
+	return dataURIPattern.ReplaceAllFunc(data, func(uri []byte) []byte {
+		match := dataURIPattern.FindSubmatch(uri)
+		contentType := string(match[1])
+		decoded, err := base64.StdEncoding.DecodeString(string(match[2]))
+		if err != nil {
+			return uri
+		}
+
+		// Every generation resends the files of the history, which are uploaded once per trace
+		sum := sha256.Sum256(decoded)
+		if reference, ok := lc.mediaRefs[sum]; ok {
+			return []byte(reference)
+		}
+		if lc.media != nil {
+			reference, err := lc.media.Upload(context.Background(), Media{
+				TraceID:     lc.traceID,
+				ContentType: contentType,
+				Data:        decoded,
+				Field:       field,
+			})
+			if err == nil {
+				lc.mediaRefs[sum] = reference
+				return []byte(reference)
+			}
+		}
+
+		return []byte(fmt.Sprintf("[%s, %d bytes, sha256:%x]", contentType, len(decoded), sum[:8]))
+	})
+}
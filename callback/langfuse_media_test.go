@@ -0,0 +1,125 @@
+package callback
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/openai/openai-go"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestLangfuseMediaUpload(t *testing.T) {
+	image := []byte("\x89PNG fake image")
+	dataURI := "data:image/png;base64," + base64.StdEncoding.EncodeToString(image)
+
+	var (
+		mu       sync.Mutex
+		created  []map[string]interface{}
+		uploaded [][]byte
+		patched  []map[string]interface{}
+	)
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/api/public/media":
+			user, _, _ := r.BasicAuth()
+			require.Equal(t, "pk", user)
+			var body map[string]interface{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			created = append(created, body)
+			_, _ = w.Write([]byte(`{"mediaId":"media-1","uploadUrl":"` + server.URL + `/upload"}`))
+		case r.Method == http.MethodPut && r.URL.Path == "/upload":
+			data, _ := io.ReadAll(r.Body)
+			uploaded = append(uploaded, data)
+		case r.Method == http.MethodPatch && r.URL.Path == "/api/public/media/media-1":
+			var body map[string]interface{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			patched = append(patched, body)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	lc := NewLangfuseCallback(LangfuseCallbackConfig{
+		Tracer:        provider.Tracer("test"),
+		ParentContext: context.Background(),
+		Media:         NewLangfuseMedia(server.URL, "pk", "sk"),
+	})
+
+	messages := []openai.ChatCompletionMessageParamUnion{
+		openai.UserMessage([]openai.ChatCompletionContentPartUnionParam{
+			openai.TextContentPart("what is this?"),
+			openai.ImageContentPart(openai.ChatCompletionContentPartImageImageURLParam{URL: dataURI}),
+		}),
+	}
+	manager := NewManager([]AgentCallback{lc}, nil)
+	manager.OnRunStart("gpt-4o", "hi", false, nil)
+	manager.OnGenerationStart(1, messages, "gpt-4o")
+	manager.OnGenerationEnd("stop", "a cat", "", nil, nil, &openai.CompletionUsage{}, GenerationInfo{})
+	manager.OnGenerationStart(2, messages, "gpt-4o")
+	manager.OnGenerationEnd("stop", "a cat", "", nil, nil, &openai.CompletionUsage{}, GenerationInfo{})
+	manager.OnRunEnd("a cat", 2, nil)
+
+	// The file is uploaded once although every generation sends it
+	require.Len(t, created, 1)
+	require.Equal(t, "image/png", created[0]["contentType"])
+	require.Equal(t, "input", created[0]["field"])
+	require.Equal(t, lc.GetTraceID(), created[0]["traceId"])
+	require.Equal(t, [][]byte{image}, uploaded)
+	require.Len(t, patched, 1)
+	require.EqualValues(t, http.StatusOK, patched[0]["uploadHttpStatus"])
+
+	generations := 0
+	for _, span := range exporter.GetSpans() {
+		for _, attr := range span.Attributes {
+			require.NotContains(t, attr.Value.Emit(), ";base64,")
+		}
+		if span.Name == "llm.generation" {
+			generations++
+			input := attributeValue(span, "langfuse.observation.input")
+			require.Contains(t, input, "@@@langfuseMedia:type=image/png|id=media-1|source=base64_data_uri@@@")
+		}
+	}
+	require.Equal(t, 2, generations)
+}
+
+func TestLangfuseMediaWithoutUploader(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	lc := NewLangfuseCallback(LangfuseCallbackConfig{Tracer: provider.Tracer("test"), ParentContext: context.Background()})
+
+	pdf := "data:application/pdf;base64," + base64.StdEncoding.EncodeToString([]byte(strings.Repeat("%PDF", 100)))
+	manager := NewManager([]AgentCallback{lc}, nil)
+	manager.OnRunStart("gpt-4o", map[string]string{"file": pdf}, false, nil)
+	manager.OnRunEnd("done", 1, nil)
+
+	for _, span := range exporter.GetSpans() {
+		if span.Name == "agent.run" {
+			require.Contains(t, attributeValue(span, "langfuse.observation.input"), "[application/pdf, 400 bytes, sha256:")
+		}
+	}
+}
+
+func attributeValue(span tracetest.SpanStub, key string) string {
+	for _, attr := range span.Attributes {
+		if string(attr.Key) == key {
+			return attr.Value.Emit()
+		}
+	}
+	return ""
+}
@@ -1,16 +1,27 @@
 package callback
 
 import (
+	"context"
+	"encoding/json"
+	"time"
+
 	"github.com/google/uuid"
 	"github.com/openai/openai-go"
 )
 
+// ErrorClassifier returns the class of an error and whether it is worth retrying
+type ErrorClassifier func(err error) (class string, retryable bool)
+
 type Manager struct {
 	callbacks     []AgentCallback
 	runID         string
 	parentRunID   *string
-	nestedRunID   map[string]string // tool_call_id -> nested_run_id for nested tool executions
-	nestedParents map[string]string // nested_run_id -> parent_run_id
+	nestedRunID   map[string]string      // tool_call_id -> nested_run_id for nested tool executions
+	nestedParents map[string]string      // nested_run_id -> parent_run_id
+	attributes    map[string]interface{} // run-level attributes added to every event
+	redactor      Redactor               // applied to PayloadFields before callbacks see them
+	classifier    ErrorClassifier        // adds error_class and retryable to OnError
+	ctx           context.Context        // context of the run, handed to ContextAwareCallback
 }
 
 // NewManager creates a new callback manager
@@ -21,9 +32,35 @@ func NewManager(callbacks []AgentCallback, parentRunID *string) *Manager {
 		parentRunID:   parentRunID,
 		nestedRunID:   make(map[string]string),
 		nestedParents: make(map[string]string),
+		attributes:    make(map[string]interface{}),
 	}
 }
 
+// SetAttribute sets a run-level attribute that is added to the context of every event
+func (cm *Manager) SetAttribute(key string, value interface{}) {
+	cm.attributes[key] = value
+}
+
+// SetRedactor sets the redactor applied to inputs and outputs before they reach any callback
+func (cm *Manager) SetRedactor(redactor Redactor) {
+	cm.redactor = redactor
+}
+
+// SetErrorClassifier sets the classifier whose result is added to the context of OnError
+func (cm *Manager) SetErrorClassifier(classifier ErrorClassifier) {
+	cm.classifier = classifier
+}
+
+// SetContext sets the context of the run, handed to callbacks implementing ContextAwareCallback
+func (cm *Manager) SetContext(ctx context.Context) {
+	cm.ctx = ctx
+}
+
+// RunID returns the ID of the run managed by this manager
+func (cm *Manager) RunID() string {
+	return cm.runID
+}
+
 // createNestedRun creates a nested run ID for tool execution
 func (cm *Manager) createNestedRun(toolCallID string) string {
 	nestedID := uuid.New().String()
@@ -40,12 +77,26 @@ func (cm *Manager) getNestedRunID(toolCallID string) *string {
 	return nil
 }
 
-// addRunContext adds run_id and parent_run_id to context
+// addRunContext adds run_id, parent_run_id and run attributes to context and redacts its payloads
 func (cm *Manager) addRunContext(ctx map[string]interface{}, nestedRunID *string) map[string]interface{} {
 	if ctx == nil {
 		ctx = make(map[string]interface{})
 	}
 
+	for key, value := range cm.attributes {
+		if _, exists := ctx[key]; !exists {
+			ctx[key] = value
+		}
+	}
+
+	if cm.redactor != nil {
+		for _, field := range PayloadFields {
+			if value, exists := ctx[field]; exists && value != nil {
+				ctx[field] = cm.redactor(field, value)
+			}
+		}
+	}
+
 	if nestedRunID != nil {
 		ctx["run_id"] = *nestedRunID
 		ctx["parent_run_id"] = cm.runID
@@ -60,25 +111,35 @@ func (cm *Manager) addRunContext(ctx map[string]interface{}, nestedRunID *string
 }
 
 // OnRunStart triggers OnRunStart for all callbacks
-func (cm *Manager) OnRunStart(model string, input interface{}, hasOutputClass bool) {
+func (cm *Manager) OnRunStart(model string, input interface{}, hasOutputClass bool, tools []string) {
 	ctx := cm.addRunContext(map[string]interface{}{
 		"model":            model,
 		"input":            input,
 		"has_output_class": hasOutputClass,
+		"tools":            tools,
 	}, nil)
 
 	for _, cb := range cm.callbacks {
+		if aware, ok := cb.(ContextAwareCallback); ok && cm.ctx != nil {
+			aware.SetRunContext(cm.ctx)
+		}
 		cb.OnRunStart(ctx)
 	}
 }
 
 // OnRunEnd triggers OnRunEnd for all callbacks
-func (cm *Manager) OnRunEnd(output interface{}, totalIterations int) {
+func (cm *Manager) OnRunEnd(output interface{}, totalIterations int, usage *openai.CompletionUsage) {
 	ctx := cm.addRunContext(map[string]interface{}{
 		"output":           output,
 		"total_iterations": totalIterations,
+		"usage":            usage,
 	}, nil)
 
+	if usage != nil {
+		ctx["cached_tokens"] = usage.PromptTokensDetails.CachedTokens
+		ctx["reasoning_tokens"] = usage.CompletionTokensDetails.ReasoningTokens
+	}
+
 	for _, cb := range cm.callbacks {
 		cb.OnRunEnd(ctx)
 	}
@@ -105,15 +166,44 @@ func (cm *Manager) OnGenerationStart(
 func (cm *Manager) OnGenerationEnd(
 	finishReason string,
 	content string,
+	reasoningContent string,
 	toolCalls []openai.ChatCompletionMessageToolCall,
+	citations []Citation,
 	usage *openai.CompletionUsage,
+	info GenerationInfo,
 ) {
-	ctx := cm.addRunContext(map[string]interface{}{
+	ctx := map[string]interface{}{
 		"finish_reason": finishReason,
 		"content":       content,
 		"tool_calls":    toolCalls,
 		"usage":         usage,
-	}, nil)
+		"model":         info.Model,
+		"latency_ms":    info.Latency.Milliseconds(),
+	}
+	if reasoningContent != "" {
+		ctx["reasoning_content"] = reasoningContent
+	}
+	ctx = cm.addRunContext(ctx, nil)
+
+	if info.RequestID != "" {
+		ctx["request_id"] = info.RequestID
+	}
+	if info.ProcessingTime > 0 {
+		ctx["processing_ms"] = info.ProcessingTime.Milliseconds()
+	}
+	if info.RateLimits != nil {
+		ctx["rate_limits"] = info.RateLimits
+	}
+	if info.ServiceTier != "" {
+		ctx["service_tier"] = info.ServiceTier
+	}
+	if len(citations) > 0 {
+		ctx["citations"] = citations
+	}
+	if usage != nil {
+		ctx["cached_tokens"] = usage.PromptTokensDetails.CachedTokens
+		ctx["reasoning_tokens"] = usage.CompletionTokensDetails.ReasoningTokens
+	}
 
 	for _, cb := range cm.callbacks {
 		cb.OnGenerationEnd(ctx)
@@ -159,12 +249,81 @@ func (cm *Manager) OnToolCallEnd(
 	}
 }
 
+// OnHistoryTrim triggers OnHistoryTrim for all callbacks implementing HistoryTrimCallback
+func (cm *Manager) OnHistoryTrim(strategy string, messagesBefore, messagesAfter int) {
+	ctx := cm.addRunContext(map[string]interface{}{
+		"strategy":        strategy,
+		"messages_before": messagesBefore,
+		"messages_after":  messagesAfter,
+	}, nil)
+
+	for _, cb := range cm.callbacks {
+		if observer, ok := cb.(HistoryTrimCallback); ok {
+			observer.OnHistoryTrim(ctx)
+		}
+	}
+}
+
+// OnProgress triggers OnProgress for all callbacks implementing ProgressCallback; toolName is empty for iteration progress
+func (cm *Manager) OnProgress(step, totalSteps int, percent float64, message, toolName string) {
+	ctx := cm.addRunContext(map[string]interface{}{
+		"step":        step,
+		"total_steps": totalSteps,
+		"percent":     percent,
+		"message":     message,
+	}, nil)
+	if toolName != "" {
+		ctx["tool_name"] = toolName
+	}
+
+	for _, cb := range cm.callbacks {
+		if observer, ok := cb.(ProgressCallback); ok {
+			observer.OnProgress(ctx)
+		}
+	}
+}
+
+// OnRetry triggers OnRetry for all callbacks implementing RetryCallback
+func (cm *Manager) OnRetry(stage string, err error, errorClass string, attempt int, backoff time.Duration, model string) {
+	ctx := cm.addRunContext(map[string]interface{}{
+		"stage":       stage,
+		"error":       err.Error(),
+		"error_class": errorClass,
+		"attempt":     attempt,
+		"backoff_ms":  backoff.Milliseconds(),
+		"model":       model,
+	}, nil)
+
+	for _, cb := range cm.callbacks {
+		if observer, ok := cb.(RetryCallback); ok {
+			observer.OnRetry(ctx)
+		}
+	}
+}
+
+// OnOutputField triggers OnOutputField for all callbacks implementing OutputFieldCallback
+func (cm *Manager) OnOutputField(field string, value json.RawMessage) {
+	ctx := cm.addRunContext(map[string]interface{}{
+		"field": field,
+		"value": value,
+	}, nil)
+
+	for _, cb := range cm.callbacks {
+		if observer, ok := cb.(OutputFieldCallback); ok {
+			observer.OnOutputField(ctx)
+		}
+	}
+}
+
 // OnError triggers OnError for all callbacks
 func (cm *Manager) OnError(err error, stage string) {
 	ctx := cm.addRunContext(map[string]interface{}{
 		"error": err.Error(),
 		"stage": stage,
 	}, nil)
+	if cm.classifier != nil {
+		ctx["error_class"], ctx["retryable"] = cm.classifier(err)
+	}
 
 	for _, cb := range cm.callbacks {
 		cb.OnError(ctx)
@@ -0,0 +1,51 @@
+package callback
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// minimalCallback implements AgentCallback without BaseCallback, as callbacks written before
+// the optional hooks existed do
+type minimalCallback struct {
+	runEnds int
+}
+
+func (m *minimalCallback) Name() string                                 { return "minimal" }
+func (m *minimalCallback) OnRunStart(ctx map[string]interface{})        {}
+func (m *minimalCallback) OnRunEnd(ctx map[string]interface{})          { m.runEnds++ }
+func (m *minimalCallback) OnGenerationStart(ctx map[string]interface{}) {}
+func (m *minimalCallback) OnGenerationEnd(ctx map[string]interface{})   {}
+func (m *minimalCallback) OnToolCallStart(ctx map[string]interface{})   {}
+func (m *minimalCallback) OnToolCallEnd(ctx map[string]interface{})     {}
+func (m *minimalCallback) OnError(ctx map[string]interface{})           {}
+
+// retryObserver only adds the optional retry hook
+type retryObserver struct {
+	minimalCallback
+	stages []string
+}
+
+func (r *retryObserver) OnRetry(ctx map[string]interface{}) {
+	r.stages = append(r.stages, ctx["stage"].(string))
+}
+
+func TestManagerOptionalCallbacks(t *testing.T) {
+	minimal := &minimalCallback{}
+	observer := &retryObserver{}
+	manager := NewManager([]AgentCallback{minimal, observer}, nil)
+
+	manager.OnRunStart("gpt-4o", "hi", false, nil)
+	manager.OnHistoryTrim("token_window", 10, 4)
+	manager.OnRetry("generation", errors.New("overloaded"), "server_error", 1, 0, "gpt-4o")
+	manager.OnProgress(1, 3, 50, "halfway", "")
+	manager.OnOutputField("name", []byte(`"Ada"`))
+	manager.OnRunEnd("hello", 1, nil)
+
+	// The optional hooks only reach the callbacks implementing them
+	require.Equal(t, []string{"generation"}, observer.stages)
+	require.Equal(t, 1, minimal.runEnds)
+	require.Equal(t, 1, observer.runEnds)
+}
@@ -0,0 +1,233 @@
+package callback
+
+import (
+	"sync"
+	"time"
+)
+
+// defaultMetricsHistory is the number of finished runs MetricsCallback keeps by default
+const defaultMetricsHistory = 100
+
+// RunMetrics are the quality signals of a single run
+type RunMetrics struct {
+	RunID string
+
+	// Iterations used by the run
+	Iterations int
+
+	// Generations performed, continuations and retried iterations included
+	Generations int
+
+	// GenerationLatency is the summed latency of all generations
+	GenerationLatency time.Duration
+
+	// ToolCalls and ToolErrors count the tool executions and those that failed
+	ToolCalls  int
+	ToolErrors int
+
+	// InvalidOutputRetries counts the iterations spent on rejected or empty answers
+	// (output guard retries and empty response nudges)
+	InvalidOutputRetries int
+
+	// Failed is set when the run ended with an error
+	Failed bool
+}
+
+// ToolErrorRate returns the share of tool calls that failed, 0 without tool calls
+func (m RunMetrics) ToolErrorRate() float64 {
+	if m.ToolCalls == 0 {
+		return 0
+	}
+	return float64(m.ToolErrors) / float64(m.ToolCalls)
+}
+
+// AvgGenerationLatency returns the mean latency of the run's generations
+func (m RunMetrics) AvgGenerationLatency() time.Duration {
+	if m.Generations == 0 {
+		return 0
+	}
+	return m.GenerationLatency / time.Duration(m.Generations)
+}
+
+// MetricsStats aggregates the metrics of the finished runs kept by a MetricsCallback
+type MetricsStats struct {
+	Runs       int
+	FailedRuns int
+
+	// AvgIterations is the mean number of iterations per run
+	AvgIterations float64
+
+	// ToolErrorRate is the share of all tool calls that failed
+	ToolErrorRate float64
+
+	// InvalidOutputRetries is the total of all runs
+	InvalidOutputRetries int
+
+	// AvgGenerationLatency is the mean latency over all generations
+	AvgGenerationLatency time.Duration
+}
+
+// MetricsCallback computes quality signals of every run (iterations, tool error rate,
+// invalid-output retries and generation latency) for dashboards, without a tracing stack.
+// It is safe to share across agents and concurrent runs.
+type MetricsCallback struct {
+	BaseCallback
+
+	mu       sync.Mutex
+	active   map[string]*RunMetrics
+	finished []RunMetrics // oldest first
+	history  int
+}
+
+// NewMetricsCallback creates a metrics callback keeping the metrics of the last history
+// finished runs (defaults to 100)
+func NewMetricsCallback(history int) *MetricsCallback {
+	if history <= 0 {
+		history = defaultMetricsHistory
+	}
+	return &MetricsCallback{
+		active:  make(map[string]*RunMetrics),
+		history: history,
+	}
+}
+
+func (m *MetricsCallback) Name() string { return "metrics" }
+
+// Stats aggregates the metrics of the kept finished runs
+func (m *MetricsCallback) Stats() MetricsStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var stats MetricsStats
+	var iterations, generations, toolCalls, toolErrors int
+	var latency time.Duration
+	for _, run := range m.finished {
+		stats.Runs++
+		if run.Failed {
+			stats.FailedRuns++
+		}
+		iterations += run.Iterations
+		generations += run.Generations
+		latency += run.GenerationLatency
+		toolCalls += run.ToolCalls
+		toolErrors += run.ToolErrors
+		stats.InvalidOutputRetries += run.InvalidOutputRetries
+	}
+
+	if stats.Runs > 0 {
+		stats.AvgIterations = float64(iterations) / float64(stats.Runs)
+	}
+	if toolCalls > 0 {
+		stats.ToolErrorRate = float64(toolErrors) / float64(toolCalls)
+	}
+	if generations > 0 {
+		stats.AvgGenerationLatency = latency / time.Duration(generations)
+	}
+	return stats
+}
+
+// Runs returns the metrics of the kept finished runs, oldest first
+func (m *MetricsCallback) Runs() []RunMetrics {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]RunMetrics(nil), m.finished...)
+}
+
+// Run returns the metrics of a finished run, if it is still kept
+func (m *MetricsCallback) Run(runID string) (RunMetrics, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, run := range m.finished {
+		if run.RunID == runID {
+			return run, true
+		}
+	}
+	return RunMetrics{}, false
+}
+
+func (m *MetricsCallback) OnRunStart(ctx map[string]interface{}) {
+	runID, _ := ctx["run_id"].(string)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.active[runID] = &RunMetrics{RunID: runID}
+}
+
+func (m *MetricsCallback) OnGenerationStart(ctx map[string]interface{}) {
+	m.update(ctx["run_id"], func(run *RunMetrics) {
+		if iteration, ok := ctx["iteration"].(int); ok {
+			run.Iterations = max(run.Iterations, iteration)
+		}
+	})
+}
+
+func (m *MetricsCallback) OnGenerationEnd(ctx map[string]interface{}) {
+	m.update(ctx["run_id"], func(run *RunMetrics) {
+		run.Generations++
+		if latency, ok := ctx["latency_ms"].(int64); ok {
+			run.GenerationLatency += time.Duration(latency) * time.Millisecond
+		}
+	})
+}
+
+// OnToolCallEnd counts the call for the run executing the tool, the parent of the tool's nested run
+func (m *MetricsCallback) OnToolCallEnd(ctx map[string]interface{}) {
+	m.update(ctx["parent_run_id"], func(run *RunMetrics) {
+		run.ToolCalls++
+		if _, failed := ctx["error"]; failed {
+			run.ToolErrors++
+		}
+	})
+}
+
+func (m *MetricsCallback) OnRetry(ctx map[string]interface{}) {
+	if stage, _ := ctx["stage"].(string); stage != "output_guard" && stage != "empty_response" {
+		return
+	}
+	m.update(ctx["run_id"], func(run *RunMetrics) {
+		run.InvalidOutputRetries++
+	})
+}
+
+func (m *MetricsCallback) OnRunEnd(ctx map[string]interface{}) {
+	m.finish(ctx, false)
+}
+
+func (m *MetricsCallback) OnError(ctx map[string]interface{}) {
+	m.finish(ctx, true)
+}
+
+// update applies fn to the metrics of an active run
+func (m *MetricsCallback) update(runID interface{}, fn func(run *RunMetrics)) {
+	id, _ := runID.(string)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if run, ok := m.active[id]; ok {
+		fn(run)
+	}
+}
+
+// finish moves an active run to the finished runs; later events of the run are ignored
+func (m *MetricsCallback) finish(ctx map[string]interface{}, failed bool) {
+	runID, _ := ctx["run_id"].(string)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	run, ok := m.active[runID]
+	if !ok {
+		return
+	}
+	delete(m.active, runID)
+
+	if iterations, ok := ctx["total_iterations"].(int); ok {
+		run.Iterations = iterations
+	}
+	run.Failed = failed
+
+	m.finished = append(m.finished, *run)
+	if len(m.finished) > m.history {
+		m.finished = m.finished[len(m.finished)-m.history:]
+	}
+}
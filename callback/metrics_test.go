@@ -0,0 +1,66 @@
+package callback
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/openai/openai-go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricsCallback(t *testing.T) {
+	metrics := NewMetricsCallback(0)
+	usage := &openai.CompletionUsage{}
+
+	manager := NewManager([]AgentCallback{metrics}, nil)
+	manager.OnRunStart("gpt-4o", "hi", true, []string{"lookup"})
+	manager.OnGenerationStart(1, nil, "gpt-4o")
+	manager.OnGenerationEnd("tool_calls", "", "", nil, nil, usage, GenerationInfo{Latency: 100 * time.Millisecond})
+	manager.OnToolCallStart("lookup", nil, "call_1")
+	manager.OnToolCallEnd("lookup", nil, "ok", "call_1", nil)
+	manager.OnToolCallStart("lookup", nil, "call_2")
+	manager.OnToolCallEnd("lookup", nil, nil, "call_2", errors.New("boom"))
+	manager.OnGenerationStart(2, nil, "gpt-4o")
+	manager.OnGenerationEnd("stop", "bad", "", nil, nil, usage, GenerationInfo{Latency: 300 * time.Millisecond})
+	manager.OnRetry("output_guard", errors.New("rejected"), "", 1, 0, "gpt-4o")
+	manager.OnRetry("generation", errors.New("rate limited"), "rate_limit", 1, 0, "gpt-4o")
+	manager.OnRunEnd("done", 3, usage)
+
+	run, ok := metrics.Run(manager.RunID())
+	require.True(t, ok)
+	require.Equal(t, 3, run.Iterations)
+	require.Equal(t, 2, run.Generations)
+	require.Equal(t, 2, run.ToolCalls)
+	require.Equal(t, 1, run.ToolErrors)
+	require.Equal(t, 0.5, run.ToolErrorRate())
+	require.Equal(t, 1, run.InvalidOutputRetries)
+	require.Equal(t, 200*time.Millisecond, run.AvgGenerationLatency())
+	require.False(t, run.Failed)
+
+	failing := NewManager([]AgentCallback{metrics}, nil)
+	failing.OnRunStart("gpt-4o", "hi", true, nil)
+	failing.OnGenerationStart(1, nil, "gpt-4o")
+	failing.OnError(errors.New("boom"), "generation")
+	failing.OnError(errors.New("boom"), "run")
+
+	stats := metrics.Stats()
+	require.Equal(t, 2, stats.Runs)
+	require.Equal(t, 1, stats.FailedRuns)
+	require.Equal(t, 2.0, stats.AvgIterations)
+	require.Equal(t, 0.5, stats.ToolErrorRate)
+	require.Equal(t, 1, stats.InvalidOutputRetries)
+	require.Equal(t, 200*time.Millisecond, stats.AvgGenerationLatency)
+}
+
+func TestMetricsCallbackHistory(t *testing.T) {
+	metrics := NewMetricsCallback(2)
+	for range 3 {
+		manager := NewManager([]AgentCallback{metrics}, nil)
+		manager.OnRunStart("gpt-4o", "hi", false, nil)
+		manager.OnRunEnd("done", 1, &openai.CompletionUsage{})
+	}
+
+	require.Len(t, metrics.Runs(), 2)
+	require.Equal(t, 2, metrics.Stats().Runs)
+}
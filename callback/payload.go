@@ -0,0 +1,92 @@
+package callback
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// payload marshals value into a JSON attribute, its files externalized and truncated to the
+// configured payload size
+func (lc *LangfuseCallback) payload(key string, value interface{}) attribute.KeyValue {
+	data, _ := json.Marshal(value)
+	data = lc.externalizeMedia(data, key[strings.LastIndex(key, ".")+1:])
+	return attribute.String(key, truncate(string(data), lc.maxPayloadSize))
+}
+
+// messagesPayload records the request messages of a generation: as the input attribute or, with
+// message events enabled, as one event per message and only the last message as input
+func (lc *LangfuseCallback) messagesPayload(span trace.Span, messages interface{}) {
+	if !lc.messageEvents {
+		span.SetAttributes(lc.payload("langfuse.observation.input", messages))
+		return
+	}
+
+	// Messages may be of any type after redaction, so they are split as JSON
+	data, _ := json.Marshal(messages)
+	data = lc.externalizeMedia(data, "input")
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil || len(raw) == 0 {
+		span.SetAttributes(lc.payload("langfuse.observation.input", messages))
+		return
+	}
+
+	for i, message := range raw {
+		var fields struct {
+			Role       string          `json:"role"`
+			Content    json.RawMessage `json:"content"`
+			ToolCalls  json.RawMessage `json:"tool_calls"`
+			ToolCallID string          `json:"tool_call_id"`
+		}
+		_ = json.Unmarshal(message, &fields)
+
+		attributes := []attribute.KeyValue{
+			attribute.Int("gen_ai.message.index", i),
+			attribute.String("content", truncate(messageContent(fields.Content), lc.maxMessageSize)),
+		}
+		if len(fields.ToolCalls) > 0 {
+			attributes = append(attributes, attribute.String("tool_calls", truncate(string(fields.ToolCalls), lc.maxMessageSize)))
+		}
+		if fields.ToolCallID != "" {
+			attributes = append(attributes, attribute.String("tool_call_id", fields.ToolCallID))
+		}
+
+		role := fields.Role
+		if role == "" {
+			role = "unknown"
+		}
+		span.AddEvent(fmt.Sprintf("gen_ai.%s.message", role), trace.WithAttributes(attributes...))
+	}
+
+	span.SetAttributes(
+		attribute.Int("gen_ai.request.message_count", len(raw)),
+		attribute.String("langfuse.observation.input", truncate("["+string(raw[len(raw)-1])+"]", lc.maxPayloadSize)),
+	)
+}
+
+// messageContent returns text content as is and any other content, e.g. parts, as JSON
+func messageContent(content json.RawMessage) string {
+	var text string
+	if err := json.Unmarshal(content, &text); err == nil {
+		return text
+	}
+	return string(content)
+}
+
+// truncate keeps at most the first limit bytes of s, cut on a rune boundary, and notes how much
+// was cut. A limit of zero or less keeps s whole.
+func truncate(s string, limit int) string {
+	if limit <= 0 || len(s) <= limit {
+		return s
+	}
+
+	cut := limit
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return fmt.Sprintf("%s…[truncated %d bytes]", s[:cut], len(s)-cut)
+}
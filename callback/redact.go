@@ -0,0 +1,156 @@
+package callback
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// PayloadFields are the context keys carrying user data (inputs, outputs, messages, tool
+// arguments and results, output field values, and error messages, which often quote them).
+// They are passed through the redactor before reaching any callback.
+var PayloadFields = []string{"input", "output", "messages", "content", "reasoning_content", "tool_calls", "arguments", "result", "value", "error"}
+
+// Redacted replaces values removed by RedactKeys
+const Redacted = "[REDACTED]"
+
+// Redactor transforms a payload field before it is handed to callbacks and trace exporters.
+// field is the context key (one of PayloadFields) and value its original value.
+type Redactor func(field string, value interface{}) interface{}
+
+// ChainRedactors applies the redactors in order
+func ChainRedactors(redactors ...Redactor) Redactor {
+	return func(field string, value interface{}) interface{} {
+		for _, redactor := range redactors {
+			value = redactor(field, value)
+		}
+		return value
+	}
+}
+
+// HashPayloads replaces every payload with the hex SHA-256 of its JSON encoding, keeping
+// payloads correlatable across events without exposing their content
+func HashPayloads() Redactor {
+	return func(field string, value interface{}) interface{} {
+		data, err := payloadBytes(value)
+		if err != nil {
+			return Redacted
+		}
+		sum := sha256.Sum256(data)
+		return "sha256:" + hex.EncodeToString(sum[:])
+	}
+}
+
+// RedactKeys replaces the values of the given JSON object keys (case-insensitive) anywhere
+// inside a payload, e.g. RedactKeys("email", "phone"). Strings holding JSON are redacted in place.
+func RedactKeys(keys ...string) Redactor {
+	names := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		names[strings.ToLower(key)] = true
+	}
+
+	return func(field string, value interface{}) interface{} {
+		if s, ok := value.(string); ok {
+			var decoded interface{}
+			if err := json.Unmarshal([]byte(s), &decoded); err != nil {
+				return s
+			}
+			redacted, err := json.Marshal(redactKeys(decoded, names))
+			if err != nil {
+				return Redacted
+			}
+			return string(redacted)
+		}
+
+		data, err := json.Marshal(value)
+		if err != nil {
+			return Redacted
+		}
+		var decoded interface{}
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			return Redacted
+		}
+		return redactKeys(decoded, names)
+	}
+}
+
+func redactKeys(value interface{}, names map[string]bool) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			if names[strings.ToLower(key)] {
+				v[key] = Redacted
+				continue
+			}
+			v[key] = redactKeys(item, names)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactKeys(item, names)
+		}
+	}
+	return value
+}
+
+// EncryptPayloads encrypts every payload with AES-GCM using a 16, 24 or 32 byte key.
+// Values are reported as base64(nonce || ciphertext) of their JSON encoding.
+func EncryptPayloads(key []byte) (Redactor, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encryption key: %w", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(field string, value interface{}) interface{} {
+		data, err := payloadBytes(value)
+		if err != nil {
+			return Redacted
+		}
+
+		nonce := make([]byte, gcm.NonceSize())
+		if _, err := rand.Read(nonce); err != nil {
+			return Redacted
+		}
+		return base64.StdEncoding.EncodeToString(gcm.Seal(nonce, nonce, data, []byte(field)))
+	}, nil
+}
+
+// DecryptPayload reverses EncryptPayloads for a value reported under field
+func DecryptPayload(key []byte, field string, encrypted string) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encryption key: %w", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := base64.StdEncoding.DecodeString(encrypted)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return nil, fmt.Errorf("encrypted payload is too short")
+	}
+
+	nonce, ciphertext := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ciphertext, []byte(field))
+}
+
+// payloadBytes returns strings as-is and everything else as JSON
+func payloadBytes(value interface{}) ([]byte, error) {
+	if s, ok := value.(string); ok {
+		return []byte(s), nil
+	}
+	return json.Marshal(value)
+}
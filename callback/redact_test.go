@@ -0,0 +1,61 @@
+package callback
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type recorder struct {
+	BaseCallback
+	ctx map[string]interface{}
+}
+
+func (r *recorder) Name() string { return "recorder" }
+
+func (r *recorder) OnToolCallStart(ctx map[string]interface{}) { r.ctx = ctx }
+
+func (r *recorder) OnError(ctx map[string]interface{}) { r.ctx = ctx }
+
+func TestManagerRedactsPayloads(t *testing.T) {
+	rec := &recorder{}
+	manager := NewManager([]AgentCallback{rec}, nil)
+	manager.SetRedactor(RedactKeys("email"))
+
+	args := map[string]interface{}{"email": "alice@example.com", "name": "alice"}
+	manager.OnToolCallStart("lookup", args, "call_1")
+
+	require.Equal(t, map[string]interface{}{"email": Redacted, "name": "alice"}, rec.ctx["arguments"])
+	require.Equal(t, "lookup", rec.ctx["tool_name"])
+	require.Equal(t, "alice@example.com", args["email"], "the original payload is left untouched")
+}
+
+func TestManagerRedactsErrors(t *testing.T) {
+	rec := &recorder{}
+	manager := NewManager([]AgentCallback{rec}, nil)
+	manager.SetRedactor(HashPayloads())
+
+	// Error messages often quote the inputs or tool results that failed
+	manager.OnError(errors.New("no account for alice@example.com"), "tool")
+
+	require.True(t, strings.HasPrefix(rec.ctx["error"].(string), "sha256:"))
+	require.Equal(t, "tool", rec.ctx["stage"])
+}
+
+func TestEncryptPayloads(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+	redactor, err := EncryptPayloads(key)
+	require.NoError(t, err)
+
+	encrypted := redactor("content", `{"answer":42}`).(string)
+	require.NotContains(t, encrypted, "answer")
+
+	plain, err := DecryptPayload(key, "content", encrypted)
+	require.NoError(t, err)
+	require.Equal(t, `{"answer":42}`, string(plain))
+
+	_, err = DecryptPayload(key, "output", encrypted)
+	require.Error(t, err, "payloads are bound to their field")
+}
@@ -0,0 +1,311 @@
+package callback
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// TranscriptMessage is one message of a run transcript
+type TranscriptMessage struct {
+	// Role is system, user, assistant or tool
+	Role    string          `json:"role"`
+	Content json.RawMessage `json:"content,omitempty"`
+
+	// ToolCalls requested by an assistant message
+	ToolCalls json.RawMessage `json:"tool_calls,omitempty"`
+
+	// ToolCallID, ToolName, Arguments and Error describe the call answered by a tool message
+	ToolCallID string          `json:"tool_call_id,omitempty"`
+	ToolName   string          `json:"tool_name,omitempty"`
+	Arguments  json.RawMessage `json:"arguments,omitempty"`
+	Error      string          `json:"error,omitempty"`
+
+	// Model and FinishReason of an assistant message
+	Model        string `json:"model,omitempty"`
+	FinishReason string `json:"finish_reason,omitempty"`
+
+	// Time is when the message was sent or received
+	Time time.Time `json:"time"`
+
+	// DurationMS is the latency of the generation or the execution time of the tool
+	DurationMS int64 `json:"duration_ms,omitempty"`
+}
+
+// Transcript is the ordered conversation of a single run
+type Transcript struct {
+	RunID       string              `json:"run_id"`
+	ParentRunID string              `json:"parent_run_id,omitempty"`
+	AgentName   string              `json:"agent_name,omitempty"`
+	Model       string              `json:"model"`
+	Tools       []string            `json:"tools,omitempty"`
+	Messages    []TranscriptMessage `json:"messages"`
+	Output      json.RawMessage     `json:"output,omitempty"`
+	Usage       json.RawMessage     `json:"usage,omitempty"`
+	Iterations  int                 `json:"iterations"`
+
+	// Error is the error that ended a failed run
+	Error string `json:"error,omitempty"`
+
+	StartedAt  time.Time `json:"started_at"`
+	EndedAt    time.Time `json:"ended_at"`
+	DurationMS int64     `json:"duration_ms"`
+}
+
+// TranscriptSink receives the transcript of every finished run
+type TranscriptSink interface {
+	WriteTranscript(transcript *Transcript) error
+}
+
+// TranscriptSinkFunc adapts a function to a TranscriptSink
+type TranscriptSinkFunc func(transcript *Transcript) error
+
+func (f TranscriptSinkFunc) WriteTranscript(transcript *Transcript) error {
+	return f(transcript)
+}
+
+// TranscriptWriter writes every transcript to w as one line of JSON
+type TranscriptWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewTranscriptWriter creates a sink writing JSON lines to w, e.g. a log file
+func NewTranscriptWriter(w io.Writer) *TranscriptWriter {
+	return &TranscriptWriter{w: w}
+}
+
+func (t *TranscriptWriter) WriteTranscript(transcript *Transcript) error {
+	data, err := json.Marshal(transcript)
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	_, err = t.w.Write(append(data, '\n'))
+	return err
+}
+
+// terminalStages are the OnError stages that end a run
+var terminalStages = map[string]bool{"run": true, "cancel": true, "quota": true, "input_guard": true}
+
+// TranscriptCallback assembles the conversation of every run (system, user, assistant and
+// tool messages with their timing) and hands it to a sink when the run ends, failed runs
+// included. It is safe to share across agents and concurrent runs.
+type TranscriptCallback struct {
+	BaseCallback
+
+	sink    TranscriptSink
+	onError func(runID string, err error)
+
+	mu     sync.Mutex
+	active map[string]*activeTranscript
+}
+
+// activeTranscript is the transcript of a running run
+type activeTranscript struct {
+	transcript Transcript
+	seen       bool                 // whether the initial messages were recorded
+	toolStarts map[string]time.Time // tool_call_id -> start of the execution
+}
+
+// NewTranscriptCallback creates a callback writing the transcript of every run to the sink
+func NewTranscriptCallback(sink TranscriptSink) *TranscriptCallback {
+	return &TranscriptCallback{
+		sink:   sink,
+		active: make(map[string]*activeTranscript),
+	}
+}
+
+// WithErrorHandler sets the function called when the sink fails to write a transcript;
+// such failures are dropped by default
+func (t *TranscriptCallback) WithErrorHandler(onError func(runID string, err error)) *TranscriptCallback {
+	t.onError = onError
+	return t
+}
+
+func (t *TranscriptCallback) Name() string { return "transcript" }
+
+func (t *TranscriptCallback) OnRunStart(ctx map[string]interface{}) {
+	runID, _ := ctx["run_id"].(string)
+	transcript := Transcript{RunID: runID, StartedAt: time.Now()}
+	transcript.ParentRunID, _ = ctx["parent_run_id"].(string)
+	transcript.AgentName, _ = ctx["agent_name"].(string)
+	transcript.Model, _ = ctx["model"].(string)
+	transcript.Tools, _ = ctx["tools"].([]string)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.active[runID] = &activeTranscript{
+		transcript: transcript,
+		toolStarts: make(map[string]time.Time),
+	}
+}
+
+// OnGenerationStart records the messages sent by the run: the whole request of the first
+// generation, then the user and system messages added since the last assistant message, such
+// as nudges. Tool messages are recorded when the tools end.
+func (t *TranscriptCallback) OnGenerationStart(ctx map[string]interface{}) {
+	now := time.Now()
+	t.update(ctx["run_id"], func(run *activeTranscript) {
+		messages := transcriptMessages(ctx["messages"], now)
+		if run.seen {
+			messages = addedMessages(messages)
+		}
+		run.seen = true
+		run.transcript.Messages = append(run.transcript.Messages, messages...)
+	})
+}
+
+func (t *TranscriptCallback) OnGenerationEnd(ctx map[string]interface{}) {
+	now := time.Now()
+	t.update(ctx["run_id"], func(run *activeTranscript) {
+		message := TranscriptMessage{Role: "assistant", Time: now}
+		message.Content = marshalTranscript(ctx["content"])
+		if toolCalls := marshalTranscript(ctx["tool_calls"]); string(toolCalls) != "null" && string(toolCalls) != "[]" {
+			message.ToolCalls = toolCalls
+		}
+		message.Model, _ = ctx["model"].(string)
+		message.FinishReason, _ = ctx["finish_reason"].(string)
+		message.DurationMS, _ = ctx["latency_ms"].(int64)
+		run.transcript.Messages = append(run.transcript.Messages, message)
+	})
+}
+
+// OnToolCallStart and OnToolCallEnd are reported for the tool's nested run, so they are
+// recorded in the transcript of its parent
+func (t *TranscriptCallback) OnToolCallStart(ctx map[string]interface{}) {
+	toolCallID, _ := ctx["tool_call_id"].(string)
+	t.update(ctx["parent_run_id"], func(run *activeTranscript) {
+		run.toolStarts[toolCallID] = time.Now()
+	})
+}
+
+func (t *TranscriptCallback) OnToolCallEnd(ctx map[string]interface{}) {
+	now := time.Now()
+	t.update(ctx["parent_run_id"], func(run *activeTranscript) {
+		message := TranscriptMessage{
+			Role:      "tool",
+			Content:   marshalTranscript(ctx["result"]),
+			Arguments: marshalTranscript(ctx["arguments"]),
+			Time:      now,
+		}
+		message.ToolCallID, _ = ctx["tool_call_id"].(string)
+		message.ToolName, _ = ctx["tool_name"].(string)
+		message.Error, _ = ctx["error"].(string)
+		if started, ok := run.toolStarts[message.ToolCallID]; ok {
+			message.DurationMS = now.Sub(started).Milliseconds()
+			delete(run.toolStarts, message.ToolCallID)
+		}
+		run.transcript.Messages = append(run.transcript.Messages, message)
+	})
+}
+
+func (t *TranscriptCallback) OnRunEnd(ctx map[string]interface{}) {
+	runID, _ := ctx["run_id"].(string)
+	t.finish(runID, func(transcript *Transcript) {
+		transcript.Output = marshalTranscript(ctx["output"])
+		transcript.Usage = marshalTranscript(ctx["usage"])
+		transcript.Iterations, _ = ctx["total_iterations"].(int)
+	})
+}
+
+// OnError writes the transcript once the error ends the run
+func (t *TranscriptCallback) OnError(ctx map[string]interface{}) {
+	if stage, _ := ctx["stage"].(string); !terminalStages[stage] {
+		return
+	}
+
+	runID, _ := ctx["run_id"].(string)
+	t.finish(runID, func(transcript *Transcript) {
+		transcript.Error, _ = ctx["error"].(string)
+	})
+}
+
+// update applies fn to the transcript of an active run
+func (t *TranscriptCallback) update(runID interface{}, fn func(run *activeTranscript)) {
+	id, _ := runID.(string)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if run, ok := t.active[id]; ok {
+		fn(run)
+	}
+}
+
+// finish completes the transcript of an active run and writes it to the sink
+func (t *TranscriptCallback) finish(runID string, fn func(transcript *Transcript)) {
+	t.mu.Lock()
+	run, ok := t.active[runID]
+	delete(t.active, runID)
+	t.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	transcript := &run.transcript
+	fn(transcript)
+	transcript.EndedAt = time.Now()
+	transcript.DurationMS = transcript.EndedAt.Sub(transcript.StartedAt).Milliseconds()
+	if transcript.Messages == nil {
+		transcript.Messages = []TranscriptMessage{}
+	}
+
+	if err := t.sink.WriteTranscript(transcript); err != nil && t.onError != nil {
+		t.onError(runID, err)
+	}
+}
+
+// transcriptMessages splits the request messages, which may be of any type after redaction,
+// as JSON
+func transcriptMessages(messages interface{}, at time.Time) []TranscriptMessage {
+	data, _ := json.Marshal(messages)
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil
+	}
+
+	result := make([]TranscriptMessage, 0, len(raw))
+	for _, message := range raw {
+		var fields struct {
+			Role       string          `json:"role"`
+			Content    json.RawMessage `json:"content"`
+			ToolCalls  json.RawMessage `json:"tool_calls"`
+			ToolCallID string          `json:"tool_call_id"`
+		}
+		_ = json.Unmarshal(message, &fields)
+		result = append(result, TranscriptMessage{
+			Role:       fields.Role,
+			Content:    fields.Content,
+			ToolCalls:  fields.ToolCalls,
+			ToolCallID: fields.ToolCallID,
+			Time:       at,
+		})
+	}
+	return result
+}
+
+// addedMessages returns the user and system messages after the last assistant message
+func addedMessages(messages []TranscriptMessage) []TranscriptMessage {
+	var added []TranscriptMessage
+	for i := len(messages) - 1; i >= 0 && messages[i].Role != "assistant"; i-- {
+		if messages[i].Role == "user" || messages[i].Role == "system" {
+			added = append([]TranscriptMessage{messages[i]}, added...)
+		}
+	}
+	return added
+}
+
+// marshalTranscript encodes a context value, nil when it is missing
+func marshalTranscript(value interface{}) json.RawMessage {
+	if value == nil {
+		return nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil
+	}
+	return data
+}
@@ -0,0 +1,92 @@
+package callback
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/openai/openai-go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTranscriptCallback(t *testing.T) {
+	var buf bytes.Buffer
+	transcripts := NewTranscriptCallback(NewTranscriptWriter(&buf))
+	usage := &openai.CompletionUsage{PromptTokens: 10}
+	toolCalls := []openai.ChatCompletionMessageToolCall{{
+		ID:       "call_1",
+		Function: openai.ChatCompletionMessageToolCallFunction{Name: "lookup", Arguments: `{"q":"go"}`},
+	}}
+
+	history := []openai.ChatCompletionMessageParamUnion{
+		openai.SystemMessage("Be brief."),
+		openai.UserMessage("hi"),
+	}
+	manager := NewManager([]AgentCallback{transcripts}, nil)
+	manager.OnRunStart("gpt-4o", "hi", false, []string{"lookup"})
+	manager.OnGenerationStart(1, history, "gpt-4o")
+	manager.OnGenerationEnd("tool_calls", "", "", toolCalls, nil, usage, GenerationInfo{Model: "gpt-4o", Latency: 100 * time.Millisecond})
+	manager.OnToolCallStart("lookup", map[string]interface{}{"q": "go"}, "call_1")
+	manager.OnToolCallEnd("lookup", map[string]interface{}{"q": "go"}, "found", "call_1", nil)
+	history = append(history,
+		openai.ChatCompletionMessageParamUnion{OfAssistant: &openai.ChatCompletionAssistantMessageParam{
+			ToolCalls: []openai.ChatCompletionMessageToolCallParam{{
+				ID:       "call_1",
+				Function: openai.ChatCompletionMessageToolCallFunctionParam{Name: "lookup", Arguments: `{"q":"go"}`},
+			}},
+		}},
+		openai.ToolMessage("found", "call_1"),
+		openai.UserMessage("Answer now."),
+	)
+	manager.OnGenerationStart(2, history, "gpt-4o")
+	manager.OnGenerationEnd("stop", "done", "", nil, nil, usage, GenerationInfo{Model: "gpt-4o"})
+	manager.OnRunEnd("done", 2, usage)
+
+	var transcript Transcript
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &transcript))
+	require.Equal(t, manager.RunID(), transcript.RunID)
+	require.Equal(t, []string{"lookup"}, transcript.Tools)
+	require.Equal(t, 2, transcript.Iterations)
+	require.JSONEq(t, `"done"`, string(transcript.Output))
+
+	var roles []string
+	for _, message := range transcript.Messages {
+		roles = append(roles, message.Role)
+	}
+	require.Equal(t, []string{"system", "user", "assistant", "tool", "user", "assistant"}, roles)
+
+	call := transcript.Messages[2]
+	require.EqualValues(t, 100, call.DurationMS)
+	require.Equal(t, "tool_calls", call.FinishReason)
+	require.Contains(t, string(call.ToolCalls), "call_1")
+
+	tool := transcript.Messages[3]
+	require.Equal(t, "lookup", tool.ToolName)
+	require.Equal(t, "call_1", tool.ToolCallID)
+	require.JSONEq(t, `"found"`, string(tool.Content))
+	require.JSONEq(t, `{"q":"go"}`, string(tool.Arguments))
+	require.JSONEq(t, `"Answer now."`, string(transcript.Messages[4].Content))
+}
+
+func TestTranscriptCallbackFailedRun(t *testing.T) {
+	var written []*Transcript
+	transcripts := NewTranscriptCallback(TranscriptSinkFunc(func(transcript *Transcript) error {
+		written = append(written, transcript)
+		return errors.New("disk full")
+	}))
+	var sinkErr error
+	transcripts.WithErrorHandler(func(runID string, err error) { sinkErr = err })
+
+	manager := NewManager([]AgentCallback{transcripts}, nil)
+	manager.OnRunStart("gpt-4o", "hi", false, nil)
+	manager.OnGenerationStart(1, []openai.ChatCompletionMessageParamUnion{openai.UserMessage("hi")}, "gpt-4o")
+	manager.OnError(errors.New("boom"), "generation")
+	manager.OnError(errors.New("boom"), "run")
+
+	require.Len(t, written, 1)
+	require.Equal(t, "boom", written[0].Error)
+	require.Len(t, written[0].Messages, 1)
+	require.EqualError(t, sinkErr, "disk full")
+}
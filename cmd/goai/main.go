@@ -0,0 +1,226 @@
+// Command goai runs a config-defined agent from the terminal:
+//
+//	goai -agent support.yaml "How do I reset my password?"
+//	echo "How do I reset my password?" | goai -agent support.yaml
+//
+// The OpenAI-compatible endpoint is configured with OPENAI_API_KEY, OPENAI_BASE_URL and
+// OPENAI_MODEL. When LANGFUSE_PUBLIC_KEY and LANGFUSE_SECRET_KEY are set, the run is traced
+// to LANGFUSE_HOST (defaults to cloud.langfuse.com) and the trace URL is printed.
+//
+// Tools listed in the definition are resolved from kit.RegisterTool. To expose your own tools,
+// build a copy of this command that imports the packages registering them. -tools lists them.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/mhrlife/goai-kit/internal/callback"
+	"github.com/mhrlife/goai-kit/internal/kit"
+	"github.com/mhrlife/goai-kit/internal/tracing"
+)
+
+func main() {
+	agentPath := flag.String("agent", "", "path to the agent definition (.yaml, .yml or .json)")
+	model := flag.String("model", "", "override the model of the agent definition")
+	timeout := flag.Duration("timeout", 0, "maximum duration of the run (0 means no limit)")
+	quiet := flag.Bool("quiet", false, "only print the final output")
+	listTools := flag.Bool("tools", false, "list the registered tools and exit")
+	flag.Parse()
+
+	if *listTools {
+		for _, name := range kit.RegisteredTools() {
+			fmt.Println(name)
+		}
+		return
+	}
+
+	if err := run(*agentPath, *model, *timeout, *quiet, flag.Args()); err != nil {
+		fmt.Fprintf(os.Stderr, "goai: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(agentPath, model string, timeout time.Duration, quiet bool, args []string) error {
+	if agentPath == "" {
+		return fmt.Errorf("-agent is required")
+	}
+
+	prompt, err := readPrompt(args)
+	if err != nil {
+		return err
+	}
+
+	var opts []kit.ClientOption
+	if apiKey := os.Getenv("OPENAI_API_KEY"); apiKey != "" {
+		opts = append(opts, kit.WithAPIKey(apiKey))
+	}
+	if baseURL := os.Getenv("OPENAI_BASE_URL"); baseURL != "" {
+		opts = append(opts, kit.WithBaseURL(baseURL))
+	}
+	if defaultModel := os.Getenv("OPENAI_MODEL"); defaultModel != "" {
+		opts = append(opts, kit.WithDefaultModel(defaultModel))
+	}
+	client := kit.NewClient(opts...)
+
+	agent, err := kit.LoadAgent(client, agentPath, nil)
+	if err != nil {
+		return err
+	}
+	if model != "" {
+		agent.WithModel(model)
+	}
+
+	var callbacks []callback.AgentCallback
+	if !quiet {
+		callbacks = append(callbacks, &progressCallback{out: os.Stderr})
+	}
+
+	traceURL, flush, err := setupTracing(agent.Name())
+	if err != nil {
+		return err
+	}
+	if traceURL != nil {
+		callbacks = append(callbacks, traceURL.callback)
+		defer flush()
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	output, err := agent.Invoke(ctx, kit.InvokeConfig{
+		Prompt:    prompt,
+		Callbacks: callbacks,
+		Timeout:   timeout,
+	})
+	if traceURL != nil {
+		fmt.Fprintf(os.Stderr, "trace: %s\n", traceURL.callback.GetTraceURL(traceURL.host))
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(output)
+	return nil
+}
+
+// readPrompt joins the arguments, falling back to stdin when there are none
+func readPrompt(args []string) (string, error) {
+	if len(args) > 0 {
+		return strings.Join(args, " "), nil
+	}
+
+	data, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return "", fmt.Errorf("failed to read prompt from stdin: %w", err)
+	}
+
+	prompt := strings.TrimSpace(string(data))
+	if prompt == "" {
+		return "", fmt.Errorf("no prompt given")
+	}
+	return prompt, nil
+}
+
+type langfuseTrace struct {
+	callback *callback.LangfuseCallback
+	host     string
+}
+
+// setupTracing enables Langfuse tracing when its keys are set in the environment
+func setupTracing(serviceName string) (*langfuseTrace, func(), error) {
+	publicKey, secretKey := os.Getenv("LANGFUSE_PUBLIC_KEY"), os.Getenv("LANGFUSE_SECRET_KEY")
+	if publicKey == "" || secretKey == "" {
+		return nil, nil, nil
+	}
+
+	host := strings.TrimPrefix(os.Getenv("LANGFUSE_HOST"), "https://")
+	if host == "" {
+		host = "cloud.langfuse.com"
+	}
+	if serviceName == "" {
+		serviceName = "goai"
+	}
+
+	tracer, err := tracing.NewOTELLangfuseTracer(tracing.LangfuseConfig{
+		SecretKey:   secretKey,
+		PublicKey:   publicKey,
+		Host:        host,
+		URLPath:     "/api/public/otel/v1/traces",
+		Environment: "development",
+		ServiceName: serviceName,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	flush := func() {
+		if err := tracer.Shutdown(); err != nil {
+			fmt.Fprintf(os.Stderr, "goai: failed to flush traces: %v\n", err)
+		}
+	}
+
+	return &langfuseTrace{
+		callback: callback.NewLangfuseCallback(callback.LangfuseCallbackConfig{
+			Tracer:      tracer.Tracer(),
+			ServiceName: serviceName,
+		}),
+		host: "https://" + host,
+	}, flush, nil
+}
+
+// progressCallback streams the run's progress (generations and tool calls) as it happens
+type progressCallback struct {
+	callback.BaseCallback
+	out io.Writer
+}
+
+func (p *progressCallback) Name() string {
+	return "ProgressCallback"
+}
+
+func (p *progressCallback) OnGenerationStart(ctx map[string]interface{}) {
+	fmt.Fprintf(p.out, "· generating (iteration %v)\n", ctx["iteration"])
+}
+
+// OnGenerationEnd prints intermediate content; the final answer is printed to stdout
+func (p *progressCallback) OnGenerationEnd(ctx map[string]interface{}) {
+	if ctx["finish_reason"] != "tool_calls" {
+		return
+	}
+	if content, ok := ctx["content"].(string); ok && content != "" {
+		fmt.Fprintf(p.out, "%s\n", content)
+	}
+}
+
+func (p *progressCallback) OnToolCallStart(ctx map[string]interface{}) {
+	fmt.Fprintf(p.out, "→ %v %v\n", ctx["tool_name"], ctx["arguments"])
+}
+
+func (p *progressCallback) OnToolCallEnd(ctx map[string]interface{}) {
+	if errMsg, ok := ctx["error"]; ok {
+		fmt.Fprintf(p.out, "✗ %v: %v\n", ctx["tool_name"], errMsg)
+		return
+	}
+	fmt.Fprintf(p.out, "← %v %v\n", ctx["tool_name"], ctx["result"])
+}
+
+func (p *progressCallback) OnRetry(ctx map[string]interface{}) {
+	fmt.Fprintf(p.out, "↻ retrying %v with %v in %vms: %v\n", ctx["stage"], ctx["model"], ctx["backoff_ms"], ctx["error"])
+}
+
+func (p *progressCallback) OnProgress(ctx map[string]interface{}) {
+	if tool, ok := ctx["tool_name"]; ok {
+		fmt.Fprintf(p.out, "… %v %.0f%% %v\n", tool, ctx["percent"], ctx["message"])
+	}
+}
+
+func (p *progressCallback) OnError(ctx map[string]interface{}) {
+	fmt.Fprintf(p.out, "✗ %v: %v\n", ctx["stage"], ctx["error"])
+}
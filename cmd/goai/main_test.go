@@ -0,0 +1,71 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// captureStdout returns what fn printed to stdout
+func captureStdout(t *testing.T, fn func()) string {
+	reader, writer, err := os.Pipe()
+	require.NoError(t, err)
+
+	stdout := os.Stdout
+	os.Stdout = writer
+	defer func() { os.Stdout = stdout }()
+
+	fn()
+	require.NoError(t, writer.Close())
+	out, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	return string(out)
+}
+
+func TestRun(t *testing.T) {
+	var request struct {
+		Model    string `json:"model"`
+		Messages []struct {
+			Role    string `json:"role"`
+			Content string `json:"content"`
+		} `json:"messages"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"1","object":"chat.completion","choices":[{"index":0,"finish_reason":"stop",` +
+			`"message":{"role":"assistant","content":"Use the reset link."}}]}`))
+	}))
+	defer server.Close()
+
+	t.Setenv("OPENAI_API_KEY", "test")
+	t.Setenv("OPENAI_BASE_URL", server.URL)
+	t.Setenv("LANGFUSE_PUBLIC_KEY", "")
+
+	path := filepath.Join(t.TempDir(), "support.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+name: support
+model: gpt-4o-mini
+system_prompt: You are a support agent.
+`), 0o644))
+
+	var err error
+	out := captureStdout(t, func() {
+		err = run(path, "gpt-4o", 0, true, []string{"How do I reset", "my password?"})
+	})
+	require.NoError(t, err)
+	require.Equal(t, "Use the reset link.\n", out)
+	require.Equal(t, "gpt-4o", request.Model, "-model overrides the definition")
+	require.Equal(t, "You are a support agent.", request.Messages[0].Content)
+	require.Equal(t, "How do I reset my password?", request.Messages[1].Content)
+
+	require.ErrorContains(t, run("", "", 0, true, []string{"hi"}), "-agent is required")
+	require.ErrorContains(t, run(filepath.Join(t.TempDir(), "missing.yaml"), "", 0, true, []string{"hi"}),
+		"failed to read agent definition")
+}
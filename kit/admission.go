@@ -0,0 +1,173 @@
+package kit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/openai/openai-go"
+)
+
+// ErrAdmissionRejected is matched (errors.Is) by the AdmissionError of a rejected generation
+var ErrAdmissionRejected = errors.New("admission rejected")
+
+// AdmissionError is returned when a generation would exceed the tokens-per-minute limit for
+// longer than AdmissionOptions.MaxWait
+type AdmissionError struct {
+	// Tokens is the estimated size of the rejected request
+	Tokens int64
+
+	// RetryAfter is when enough of the limit was expected to be available again
+	RetryAfter time.Duration
+}
+
+func (e *AdmissionError) Error() string {
+	return fmt.Sprintf("request of ~%d tokens exceeds the tokens-per-minute limit, retry after %s", e.Tokens, e.RetryAfter)
+}
+
+// Unwrap lets callers match the error with errors.Is(err, ErrAdmissionRejected)
+func (e *AdmissionError) Unwrap() error {
+	return ErrAdmissionRejected
+}
+
+// AdmissionOptions configures the client's tokens-per-minute admission control
+type AdmissionOptions struct {
+	// TokensPerMinute is the organization's limit (defaults to the limit reported in the
+	// x-ratelimit-limit-tokens header, admission is open until one was observed)
+	TokensPerMinute int64
+
+	// MaxWait is the longest a generation is delayed before it is rejected with an
+	// AdmissionError (zero rejects right away)
+	MaxWait time.Duration
+}
+
+// WithAdmissionControl delays or rejects agent generations that would exceed the tokens-per-minute
+// limit. Every request is estimated from its messages and completion token limit, and admitted
+// against both the tokens admitted during the last minute and the remaining tokens reported in
+// the latest rate limit headers, smoothing throughput of batch workloads sharing the client.
+func WithAdmissionControl(opts AdmissionOptions) ClientOption {
+	return func(c *Config) {
+		c.Admission = &opts
+	}
+}
+
+// admissionController keeps the requests admitted during the last minute
+type admissionController struct {
+	opts       AdmissionOptions
+	rateLimits *rateLimitTracker
+	now        func() time.Time
+
+	mu       sync.Mutex
+	admitted []admittedRequest // oldest first
+}
+
+type admittedRequest struct {
+	at     time.Time
+	tokens int64
+}
+
+func newAdmissionController(opts AdmissionOptions, rateLimits *rateLimitTracker) *admissionController {
+	return &admissionController{
+		opts:       opts,
+		rateLimits: rateLimits,
+		now:        time.Now,
+	}
+}
+
+// admit waits until a request of the given estimated size fits the limit, or returns an
+// AdmissionError if that takes longer than MaxWait. A nil controller admits everything.
+func (c *admissionController) admit(ctx context.Context, tokens int64) error {
+	if c == nil {
+		return nil
+	}
+
+	deadline := c.now().Add(c.opts.MaxWait)
+	for {
+		wait := c.reserve(tokens)
+		if wait <= 0 {
+			return nil
+		}
+		if c.now().Add(wait).After(deadline) {
+			return &AdmissionError{Tokens: tokens, RetryAfter: wait}
+		}
+
+		select {
+		case <-ctx.Done():
+			return context.Cause(ctx)
+		case <-time.After(wait):
+		}
+	}
+}
+
+// reserve admits the request when it fits, otherwise it returns how long to wait before trying again
+func (c *admissionController) reserve(tokens int64) time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	for len(c.admitted) > 0 && now.Sub(c.admitted[0].at) >= time.Minute {
+		c.admitted = c.admitted[1:]
+	}
+
+	limits, observed := c.rateLimits.get()
+	limit := c.opts.TokensPerMinute
+	if limit <= 0 && observed {
+		limit = limits.LimitTokens
+	}
+
+	var wait time.Duration
+
+	// The tokens admitted during the last minute, freed as they leave the window. A request
+	// larger than the whole limit is admitted once the window is empty.
+	var used int64
+	for _, request := range c.admitted {
+		used += request.tokens
+	}
+	if limit > 0 && used+tokens > limit {
+		for _, request := range c.admitted {
+			used -= request.tokens
+			if used+tokens <= limit {
+				wait = request.at.Add(time.Minute).Sub(now)
+				break
+			}
+		}
+		if wait <= 0 && len(c.admitted) > 0 {
+			wait = c.admitted[len(c.admitted)-1].at.Add(time.Minute).Sub(now)
+		}
+	}
+
+	// The provider's view, minus what was admitted since it was reported
+	if observed && limits.LimitTokens > 0 {
+		if reset := limits.ObservedAt.Add(limits.ResetTokens); now.Before(reset) {
+			remaining := limits.RemainingTokens
+			for _, request := range c.admitted {
+				if !request.at.Before(limits.ObservedAt) {
+					remaining -= request.tokens
+				}
+			}
+			if remaining < tokens {
+				wait = max(wait, reset.Sub(now))
+			}
+		}
+	}
+
+	if wait > 0 {
+		return wait
+	}
+	c.admitted = append(c.admitted, admittedRequest{at: now, tokens: tokens})
+	return 0
+}
+
+// estimateRequestTokens estimates the tokens a request counts against the limit: its messages
+// and the completion token limit, as the provider reserves it up front
+func estimateRequestTokens(params openai.ChatCompletionNewParams) int64 {
+	tokens := int64(CountTokens(params.Model, params.Messages))
+	if params.MaxCompletionTokens.Valid() {
+		tokens += params.MaxCompletionTokens.Value
+	} else if params.MaxTokens.Valid() {
+		tokens += params.MaxTokens.Value
+	}
+	return tokens
+}
@@ -0,0 +1,40 @@
+package kit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdmissionControl(t *testing.T) {
+	now := time.Date(2025, 3, 10, 15, 0, 0, 0, time.UTC)
+	rateLimits := &rateLimitTracker{}
+	controller := newAdmissionController(AdmissionOptions{TokensPerMinute: 1000}, rateLimits)
+	controller.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	require.NoError(t, controller.admit(ctx, 600))
+	now = now.Add(10 * time.Second)
+	require.NoError(t, controller.admit(ctx, 300))
+
+	// The next request only fits once the first one left the window
+	err := controller.admit(ctx, 200)
+	require.ErrorIs(t, err, ErrAdmissionRejected)
+	var admissionErr *AdmissionError
+	require.ErrorAs(t, err, &admissionErr)
+	require.Equal(t, 50*time.Second, admissionErr.RetryAfter)
+	require.Equal(t, ErrorClassRateLimit, ClassifyError(err))
+
+	now = now.Add(50 * time.Second)
+	require.NoError(t, controller.admit(ctx, 200))
+
+	// The provider's remaining tokens apply until they reset
+	now = now.Add(time.Second)
+	rateLimits.set(RateLimits{LimitTokens: 1000, RemainingTokens: 100, ResetTokens: 5 * time.Second, ObservedAt: now})
+	require.ErrorIs(t, controller.admit(ctx, 150), ErrAdmissionRejected)
+	require.NoError(t, controller.admit(ctx, 100))
+	now = now.Add(5 * time.Second)
+	require.NoError(t, controller.admit(ctx, 150))
+}
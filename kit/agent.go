@@ -3,10 +3,16 @@ package kit
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"reflect"
+	"maps"
+	"slices"
+	"sort"
+	"time"
 
 	"github.com/mhrlife/goai-kit/internal/callback"
+	"github.com/mhrlife/goai-kit/internal/memory"
+	"github.com/mhrlife/goai-kit/internal/runs"
 	"github.com/mhrlife/goai-kit/internal/schema"
 	"github.com/openai/openai-go"
 	"github.com/openai/openai-go/option"
@@ -16,13 +22,58 @@ import (
 
 // Agent represents an AI agent that can execute tasks with tools
 type Agent[Output any] struct {
-	client        *Client
-	tools         map[string]ToolExecutor // toolID -> ToolExecutor
-	schemas       map[string]ToolSchema   // toolID -> ToolSchema
-	model         string
-	callbacks     []callback.AgentCallback
-	maxIterations int
-	temperature   *float64
+	name           string
+	client         *Client
+	tools          map[string]ToolExecutor // toolID -> ToolExecutor
+	schemas        map[string]ToolSchema   // toolID -> ToolSchema
+	model          string
+	callbacks      []callback.AgentCallback
+	maxIterations  int
+	temperature    *float64
+	transformers   []MessageTransformer
+	inputGuards    []InputGuard
+	outputGuards   []OutputGuard[Output]
+	guardRetry     bool
+	stopConds      []StopCondition
+	stopSequences  StopSequences
+	stripReasoning bool
+	runStore       runs.Store
+	templates      TemplateRenderer
+	trimStrategy   TrimStrategy
+	messageStore   memory.Store
+	quota          QuotaEnforcer
+	redactor       callback.Redactor
+	systemPrompt   string
+	systemTemplate *SystemTemplate
+	retry          RetryPolicy
+	promptCache    PromptCache
+	store          *bool
+	completionMeta map[string]string
+	logitBias      map[string]int
+	serviceTier    ServiceTier
+	openRouter     OpenRouterOptions
+	extraBody      map[string]any
+	dropReasoning  bool
+	reflection     *Reflection
+	reactMode      bool
+	toolExamples   []ToolExample
+	coalesce       bool
+	partialOnLimit bool
+	emptyResponse  EmptyResponsePolicy
+	continuations  int
+	overflow       *ContextOverflow
+	contextManager *ContextManager
+	modelPolicy    *ModelPolicy
+	experiment     *Experiment
+	outputVersions *OutputVersions[Output]
+	toolCache      *ToolCache
+	toolBudget     ToolBudget
+	refusal        RefusalPolicy
+	toolPolicy     ToolPolicy
+	toolErrors     ToolErrorHandling
+	toolFailures   int
+	toolTimeout    time.Duration
+	idempotency    *idempotencyKeys
 }
 
 // InvokeConfig contains configuration for agent invocation
@@ -33,17 +84,73 @@ type InvokeConfig struct {
 	// Messages is a list of OpenAI chat completion messages (mutually exclusive with Prompt)
 	Messages []openai.ChatCompletionMessageParamUnion
 
+	// Files are attached to the prompt, e.g. FileImage, FilePDF or FileAudio. With Messages,
+	// they are sent in an additional user message (optional)
+	Files []File
+
 	// Callbacks to be notified of agent lifecycle events
 	Callbacks []callback.AgentCallback
 
 	// ParentRunID for nested agent calls (optional)
 	ParentRunID *string
 
+	// Model overrides the agent's model and its model policy for this invoke (optional)
+	Model string
+
+	// Task labels the task of the invoke for the agent's model policy (optional)
+	Task string
+
 	// SystemPrompt to prepend to messages (optional)
 	SystemPrompt string
 
+	// SystemTemplate renders the system prompt from a registered template (optional,
+	// mutually exclusive with SystemPrompt)
+	SystemTemplate *SystemTemplate
+
 	// MaxIterations for tool calling loop (optional, defaults to agent's maxIterations)
 	MaxIterations *int
+
+	// Timeout bounds the wall-clock duration of the whole run, generations and tools included (optional)
+	Timeout time.Duration
+
+	// Canceller stops the run at the next safe point, returning its partial state (optional)
+	Canceller *Canceller
+
+	// ConversationID groups runs belonging to the same conversation. With a message store,
+	// the prior history is loaded before the run and the new turn is persisted after it (optional)
+	ConversationID string
+
+	// User identifies the end user the run is executed for. It is sent as the user parameter
+	// for the provider's abuse monitoring and reported to callbacks and traces as user_id (optional)
+	User string
+
+	// Metadata is attached to every callback event and trace span of the run, e.g. feature,
+	// tenant or experiment. Nested runs started from tools inherit it (optional)
+	Metadata map[string]string
+
+	// Tags are attached to every callback event and trace span of the run. Nested runs
+	// started from tools inherit them (optional)
+	Tags []string
+
+	// LogitBias maps token IDs to a bias from -100 to 100, overriding the agent's bias for
+	// the same tokens (optional)
+	LogitBias map[string]int
+
+	// IdempotencyKey makes repeated submissions return the original result instead of
+	// re-running the agent; concurrent submissions to the agent wait for the first one to end.
+	// Requires a run store (optional)
+	IdempotencyKey string
+
+	// AllowedTools restricts the run to the named tools, on top of the agent's tool policy. The
+	// other tools are neither offered to the model nor executed (optional, nil allows all)
+	AllowedTools []string
+
+	// OpenRouter overrides the agent's OpenRouter options; fields left empty keep the agent's (optional)
+	OpenRouter *OpenRouterOptions
+
+	// ExtraBody adds provider-specific fields to the request body of every generation of the
+	// run, overriding the agent's and any field set by the kit (optional)
+	ExtraBody map[string]any
 }
 
 // CreateAgent creates a new agent that returns string output
@@ -62,21 +169,42 @@ func CreateAgentWithOutput[Output any](client *Client, tools ...ToolExecutor) *A
 		schemaMap[toolSchema.ID] = toolSchema
 	}
 
-	model := "gpt-4o"
-	if client.config.DefaultModel != "" {
-		model = client.config.DefaultModel
-	}
-
 	return &Agent[Output]{
 		client:        client,
 		tools:         toolMap,
 		schemas:       schemaMap,
-		model:         model,
+		model:         client.defaultModel(),
 		callbacks:     []callback.AgentCallback{},
 		maxIterations: 10,
+		idempotency:   &idempotencyKeys{inflight: make(map[string]chan struct{})},
 	}
 }
 
+// Clone returns a copy of the agent that can be configured further, e.g. given more tools,
+// without changing the original
+func (a *Agent[Output]) Clone() *Agent[Output] {
+	clone := *a
+	clone.tools = maps.Clone(a.tools)
+	clone.schemas = maps.Clone(a.schemas)
+	clone.callbacks = slices.Clone(a.callbacks)
+	clone.transformers = slices.Clone(a.transformers)
+	clone.inputGuards = slices.Clone(a.inputGuards)
+	clone.outputGuards = slices.Clone(a.outputGuards)
+	clone.stopConds = slices.Clone(a.stopConds)
+	clone.completionMeta = maps.Clone(a.completionMeta)
+	clone.logitBias = maps.Clone(a.logitBias)
+	clone.extraBody = maps.Clone(a.extraBody)
+	clone.toolExamples = slices.Clone(a.toolExamples)
+	clone.idempotency = &idempotencyKeys{inflight: make(map[string]chan struct{})}
+	return &clone
+}
+
+// WithName sets the agent's name, reported to callbacks as agent_name
+func (a *Agent[Output]) WithName(name string) *Agent[Output] {
+	a.name = name
+	return a
+}
+
 // WithModel sets the model for the agent
 func (a *Agent[Output]) WithModel(model string) *Agent[Output] {
 	a.model = model
@@ -101,9 +229,41 @@ func (a *Agent[Output]) WithTemperature(temp float64) *Agent[Output] {
 	return a
 }
 
+// WithTools adds tools to the agent
+func (a *Agent[Output]) WithTools(tools ...ToolExecutor) *Agent[Output] {
+	for _, tool := range tools {
+		toolSchema := BuildToolSchema(tool)
+		a.tools[toolSchema.ID] = tool
+		a.schemas[toolSchema.ID] = toolSchema
+	}
+	return a
+}
+
+// WithMessageTransformer adds transformers that run on the message list before every LLM call
+func (a *Agent[Output]) WithMessageTransformer(transformers ...MessageTransformer) *Agent[Output] {
+	a.transformers = append(a.transformers, transformers...)
+	return a
+}
+
 // Invoke executes the agent with the given configuration
 func (a *Agent[Output]) Invoke(ctx context.Context, config InvokeConfig) (Output, error) {
-	var zero Output
+	result, err := a.InvokeDetailed(ctx, config)
+	if err != nil {
+		var zero Output
+		return zero, err
+	}
+	return result.Output, nil
+}
+
+// InvokeDetailed executes the agent like Invoke and also returns the details of the run
+func (a *Agent[Output]) InvokeDetailed(ctx context.Context, config InvokeConfig) (*InvokeResult[Output], error) {
+	// Return the original result of an already completed submission, waiting for a submission
+	// with the same key still in flight
+	previous, release, err := a.reserveIdempotencyKey(ctx, config.IdempotencyKey)
+	if err != nil || previous != nil {
+		return previous, err
+	}
+	defer release()
 
 	// merge all callbacks but when there are two callbacks with the same name, only keep
 	// the invoke callback
@@ -111,12 +271,50 @@ func (a *Agent[Output]) Invoke(ctx context.Context, config InvokeConfig) (Output
 
 	// Create callback manager
 	cbManager := callback.NewManager(allCallbacks, config.ParentRunID)
+	if a.name != "" {
+		cbManager.SetAttribute("agent_name", a.name)
+	}
+	if config.User != "" {
+		cbManager.SetAttribute("user_id", config.User)
+	}
+	if a.redactor != nil {
+		cbManager.SetRedactor(a.redactor)
+	}
+	cbManager.SetErrorClassifier(func(err error) (string, bool) {
+		class := ClassifyError(err)
+		return string(class), class.Retryable()
+	})
+
+	// Assign the run to an arm of the experiment, if any
+	arm := a.assignArm(&config)
+
+	// Attach the metadata and tags, merged with those of the enclosing run, if any
+	ctx, metadata, tags := withRunMetadata(ctx, config.Metadata, config.Tags)
+	if len(metadata) > 0 {
+		cbManager.SetAttribute("metadata", metadata)
+	}
+	if len(tags) > 0 {
+		cbManager.SetAttribute("tags", tags)
+	}
+	cbManager.SetContext(ctx)
+
+	// Render the system template, if any, so everything downstream sees a plain system prompt
+	if err := a.resolveSystemTemplate(&config); err != nil {
+		cbManager.OnError(err, "run")
+		return nil, err
+	}
 
 	// Build messages
-	messages, err := a.buildMessages(config)
+	messages, input, err := a.buildMessages(ctx, config)
 	if err != nil {
 		cbManager.OnError(err, "run")
-		return zero, err
+		return nil, err
+	}
+
+	// Pick the model of the run
+	if err := a.selectModel(&config, messages); err != nil {
+		cbManager.OnError(err, "run")
+		return nil, err
 	}
 
 	// Determine if we have a typed output
@@ -124,11 +322,27 @@ func (a *Agent[Output]) Invoke(ctx context.Context, config InvokeConfig) (Output
 	hasOutputClass := !isStringType(outputType)
 
 	// Trigger OnRunStart
-	input := config.Prompt
+	runInput := config.Prompt
 	if config.Prompt == "" {
-		input = "messages"
+		runInput = "messages"
+	}
+	schemas := a.runSchemas(ctx, config)
+	cbManager.OnRunStart(a.runModel(config), runInput, hasOutputClass, schemaNames(schemas))
+	startedAt := time.Now()
+
+	// Enforce the user's quota before spending any tokens
+	if err := a.checkQuota(ctx, config.User); err != nil {
+		cbManager.OnError(err, "quota")
+		a.recordRun(ctx, config, startedAt, &InvokeResult[Output]{RunID: cbManager.RunID(), Messages: messages}, err)
+		return nil, err
+	}
+
+	// Run input guardrails before spending any tokens
+	if err := a.checkInput(ctx, messages); err != nil {
+		cbManager.OnError(err, "input_guard")
+		a.recordRun(ctx, config, startedAt, &InvokeResult[Output]{RunID: cbManager.RunID(), Messages: messages}, err)
+		return nil, err
 	}
-	cbManager.OnRunStart(a.model, input, hasOutputClass)
 
 	// Determine max iterations
 	maxIter := a.maxIterations
@@ -136,15 +350,48 @@ func (a *Agent[Output]) Invoke(ctx context.Context, config InvokeConfig) (Output
 		maxIter = *config.MaxIterations
 	}
 
+	// Bound the whole run by the configured timeout
+	runCtx := ctx
+	if config.Timeout > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeoutCause(ctx, config.Timeout, &TimeoutError{Timeout: config.Timeout})
+		defer cancel()
+	}
+
 	// Execute the agent loop
-	result, iterations, err := a.executeLoop(ctx, messages, cbManager, maxIter)
+	result, err := a.executeLoop(runCtx, config, messages, schemas, cbManager, maxIter)
+	result.ExperimentArm = arm
+	a.recordQuota(ctx, config.User, result)
 	if err != nil {
+		// A cancelled run returns its partial state
+		if errors.Is(err, ErrRunCancelled) {
+			cbManager.OnError(err, "cancel")
+			a.recordRun(ctx, config, startedAt, result, err)
+			return result, err
+		}
+
+		// So does a run out of iterations, when enabled; the loop already reported the error
+		if result.Partial {
+			a.recordRun(ctx, config, startedAt, result, err)
+			return result, err
+		}
+
+		err = timeoutCause(ctx, runCtx, result.Iterations, err)
 		cbManager.OnError(err, "run")
-		return zero, err
+		a.recordRun(ctx, config, startedAt, result, err)
+		return nil, err
+	}
+
+	// Persist the new turn of the conversation, before the run is reported as successful
+	if err := a.persistTurn(ctx, config, input, result); err != nil {
+		cbManager.OnError(err, "run")
+		a.recordRun(ctx, config, startedAt, result, err)
+		return nil, err
 	}
 
 	// Trigger OnRunEnd
-	cbManager.OnRunEnd(result, iterations)
+	cbManager.OnRunEnd(a.traceOutput(result.Output), result.Iterations, &result.Usage)
+	a.recordRun(ctx, config, startedAt, result, nil)
 
 	return result, nil
 }
@@ -165,155 +412,362 @@ func (a *Agent[Output]) mergeCallbacks(invokeCallbacks []callback.AgentCallback)
 	return allCallbacks
 }
 
-// buildMessages constructs the message list from InvokeConfig
-func (a *Agent[Output]) buildMessages(config InvokeConfig) ([]openai.ChatCompletionMessageParamUnion, error) {
-	var messages []openai.ChatCompletionMessageParamUnion
-
-	// Add system prompt if provided
-	if config.SystemPrompt != "" {
-		messages = append(messages, openai.SystemMessage(config.SystemPrompt))
-	}
-
+// buildMessages constructs the message list from InvokeConfig, including the stored
+// conversation history. It also returns the new input messages of this turn.
+func (a *Agent[Output]) buildMessages(
+	ctx context.Context,
+	config InvokeConfig,
+) (messages, input []openai.ChatCompletionMessageParamUnion, err error) {
 	// Use either Prompt or Messages
 	if config.Prompt != "" && len(config.Messages) > 0 {
-		return nil, fmt.Errorf("cannot specify both Prompt and Messages")
+		return nil, nil, fmt.Errorf("cannot specify both Prompt and Messages")
 	}
 
 	if config.Prompt != "" {
-		messages = append(messages, openai.UserMessage(config.Prompt))
+		input = []openai.ChatCompletionMessageParamUnion{PromptMessage(config.Prompt, config.Files)}
 	} else if len(config.Messages) > 0 {
-		messages = append(messages, config.Messages...)
+		input = config.Messages
+		if len(config.Files) > 0 {
+			input = append(slices.Clone(input), PromptMessage("", config.Files))
+		}
 	} else {
-		return nil, fmt.Errorf("must specify either Prompt or Messages")
+		return nil, nil, fmt.Errorf("must specify either Prompt or Messages")
 	}
 
-	return messages, nil
+	// Load the prior conversation history
+	history, err := a.loadHistory(ctx, config.ConversationID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Inject the few-shot tool examples
+	examples, err := a.exampleMessages()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Add system prompt if provided
+	if config.SystemPrompt != "" {
+		messages = append(messages, openai.SystemMessage(config.SystemPrompt))
+	}
+	messages = append(messages, examples...)
+	messages = append(messages, history...)
+	messages = append(messages, input...)
+
+	return messages, input, nil
 }
 
 // executeLoop runs the agent's tool calling loop
 func (a *Agent[Output]) executeLoop(
 	ctx context.Context,
+	config InvokeConfig,
 	messages []openai.ChatCompletionMessageParamUnion,
+	schemas []ToolSchema,
 	cbManager *callback.Manager,
 	maxIterations int,
-) (Output, int, error) {
-	var zero Output
-	iteration := 0
-
-	// Convert tool schemas to OpenAI tool definitions
-	tools := make([]openai.ChatCompletionToolParam, 0, len(a.schemas))
-	for _, toolSchema := range a.schemas {
+) (*InvokeResult[Output], error) {
+	run := &InvokeResult[Output]{
+		RunID:    cbManager.RunID(),
+		Messages: messages,
+	}
+	guardRetried := false
+	reflected := false
+	lastContent := ""
+	nudges := 0
+	model := a.runModel(config)
+	overflowRetries := 0
+	budgetUsage := &toolUsage{}
+	wrappedUp := false
+	refusals := 0
+
+	// Convert tool schemas to OpenAI tool definitions, in a stable order so the request
+	// prefix stays cacheable across generations
+	tools := make([]openai.ChatCompletionToolParam, 0, len(schemas))
+	for _, toolSchema := range schemas {
 		tools = append(tools, openai.ChatCompletionToolParam{
 			Function: shared.FunctionDefinitionParam{
 				Name:        toolSchema.Name,
 				Description: param.NewOpt(toolSchema.Description),
 				Parameters:  toolSchema.JSONSchema,
-				Strict:      param.NewOpt(true),
+				Strict:      param.NewOpt(toolSchema.Strict && a.client.strictSchemas()),
 			},
 		})
 	}
 
-	for iteration < maxIterations {
-		iteration++
+	for run.Iterations < maxIterations {
+		// Stop at the safe point between iterations when the run was cancelled
+		if err := config.Canceller.check(run.Iterations); err != nil {
+			return run, err
+		}
+		run.Iterations++
+		cbManager.OnProgress(
+			run.Iterations,
+			maxIterations,
+			float64(run.Iterations-1)/float64(maxIterations)*100,
+			fmt.Sprintf("iteration %d of at most %d", run.Iterations, maxIterations),
+			"",
+		)
+
+		// Trim the history according to the configured strategy
+		trimmed, err := a.trimHistory(ctx, model, run.Messages, cbManager.OnHistoryTrim)
+		if err != nil {
+			cbManager.OnError(err, "generation")
+			return run, err
+		}
+		run.Messages = trimmed
+
+		// Evict the oldest messages that don't fit the model's context window
+		offeredTools := tools
+		if a.reactMode || wrappedUp {
+			offeredTools = nil
+		}
+		run.Messages = a.fitContext(run.Messages, model, offeredTools, cbManager.OnHistoryTrim)
+
+		// Apply message transformers; the history itself is left untouched
+		requestMessages := a.transformMessages(run.Messages)
+		if a.reactMode {
+			requestMessages = append([]openai.ChatCompletionMessageParamUnion{a.reactInstructions(schemas)}, requestMessages...)
+		}
 
 		// Trigger OnGenerationStart
-		cbManager.OnGenerationStart(iteration, messages, a.model)
+		cbManager.OnGenerationStart(run.Iterations, requestMessages, model)
 
 		// Build request params
 		params := openai.ChatCompletionNewParams{
-			Model:    a.model,
-			Messages: messages,
+			Model:    model,
+			Messages: requestMessages,
 		}
 
 		if a.temperature != nil {
 			params.Temperature = param.NewOpt(*a.temperature)
 		}
+		a.applyParams(&params, config)
+		a.stopSequences.apply(&params, len(tools) > 0 && !wrappedUp)
 
-		// Add tools if available
-		if len(tools) > 0 {
-			params.Tools = tools
+		// Add tools if available; in ReAct mode they are described in the instructions instead.
+		// They are no longer offered once the tool call budget is used up.
+		if len(offeredTools) > 0 {
+			params.Tools = offeredTools
 		}
 
 		// Check if Output is a struct type for response_format
 		var outputType Output
-		if !isStringType(outputType) {
+		if !isStringType(outputType) && !a.reactMode {
 			// Add response format for structured output
-			outputSchema := schema.InferJSONSchema(outputType)
-			params.ResponseFormat = openai.ChatCompletionNewParamsResponseFormatUnion{
-				OfJSONSchema: &shared.ResponseFormatJSONSchemaParam{
-					JSONSchema: shared.ResponseFormatJSONSchemaJSONSchemaParam{
-						Strict: param.NewOpt(true),
-						Name:   "response",
-						Schema: outputSchema,
-					},
-				},
-			}
+			outputFormat[Output](a.client, &params)
 		}
 
+		// Apply the prompt caching controls
+		a.promptCache.apply(&params)
+		a.openRouter.merge(config.OpenRouter).apply(&params)
+		a.applyExtraBody(&params, config)
+
 		// Call OpenAI API
-		completion, err := a.client.client.Chat.Completions.New(ctx, params)
+		completion, info, err := a.createCompletion(a.watchOutputFields(ctx, cbManager), params, cbManager)
 		if err != nil {
+			// Retry the iteration with a shorter history or a larger model, if configured
+			trimmed, nextModel, ok, trimErr := a.recoverOverflow(ctx, err, run.Messages, model, overflowRetries, cbManager)
+			if trimErr != nil {
+				cbManager.OnError(trimErr, "generation")
+				return run, trimErr
+			}
+			if ok {
+				overflowRetries++
+				run.Messages = trimmed
+				model = nextModel
+				run.Iterations--
+				continue
+			}
+
+			err = overflowError(err, overflowRetries)
 			cbManager.OnError(err, "generation")
-			return zero, iteration, fmt.Errorf("OpenAI API error: %w", err)
+			return run, fmt.Errorf("OpenAI API error: %w", err)
 		}
 
 		if len(completion.Choices) == 0 {
 			err := fmt.Errorf("no choices in response")
 			cbManager.OnError(err, "generation")
-			return zero, iteration, err
+			return run, err
 		}
 
 		choice := completion.Choices[0]
 		finishReason := string(choice.FinishReason)
 		content := choice.Message.Content
 		toolCalls := choice.Message.ToolCalls
+		if a.reactMode {
+			toolCalls, content = a.reactStep(content, run.Iterations)
+		} else {
+			content = a.collectImages(run, choice.Message)
+		}
+		if content != "" {
+			lastContent = content
+		}
+		citations := extractCitations(completion)
+		run.Citations = append(run.Citations, citations...)
+		reasoning := reasoningContent(choice.Message)
+		if reasoning != "" {
+			run.ReasoningContent = append(run.ReasoningContent, reasoning)
+		}
 
 		// Trigger OnGenerationEnd
-		cbManager.OnGenerationEnd(finishReason, content, toolCalls, &completion.Usage)
+		cbManager.OnGenerationEnd(
+			finishReason,
+			a.traceContent(choice.Message.Content),
+			a.traceReasoningContent(reasoning),
+			toolCalls,
+			citations,
+			&completion.Usage,
+			info,
+		)
+
+		addUsage(&run.Usage, completion.Usage)
+
+		// Surface refusals and filtered responses instead of parsing them, retrying if configured
+		if refusal := refusalError(choice.Message, finishReason); refusal != nil && !a.reactMode {
+			if rephrase, ok := a.refusal.rephraseMessage(refusals); ok {
+				refusals++
+				cbManager.OnRetry("refusal", refusal, string(ErrorClassContentFilter), refusals, 0, model)
+				run.appendGuidance(rephrase)
+				continue
+			}
+			cbManager.OnError(refusal, "generation")
+			return run, refusal
+		}
+
+		// Continue a final response cut off by the token limit
+		if finishReason == "length" && len(toolCalls) == 0 && a.continuations > 0 && !a.reactMode {
+			content, finishReason, err = a.continueTruncated(ctx, run, params, content, cbManager)
+			if err != nil {
+				cbManager.OnError(err, "generation")
+				return run, err
+			}
+			choice.Message.Content = content
+			lastContent = content
+		}
+
+		// Handle empty responses, nudging the model for its answer when configured
+		if isEmptyResponse(content, toolCalls) {
+			nudge, ok, err := a.emptyResponse.handleEmpty(finishReason, nudges)
+			if err != nil {
+				cbManager.OnError(err, "generation")
+				return run, err
+			}
+			if ok {
+				nudges++
+				a.reportNudge(cbManager, model, finishReason, nudges)
+				run.appendGuidance(nudge)
+				continue
+			}
+		}
+
+		// Content sent alongside tool calls may end the run
+		if !a.reactMode && a.preferContent(&choice.Message, content) {
+			toolCalls = nil
+		}
 
 		// Add assistant message to history
-		messages = append(messages, choice.Message.ToParam())
+		run.appendMessages(a.assistantMessage(choice.Message, reasoning))
 
 		// Check if we're done (no tool calls means we have final response)
 		if len(toolCalls) == 0 {
-			// Parse output
-			if isStringType(outputType) {
-				// Return string directly
-				return any(content).(Output), iteration, nil
+			if a.client.promptSchemas() && !a.reactMode {
+				content = extractJSON[Output](content)
 			}
-
-			// Parse JSON for structured output
-			var result Output
-			if err := json.Unmarshal([]byte(content), &result); err != nil {
+			result, err := parseOutput[Output](content)
+			if err != nil {
 				cbManager.OnError(err, "generation")
-				return zero, iteration, fmt.Errorf("failed to parse output JSON: %w", err)
+				return run, err
 			}
-			return result, iteration, nil
+
+			// Let the critic review the draft, revising it once unless approved
+			if a.reflection != nil && !reflected {
+				reflected = true
+				critique, usage, err := a.critique(ctx, run.Messages, content, cbManager)
+				addUsage(&run.Usage, usage)
+				if err != nil {
+					cbManager.OnError(err, "reflection")
+					return run, err
+				}
+				if critique != "" {
+					cbManager.OnRetry("reflection", fmt.Errorf("revision requested: %s", critique), "revision_requested", 1, 0, model)
+					run.appendGuidance(revisionMessage(critique))
+					continue
+				}
+			}
+
+			// Run output guardrails, optionally retrying once with guidance
+			guarded, err := a.checkOutput(ctx, result)
+			if err != nil {
+				if a.guardRetry && !guardRetried {
+					guardRetried = true
+					cbManager.OnRetry("output_guard", err, "output_rejected", 1, 0, model)
+					run.appendGuidance(outputGuidanceMessage(err))
+					continue
+				}
+				cbManager.OnError(err, "output_guard")
+				return run, err
+			}
+			run.Output = guarded
+			return run, nil
 		}
 
 		// Execute tool calls
 		if len(toolCalls) > 0 {
-			toolMessages, err := a.executeToolCalls(ctx, toolCalls, cbManager)
+			toolMessages, err := a.executeToolCalls(ctx, toolCalls, schemas, cbManager, run.Iterations, maxIterations, budgetUsage)
 			if err != nil {
 				cbManager.OnError(err, "tool")
-				return zero, iteration, err
+				return run, err
+			}
+			if a.reactMode {
+				toolMessages = reactObservations(toolMessages)
+			}
+			run.appendMessages(toolMessages...)
+
+			// Ask for the final answer once the tool call budget is used up
+			if a.toolBudget.WrapUp && !wrappedUp && a.toolBudget.exhausted(budgetUsage) {
+				wrappedUp = true
+				run.appendGuidance(a.toolBudget.wrapUpMessage())
 			}
-			messages = append(messages, toolMessages...)
+		}
+
+		// Check custom stop conditions once the iteration is complete
+		state := LoopState{
+			Iteration: run.Iterations,
+			Messages:  run.Messages,
+			Content:   content,
+			ToolCalls: toolCalls,
+			Usage:     run.Usage,
+		}
+		if a.shouldStop(state) {
+			result, err := stopOutput[Output](content)
+			if err != nil {
+				cbManager.OnError(err, "run")
+				return run, err
+			}
+			run.Output = result
+			return run, nil
 		}
 	}
 
-	err := fmt.Errorf("max iterations (%d) reached without completion", maxIterations)
+	if a.partialOnLimit {
+		run.markPartial(lastContent)
+	}
+	err := fmt.Errorf("%w (limit %d)", ErrMaxIterations, maxIterations)
 	cbManager.OnError(err, "run")
-	return zero, iteration, err
+	return run, err
 }
 
 // executeToolCalls executes all tool calls and returns tool messages
 func (a *Agent[Output]) executeToolCalls(
 	ctx context.Context,
 	toolCalls []openai.ChatCompletionMessageToolCall,
+	schemas []ToolSchema,
 	cbManager *callback.Manager,
+	step, totalSteps int,
+	usage *toolUsage,
 ) ([]openai.ChatCompletionMessageParamUnion, error) {
 	var toolMessages []openai.ChatCompletionMessageParamUnion
+	var attachments []openai.ChatCompletionContentPartUnionParam
 
 	// Execute each tool call
 	for _, toolCall := range toolCalls {
@@ -323,72 +777,150 @@ func (a *Agent[Output]) executeToolCalls(
 		// Parse arguments
 		var args map[string]interface{}
 		if err := json.Unmarshal([]byte(toolCall.Function.Arguments), &args); err != nil {
+			err = fmt.Errorf("failed to parse tool arguments: %w", err)
 			cbManager.OnToolCallEnd(toolName, args, nil, toolCallID, err)
-			return nil, fmt.Errorf("failed to parse tool arguments: %w", err)
+			message, err := a.toolFailed(ctx, err, err, toolCallID, usage, cbManager)
+			if err != nil {
+				return nil, err
+			}
+			toolMessages = append(toolMessages, message)
+			continue
 		}
 
 		// Trigger OnToolCallStart
 		cbManager.OnToolCallStart(toolName, args, toolCallID)
 
-		// Find tool by name in schemas and tools maps
+		// Find tool by name among the run's tools
 		var foundToolID string
-		for id, toolSchema := range a.schemas {
+		for _, toolSchema := range schemas {
 			if toolSchema.Name == toolName {
-				foundToolID = id
+				foundToolID = toolSchema.ID
 				break
 			}
 		}
 
 		if foundToolID == "" {
-			err := fmt.Errorf("tool not found: %s", toolName)
+			err := fmt.Errorf("%w: %s", ErrToolNotFound, toolName)
+			if slices.ContainsFunc(a.sortedSchemas(), func(s ToolSchema) bool { return s.Name == toolName }) {
+				err = fmt.Errorf("%w: %s", ErrToolNotAllowed, toolName)
+			}
 			cbManager.OnToolCallEnd(toolName, args, nil, toolCallID, err)
-			return nil, err
+			message, err := a.toolFailed(ctx, err, err, toolCallID, usage, cbManager)
+			if err != nil {
+				return nil, err
+			}
+			toolMessages = append(toolMessages, message)
+			continue
 		}
 
 		executor := a.tools[foundToolID]
 
-		// Create a copy of the tool struct to unmarshal args into
-		toolValue := reflect.ValueOf(executor)
-		if toolValue.Kind() == reflect.Ptr {
-			toolValue = toolValue.Elem()
+		// Enforce the tool call budget of the run
+		if err := a.toolBudget.use(usage, toolName); err != nil {
+			cbManager.OnToolCallEnd(toolName, args, nil, toolCallID, err)
+			if !a.toolBudget.WrapUp {
+				return nil, err
+			}
+			toolMessages = append(toolMessages, rejectedCall(err, toolCallID))
+			continue
 		}
 
-		// Create a new instance of the tool
-		toolCopy := reflect.New(toolValue.Type()).Interface().(ToolExecutor)
+		// Serve cached results of idempotent tools without executing them
+		cacheKey, cacheTTL, cached := a.toolCache.key(executor, toolName, toolCall.Function.Arguments)
+		if cached {
+			if result, ok := a.cachedToolResult(ctx, cacheKey); ok {
+				cbManager.OnToolCallEnd(toolName, args, result, toolCallID, nil)
+				toolMessages = append(toolMessages, openai.ToolMessage(result, toolCallID))
+				usage.failures = nil
+				continue
+			}
+		}
 
-		// Unmarshal args into the tool copy
-		if err := json.Unmarshal([]byte(toolCall.Function.Arguments), toolCopy); err != nil {
+		// Bind the arguments to a fresh instance of the tool
+		toolCopy, err := toolInstance(executor, toolCall.Function.Arguments)
+		if err != nil {
+			err = fmt.Errorf("failed to unmarshal tool arguments: %w", err)
 			cbManager.OnToolCallEnd(toolName, args, nil, toolCallID, err)
-			return nil, fmt.Errorf("failed to unmarshal tool arguments: %w", err)
+			message, err := a.toolFailed(ctx, err, err, toolCallID, usage, cbManager)
+			if err != nil {
+				return nil, err
+			}
+			toolMessages = append(toolMessages, message)
+			continue
 		}
 
 		// Create Context wrapper
 		ctxWrapper := &Context{
-			Context: ctx,
-			logger:  a.client.Logger,
+			Context: WithProgressReporter(ctx, func(percent float64, message string) {
+				cbManager.OnProgress(step, totalSteps, percent, message, toolName)
+			}),
+			logger: a.client.Logger,
 		}
 
-		// Execute tool
-		result, err := toolCopy.Execute(ctxWrapper)
+		// Execute tool, within its timeout and retry policy
+		result, err := executeWithRetry(ctxWrapper, toolCopy, toolName, a.timeoutFor(executor), cbManager)
 		cbManager.OnToolCallEnd(toolName, args, result, toolCallID, err)
 
 		if err != nil {
-			return nil, fmt.Errorf("tool %s failed: %w", toolName, err)
+			message, err := a.toolFailed(ctx, err, &ToolFailedError{Tool: toolName, Err: err}, toolCallID, usage, cbManager)
+			if err != nil {
+				return nil, err
+			}
+			toolMessages = append(toolMessages, message)
+			continue
+		}
+		usage.failures = nil
+		if cached {
+			a.cacheToolResult(ctx, cacheKey, result, cacheTTL)
 		}
 
-		// Convert result to string
-		resultStr, err := resultToString(result)
+		// Convert the result into a tool message, collecting rich content to attach
+		toolMessage, parts, err := toolResultMessage(result, toolCallID)
 		if err != nil {
-			return nil, fmt.Errorf("failed to convert tool result to string: %w", err)
+			return nil, err
 		}
+		toolMessages = append(toolMessages, toolMessage)
+		attachments = append(attachments, parts...)
+	}
 
-		// Add tool message
-		toolMessages = append(toolMessages, openai.ToolMessage(resultStr, toolCallID))
+	// Files returned by tools follow all tool messages, which must directly follow the tool calls
+	if len(attachments) > 0 {
+		toolMessages = append(toolMessages, openai.UserMessage(attachments))
 	}
 
 	return toolMessages, nil
 }
 
+// responseFormat builds the JSON schema response format for a structured output, strict unless
+// the provider doesn't support it
+func responseFormat[Output any](strict bool) openai.ChatCompletionNewParamsResponseFormatUnion {
+	var outputType Output
+	return openai.ChatCompletionNewParamsResponseFormatUnion{
+		OfJSONSchema: &shared.ResponseFormatJSONSchemaParam{
+			JSONSchema: shared.ResponseFormatJSONSchemaJSONSchemaParam{
+				Strict: param.NewOpt(strict),
+				Name:   "response",
+				Schema: schema.InferJSONSchema(outputType),
+			},
+		},
+	}
+}
+
+// parseOutput converts the final assistant content into the typed output
+func parseOutput[Output any](content string) (Output, error) {
+	var result Output
+	if isStringType(result) {
+		// Return string directly
+		return any(content).(Output), nil
+	}
+
+	// Parse JSON for structured output
+	if err := json.Unmarshal([]byte(content), &result); err != nil {
+		return result, fmt.Errorf("%w: %w", ErrOutputParse, err)
+	}
+	return result, nil
+}
+
 // resultToString converts tool result to string representation
 func resultToString(result interface{}) (string, error) {
 	if result == nil {
@@ -443,6 +975,33 @@ func (a *Agent[Output]) Tools() []ToolExecutor {
 	return tools
 }
 
+// toolNames returns the sorted names of the agent's tools
+func (a *Agent[Output]) toolNames() []string {
+	names := make([]string, 0, len(a.schemas))
+	for _, toolSchema := range a.schemas {
+		names = append(names, toolSchema.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// sortedSchemas returns the tool schemas ordered by tool name
+func (a *Agent[Output]) sortedSchemas() []ToolSchema {
+	schemas := make([]ToolSchema, 0, len(a.schemas))
+	for _, toolSchema := range a.schemas {
+		schemas = append(schemas, toolSchema)
+	}
+	sort.Slice(schemas, func(i, j int) bool {
+		return schemas[i].Name < schemas[j].Name
+	})
+	return schemas
+}
+
+// Name returns the agent's name
+func (a *Agent[Output]) Name() string {
+	return a.name
+}
+
 // Model returns the agent's model
 func (a *Agent[Output]) Model() string {
 	return a.model
@@ -0,0 +1,405 @@
+package kit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
+)
+
+// ModelClaudeSonnet is the default model of NewAnthropicClient
+const ModelClaudeSonnet = "claude-sonnet-4-5"
+
+// AnthropicOptions configures an AnthropicProvider. All fields but APIKey are optional.
+type AnthropicOptions struct {
+	APIKey string
+
+	// BaseURL of the API (defaults to https://api.anthropic.com)
+	BaseURL string
+
+	// Version is sent as the anthropic-version header (defaults to 2023-06-01)
+	Version string
+
+	// MaxTokens limits requests that set no token limit, which the Messages API requires
+	// (defaults to 4096)
+	MaxTokens int64
+
+	// HTTPClient sends the requests (defaults to http.DefaultClient)
+	HTTPClient *http.Client
+}
+
+// AnthropicProvider serves chat completions with Anthropic's Messages API. System messages
+// become the system prompt, tool calls and results map to tool_use and tool_result blocks, and
+// structured outputs are requested through a final_answer tool whose input is the output.
+type AnthropicProvider struct {
+	opts AnthropicOptions
+}
+
+// NewAnthropicProvider creates a provider for Anthropic's Messages API
+func NewAnthropicProvider(opts AnthropicOptions) *AnthropicProvider {
+	if opts.BaseURL == "" {
+		opts.BaseURL = "https://api.anthropic.com"
+	}
+	if opts.Version == "" {
+		opts.Version = "2023-06-01"
+	}
+	if opts.MaxTokens <= 0 {
+		opts.MaxTokens = 4096
+	}
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = http.DefaultClient
+	}
+	return &AnthropicProvider{opts: opts}
+}
+
+// NewAnthropicClient creates a Client for Anthropic, authenticated with ANTHROPIC_API_KEY. The
+// options override the preset; WithAPIKey and WithBaseURL configure the provider.
+func NewAnthropicClient(opts ...ClientOption) *Client {
+	presetOpt := func(c *Config) {
+		c.ApiBase = ""
+		c.ApiKey = os.Getenv("ANTHROPIC_API_KEY")
+		c.DefaultModel = ModelClaudeSonnet
+	}
+	providerOpt := func(c *Config) {
+		if c.Provider == nil {
+			c.Provider = NewAnthropicProvider(AnthropicOptions{APIKey: c.ApiKey, BaseURL: c.ApiBase})
+		}
+	}
+
+	options := append([]ClientOption{presetOpt}, opts...)
+	return NewClient(append(options, providerOpt)...)
+}
+
+// Complete translates the request to the Messages API and its response back. The OpenAI
+// request options are ignored.
+func (p *AnthropicProvider) Complete(
+	ctx context.Context,
+	params openai.ChatCompletionNewParams,
+	_ ...option.RequestOption,
+) (*openai.ChatCompletion, error) {
+	request, err := anthropicRequest(params, p.opts.MaxTokens)
+	if err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal anthropic request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(p.opts.BaseURL, "/")+"/v1/messages", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("x-api-key", p.opts.APIKey)
+	httpReq.Header.Set("anthropic-version", p.opts.Version)
+
+	resp, err := p.opts.HTTPClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		return nil, anthropicError(httpReq, resp, data)
+	}
+
+	var message anthropicMessage
+	if err := json.Unmarshal(data, &message); err != nil {
+		return nil, fmt.Errorf("failed to decode anthropic response: %w", err)
+	}
+	return message.completion()
+}
+
+type anthropicTool struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	InputSchema any    `json:"input_schema"`
+}
+
+type anthropicBlock struct {
+	Type string `json:"type"`
+
+	// text
+	Text string `json:"text,omitempty"`
+
+	// image and document
+	Source *anthropicSource `json:"source,omitempty"`
+
+	// tool_use
+	ID    string          `json:"id,omitempty"`
+	Name  string          `json:"name,omitempty"`
+	Input json.RawMessage `json:"input,omitempty"`
+
+	// tool_result
+	ToolUseID string `json:"tool_use_id,omitempty"`
+	Content   string `json:"content,omitempty"`
+}
+
+type anthropicSource struct {
+	Type      string `json:"type"`
+	MediaType string `json:"media_type,omitempty"`
+	Data      string `json:"data,omitempty"`
+	URL       string `json:"url,omitempty"`
+}
+
+type anthropicTurn struct {
+	Role    string           `json:"role"`
+	Content []anthropicBlock `json:"content"`
+}
+
+type anthropicMessagesRequest struct {
+	Model         string          `json:"model"`
+	MaxTokens     int64           `json:"max_tokens"`
+	System        string          `json:"system,omitempty"`
+	Messages      []anthropicTurn `json:"messages"`
+	Tools         []anthropicTool `json:"tools,omitempty"`
+	ToolChoice    map[string]any  `json:"tool_choice,omitempty"`
+	Temperature   *float64        `json:"temperature,omitempty"`
+	TopP          *float64        `json:"top_p,omitempty"`
+	StopSequences []string        `json:"stop_sequences,omitempty"`
+}
+
+// anthropicRequest translates a chat completion request to the Messages API
+func anthropicRequest(params openai.ChatCompletionNewParams, defaultMaxTokens int64) (*anthropicMessagesRequest, error) {
+	request := &anthropicMessagesRequest{
+		Model:     params.Model,
+		MaxTokens: defaultMaxTokens,
+	}
+	switch {
+	case params.MaxCompletionTokens.Valid():
+		request.MaxTokens = params.MaxCompletionTokens.Value
+	case params.MaxTokens.Valid():
+		request.MaxTokens = params.MaxTokens.Value
+	}
+	if params.Temperature.Valid() {
+		request.Temperature = &params.Temperature.Value
+	}
+	if params.TopP.Valid() {
+		request.TopP = &params.TopP.Value
+	}
+	if params.Stop.OfString.Valid() {
+		request.StopSequences = []string{params.Stop.OfString.Value}
+	}
+	request.StopSequences = append(request.StopSequences, params.Stop.OfStringArray...)
+
+	var system []string
+	for _, msg := range params.Messages {
+		switch {
+		case msg.OfSystem != nil, msg.OfDeveloper != nil:
+			system = append(system, MessageText(msg))
+		case msg.OfUser != nil:
+			request.addTurn("user", anthropicUserBlocks(msg.OfUser)...)
+		case msg.OfAssistant != nil:
+			blocks, err := anthropicAssistantBlocks(msg)
+			if err != nil {
+				return nil, err
+			}
+			request.addTurn("assistant", blocks...)
+		case msg.OfTool != nil:
+			request.addTurn("user", anthropicBlock{
+				Type:      "tool_result",
+				ToolUseID: msg.OfTool.ToolCallID,
+				Content:   MessageText(msg),
+			})
+		}
+	}
+
+	for _, tool := range params.Tools {
+		request.Tools = append(request.Tools, anthropicTool{
+			Name:        tool.Function.Name,
+			Description: tool.Function.Description.Value,
+			InputSchema: anthropicSchema(tool.Function.Parameters),
+		})
+	}
+
+	switch {
+	case params.ToolChoice.OfChatCompletionNamedToolChoice != nil:
+		request.ToolChoice = map[string]any{"type": "tool", "name": params.ToolChoice.OfChatCompletionNamedToolChoice.Function.Name}
+	case params.ToolChoice.OfAuto.Value == "required":
+		request.ToolChoice = map[string]any{"type": "any"}
+	case params.ToolChoice.OfAuto.Value == "none":
+		request.ToolChoice = map[string]any{"type": "none"}
+	}
+
+	// Structured outputs are the input of the output tool, which the model must call unless it
+	// calls one of the other tools
+	if format := params.ResponseFormat.OfJSONSchema; format != nil {
+		request.Tools = append(request.Tools, anthropicTool{
+			Name:        outputTool,
+			Description: outputToolDescription,
+			InputSchema: anthropicSchema(format.JSONSchema.Schema),
+		})
+		if request.ToolChoice == nil {
+			request.ToolChoice = map[string]any{"type": "any"}
+			if len(params.Tools) == 0 {
+				request.ToolChoice = map[string]any{"type": "tool", "name": outputTool}
+			}
+		}
+	} else if params.ResponseFormat.OfJSONObject != nil {
+		system = append(system, "Respond with a single JSON object and nothing else.")
+	}
+
+	request.System = strings.Join(system, "\n\n")
+	return request, nil
+}
+
+// addTurn appends the blocks to the conversation, merging consecutive turns of the same role
+// since the Messages API requires alternating roles
+func (r *anthropicMessagesRequest) addTurn(role string, blocks ...anthropicBlock) {
+	if len(blocks) == 0 {
+		return
+	}
+	if n := len(r.Messages); n > 0 && r.Messages[n-1].Role == role {
+		r.Messages[n-1].Content = append(r.Messages[n-1].Content, blocks...)
+		return
+	}
+	r.Messages = append(r.Messages, anthropicTurn{Role: role, Content: blocks})
+}
+
+// anthropicSchema returns the tool input schema, which must describe an object
+func anthropicSchema(schema any) any {
+	if schema == nil {
+		return map[string]any{"type": "object"}
+	}
+	return schema
+}
+
+func anthropicUserBlocks(msg *openai.ChatCompletionUserMessageParam) []anthropicBlock {
+	if msg.Content.OfString.Valid() {
+		if msg.Content.OfString.Value == "" {
+			return nil
+		}
+		return []anthropicBlock{{Type: "text", Text: msg.Content.OfString.Value}}
+	}
+
+	// The Messages API rejects empty text blocks
+	var blocks []anthropicBlock
+	for _, part := range msg.Content.OfArrayOfContentParts {
+		switch {
+		case part.OfText != nil && part.OfText.Text != "":
+			blocks = append(blocks, anthropicBlock{Type: "text", Text: part.OfText.Text})
+		case part.OfImageURL != nil:
+			blocks = append(blocks, anthropicBlock{Type: "image", Source: anthropicFileSource(part.OfImageURL.ImageURL.URL)})
+		case part.OfFile != nil && part.OfFile.File.FileData.Valid():
+			blocks = append(blocks, anthropicBlock{Type: "document", Source: anthropicFileSource(part.OfFile.File.FileData.Value)})
+		}
+	}
+	return blocks
+}
+
+// anthropicFileSource converts a data URI into a base64 source; other URLs are passed by URL
+func anthropicFileSource(uri string) *anthropicSource {
+	mimeType, data, ok := splitDataURI(uri)
+	if !ok {
+		return &anthropicSource{Type: "url", URL: uri}
+	}
+	return &anthropicSource{Type: "base64", MediaType: mimeType, Data: data}
+}
+
+func anthropicAssistantBlocks(msg openai.ChatCompletionMessageParamUnion) ([]anthropicBlock, error) {
+	var blocks []anthropicBlock
+	if text := MessageText(msg); text != "" {
+		blocks = append(blocks, anthropicBlock{Type: "text", Text: text})
+	}
+	for _, call := range msg.OfAssistant.ToolCalls {
+		input := json.RawMessage(call.Function.Arguments)
+		if len(bytes.TrimSpace(input)) == 0 {
+			input = json.RawMessage("{}")
+		}
+		if !json.Valid(input) {
+			return nil, fmt.Errorf("invalid arguments of tool call %s", call.ID)
+		}
+		blocks = append(blocks, anthropicBlock{Type: "tool_use", ID: call.ID, Name: call.Function.Name, Input: input})
+	}
+	return blocks, nil
+}
+
+// anthropicMessage is a response of the Messages API
+type anthropicMessage struct {
+	ID         string           `json:"id"`
+	Model      string           `json:"model"`
+	StopReason string           `json:"stop_reason"`
+	Content    []anthropicBlock `json:"content"`
+	Usage      struct {
+		InputTokens              int64 `json:"input_tokens"`
+		OutputTokens             int64 `json:"output_tokens"`
+		CacheCreationInputTokens int64 `json:"cache_creation_input_tokens"`
+		CacheReadInputTokens     int64 `json:"cache_read_input_tokens"`
+	} `json:"usage"`
+}
+
+// completion translates the response to a chat completion. A call of the output tool becomes
+// the content of the response.
+func (m *anthropicMessage) completion() (*openai.ChatCompletion, error) {
+	var text []string
+	var toolCalls []map[string]any
+	answered := false
+	for _, block := range m.Content {
+		switch block.Type {
+		case "text":
+			text = append(text, block.Text)
+		case "tool_use":
+			if block.Name == outputTool {
+				text = []string{string(block.Input)}
+				answered = true
+				continue
+			}
+			toolCalls = append(toolCalls, toolCallJSON(block.ID, block.Name, string(block.Input)))
+		}
+	}
+	if answered {
+		toolCalls = nil
+	}
+
+	finishReason := "stop"
+	switch {
+	case len(toolCalls) > 0:
+		finishReason = "tool_calls"
+	case m.StopReason == "max_tokens":
+		finishReason = "length"
+	case m.StopReason == "refusal":
+		finishReason = "content_filter"
+	}
+
+	message := map[string]any{"role": "assistant", "content": strings.Join(text, "")}
+	if len(toolCalls) > 0 {
+		message["tool_calls"] = toolCalls
+	}
+
+	promptTokens := m.Usage.InputTokens + m.Usage.CacheCreationInputTokens + m.Usage.CacheReadInputTokens
+	return newCompletion(m.ID, m.Model, message, finishReason, map[string]any{
+		"prompt_tokens":         promptTokens,
+		"completion_tokens":     m.Usage.OutputTokens,
+		"total_tokens":          promptTokens + m.Usage.OutputTokens,
+		"prompt_tokens_details": map[string]any{"cached_tokens": m.Usage.CacheReadInputTokens},
+	})
+}
+
+// anthropicError converts an error response of the Messages API
+func anthropicError(req *http.Request, resp *http.Response, data []byte) error {
+	var body struct {
+		Error struct {
+			Type    string `json:"type"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	_ = json.Unmarshal(data, &body)
+
+	code := body.Error.Type
+	if strings.Contains(strings.ToLower(body.Error.Message), "prompt is too long") {
+		code = "context_length_exceeded"
+	}
+	return providerError(req, resp, code, body.Error.Message, body.Error.Type)
+}
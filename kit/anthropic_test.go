@@ -0,0 +1,82 @@
+package kit
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnthropicProvider(t *testing.T) {
+	var requests []anthropicMessagesRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v1/messages", r.URL.Path)
+		require.Equal(t, "test", r.Header.Get("x-api-key"))
+
+		var request anthropicMessagesRequest
+		body, _ := io.ReadAll(r.Body)
+		require.NoError(t, json.Unmarshal(body, &request))
+		requests = append(requests, request)
+
+		w.Header().Set("Content-Type", "application/json")
+		if len(requests) == 1 {
+			_, _ = w.Write([]byte(`{"id":"msg_1","model":"claude","stop_reason":"tool_use","content":[` +
+				`{"type":"text","text":"Let me look."},` +
+				`{"type":"tool_use","id":"toolu_1","name":"lookup_tool","input":{}}],` +
+				`"usage":{"input_tokens":10,"output_tokens":5,"cache_read_input_tokens":4}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":"msg_2","model":"claude","stop_reason":"tool_use","content":[` +
+			`{"type":"tool_use","id":"toolu_2","name":"final_answer","input":{"answer":"nothing found"}}],` +
+			`"usage":{"input_tokens":20,"output_tokens":5}}`))
+	}))
+	defer server.Close()
+
+	type answer struct {
+		Answer string `json:"answer"`
+	}
+
+	client := NewAnthropicClient(WithAPIKey("test"), WithBaseURL(server.URL))
+	result, err := CreateAgentWithOutput[answer](client, &lookupTool{}).
+		InvokeDetailed(context.Background(), InvokeConfig{Prompt: "find it", SystemPrompt: "Be brief."})
+	require.NoError(t, err)
+	require.Equal(t, "nothing found", result.Output.Answer)
+	require.Equal(t, int64(34), result.Usage.PromptTokens)
+	require.Equal(t, int64(4), result.Usage.PromptTokensDetails.CachedTokens)
+
+	require.Len(t, requests, 2)
+	first := requests[0]
+	require.Equal(t, ModelClaudeSonnet, first.Model)
+	require.Equal(t, "Be brief.", first.System)
+	require.Equal(t, []string{"lookup_tool", outputTool}, []string{first.Tools[0].Name, first.Tools[1].Name})
+	require.Equal(t, map[string]any{"type": "any"}, first.ToolChoice)
+
+	// The tool call and its result map to tool_use and tool_result blocks
+	second := requests[1].Messages
+	require.Len(t, second, 3)
+	require.Equal(t, "assistant", second[1].Role)
+	require.Equal(t, "tool_use", second[1].Content[1].Type)
+	require.Equal(t, "toolu_1", second[1].Content[1].ID)
+	require.Equal(t, "user", second[2].Role)
+	require.Equal(t, anthropicBlock{Type: "tool_result", ToolUseID: "toolu_1", Content: "nothing found"}, second[2].Content[0])
+}
+
+func TestAnthropicProviderErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"type":"error","error":{"type":"invalid_request_error",` +
+			`"message":"prompt is too long: 210000 tokens > 200000 maximum"}}`))
+	}))
+	defer server.Close()
+
+	client := NewAnthropicClient(WithAPIKey("test"), WithBaseURL(server.URL))
+	_, err := CreateAgent(client).Invoke(context.Background(), InvokeConfig{Prompt: "hi"})
+	require.Error(t, err)
+	require.Equal(t, ErrorClassContextLength, ClassifyError(err))
+	require.Contains(t, err.Error(), "prompt is too long")
+}
@@ -0,0 +1,76 @@
+package kit
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/packages/param"
+)
+
+// AskOptions configures a single-turn Ask call. All fields are optional.
+type AskOptions struct {
+	// Model overrides the client's default model
+	Model string
+
+	// SystemPrompt is prepended as a system message
+	SystemPrompt string
+
+	// Temperature for generation
+	Temperature *float64
+
+	// MaxTokens caps the number of completion tokens
+	MaxTokens int64
+
+	// MaxRetries is the number of repair attempts Generate makes when the output
+	// doesn't parse (defaults to 1, negative disables them)
+	MaxRetries int
+}
+
+// Ask performs a single chat completion without tools or the agent loop and returns the content
+func Ask(ctx context.Context, client *Client, prompt string, opts AskOptions) (string, error) {
+	params := askParams(client, prompt, opts)
+
+	completion, err := client.complete(ctx, params)
+	if err != nil {
+		return "", fmt.Errorf("OpenAI API error: %w", err)
+	}
+
+	if len(completion.Choices) == 0 {
+		return "", fmt.Errorf("no choices in response")
+	}
+
+	return completion.Choices[0].Message.Content, nil
+}
+
+// askParams builds the completion params for a single-turn call
+func askParams(client *Client, prompt string, opts AskOptions) openai.ChatCompletionNewParams {
+	model := opts.Model
+	if model == "" {
+		model = client.defaultModel()
+	}
+
+	var messages []openai.ChatCompletionMessageParamUnion
+	if opts.SystemPrompt != "" {
+		messages = append(messages, openai.SystemMessage(opts.SystemPrompt))
+	}
+	messages = append(messages, openai.UserMessage(prompt))
+
+	params := openai.ChatCompletionNewParams{
+		Model:    model,
+		Messages: messages,
+	}
+
+	if opts.Temperature != nil {
+		params.Temperature = param.NewOpt(*opts.Temperature)
+	}
+	if opts.MaxTokens > 0 {
+		if client.config.Quirks.LegacyMaxTokens {
+			params.MaxTokens = param.NewOpt(opts.MaxTokens)
+		} else {
+			params.MaxCompletionTokens = param.NewOpt(opts.MaxTokens)
+		}
+	}
+
+	return params
+}
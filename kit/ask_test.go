@@ -0,0 +1,65 @@
+package kit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/openai/openai-go/option"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAsk(t *testing.T) {
+	var requests []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		requests = append(requests, body)
+
+		w.Header().Set("Content-Type", "application/json")
+		if body["model"] == "empty-model" {
+			_, _ = w.Write([]byte(`{"id":"1","object":"chat.completion","choices":[]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":"1","object":"chat.completion","choices":[{"index":0,"finish_reason":"stop",` +
+			`"message":{"role":"assistant","content":"{\"city\":\"Paris\"}"}}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("test"), WithBaseURL(server.URL), WithRequestOptions(option.WithMaxRetries(0)))
+	temperature := 0.2
+
+	content, err := Ask(context.Background(), client, "capital of France?", AskOptions{
+		Model:        "small-model",
+		SystemPrompt: "Answer briefly",
+		Temperature:  &temperature,
+		MaxTokens:    50,
+	})
+	require.NoError(t, err)
+	require.Equal(t, `{"city":"Paris"}`, content)
+
+	// A single completion, without tools or the agent loop
+	require.Len(t, requests, 1)
+	request := requests[0]
+	require.Equal(t, "small-model", request["model"])
+	require.Equal(t, 0.2, request["temperature"])
+	require.Equal(t, float64(50), request["max_completion_tokens"])
+	require.NotContains(t, request, "tools")
+	messages := request["messages"].([]interface{})
+	require.Len(t, messages, 2)
+	require.Equal(t, "system", messages[0].(map[string]interface{})["role"])
+	require.Equal(t, "Answer briefly", messages[0].(map[string]interface{})["content"])
+
+	type capital struct {
+		City string `json:"city"`
+	}
+	answer, err := Generate[capital](context.Background(), client, "capital of France?", AskOptions{})
+	require.NoError(t, err)
+	require.Equal(t, "Paris", answer.City)
+	require.Contains(t, requests[1], "response_format")
+
+	_, err = Ask(context.Background(), client, "hi", AskOptions{Model: "empty-model"})
+	require.ErrorContains(t, err, "no choices in response")
+}
@@ -0,0 +1,127 @@
+package kit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// BatchResult is the outcome of a single invocation in a batch
+type BatchResult[Output any] struct {
+	// Index is the position of the config in the batch
+	Index int
+
+	Output Output
+	Err    error
+}
+
+// BatchOptions configures InvokeBatch. All fields are optional.
+type BatchOptions struct {
+	// Concurrency is the maximum number of invocations in flight (0 or less runs everything at once)
+	Concurrency int
+
+	// RequestsPerSecond caps the rate at which invocations start, spacing them evenly, to stay
+	// within the provider's request rate limit (0 or less starts them as fast as Concurrency allows)
+	RequestsPerSecond float64
+}
+
+// InvokeAll runs all configs concurrently, with at most concurrency invocations in flight,
+// and returns one result per config in the same order. A concurrency of 0 or less runs
+// everything at once. Items not started before ctx is done fail with the context error.
+func (a *Agent[Output]) InvokeAll(
+	ctx context.Context,
+	configs []InvokeConfig,
+	concurrency int,
+) []BatchResult[Output] {
+	return a.InvokeBatch(ctx, configs, BatchOptions{Concurrency: concurrency})
+}
+
+// InvokeBatch runs all configs like InvokeAll, bounded by the concurrency and the start rate
+// of opts, and returns one result per config in the same order
+func (a *Agent[Output]) InvokeBatch(
+	ctx context.Context,
+	configs []InvokeConfig,
+	opts BatchOptions,
+) []BatchResult[Output] {
+	concurrency := opts.Concurrency
+	if concurrency <= 0 || concurrency > len(configs) {
+		concurrency = len(configs)
+	}
+
+	var interval time.Duration
+	if opts.RequestsPerSecond > 0 {
+		interval = time.Duration(float64(time.Second) / opts.RequestsPerSecond)
+	}
+
+	results := make([]BatchResult[Output], len(configs))
+	semaphore := make(chan struct{}, concurrency)
+	next := time.Now()
+
+	var wg sync.WaitGroup
+	for i, config := range configs {
+		results[i].Index = i
+
+		select {
+		case semaphore <- struct{}{}:
+		case <-ctx.Done():
+			results[i].Err = ctx.Err()
+			continue
+		}
+
+		// Wait for the next start slot of the rate limit
+		if interval > 0 {
+			if err := sleepUntil(ctx, next); err != nil {
+				<-semaphore
+				results[i].Err = err
+				continue
+			}
+			next = time.Now().Add(interval)
+		}
+
+		wg.Add(1)
+		go func(i int, config InvokeConfig) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			results[i].Output, results[i].Err = a.Invoke(ctx, config)
+		}(i, config)
+	}
+
+	wg.Wait()
+	return results
+}
+
+// sleepUntil waits until t, returning early with the context error when ctx is done
+func sleepUntil(ctx context.Context, t time.Time) error {
+	wait := time.Until(t)
+	if wait <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// MapReduce builds an invocation per item, runs them with InvokeAll and reduces the results
+func MapReduce[Item, Output, Result any](
+	ctx context.Context,
+	agent *Agent[Output],
+	items []Item,
+	mapper func(item Item) InvokeConfig,
+	reducer func(results []BatchResult[Output]) (Result, error),
+	concurrency int,
+) (Result, error) {
+	configs := make([]InvokeConfig, len(items))
+	for i, item := range items {
+		configs[i] = mapper(item)
+	}
+
+	return reducer(agent.InvokeAll(ctx, configs, concurrency))
+}
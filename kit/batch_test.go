@@ -0,0 +1,84 @@
+package kit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/openai/openai-go/option"
+	"github.com/stretchr/testify/require"
+)
+
+func batchServer(inflight, peak *atomic.Int32, starts *[]time.Time, mu *sync.Mutex) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		*starts = append(*starts, time.Now())
+		mu.Unlock()
+
+		current := inflight.Add(1)
+		defer inflight.Add(-1)
+		for {
+			seen := peak.Load()
+			if current <= seen || peak.CompareAndSwap(seen, current) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"1","object":"chat.completion","choices":[` +
+			`{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":"ok"}}]}`))
+	}))
+}
+
+func TestInvokeBatch(t *testing.T) {
+	var (
+		inflight, peak atomic.Int32
+		starts         []time.Time
+		mu             sync.Mutex
+	)
+	server := batchServer(&inflight, &peak, &starts, &mu)
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("test"), WithBaseURL(server.URL), WithRequestOptions(option.WithMaxRetries(0)))
+	agent := CreateAgent(client)
+	configs := make([]InvokeConfig, 6)
+	for i := range configs {
+		configs[i] = InvokeConfig{Prompt: "hi"}
+	}
+
+	// Concurrency bounds the invocations in flight
+	results := agent.InvokeAll(context.Background(), configs, 2)
+	for i, result := range results {
+		require.NoError(t, result.Err)
+		require.Equal(t, i, result.Index)
+		require.Equal(t, "ok", result.Output)
+	}
+	require.Equal(t, int32(2), peak.Load())
+
+	// The rate spaces the starts evenly
+	starts = nil
+	results = agent.InvokeBatch(context.Background(), configs[:4], BatchOptions{RequestsPerSecond: 20})
+	for _, result := range results {
+		require.NoError(t, result.Err)
+	}
+	require.Len(t, starts, 4)
+	for i := 1; i < len(starts); i++ {
+		require.GreaterOrEqual(t, starts[i].Sub(starts[i-1]), 40*time.Millisecond)
+	}
+}
+
+func TestInvokeBatchCancelled(t *testing.T) {
+	client := NewClient(WithAPIKey("test"), WithBaseURL("http://127.0.0.1:0"), WithRequestOptions(option.WithMaxRetries(0)))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	results := CreateAgent(client).InvokeBatch(ctx, []InvokeConfig{{Prompt: "a"}, {Prompt: "b"}}, BatchOptions{RequestsPerSecond: 1})
+	for _, result := range results {
+		require.ErrorIs(t, result.Err, context.Canceled)
+	}
+}
@@ -0,0 +1,60 @@
+package kit
+
+import (
+	"github.com/openai/openai-go"
+)
+
+// PromptCache configures provider prompt caching, which makes requests sharing a long static
+// prefix (system prompt, tools) cheaper and faster. Tools are always sent in a stable order.
+type PromptCache struct {
+	// Key is sent as OpenAI's prompt_cache_key, routing requests that share a prefix to
+	// the same cache (optional)
+	Key string
+
+	// Breakpoints marks the system prompt with an Anthropic cache_control breakpoint, for
+	// Anthropic models served through OpenAI-compatible gateways such as OpenRouter (optional)
+	Breakpoints bool
+
+	// TTL of the breakpoints, "5m" or "1h" (optional, defaults to the provider's default)
+	TTL string
+}
+
+// WithPromptCache enables the prompt caching controls for every generation of the agent
+func (a *Agent[Output]) WithPromptCache(cache PromptCache) *Agent[Output] {
+	a.promptCache = cache
+	return a
+}
+
+// apply adds the caching controls to the request; the run's history is left untouched
+func (c PromptCache) apply(params *openai.ChatCompletionNewParams) {
+	if c.Key != "" {
+		setExtraField(params, "prompt_cache_key", c.Key)
+	}
+
+	if !c.Breakpoints {
+		return
+	}
+
+	cacheControl := map[string]any{"type": "ephemeral"}
+	if c.TTL != "" {
+		cacheControl["ttl"] = c.TTL
+	}
+
+	messages := make([]openai.ChatCompletionMessageParamUnion, len(params.Messages))
+	copy(messages, params.Messages)
+	for i, msg := range messages {
+		if msg.OfSystem == nil {
+			continue
+		}
+
+		part := openai.ChatCompletionContentPartTextParam{Text: MessageText(msg)}
+		part.SetExtraFields(map[string]any{"cache_control": cacheControl})
+
+		system := *msg.OfSystem
+		system.Content = openai.ChatCompletionSystemMessageParamContentUnion{
+			OfArrayOfContentParts: []openai.ChatCompletionContentPartTextParam{part},
+		}
+		messages[i] = openai.ChatCompletionMessageParamUnion{OfSystem: &system}
+	}
+	params.Messages = messages
+}
@@ -0,0 +1,35 @@
+package kit
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/openai/openai-go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPromptCacheApply(t *testing.T) {
+	history := []openai.ChatCompletionMessageParamUnion{
+		openai.SystemMessage("You are a very long static prompt."),
+		openai.UserMessage("hi"),
+	}
+	params := openai.ChatCompletionNewParams{Model: "gpt-4o", Messages: history}
+
+	PromptCache{Key: "support-v1", Breakpoints: true}.apply(&params)
+
+	body, err := json.Marshal(params)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"model": "gpt-4o",
+		"prompt_cache_key": "support-v1",
+		"messages": [
+			{"role": "system", "content": [
+				{"type": "text", "text": "You are a very long static prompt.", "cache_control": {"type": "ephemeral"}}
+			]},
+			{"role": "user", "content": "hi"}
+		]
+	}`, string(body))
+
+	// The history keeps its plain system prompt
+	require.Equal(t, "You are a very long static prompt.", history[0].OfSystem.Content.OfString.Value)
+}
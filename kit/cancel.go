@@ -0,0 +1,63 @@
+package kit
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrRunCancelled is matched (errors.Is) by the CancelError of a run stopped by a Canceller
+var ErrRunCancelled = errors.New("run cancelled")
+
+// CancelError is returned when a run was stopped by Canceller.Cancel. The run's partial state
+// is returned alongside it by InvokeDetailed.
+type CancelError struct {
+	Reason     string
+	Iterations int
+}
+
+func (e *CancelError) Error() string {
+	return fmt.Sprintf("run cancelled after %d iterations: %s", e.Iterations, e.Reason)
+}
+
+// Unwrap lets callers match the error with errors.Is(err, ErrRunCancelled)
+func (e *CancelError) Unwrap() error {
+	return ErrRunCancelled
+}
+
+// Canceller stops a run at the next safe point, between iterations, so the history stays
+// consistent: an in-flight generation or tool call completes first. Unlike canceling the
+// context, the partial state of the run is returned. Pass it in InvokeConfig.Canceller.
+type Canceller struct {
+	mu        sync.Mutex
+	cancelled bool
+	reason    string
+}
+
+// NewCanceller creates a Canceller for one run
+func NewCanceller() *Canceller {
+	return &Canceller{}
+}
+
+// Cancel requests the run to stop; only the first reason is kept
+func (c *Canceller) Cancel(reason string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.cancelled {
+		c.cancelled, c.reason = true, reason
+	}
+}
+
+// check returns a CancelError if the run was cancelled; a nil Canceller never cancels
+func (c *Canceller) check(iterations int) error {
+	if c == nil {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.cancelled {
+		return nil
+	}
+	return &CancelError{Reason: c.reason, Iterations: iterations}
+}
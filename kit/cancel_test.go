@@ -0,0 +1,51 @@
+package kit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/openai/openai-go/option"
+	"github.com/stretchr/testify/require"
+)
+
+type cancellingTool struct {
+	BaseTool
+	canceller *Canceller
+}
+
+func (c *cancellingTool) Execute(ctx *Context) (any, error) {
+	c.canceller.Cancel("user left")
+	return "ok", nil
+}
+
+func TestCancellerStopsAtSafePoint(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"1","object":"chat.completion","choices":[{"index":0,"finish_reason":"tool_calls",` +
+			`"message":{"role":"assistant","tool_calls":[{"id":"call_1","type":"function",` +
+			`"function":{"name":"cancelling_tool","arguments":"{}"}}]}}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(
+		WithAPIKey("test"),
+		WithBaseURL(server.URL),
+		WithRequestOptions(option.WithMaxRetries(0)),
+	)
+	canceller := NewCanceller()
+	agent := CreateAgent(client, &cancellingTool{canceller: canceller})
+
+	result, err := agent.InvokeDetailed(context.Background(), InvokeConfig{Prompt: "hi", Canceller: canceller})
+	require.ErrorIs(t, err, ErrRunCancelled)
+	require.ErrorContains(t, err, "user left")
+	require.Equal(t, 1, calls)
+
+	// The partial state holds the completed iteration, tool result included
+	require.Equal(t, 1, result.Iterations)
+	require.Len(t, result.Generated, 2)
+	require.NotNil(t, result.Generated[1].OfTool)
+}
@@ -0,0 +1,132 @@
+package kit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/packages/param"
+)
+
+// AskN requests n candidate completions in a single call and returns their contents
+func AskN(ctx context.Context, client *Client, prompt string, n int, opts AskOptions) ([]string, error) {
+	choices, err := completeN(ctx, client, askParams(client, prompt, opts), n)
+	if err != nil {
+		return nil, err
+	}
+
+	contents := make([]string, len(choices))
+	for i, choice := range choices {
+		contents[i] = choice.Message.Content
+	}
+	return contents, nil
+}
+
+// GenerateN requests n structured candidates in a single call and returns the ones that parse.
+// It fails only when no candidate could be parsed.
+func GenerateN[T any](ctx context.Context, client *Client, prompt string, n int, opts AskOptions) ([]T, error) {
+	var zero T
+
+	params := askParams(client, prompt, opts)
+	if !isStringType(zero) {
+		outputFormat[T](client, &params)
+	}
+
+	choices, err := completeN(ctx, client, params, n)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]T, 0, len(choices))
+	var lastErr error
+	for _, choice := range choices {
+		content := choice.Message.Content
+		if !isStringType(zero) {
+			content = repairJSON(content)
+		}
+
+		candidate, err := parseOutput[T](content)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		candidates = append(candidates, candidate)
+	}
+
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("no candidate could be parsed: %w", lastErr)
+	}
+	return candidates, nil
+}
+
+// completeN performs a completion with n choices
+func completeN(
+	ctx context.Context,
+	client *Client,
+	params openai.ChatCompletionNewParams,
+	n int,
+) ([]openai.ChatCompletionChoice, error) {
+	if n < 1 {
+		return nil, fmt.Errorf("n must be at least 1, got %d", n)
+	}
+	params.N = param.NewOpt(int64(n))
+
+	completion, err := client.complete(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("OpenAI API error: %w", err)
+	}
+
+	if len(completion.Choices) == 0 {
+		return nil, fmt.Errorf("no choices in response")
+	}
+	return completion.Choices, nil
+}
+
+// MajorityVote returns the most frequent candidate and how many times it occurred.
+// Candidates are compared by their JSON encoding, so it works for structured outputs;
+// ties are won by the candidate that appeared first.
+func MajorityVote[T any](candidates []T) (T, int, error) {
+	var zero T
+	if len(candidates) == 0 {
+		return zero, 0, fmt.Errorf("no candidates to vote on")
+	}
+
+	counts := make(map[string]int, len(candidates))
+	first := make(map[string]int, len(candidates))
+	for i, candidate := range candidates {
+		key, err := json.Marshal(candidate)
+		if err != nil {
+			return zero, 0, fmt.Errorf("failed to encode candidate: %w", err)
+		}
+		if _, seen := first[string(key)]; !seen {
+			first[string(key)] = i
+		}
+		counts[string(key)]++
+	}
+
+	bestKey, bestCount := "", 0
+	for key, count := range counts {
+		if count > bestCount || (count == bestCount && first[key] < first[bestKey]) {
+			bestKey, bestCount = key, count
+		}
+	}
+
+	return candidates[first[bestKey]], bestCount, nil
+}
+
+// PickBest returns the candidate with the highest score; ties are won by the earliest candidate
+func PickBest[T any](candidates []T, score func(candidate T) float64) (T, error) {
+	var zero T
+	if len(candidates) == 0 {
+		return zero, fmt.Errorf("no candidates to pick from")
+	}
+
+	best, bestScore := 0, score(candidates[0])
+	for i := 1; i < len(candidates); i++ {
+		if s := score(candidates[i]); s > bestScore {
+			best, bestScore = i, s
+		}
+	}
+	return candidates[best], nil
+}
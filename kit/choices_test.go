@@ -0,0 +1,33 @@
+package kit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMajorityVote(t *testing.T) {
+	type answer struct {
+		Value int `json:"value"`
+	}
+
+	winner, count, err := MajorityVote([]answer{{1}, {2}, {2}, {1}, {2}})
+	require.NoError(t, err)
+	require.Equal(t, answer{2}, winner)
+	require.Equal(t, 3, count)
+
+	// Ties are won by the first candidate
+	winner, count, err = MajorityVote([]answer{{3}, {4}})
+	require.NoError(t, err)
+	require.Equal(t, answer{3}, winner)
+	require.Equal(t, 1, count)
+
+	_, _, err = MajorityVote([]answer{})
+	require.Error(t, err)
+}
+
+func TestPickBest(t *testing.T) {
+	best, err := PickBest([]string{"a", "abc", "ab"}, func(s string) float64 { return float64(len(s)) })
+	require.NoError(t, err)
+	require.Equal(t, "abc", best)
+}
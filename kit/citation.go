@@ -0,0 +1,73 @@
+package kit
+
+import (
+	"encoding/json"
+
+	"github.com/mhrlife/goai-kit/internal/callback"
+	"github.com/openai/openai-go"
+)
+
+// Citation is a source the provider cited in a generation, e.g. from web or file search
+type Citation = callback.Citation
+
+// extractCitations parses the annotations of the first choice and, for providers such as
+// Perplexity, the top-level citations list of the completion
+func extractCitations(completion *openai.ChatCompletion) []Citation {
+	var citations []Citation
+
+	if len(completion.Choices) > 0 {
+		var message struct {
+			Annotations []map[string]json.RawMessage `json:"annotations"`
+		}
+		if err := json.Unmarshal([]byte(completion.Choices[0].Message.RawJSON()), &message); err == nil {
+			for _, annotation := range message.Annotations {
+				if citation, ok := parseAnnotation(annotation); ok {
+					citations = append(citations, citation)
+				}
+			}
+		}
+	}
+
+	var extra struct {
+		Citations []string `json:"citations"`
+	}
+	if err := json.Unmarshal([]byte(completion.RawJSON()), &extra); err == nil {
+		for _, url := range extra.Citations {
+			citations = append(citations, Citation{Type: "url_citation", URL: url})
+		}
+	}
+
+	return citations
+}
+
+// parseAnnotation reads an annotation of the form {"type": "<type>", "<type>": {...}}
+func parseAnnotation(annotation map[string]json.RawMessage) (Citation, bool) {
+	var annotationType string
+	if err := json.Unmarshal(annotation["type"], &annotationType); err != nil || annotationType == "" {
+		return Citation{}, false
+	}
+
+	var details struct {
+		URL        string `json:"url"`
+		Title      string `json:"title"`
+		FileID     string `json:"file_id"`
+		Filename   string `json:"filename"`
+		StartIndex int    `json:"start_index"`
+		EndIndex   int    `json:"end_index"`
+	}
+	if raw, ok := annotation[annotationType]; ok {
+		if err := json.Unmarshal(raw, &details); err != nil {
+			return Citation{}, false
+		}
+	}
+
+	return Citation{
+		Type:       annotationType,
+		URL:        details.URL,
+		Title:      details.Title,
+		FileID:     details.FileID,
+		Filename:   details.Filename,
+		StartIndex: details.StartIndex,
+		EndIndex:   details.EndIndex,
+	}, true
+}
@@ -0,0 +1,29 @@
+package kit
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/openai/openai-go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractCitations(t *testing.T) {
+	var completion openai.ChatCompletion
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"id": "1",
+		"citations": ["https://example.com/b"],
+		"choices": [{"index": 0, "finish_reason": "stop", "message": {
+			"role": "assistant",
+			"content": "Go 1.24 is out.",
+			"annotations": [{"type": "url_citation", "url_citation": {
+				"url": "https://go.dev/blog", "title": "Go Blog", "start_index": 0, "end_index": 15
+			}}]
+		}}]
+	}`), &completion))
+
+	require.Equal(t, []Citation{
+		{Type: "url_citation", URL: "https://go.dev/blog", Title: "Go Blog", EndIndex: 15},
+		{Type: "url_citation", URL: "https://example.com/b"},
+	}, extractCitations(&completion))
+}
@@ -0,0 +1,104 @@
+package kit
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/openai/openai-go"
+)
+
+// ErrorClass is the class of an error returned by a run, see ClassifyError
+type ErrorClass string
+
+const (
+	ErrorClassRateLimit      ErrorClass = "rate_limit"
+	ErrorClassTimeout        ErrorClass = "timeout"
+	ErrorClassServerError    ErrorClass = "server_error"
+	ErrorClassConnection     ErrorClass = "connection"
+	ErrorClassAuth           ErrorClass = "auth"
+	ErrorClassContextLength  ErrorClass = "context_length"
+	ErrorClassContentFilter  ErrorClass = "content_filter"
+	ErrorClassInvalidRequest ErrorClass = "invalid_request"
+	ErrorClassBudget         ErrorClass = "budget_exceeded"
+	ErrorClassCanceled       ErrorClass = "canceled"
+	ErrorClassUnknown        ErrorClass = "unknown"
+)
+
+// Retryable reports whether an error of this class may succeed when the same request is
+// sent again: rate limits, timeouts, server and connection errors. Auth, context-length,
+// content-filter and invalid requests fail the same way every time.
+func (c ErrorClass) Retryable() bool {
+	switch c {
+	case ErrorClassRateLimit, ErrorClassTimeout, ErrorClassServerError, ErrorClassConnection:
+		return true
+	default:
+		return false
+	}
+}
+
+// ClassifyError returns the class of an error returned by Invoke, InvokeDetailed or a
+// generation. The same class is reported as error_class to OnError and OnRetry.
+func ClassifyError(err error) ErrorClass {
+	var apiErr *openai.Error
+	if errors.As(err, &apiErr) {
+		return classifyAPIError(apiErr)
+	}
+
+	var timeoutErr *TimeoutError
+	var toolTimeoutErr *ToolTimeoutError
+	switch {
+	case errors.As(err, &timeoutErr), errors.As(err, &toolTimeoutErr):
+		return ErrorClassTimeout
+	case errors.Is(err, ErrAdmissionRejected):
+		return ErrorClassRateLimit
+	case errors.Is(err, ErrBudgetExceeded), errors.Is(err, ErrToolBudgetExceeded), errors.Is(err, ErrTooManyToolFailures):
+		return ErrorClassBudget
+	case errors.Is(err, ErrContentFiltered), errors.Is(err, ErrRefusal):
+		return ErrorClassContentFilter
+	case errors.Is(err, ErrRunCancelled), errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return ErrorClassCanceled
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		if netErr.Timeout() {
+			return ErrorClassTimeout
+		}
+		return ErrorClassConnection
+	}
+
+	return ErrorClassUnknown
+}
+
+// classifyAPIError classifies an error response of the provider by its error code first,
+// since context-length and content-filter errors share the 400 status with any bad request
+func classifyAPIError(apiErr *openai.Error) ErrorClass {
+	code := strings.ToLower(apiErr.Code)
+	message := strings.ToLower(apiErr.Message)
+	switch {
+	case code == "context_length_exceeded", code == "string_above_max_length",
+		strings.Contains(message, "maximum context length"), strings.Contains(message, "context window"):
+		return ErrorClassContextLength
+	case code == "content_filter", code == "content_policy_violation",
+		strings.Contains(message, "content management policy"):
+		return ErrorClassContentFilter
+	}
+
+	switch {
+	case apiErr.StatusCode == http.StatusTooManyRequests:
+		return ErrorClassRateLimit
+	case apiErr.StatusCode == http.StatusRequestTimeout, apiErr.StatusCode == http.StatusGatewayTimeout:
+		return ErrorClassTimeout
+	case apiErr.StatusCode >= 500:
+		return ErrorClassServerError
+	case apiErr.StatusCode == http.StatusUnauthorized || apiErr.StatusCode == http.StatusForbidden:
+		return ErrorClassAuth
+	case apiErr.StatusCode == http.StatusRequestEntityTooLarge:
+		return ErrorClassContextLength
+	default:
+		return ErrorClassInvalidRequest
+	}
+}
@@ -0,0 +1,68 @@
+package kit
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mhrlife/goai-kit/internal/callback"
+	"github.com/openai/openai-go/option"
+	"github.com/stretchr/testify/require"
+)
+
+type errorRecorder struct {
+	callback.BaseCallback
+	errors []map[string]interface{}
+}
+
+func (r *errorRecorder) Name() string { return "errorRecorder" }
+
+func (r *errorRecorder) OnError(ctx map[string]interface{}) { r.errors = append(r.errors, ctx) }
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		status int
+		body   string
+		class  ErrorClass
+	}{
+		{http.StatusTooManyRequests, `{"error":{"message":"slow down"}}`, ErrorClassRateLimit},
+		{http.StatusUnauthorized, `{"error":{"message":"bad key"}}`, ErrorClassAuth},
+		{http.StatusBadRequest, `{"error":{"message":"too long","code":"context_length_exceeded"}}`, ErrorClassContextLength},
+		{http.StatusBadRequest, `{"error":{"message":"flagged","code":"content_filter"}}`, ErrorClassContentFilter},
+		{http.StatusBadRequest, `{"error":{"message":"unknown parameter"}}`, ErrorClassInvalidRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.class), func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			client := NewClient(
+				WithAPIKey("test"),
+				WithBaseURL(server.URL),
+				WithRequestOptions(option.WithMaxRetries(0)),
+			)
+			recorder := &errorRecorder{}
+			agent := CreateAgent(client).WithCallbacks(recorder)
+
+			_, err := agent.Invoke(context.Background(), InvokeConfig{Prompt: "hi"})
+			require.Error(t, err)
+			require.Equal(t, tt.class, ClassifyError(err))
+
+			require.NotEmpty(t, recorder.errors)
+			require.Equal(t, string(tt.class), recorder.errors[0]["error_class"])
+			require.Equal(t, tt.class.Retryable(), recorder.errors[0]["retryable"])
+		})
+	}
+
+	require.Equal(t, ErrorClassTimeout, ClassifyError(fmt.Errorf("run: %w", &TimeoutError{})))
+	require.Equal(t, ErrorClassCanceled, ClassifyError(context.Canceled))
+	require.True(t, ErrorClassRateLimit.Retryable())
+	require.False(t, ErrorClassContextLength.Retryable())
+}
@@ -9,9 +9,15 @@ import (
 )
 
 type Client struct {
-	client openai.Client
-	config Config
-	Logger *slog.Logger // Add a dedicated Logger instance
+	client     openai.Client
+	config     Config
+	Logger     *slog.Logger // Add a dedicated Logger instance
+	rateLimits *rateLimitTracker
+	flights    *flightGroup
+	admission  *admissionController
+	rotated    *rotatedCredentials
+	provider   Provider
+	ledger     *UsageLedger
 }
 
 // ClientOption is a function that configures a Client.
@@ -23,6 +29,12 @@ type Config struct {
 	RequestOptions []option.RequestOption
 	DefaultModel   string
 	LogLevel       slog.Level
+	Quirks         Quirks
+	Admission      *AdmissionOptions
+	KeyResolver    KeyResolver
+	APIKeySource   SecretSource
+	BackupAPIKeys  []string
+	Provider       Provider
 }
 
 // NewClient creates a new goaikit Client with the given options.
@@ -59,15 +71,60 @@ func NewClient(opts ...ClientOption) *Client {
 		c.RequestOptions = append(c.RequestOptions, option.WithBaseURL(c.ApiBase))
 	}
 
-	// Add default middleware (like logging)
+	// Resolve per-request credentials before anything sees the request; later middleware wins:
+	// the key source, then the failover keys, then rotated credentials, then the tenant's own
+	if c.APIKeySource != nil {
+		c.RequestOptions = append(c.RequestOptions, option.WithMiddleware(apiKeyMiddleware(c.APIKeySource)))
+	}
+	if len(c.BackupAPIKeys) > 0 {
+		keys := c.BackupAPIKeys
+		if c.ApiKey != "" {
+			keys = append([]string{c.ApiKey}, keys...)
+		}
+		c.RequestOptions = append(c.RequestOptions, option.WithMiddleware(newKeyRing(keys).middleware()))
+	}
+	rotated := &rotatedCredentials{}
+	c.RequestOptions = append(c.RequestOptions, option.WithMiddleware(rotated.middleware(c.ApiBase)))
+	if c.KeyResolver != nil {
+		c.RequestOptions = append(c.RequestOptions, option.WithMiddleware(c.KeyResolver.middleware(c.ApiBase)))
+	}
+
+	// Add default middleware (like logging and rate limit tracking)
+	rateLimits := &rateLimitTracker{}
 	c.RequestOptions = append(
 		c.RequestOptions,
 		option.WithMiddleware(LoggingMiddleware(logger, c.LogLevel)),
+		option.WithMiddleware(rateLimits.middleware()),
 	)
 
-	return &Client{
-		client: openai.NewClient(c.RequestOptions...),
-		config: c,
-		Logger: logger, // Assign the dedicated Logger
+	client := &Client{
+		client:     openai.NewClient(c.RequestOptions...),
+		config:     c,
+		Logger:     logger, // Assign the dedicated Logger
+		rateLimits: rateLimits,
+		flights:    &flightGroup{},
+		rotated:    rotated,
+		ledger:     newUsageLedger(),
+	}
+	if c.Admission != nil {
+		client.admission = newAdmissionController(*c.Admission, rateLimits)
+	}
+	client.provider = c.Provider
+	if client.provider == nil {
+		client.provider = openAIProvider{client: &client.client}
 	}
+	return client
+}
+
+// defaultModel returns the configured default model, falling back to gpt-4o
+func (c *Client) defaultModel() string {
+	if c.config.DefaultModel != "" {
+		return c.config.DefaultModel
+	}
+	return "gpt-4o"
+}
+
+// strictSchemas reports whether the provider accepts strict tool and response schemas
+func (c *Client) strictSchemas() bool {
+	return !c.config.Quirks.NoStrictSchema
 }
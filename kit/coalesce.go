@@ -0,0 +1,97 @@
+package kit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"sync"
+
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
+)
+
+// WithCoalescing makes concurrent identical generations (same model, messages and parameters)
+// share a single API call, across all agents of the client. The call runs with the context of
+// the first caller without its cancellation, so every caller waits for it until its own context
+// is done; streamed runs are never coalesced.
+func (a *Agent[Output]) WithCoalescing(enabled bool) *Agent[Output] {
+	a.coalesce = enabled
+	return a
+}
+
+// flight is an in-flight completion shared by identical requests
+type flight struct {
+	done       chan struct{}
+	completion *openai.ChatCompletion
+	resp       *http.Response
+	err        error
+}
+
+// flightGroup deduplicates concurrent identical completions
+type flightGroup struct {
+	mu      sync.Mutex
+	flights map[string]*flight
+}
+
+// do runs fn once for all concurrent callers with the same key and hands every caller its result.
+// fn runs on the first caller's context without its cancellation, so a cancelled caller only
+// stops waiting and the others still get the result.
+func (g *flightGroup) do(
+	ctx context.Context,
+	key string,
+	fn func(ctx context.Context) (*openai.ChatCompletion, *http.Response, error),
+) (*openai.ChatCompletion, *http.Response, error) {
+	g.mu.Lock()
+	if g.flights == nil {
+		g.flights = make(map[string]*flight)
+	}
+	f, ok := g.flights[key]
+	if !ok {
+		f = &flight{done: make(chan struct{})}
+		g.flights[key] = f
+
+		go func(ctx context.Context) {
+			f.completion, f.resp, f.err = fn(ctx)
+
+			g.mu.Lock()
+			delete(g.flights, key)
+			g.mu.Unlock()
+			close(f.done)
+		}(context.WithoutCancel(ctx))
+	}
+	g.mu.Unlock()
+
+	select {
+	case <-f.done:
+		return f.completion, f.resp, f.err
+	case <-ctx.Done():
+		return nil, nil, ctx.Err()
+	}
+}
+
+// completeCoalesced performs the completion, sharing it with identical concurrent requests when
+// coalescing is enabled
+func (a *Agent[Output]) completeCoalesced(
+	ctx context.Context,
+	params openai.ChatCompletionNewParams,
+) (*openai.ChatCompletion, *http.Response, error) {
+	call := func(ctx context.Context) (*openai.ChatCompletion, *http.Response, error) {
+		var httpResp *http.Response
+		completion, err := a.complete(ctx, params, option.WithResponseInto(&httpResp))
+		return completion, httpResp, err
+	}
+
+	if _, streamed := ctx.Value(streamSinkKey{}).(*streamSink); !a.coalesce || streamed {
+		return call(ctx)
+	}
+
+	body, err := json.Marshal(params)
+	if err != nil {
+		return call(ctx)
+	}
+	body = append(body, a.client.credentialKey(ctx)...)
+	sum := sha256.Sum256(body)
+	return a.client.flights.do(ctx, hex.EncodeToString(sum[:]), call)
+}
@@ -0,0 +1,82 @@
+package kit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCoalescingSharesIdenticalRequests(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		time.Sleep(50 * time.Millisecond)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"1","object":"chat.completion","choices":[` +
+			`{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":"shared"}}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(
+		WithAPIKey("test"),
+		WithBaseURL(server.URL),
+		WithRequestOptions(option.WithMaxRetries(0)),
+	)
+	agent := CreateAgent(client).WithCoalescing(true)
+
+	var wg sync.WaitGroup
+	outputs := make([]string, 5)
+	for i := range outputs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			output, err := agent.Invoke(context.Background(), InvokeConfig{Prompt: "same"})
+			require.NoError(t, err)
+			outputs[i] = output
+		}(i)
+	}
+	wg.Wait()
+
+	require.Equal(t, int32(1), calls.Load())
+	require.Equal(t, []string{"shared", "shared", "shared", "shared", "shared"}, outputs)
+}
+
+func TestCoalescingOutlivesCancelledCaller(t *testing.T) {
+	var (
+		group   flightGroup
+		started = make(chan struct{})
+		release = make(chan struct{})
+	)
+	fn := func(ctx context.Context) (*openai.ChatCompletion, *http.Response, error) {
+		close(started)
+		<-release
+		return &openai.ChatCompletion{ID: "shared"}, nil, ctx.Err()
+	}
+
+	// The first caller gives up while the shared call is still running
+	ctx, cancel := context.WithCancel(context.Background())
+	leader := make(chan error, 1)
+	go func() {
+		_, _, err := group.do(ctx, "key", fn)
+		leader <- err
+	}()
+	<-started
+	cancel()
+	require.ErrorIs(t, <-leader, context.Canceled)
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		close(release)
+	}()
+	completion, _, err := group.do(context.Background(), "key", fn)
+	require.NoError(t, err)
+	require.Equal(t, "shared", completion.ID)
+}
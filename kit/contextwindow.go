@@ -0,0 +1,150 @@
+package kit
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/openai/openai-go"
+)
+
+// knownContextWindows are the context windows of popular models, matched by the longest
+// prefix of the lowercased model name without its gateway vendor, e.g. "openai/gpt-4o"
+var knownContextWindows = map[string]int{
+	"gpt-3.5-turbo":  16_385,
+	"gpt-4":          8_192,
+	"gpt-4-turbo":    128_000,
+	"gpt-4o":         128_000,
+	"gpt-4.1":        1_047_576,
+	"gpt-5":          400_000,
+	"o1":             200_000,
+	"o3":             200_000,
+	"o4-mini":        200_000,
+	"claude-":        200_000,
+	"gemini-":        1_048_576,
+	"gemini-1.5-pro": 2_097_152,
+	"deepseek-":      65_536,
+	"llama-3":        131_072,
+	"llama3":         131_072,
+	"qwen2.5":        32_768,
+}
+
+// ContextManager fits the history into the context window of the model before every
+// generation, evicting the oldest messages instead of letting the provider reject the request
+// with a context length error. The system messages and the newest message are always kept, as
+// are the latest tool call and its results when the history ends with them.
+type ContextManager struct {
+	// Limits maps model names to their context window in tokens, taking precedence over the
+	// registered models and the known models (optional)
+	Limits map[string]int
+
+	// DefaultLimit is the context window of unknown models (optional, unknown models aren't
+	// trimmed when zero)
+	DefaultLimit int
+
+	// Reserve is the number of tokens left free for the completion (optional, defaults to 4096)
+	Reserve int
+}
+
+// WithContextManager evicts the oldest messages from the history whenever it would exceed the
+// model's context window. Evictions are reported to OnHistoryTrim with the context_window
+// strategy, after the trim strategy ran.
+func (a *Agent[Output]) WithContextManager(manager ContextManager) *Agent[Output] {
+	a.contextManager = &manager
+	return a
+}
+
+// ContextWindow returns the context window of the model in tokens, zero when it is unknown
+func (m ContextManager) ContextWindow(model string) int {
+	if limit, ok := m.Limits[model]; ok {
+		return limit
+	}
+	if info, ok := LookupModel(model); ok && info.ContextWindow > 0 {
+		return info.ContextWindow
+	}
+
+	name := strings.ToLower(model)
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+
+	limit, matched := m.DefaultLimit, 0
+	for prefix, window := range knownContextWindows {
+		if strings.HasPrefix(name, prefix) && len(prefix) > matched {
+			limit, matched = window, len(prefix)
+		}
+	}
+	return limit
+}
+
+// Fit returns the newest messages that fit the context window of the model together with the
+// tools offered to it
+func (m ContextManager) Fit(
+	model string,
+	messages []openai.ChatCompletionMessageParamUnion,
+	tools []openai.ChatCompletionToolParam,
+) []openai.ChatCompletionMessageParamUnion {
+	window := m.ContextWindow(model)
+	if window <= 0 {
+		return messages
+	}
+
+	reserve := m.Reserve
+	if reserve <= 0 {
+		reserve = 4096
+	}
+	budget := window - reserve
+	if len(tools) > 0 {
+		data, _ := json.Marshal(tools)
+		budget -= tokenizerFor(model).CountTokens(string(data))
+	}
+
+	system, rest := splitSystem(messages)
+	for _, msg := range system {
+		budget -= estimateTokens(model, msg)
+	}
+	if len(rest) == 0 {
+		return messages
+	}
+
+	// The newest message is kept, with the assistant message whose tool results end the history
+	start := len(rest) - 1
+	for start > 0 && rest[start].OfTool != nil {
+		start--
+	}
+	for _, msg := range rest[start:] {
+		budget -= estimateTokens(model, msg)
+	}
+
+	// Walk backwards from there until the budget runs out
+	for start > 0 {
+		cost := estimateTokens(model, rest[start-1])
+		if budget-cost < 0 {
+			break
+		}
+		budget -= cost
+		start--
+	}
+
+	if start == 0 {
+		return messages
+	}
+	return joinHistory(system, dropOrphanToolMessages(rest[start:]))
+}
+
+// fitContext evicts the messages that don't fit the context window and reports the eviction
+func (a *Agent[Output]) fitContext(
+	messages []openai.ChatCompletionMessageParamUnion,
+	model string,
+	tools []openai.ChatCompletionToolParam,
+	report func(strategy string, before, after int),
+) []openai.ChatCompletionMessageParamUnion {
+	if a.contextManager == nil {
+		return messages
+	}
+
+	fitted := a.contextManager.Fit(model, messages, tools)
+	if len(fitted) != len(messages) {
+		report("context_window", len(messages), len(fitted))
+	}
+	return fitted
+}
@@ -0,0 +1,52 @@
+package kit
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/openai/openai-go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContextManagerWindow(t *testing.T) {
+	RegisterModel(ModelInfo{Name: "registered-model", ContextWindow: 1000})
+
+	manager := ContextManager{Limits: map[string]int{"gpt-4o": 500}, DefaultLimit: 300}
+	require.Equal(t, 500, manager.ContextWindow("gpt-4o"))
+	require.Equal(t, 1000, manager.ContextWindow("registered-model"))
+	require.Equal(t, 128_000, manager.ContextWindow("openai/gpt-4o-mini"))
+	require.Equal(t, 1_047_576, manager.ContextWindow("gpt-4.1-nano"))
+	require.Equal(t, 200_000, manager.ContextWindow(ModelClaudeSonnet))
+	require.Equal(t, 300, manager.ContextWindow("unknown-model"))
+	require.Zero(t, ContextManager{}.ContextWindow("unknown-model"))
+}
+
+func TestContextManagerFit(t *testing.T) {
+	manager := ContextManager{Limits: map[string]int{"small": 280}, Reserve: 100}
+	long := strings.Repeat("word ", 60) // 63 tokens
+
+	messages := []openai.ChatCompletionMessageParamUnion{
+		openai.SystemMessage("system"),
+		openai.UserMessage(long),
+		openai.AssistantMessage(long),
+		openai.UserMessage(long),
+		{OfAssistant: &openai.ChatCompletionAssistantMessageParam{
+			ToolCalls: []openai.ChatCompletionMessageToolCallParam{{
+				ID:       "call_1",
+				Function: openai.ChatCompletionMessageToolCallFunctionParam{Name: "search_tool", Arguments: "{}"},
+			}},
+		}},
+		openai.ToolMessage(long, "call_1"),
+	}
+
+	// The system prompt and the latest tool call with its result are kept, the oldest evicted
+	fitted := manager.Fit("small", messages, nil)
+	require.Len(t, fitted, 4)
+	require.Equal(t, "system", MessageText(fitted[0]))
+	require.NotNil(t, fitted[2].OfAssistant)
+	require.NotNil(t, fitted[3].OfTool)
+
+	// Histories that fit, and unknown models, are left alone
+	require.Len(t, manager.Fit("small", messages[:3], nil), 3)
+	require.Len(t, manager.Fit("unknown-model", messages, nil), len(messages))
+}
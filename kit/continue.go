@@ -0,0 +1,102 @@
+package kit
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/mhrlife/goai-kit/internal/callback"
+	"github.com/openai/openai-go"
+)
+
+// continuePrompt asks the model to continue a response cut off by the token limit
+const continuePrompt = "Your previous response was cut off. Continue exactly where it stopped, " +
+	"without repeating anything and without any commentary."
+
+// continueJSONPrompt is appended to continuePrompt for structured outputs
+const continueJSONPrompt = " Output only the remaining characters of the JSON document, even if the cut " +
+	"is in the middle of a string."
+
+// maxOverlap bounds the text a continuation may repeat from the end of the truncated content
+const maxOverlap = 200
+
+// WithAutoContinue continues responses truncated by the token limit (finish_reason length) with up
+// to maxContinuations follow-up requests, stitching the content before it is parsed, including
+// structured outputs cut off mid-JSON. Not applied in ReAct mode.
+func (a *Agent[Output]) WithAutoContinue(maxContinuations int) *Agent[Output] {
+	a.continuations = maxContinuations
+	return a
+}
+
+// continueTruncated requests continuations of content until the model finishes or the limit
+// is reached. The continuations are reported as generations of the same iteration and their
+// usage is added to run. It returns the stitched content and the last finish reason.
+func (a *Agent[Output]) continueTruncated(
+	ctx context.Context,
+	run *InvokeResult[Output],
+	params openai.ChatCompletionNewParams,
+	content string,
+	cbManager *callback.Manager,
+) (string, string, error) {
+	prompt := continuePrompt
+	var outputType Output
+	if !isStringType(outputType) {
+		prompt += continueJSONPrompt
+	}
+
+	// The continuation is free text: a schema would force a new document from the start
+	params.ResponseFormat = openai.ChatCompletionNewParamsResponseFormatUnion{}
+	params.Tools = nil
+	a.stopSequences.apply(&params, false)
+	requestMessages := params.Messages
+
+	finishReason := "length"
+	for i := 0; i < a.continuations && finishReason == "length"; i++ {
+		params.Messages = append(
+			slices.Clone(requestMessages),
+			openai.AssistantMessage(content),
+			openai.UserMessage(prompt),
+		)
+		cbManager.OnGenerationStart(run.Iterations, params.Messages, params.Model)
+
+		completion, info, err := a.createCompletion(ctx, params, cbManager)
+		if err != nil {
+			return content, finishReason, fmt.Errorf("OpenAI API error: %w", err)
+		}
+		if len(completion.Choices) == 0 {
+			return content, finishReason, fmt.Errorf("no choices in continuation response")
+		}
+
+		choice := completion.Choices[0]
+		finishReason = string(choice.FinishReason)
+		cbManager.OnGenerationEnd(
+			finishReason,
+			a.traceContent(choice.Message.Content),
+			"",
+			nil,
+			nil,
+			&completion.Usage,
+			info,
+		)
+		addUsage(&run.Usage, completion.Usage)
+
+		content = stitch(content, choice.Message.Content)
+	}
+	return content, finishReason, nil
+}
+
+// stitch appends continuation to content, dropping the longest prefix of the continuation
+// that repeats the end of content
+func stitch(content, continuation string) string {
+	for n := min(len(content), len(continuation), maxOverlap); n > 0; n-- {
+		if strings.HasSuffix(content, continuation[:n]) {
+			// Ignore coincidental overlaps of a few characters, e.g. a repeated quote
+			if n >= 8 {
+				return content + continuation[n:]
+			}
+			break
+		}
+	}
+	return content + continuation
+}
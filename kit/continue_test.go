@@ -0,0 +1,60 @@
+package kit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/openai/openai-go/option"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAutoContinueStitchesJSON(t *testing.T) {
+	var requests []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		requests = append(requests, body)
+
+		w.Header().Set("Content-Type", "application/json")
+		if len(requests) == 1 {
+			_, _ = w.Write([]byte(`{"id":"1","object":"chat.completion","choices":[{"index":0,"finish_reason":"length",` +
+				`"message":{"role":"assistant","content":"{\"Title\":\"A long sto"}}]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":"2","object":"chat.completion","choices":[{"index":0,"finish_reason":"stop",` +
+			`"message":{"role":"assistant","content":"ry\",\"Words\":3}"}}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(
+		WithAPIKey("test"),
+		WithBaseURL(server.URL),
+		WithRequestOptions(option.WithMaxRetries(0)),
+	)
+	agent := CreateAgentWithOutput[struct {
+		Title string
+		Words int
+	}](client).WithAutoContinue(2)
+
+	result, err := agent.InvokeDetailed(context.Background(), InvokeConfig{Prompt: "write"})
+	require.NoError(t, err)
+	require.Equal(t, "A long story", result.Output.Title)
+	require.Equal(t, 3, result.Output.Words)
+	require.Equal(t, 1, result.Iterations)
+
+	// The continuation is free text and sees the truncated response
+	require.Len(t, requests, 2)
+	require.Contains(t, requests[0], "response_format")
+	require.NotContains(t, requests[1], "response_format")
+	messages := requests[1]["messages"].([]any)
+	require.Len(t, messages, 3)
+	require.Equal(t, `{"Title":"A long sto`, messages[1].(map[string]any)["content"])
+}
+
+func TestStitchDropsRepeatedOverlap(t *testing.T) {
+	require.Equal(t, "the quick brown fox jumps", stitch("the quick brown", " quick brown fox jumps"))
+	require.Equal(t, `{"a":"b"}`, stitch(`{"a":"`, `b"}`))
+}
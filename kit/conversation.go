@@ -0,0 +1,69 @@
+package kit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mhrlife/goai-kit/internal/memory"
+	"github.com/openai/openai-go"
+)
+
+// WithMessageStore sets the store used to load and persist conversations identified by
+// InvokeConfig.ConversationID
+func (a *Agent[Output]) WithMessageStore(store memory.Store) *Agent[Output] {
+	a.messageStore = store
+	return a
+}
+
+// loadHistory returns the stored history of the conversation, if a message store is configured
+func (a *Agent[Output]) loadHistory(
+	ctx context.Context,
+	conversationID string,
+) ([]openai.ChatCompletionMessageParamUnion, error) {
+	if conversationID == "" || a.messageStore == nil {
+		return nil, nil
+	}
+
+	history, err := a.messageStore.Load(ctx, conversationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load conversation %s: %w", conversationID, err)
+	}
+	return history, nil
+}
+
+// persistTurn appends the input and generated messages of a successful run to the conversation,
+// along with the run's details when the store records them. The guidance messages of the loop
+// are left out, so later turns don't replay them as if the user wrote them.
+func (a *Agent[Output]) persistTurn(
+	ctx context.Context,
+	config InvokeConfig,
+	input []openai.ChatCompletionMessageParamUnion,
+	result *InvokeResult[Output],
+) error {
+	conversationID := config.ConversationID
+	if conversationID == "" || a.messageStore == nil {
+		return nil
+	}
+
+	generated := result.TurnMessages()
+	turn := make([]openai.ChatCompletionMessageParamUnion, 0, len(input)+len(generated))
+	turn = append(turn, input...)
+	turn = append(turn, generated...)
+
+	var err error
+	if turns, ok := a.messageStore.(memory.TurnStore); ok {
+		err = turns.AppendTurn(ctx, conversationID, memory.Turn{
+			RunID:     result.RunID,
+			Model:     a.runModel(config),
+			Usage:     result.Usage,
+			CreatedAt: time.Now(),
+		}, turn...)
+	} else {
+		err = a.messageStore.Append(ctx, conversationID, turn...)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to persist conversation %s: %w", conversationID, err)
+	}
+	return nil
+}
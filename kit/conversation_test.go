@@ -0,0 +1,150 @@
+package kit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mhrlife/goai-kit/internal/callback"
+	"github.com/mhrlife/goai-kit/internal/memory"
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
+	"github.com/stretchr/testify/require"
+)
+
+// lifecycleRecorder records the stages of the errors and the ends of runs
+type lifecycleRecorder struct {
+	callback.BaseCallback
+	runEnds int
+	errors  []string
+}
+
+func (r *lifecycleRecorder) Name() string { return "lifecycleRecorder" }
+
+func (r *lifecycleRecorder) OnRunEnd(ctx map[string]interface{}) { r.runEnds++ }
+
+func (r *lifecycleRecorder) OnError(ctx map[string]interface{}) {
+	r.errors = append(r.errors, ctx["stage"].(string))
+}
+
+// failingStore fails to append to any conversation
+type failingStore struct {
+	*memory.InMemoryStore
+}
+
+func (s failingStore) Append(context.Context, string, ...openai.ChatCompletionMessageParamUnion) error {
+	return errDiskFull
+}
+
+// turnStore records the run of every appended turn
+type turnStore struct {
+	*memory.InMemoryStore
+	turns []memory.Turn
+}
+
+func (s *turnStore) AppendTurn(
+	ctx context.Context,
+	conversationID string,
+	turn memory.Turn,
+	messages ...openai.ChatCompletionMessageParamUnion,
+) error {
+	s.turns = append(s.turns, turn)
+	return s.Append(ctx, conversationID, messages...)
+}
+
+func TestConversationTurnStore(t *testing.T) {
+	var historyLengths []int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Messages []json.RawMessage `json:"messages"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		historyLengths = append(historyLengths, len(body.Messages))
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"1","object":"chat.completion","choices":[` +
+			`{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":"42"}}],` +
+			`"usage":{"prompt_tokens":10,"completion_tokens":2,"total_tokens":12}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("test"), WithBaseURL(server.URL), WithRequestOptions(option.WithMaxRetries(0)))
+	store := &turnStore{InMemoryStore: memory.NewInMemoryStore()}
+	agent := CreateAgent(client).WithModel("gpt-4o-mini").WithMessageStore(store)
+
+	first, err := agent.InvokeDetailed(context.Background(), InvokeConfig{Prompt: "hi", ConversationID: "c1"})
+	require.NoError(t, err)
+	second, err := agent.InvokeDetailed(context.Background(), InvokeConfig{Prompt: "again", ConversationID: "c1"})
+	require.NoError(t, err)
+
+	// Every turn is recorded with its run, and the next run continues the conversation
+	require.Equal(t, []int{1, 3}, historyLengths)
+	require.Len(t, store.turns, 2)
+	require.Equal(t, first.RunID, store.turns[0].RunID)
+	require.Equal(t, second.RunID, store.turns[1].RunID)
+	require.Equal(t, "gpt-4o-mini", store.turns[1].Model)
+	require.Equal(t, int64(12), store.turns[1].Usage.TotalTokens)
+	require.False(t, store.turns[1].CreatedAt.IsZero())
+
+	history, err := store.Load(context.Background(), "c1")
+	require.NoError(t, err)
+	require.Len(t, history, 4)
+	require.Equal(t, "again", MessageText(history[2]))
+}
+
+func TestConversationSkipsGuidanceMessages(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		if calls == 1 {
+			_, _ = w.Write([]byte(`{"id":"1","object":"chat.completion","choices":[` +
+				`{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":""}}]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":"2","object":"chat.completion","choices":[` +
+			`{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":"42"}}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("test"), WithBaseURL(server.URL), WithRequestOptions(option.WithMaxRetries(0)))
+	store := memory.NewInMemoryStore()
+
+	result, err := CreateAgent(client).
+		WithMessageStore(store).
+		WithEmptyResponsePolicy(EmptyResponsePolicy{Nudge: true}).
+		InvokeDetailed(context.Background(), InvokeConfig{Prompt: "hi", ConversationID: "c1"})
+	require.NoError(t, err)
+	require.Len(t, result.Generated, 2)
+
+	// The nudge stays in the run's messages but not in the conversation
+	history, err := store.Load(context.Background(), "c1")
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	require.Equal(t, "hi", MessageText(history[0]))
+	require.NotNil(t, history[1].OfAssistant)
+	require.Equal(t, "42", MessageText(history[1]))
+}
+
+func TestConversationPersistFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"1","object":"chat.completion","choices":[` +
+			`{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":"42"}}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("test"), WithBaseURL(server.URL), WithRequestOptions(option.WithMaxRetries(0)))
+	recorder := &lifecycleRecorder{}
+
+	// A run whose turn isn't persisted fails without being reported as successful
+	_, err := CreateAgent(client).
+		WithMessageStore(failingStore{memory.NewInMemoryStore()}).
+		WithCallbacks(recorder).
+		Invoke(context.Background(), InvokeConfig{Prompt: "hi", ConversationID: "c1"})
+	require.ErrorIs(t, err, errDiskFull)
+	require.Zero(t, recorder.runEnds)
+	require.Equal(t, []string{"run"}, recorder.errors)
+}
@@ -0,0 +1,34 @@
+package kit
+
+import (
+	"github.com/openai/openai-go"
+)
+
+// Pricing is the price of a model in dollars per million tokens
+type Pricing struct {
+	// Input is the price of uncached prompt tokens
+	Input float64
+
+	// CachedInput is the price of prompt tokens served from the provider's prompt cache
+	// (optional, defaults to Input)
+	CachedInput float64
+
+	// Output is the price of completion tokens, reasoning tokens included
+	Output float64
+}
+
+// Cost returns the price of the usage, e.g. InvokeResult.Usage, in dollars. Cached prompt tokens
+// are billed at CachedInput and reasoning tokens, which are part of the completion tokens, at Output.
+func (p Pricing) Cost(usage openai.CompletionUsage) float64 {
+	cachedPrice := p.CachedInput
+	if cachedPrice == 0 {
+		cachedPrice = p.Input
+	}
+
+	cached := usage.PromptTokensDetails.CachedTokens
+	uncached := usage.PromptTokens - cached
+
+	return (float64(uncached)*p.Input +
+		float64(cached)*cachedPrice +
+		float64(usage.CompletionTokens)*p.Output) / 1_000_000
+}
@@ -0,0 +1,27 @@
+package kit
+
+import (
+	"testing"
+
+	"github.com/openai/openai-go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPricingCost(t *testing.T) {
+	var total openai.CompletionUsage
+	usage := openai.CompletionUsage{PromptTokens: 1_000_000, CompletionTokens: 500_000, TotalTokens: 1_500_000}
+	usage.PromptTokensDetails.CachedTokens = 400_000
+	usage.CompletionTokensDetails.ReasoningTokens = 200_000
+	addUsage(&total, usage)
+	addUsage(&total, usage)
+
+	require.Equal(t, int64(800_000), total.PromptTokensDetails.CachedTokens)
+	require.Equal(t, int64(400_000), total.CompletionTokensDetails.ReasoningTokens)
+
+	pricing := Pricing{Input: 2, CachedInput: 0.5, Output: 8}
+	// 1.2M uncached * $2 + 0.8M cached * $0.5 + 1M output * $8
+	require.InDelta(t, 2.4+0.4+8, pricing.Cost(total), 1e-9)
+
+	// Without a cached price, cached tokens are billed as input
+	require.InDelta(t, 4+8, Pricing{Input: 2, Output: 8}.Cost(total), 1e-9)
+}
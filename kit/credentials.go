@@ -0,0 +1,138 @@
+package kit
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/openai/openai-go/option"
+)
+
+// defaultBaseURL is the base URL requests are built with when none is configured
+const defaultBaseURL = "https://api.openai.com/v1/"
+
+// KeyResolver returns the API key and base URL to use for a request, resolved from its context,
+// e.g. the tenant an invoke runs for. Empty values keep the client's own.
+type KeyResolver func(ctx context.Context) (apiKey, baseURL string)
+
+// WithKeyResolver resolves the credentials of every request at invoke time, so a multi-tenant
+// service can route each invoke to the tenant's own provider account through a single client.
+func WithKeyResolver(resolver KeyResolver) ClientOption {
+	return func(c *Config) {
+		c.KeyResolver = resolver
+	}
+}
+
+// SecretSource provides the client's API key when a request is sent, e.g. one of the sources of
+// the secrets package (environment, file, Vault, AWS Secrets Manager)
+type SecretSource interface {
+	Secret(ctx context.Context) (string, error)
+}
+
+// WithAPIKeySource reads the API key from source for every request instead of taking it as a
+// plain string. Remote sources cache the key, see secrets.Cached. A KeyResolver still takes
+// precedence for the requests it resolves a key for.
+func WithAPIKeySource(source SecretSource) ClientOption {
+	return func(c *Config) {
+		c.APIKeySource = source
+	}
+}
+
+// apiKeyMiddleware sets the API key read from source on every request
+func apiKeyMiddleware(source SecretSource) option.Middleware {
+	return func(request *http.Request, next option.MiddlewareNext) (*http.Response, error) {
+		apiKey, err := source.Secret(request.Context())
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve API key: %w", err)
+		}
+		request.Header.Set("Authorization", "Bearer "+apiKey)
+		return next(request)
+	}
+}
+
+// middleware replaces the API key and base URL of requests with the resolved ones
+func (r KeyResolver) middleware(clientBaseURL string) option.Middleware {
+	if clientBaseURL == "" {
+		clientBaseURL = defaultBaseURL
+	}
+	clientBase, _ := url.Parse(clientBaseURL)
+
+	return func(request *http.Request, next option.MiddlewareNext) (*http.Response, error) {
+		apiKey, baseURL := r(request.Context())
+		if apiKey != "" {
+			request.Header.Set("Authorization", "Bearer "+apiKey)
+		}
+		if baseURL != "" && clientBase != nil {
+			if err := rebase(request, clientBase, baseURL); err != nil {
+				return nil, err
+			}
+		}
+		return next(request)
+	}
+}
+
+// rebase moves the request from the client's base URL to baseURL, keeping the endpoint path
+func rebase(request *http.Request, clientBase *url.URL, baseURL string) error {
+	base, err := url.Parse(strings.TrimSuffix(baseURL, "/") + "/")
+	if err != nil {
+		return err
+	}
+
+	endpoint := strings.TrimPrefix(request.URL.Path, strings.TrimSuffix(clientBase.Path, "/")+"/")
+	request.URL.Scheme = base.Scheme
+	request.URL.Host = base.Host
+	request.URL.Path = base.Path + endpoint
+	request.Host = base.Host
+	return nil
+}
+
+// RotateCredentials swaps the API key and base URL used by all subsequent requests of the client,
+// including those of agents already built on it, so rotated credentials take effect without a
+// restart. Empty values keep the current ones. Safe for concurrent use with running requests.
+func (c *Client) RotateCredentials(apiKey, baseURL string) {
+	c.rotated.set(apiKey, baseURL)
+}
+
+// rotatedCredentials holds the credentials set by RotateCredentials
+type rotatedCredentials struct {
+	mu      sync.RWMutex
+	apiKey  string
+	baseURL string
+}
+
+func (r *rotatedCredentials) set(apiKey, baseURL string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if apiKey != "" {
+		r.apiKey = apiKey
+	}
+	if baseURL != "" {
+		r.baseURL = baseURL
+	}
+}
+
+func (r *rotatedCredentials) get() (apiKey, baseURL string) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.apiKey, r.baseURL
+}
+
+// middleware applies the rotated credentials, once any were set
+func (r *rotatedCredentials) middleware(clientBaseURL string) option.Middleware {
+	return KeyResolver(func(context.Context) (string, string) {
+		return r.get()
+	}).middleware(clientBaseURL)
+}
+
+// credentialKey identifies the credentials resolved for ctx, so requests of different tenants
+// are never shared
+func (c *Client) credentialKey(ctx context.Context) string {
+	if c.config.KeyResolver == nil {
+		return ""
+	}
+	apiKey, baseURL := c.config.KeyResolver(ctx)
+	return apiKey + "\x00" + baseURL
+}
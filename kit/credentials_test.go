@@ -0,0 +1,112 @@
+package kit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/openai/openai-go/option"
+	"github.com/stretchr/testify/require"
+)
+
+type tenantKey struct{}
+
+func TestKeyResolverRoutesTenants(t *testing.T) {
+	newServer := func(name string, auth *[]string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "/v1/chat/completions", r.URL.Path)
+			*auth = append(*auth, r.Header.Get("Authorization"))
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"id":"1","object":"chat.completion","choices":[` +
+				`{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":"` + name + `"}}]}`))
+		}))
+	}
+	var defaultAuth, tenantAuth []string
+	defaultServer := newServer("default", &defaultAuth)
+	defer defaultServer.Close()
+	tenantServer := newServer("tenant", &tenantAuth)
+	defer tenantServer.Close()
+
+	client := NewClient(
+		WithAPIKey("default-key"),
+		WithBaseURL(defaultServer.URL+"/v1"),
+		WithRequestOptions(option.WithMaxRetries(0)),
+		WithKeyResolver(func(ctx context.Context) (string, string) {
+			if tenant, _ := ctx.Value(tenantKey{}).(string); tenant == "acme" {
+				return "acme-key", tenantServer.URL + "/v1"
+			}
+			return "", ""
+		}),
+	)
+	agent := CreateAgent(client)
+
+	output, err := agent.Invoke(context.Background(), InvokeConfig{Prompt: "hi"})
+	require.NoError(t, err)
+	require.Equal(t, "default", output)
+	require.Equal(t, []string{"Bearer default-key"}, defaultAuth)
+
+	ctx := context.WithValue(context.Background(), tenantKey{}, "acme")
+	output, err = agent.Invoke(ctx, InvokeConfig{Prompt: "hi"})
+	require.NoError(t, err)
+	require.Equal(t, "tenant", output)
+	require.Equal(t, []string{"Bearer acme-key"}, tenantAuth)
+}
+
+type staticSecret string
+
+func (s staticSecret) Secret(context.Context) (string, error) { return string(s), nil }
+
+func TestAPIKeySource(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "Bearer sk-from-source", r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"1","object":"chat.completion","choices":[` +
+			`{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":"ok"}}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(
+		WithBaseURL(server.URL),
+		WithRequestOptions(option.WithMaxRetries(0)),
+		WithAPIKeySource(staticSecret("sk-from-source")),
+	)
+	output, err := CreateAgent(client).Invoke(context.Background(), InvokeConfig{Prompt: "hi"})
+	require.NoError(t, err)
+	require.Equal(t, "ok", output)
+}
+
+func TestRotateCredentials(t *testing.T) {
+	newServer := func(name string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"id":"1","object":"chat.completion","choices":[{"index":0,"finish_reason":"stop",` +
+				`"message":{"role":"assistant","content":"` + name + ` ` + r.Header.Get("Authorization") + `"}}]}`))
+		}))
+	}
+	oldServer := newServer("old")
+	defer oldServer.Close()
+	rotatedServer := newServer("new")
+	defer rotatedServer.Close()
+
+	client := NewClient(
+		WithAPIKey("sk-old"),
+		WithBaseURL(oldServer.URL),
+		WithRequestOptions(option.WithMaxRetries(0)),
+	)
+	agent := CreateAgent(client)
+
+	output, err := agent.Invoke(context.Background(), InvokeConfig{Prompt: "hi"})
+	require.NoError(t, err)
+	require.Equal(t, "old Bearer sk-old", output)
+
+	client.RotateCredentials("sk-new", "")
+	output, err = agent.Invoke(context.Background(), InvokeConfig{Prompt: "hi"})
+	require.NoError(t, err)
+	require.Equal(t, "old Bearer sk-new", output)
+
+	client.RotateCredentials("", rotatedServer.URL)
+	output, err = agent.Invoke(context.Background(), InvokeConfig{Prompt: "hi"})
+	require.NoError(t, err)
+	require.Equal(t, "new Bearer sk-new", output)
+}
@@ -0,0 +1,143 @@
+package kit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// AgentDefinition declares an agent in a config file, so it can be tuned without code changes:
+//
+//	name: support
+//	model: gpt-4o-mini
+//	temperature: 0.2
+//	system_template: support
+//	tools: [search_docs, create_ticket]
+//	max_iterations: 5
+type AgentDefinition struct {
+	Name           string   `json:"name" yaml:"name"`
+	Model          string   `json:"model" yaml:"model"`
+	Temperature    *float64 `json:"temperature" yaml:"temperature"`
+	SystemPrompt   string   `json:"system_prompt" yaml:"system_prompt"`
+	SystemTemplate string   `json:"system_template" yaml:"system_template"`
+	Tools          []string `json:"tools" yaml:"tools"`
+	MaxIterations  int      `json:"max_iterations" yaml:"max_iterations"`
+	ServiceTier    string   `json:"service_tier" yaml:"service_tier"`
+}
+
+// ToolRegistry resolves the tool names used in agent definitions
+type ToolRegistry map[string]ToolExecutor
+
+// NewToolRegistry registers the tools under their IDs (see BuildToolSchema)
+func NewToolRegistry(tools ...ToolExecutor) ToolRegistry {
+	registry := make(ToolRegistry, len(tools))
+	for _, tool := range tools {
+		registry.Register(tool)
+	}
+	return registry
+}
+
+// Register adds a tool to the registry under its ID
+func (r ToolRegistry) Register(tool ToolExecutor) {
+	r[BuildToolSchema(tool).ID] = tool
+}
+
+// lookup resolves a tool, falling back to the global registry for a nil registry
+func (r ToolRegistry) lookup(name string) (ToolExecutor, bool) {
+	if r == nil {
+		return LookupTool(name)
+	}
+	tool, ok := r[name]
+	return tool, ok
+}
+
+// LoadAgentDefinition reads a definition from a .yaml, .yml or .json file
+func LoadAgentDefinition(path string) (*AgentDefinition, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read agent definition: %w", err)
+	}
+
+	var definition AgentDefinition
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(&definition)
+	case ".yaml", ".yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		err = decoder.Decode(&definition)
+	default:
+		return nil, fmt.Errorf("unsupported agent definition format %q", filepath.Ext(path))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse agent definition %s: %w", path, err)
+	}
+
+	return &definition, nil
+}
+
+// AgentFromDefinition builds an agent from a definition, resolving its tools from the registry,
+// or from the tools registered with RegisterTool when the registry is nil.
+// Templates referenced by system_template are rendered by the renderer set with WithTemplates.
+func AgentFromDefinition[Output any](client *Client, definition AgentDefinition, registry ToolRegistry) (*Agent[Output], error) {
+	if definition.SystemPrompt != "" && definition.SystemTemplate != "" {
+		return nil, fmt.Errorf("agent definition cannot specify both system_prompt and system_template")
+	}
+	if definition.MaxIterations < 0 {
+		return nil, fmt.Errorf("agent definition max_iterations must not be negative")
+	}
+
+	tools := make([]ToolExecutor, 0, len(definition.Tools))
+	var missing []string
+	for _, name := range definition.Tools {
+		tool, ok := registry.lookup(name)
+		if !ok {
+			missing = append(missing, name)
+			continue
+		}
+		tools = append(tools, tool)
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, fmt.Errorf("unknown tools in agent definition: %s", strings.Join(missing, ", "))
+	}
+
+	agent := CreateAgentWithOutput[Output](client, tools...).
+		WithName(definition.Name).
+		WithSystemPrompt(definition.SystemPrompt)
+
+	if definition.Model != "" {
+		agent.WithModel(definition.Model)
+	}
+	if definition.Temperature != nil {
+		agent.WithTemperature(*definition.Temperature)
+	}
+	if definition.SystemTemplate != "" {
+		agent.WithSystemTemplate(SystemTemplate{Name: definition.SystemTemplate})
+	}
+	if definition.MaxIterations > 0 {
+		agent.WithMaxIterations(definition.MaxIterations)
+	}
+	if definition.ServiceTier != "" {
+		agent.WithServiceTier(ServiceTier(definition.ServiceTier))
+	}
+
+	return agent, nil
+}
+
+// LoadAgent reads a definition file and builds a string output agent from it
+func LoadAgent(client *Client, path string, registry ToolRegistry) (*Agent[string], error) {
+	definition, err := LoadAgentDefinition(path)
+	if err != nil {
+		return nil, err
+	}
+	return AgentFromDefinition[string](client, *definition, registry)
+}
@@ -0,0 +1,65 @@
+package kit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type searchDocs struct {
+	BaseTool
+	Query string `json:"query"`
+}
+
+func (s *searchDocs) Execute(ctx *Context) (any, error) {
+	return "no results", nil
+}
+
+func TestLoadAgent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "support.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+name: support
+model: gpt-4o-mini
+temperature: 0.2
+system_prompt: You are a support agent.
+tools: [search_docs]
+max_iterations: 3
+`), 0o644))
+
+	client := NewClient(WithAPIKey("test"))
+	agent, err := LoadAgent(client, path, NewToolRegistry(&searchDocs{}))
+	require.NoError(t, err)
+	require.Equal(t, "support", agent.Name())
+	require.Equal(t, "gpt-4o-mini", agent.Model())
+	require.Equal(t, []string{"search_docs"}, agent.toolNames())
+	require.Equal(t, 3, agent.maxIterations)
+
+	_, err = LoadAgent(client, path, NewToolRegistry())
+	require.ErrorContains(t, err, "unknown tools in agent definition: search_docs")
+
+	require.NoError(t, os.WriteFile(path, []byte("modle: gpt-4o\n"), 0o644))
+	_, err = LoadAgentDefinition(path)
+	require.Error(t, err, "unknown fields are rejected")
+}
+
+func TestAgentFromDefinitionUsesRegisteredTools(t *testing.T) {
+	// The registration is removed again, so the test can run more than once in a process
+	t.Cleanup(func() {
+		toolFactoriesMu.Lock()
+		defer toolFactoriesMu.Unlock()
+		delete(toolFactories, "docs")
+	})
+
+	RegisterTool("docs", func() ToolExecutor { return &searchDocs{} })
+	require.Panics(t, func() {
+		RegisterTool("docs", func() ToolExecutor { return &searchDocs{} })
+	})
+	require.Contains(t, RegisteredTools(), "docs")
+
+	client := NewClient(WithAPIKey("test"))
+	agent, err := AgentFromDefinition[string](client, AgentDefinition{Tools: []string{"docs"}}, nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{"search_docs"}, agent.toolNames())
+}
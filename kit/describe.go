@@ -0,0 +1,89 @@
+package kit
+
+import (
+	"maps"
+
+	"github.com/mhrlife/goai-kit/internal/schema"
+)
+
+// AgentDescription is the structured description of an agent's capabilities returned by
+// Describe, e.g. for admin UIs and agent cards. It marshals to JSON.
+type AgentDescription struct {
+	Name  string `json:"name,omitempty"`
+	Model string `json:"model"`
+
+	// Temperature is the sampling temperature, nil when the provider's default is used
+	Temperature *float64 `json:"temperature,omitempty"`
+
+	// Tools are the agent's tools sorted by name; a tool policy or InvokeConfig.AllowedTools
+	// may offer fewer of them to a run
+	Tools []ToolDescription `json:"tools"`
+
+	// OutputSchema is the JSON schema of the structured output, nil for string outputs
+	OutputSchema map[string]any `json:"output_schema,omitempty"`
+
+	// ReActMode is set when tools are called through the ReAct text format
+	ReActMode bool `json:"react_mode,omitempty"`
+
+	Limits AgentLimits `json:"limits"`
+}
+
+// ToolDescription describes a tool of an agent
+type ToolDescription struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	Parameters  map[string]any `json:"parameters"`
+	Strict      bool           `json:"strict,omitempty"`
+}
+
+// AgentLimits are the limits a run of the agent is bound by; zero values are unlimited
+type AgentLimits struct {
+	// MaxIterations of the tool calling loop, which InvokeConfig.MaxIterations overrides
+	MaxIterations int `json:"max_iterations"`
+
+	// MaxToolCalls and ToolCallLimits are the tool call budget of a run
+	MaxToolCalls   int            `json:"max_tool_calls,omitempty"`
+	ToolCallLimits map[string]int `json:"tool_call_limits,omitempty"`
+
+	// MaxAttempts per model of a generation, and the models tried once they are exhausted
+	MaxAttempts    int      `json:"max_attempts"`
+	FallbackModels []string `json:"fallback_models,omitempty"`
+
+	// MaxContinuations of a final response cut off by the token limit
+	MaxContinuations int `json:"max_continuations,omitempty"`
+}
+
+// Describe returns the agent's model, tools with their parameter schemas, output schema
+// and limits
+func (a *Agent[Output]) Describe() AgentDescription {
+	description := AgentDescription{
+		Name:        a.name,
+		Model:       a.model,
+		Temperature: a.temperature,
+		Tools:       make([]ToolDescription, 0, len(a.schemas)),
+		ReActMode:   a.reactMode,
+		Limits: AgentLimits{
+			MaxIterations:    a.maxIterations,
+			MaxToolCalls:     a.toolBudget.MaxCalls,
+			ToolCallLimits:   maps.Clone(a.toolBudget.PerTool),
+			MaxAttempts:      max(a.retry.MaxAttempts, 1),
+			FallbackModels:   a.retry.FallbackModels,
+			MaxContinuations: a.continuations,
+		},
+	}
+
+	for _, toolSchema := range a.sortedSchemas() {
+		description.Tools = append(description.Tools, ToolDescription{
+			Name:        toolSchema.Name,
+			Description: toolSchema.Description,
+			Parameters:  toolSchema.JSONSchema,
+			Strict:      toolSchema.Strict,
+		})
+	}
+
+	var outputType Output
+	if !isStringType(outputType) {
+		description.OutputSchema = schema.MarshalToSchema(outputType)
+	}
+	return description
+}
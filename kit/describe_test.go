@@ -0,0 +1,38 @@
+package kit
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAgentDescribe(t *testing.T) {
+	type answer struct {
+		Answer string `json:"answer"`
+	}
+
+	agent := CreateAgentWithOutput[answer](NewClient(WithAPIKey("test")), &lookupTool{}, &adminTool{}).
+		WithName("support").
+		WithModel("gpt-4o-mini").
+		WithMaxToolCalls(3)
+
+	description := agent.Describe()
+	require.Equal(t, "support", description.Name)
+	require.Equal(t, "gpt-4o-mini", description.Model)
+	require.Len(t, description.Tools, 2)
+	require.Equal(t, "admin_tool", description.Tools[0].Name)
+	require.Equal(t, "lookup_tool", description.Tools[1].Name)
+	require.Contains(t, description.OutputSchema["properties"], "answer")
+	require.Equal(t, AgentLimits{MaxIterations: 10, MaxToolCalls: 3, MaxAttempts: 1}, description.Limits)
+
+	data, err := json.Marshal(description)
+	require.NoError(t, err)
+	var fields map[string]any
+	require.NoError(t, json.Unmarshal(data, &fields))
+	require.Contains(t, fields, "output_schema")
+	require.NotContains(t, fields, "temperature")
+	require.Equal(t, "object", fields["tools"].([]any)[0].(map[string]any)["parameters"].(map[string]any)["type"])
+
+	require.Nil(t, CreateAgent(NewClient(WithAPIKey("test"))).Describe().OutputSchema)
+}
@@ -0,0 +1,96 @@
+package kit
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mhrlife/goai-kit/internal/callback"
+	"github.com/openai/openai-go"
+)
+
+// DefaultNudgePrompt asks the model for its final answer after an empty response
+const DefaultNudgePrompt = "Your last response was empty. Please provide your final answer."
+
+// EmptyResponsePolicy controls how the loop handles responses without a usable answer. The zero
+// value keeps the default behavior: an empty response ends the run with an empty output and
+// content sent alongside tool calls is ignored.
+type EmptyResponsePolicy struct {
+	// Nudge sends NudgePrompt and iterates again when the model returned neither content nor
+	// tool calls. Every nudge uses an iteration and is reported to OnRetry.
+	Nudge bool
+
+	// MaxNudges per run (defaults to 1)
+	MaxNudges int
+
+	// NudgePrompt is the user message sent to nudge the model (defaults to DefaultNudgePrompt)
+	NudgePrompt string
+
+	// Fail returns ErrEmptyResponse when the response is still empty, instead of an empty output
+	Fail bool
+
+	// PreferContent ends the run with the content returned alongside tool calls, when it parses
+	// into Output, skipping the tool calls
+	PreferContent bool
+}
+
+// WithEmptyResponsePolicy sets how empty responses and content alongside tool calls are handled
+func (a *Agent[Output]) WithEmptyResponsePolicy(policy EmptyResponsePolicy) *Agent[Output] {
+	a.emptyResponse = policy
+	return a
+}
+
+// isEmptyResponse reports whether a response has neither content nor tool calls
+func isEmptyResponse(content string, toolCalls []openai.ChatCompletionMessageToolCall) bool {
+	return len(toolCalls) == 0 && strings.TrimSpace(content) == ""
+}
+
+// handleEmpty decides what to do with an empty response after the given number of nudges:
+// nudge returns the message to continue with, otherwise err ends the run. Both are zero when
+// the empty output should be returned as before.
+func (p EmptyResponsePolicy) handleEmpty(
+	finishReason string,
+	nudges int,
+) (nudge openai.ChatCompletionMessageParamUnion, ok bool, err error) {
+	if !p.Nudge && !p.Fail {
+		return nudge, false, nil
+	}
+
+	if p.Nudge && nudges < max(p.MaxNudges, 1) {
+		prompt := p.NudgePrompt
+		if prompt == "" {
+			prompt = DefaultNudgePrompt
+		}
+		return openai.UserMessage(prompt), true, nil
+	}
+
+	if p.Fail {
+		return nudge, false, fmt.Errorf("%w (finish_reason %s)", ErrEmptyResponse, finishReason)
+	}
+	return nudge, false, nil
+}
+
+// preferContent drops the tool calls of a response whose content already parses into Output,
+// so the content ends the run
+func (a *Agent[Output]) preferContent(message *openai.ChatCompletionMessage, content string) bool {
+	if !a.emptyResponse.PreferContent || content == "" || len(message.ToolCalls) == 0 {
+		return false
+	}
+	if _, err := parseOutput[Output](content); err != nil {
+		return false
+	}
+
+	message.ToolCalls = nil
+	return true
+}
+
+// reportNudge reports a nudge to OnRetry
+func (a *Agent[Output]) reportNudge(cbManager *callback.Manager, model, finishReason string, nudges int) {
+	cbManager.OnRetry(
+		"empty_response",
+		fmt.Errorf("%w (finish_reason %s)", ErrEmptyResponse, finishReason),
+		"empty_response",
+		nudges,
+		0,
+		model,
+	)
+}
@@ -0,0 +1,92 @@
+package kit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/openai/openai-go/option"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmptyResponseNudge(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		if calls == 1 {
+			_, _ = w.Write([]byte(`{"id":"1","object":"chat.completion","choices":[` +
+				`{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":""}}]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":"2","object":"chat.completion","choices":[` +
+			`{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":"42"}}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(
+		WithAPIKey("test"),
+		WithBaseURL(server.URL),
+		WithRequestOptions(option.WithMaxRetries(0)),
+	)
+	recorder := &retryRecorder{}
+	agent := CreateAgent(client).
+		WithCallbacks(recorder).
+		WithEmptyResponsePolicy(EmptyResponsePolicy{Nudge: true, Fail: true})
+
+	result, err := agent.InvokeDetailed(context.Background(), InvokeConfig{Prompt: "hi"})
+	require.NoError(t, err)
+	require.Equal(t, "42", result.Output)
+	require.Equal(t, 2, result.Iterations)
+
+	// The empty response isn't kept, only the nudge
+	require.Len(t, result.Generated, 2)
+	require.Equal(t, DefaultNudgePrompt, result.Generated[0].OfUser.Content.OfString.Value)
+	require.Len(t, recorder.retries, 1)
+	require.Equal(t, "empty_response", recorder.retries[0]["stage"])
+}
+
+func TestEmptyResponseFails(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"1","object":"chat.completion","choices":[` +
+			`{"index":0,"finish_reason":"content_filter","message":{"role":"assistant","content":""}}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(
+		WithAPIKey("test"),
+		WithBaseURL(server.URL),
+		WithRequestOptions(option.WithMaxRetries(0)),
+	)
+	agent := CreateAgent(client).WithEmptyResponsePolicy(EmptyResponsePolicy{Nudge: true})
+
+	_, err := agent.Invoke(context.Background(), InvokeConfig{Prompt: "hi"})
+	require.ErrorIs(t, err, ErrContentFiltered)
+	require.Equal(t, ErrorClassContentFilter, ClassifyError(err))
+}
+
+func TestPreferContentOverToolCalls(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"1","object":"chat.completion","choices":[{"index":0,"finish_reason":"tool_calls",` +
+			`"message":{"role":"assistant","content":"{\"Answer\":\"done\"}","tool_calls":[{"id":"call_1","type":"function",` +
+			`"function":{"name":"lookup_tool","arguments":"{}"}}]}}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(
+		WithAPIKey("test"),
+		WithBaseURL(server.URL),
+		WithRequestOptions(option.WithMaxRetries(0)),
+	)
+	agent := CreateAgentWithOutput[struct{ Answer string }](client, &lookupTool{}).
+		WithEmptyResponsePolicy(EmptyResponsePolicy{PreferContent: true})
+
+	result, err := agent.InvokeDetailed(context.Background(), InvokeConfig{Prompt: "hi"})
+	require.NoError(t, err)
+	require.Equal(t, "done", result.Output.Answer)
+	require.Len(t, result.Generated, 1)
+	require.Empty(t, result.Generated[0].OfAssistant.ToolCalls)
+}
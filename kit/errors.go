@@ -0,0 +1,118 @@
+package kit
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrMaxIterations is returned (wrapped) when a run reaches its iteration limit without a
+// final answer
+var ErrMaxIterations = errors.New("max iterations reached without completion")
+
+// ErrToolNotFound is returned (wrapped) when the model calls a tool the agent doesn't have
+var ErrToolNotFound = errors.New("tool not found")
+
+// ErrToolNotAllowed is returned (wrapped) when the model calls a tool the run may not use, see
+// InvokeConfig.AllowedTools and WithToolPolicy
+var ErrToolNotAllowed = errors.New("tool not allowed")
+
+// ErrToolFailed is matched (errors.Is) by the ToolFailedError of a failing tool
+var ErrToolFailed = errors.New("tool failed")
+
+// ErrTooManyToolFailures is matched (errors.Is) by the ToolFailuresError of a run whose tool
+// calls kept failing
+var ErrTooManyToolFailures = errors.New("too many consecutive tool failures")
+
+// ErrOutputParse is returned (wrapped) when the final content doesn't parse into the output type
+var ErrOutputParse = errors.New("failed to parse output JSON")
+
+// ErrEmptyResponse is returned (wrapped) when the model returned neither content nor tool calls
+// and EmptyResponsePolicy.Fail is set
+var ErrEmptyResponse = errors.New("model returned an empty response")
+
+// ErrContentFiltered is matched (errors.Is) by the RefusalError of a response the provider's
+// content filter blocked (finish_reason content_filter)
+var ErrContentFiltered = errors.New("response blocked by content filter")
+
+// ErrRefusal is matched (errors.Is) by the RefusalError of a response the model refused to give
+var ErrRefusal = errors.New("model refused to respond")
+
+// ErrBudgetExceeded is matched (errors.Is) by quota errors of users who used up their budget,
+// e.g. *quota.ExceededError
+var ErrBudgetExceeded = errors.New("budget exceeded")
+
+// ErrToolBudgetExceeded is matched (errors.Is) by the ToolBudgetError of a run that used up its
+// tool call budget
+var ErrToolBudgetExceeded = errors.New("tool call budget exceeded")
+
+// ToolFailedError is returned when a tool's Execute returns an error
+type ToolFailedError struct {
+	Tool string
+	Err  error
+}
+
+func (e *ToolFailedError) Error() string {
+	return fmt.Sprintf("tool %s failed: %v", e.Tool, e.Err)
+}
+
+// Unwrap matches both ErrToolFailed and the tool's own error
+func (e *ToolFailedError) Unwrap() []error {
+	return []error{ErrToolFailed, e.Err}
+}
+
+// ToolFailuresError is returned when a run reached its limit of consecutive failed tool calls,
+// see WithMaxToolFailures
+type ToolFailuresError struct {
+	// Errors of the consecutive failed calls, oldest first
+	Errors []error
+}
+
+func (e *ToolFailuresError) Error() string {
+	return fmt.Sprintf("%s: %d in a row, last: %v", ErrTooManyToolFailures, len(e.Errors), e.Errors[len(e.Errors)-1])
+}
+
+// Unwrap matches ErrTooManyToolFailures and the errors of the failed calls
+func (e *ToolFailuresError) Unwrap() []error {
+	return append([]error{ErrTooManyToolFailures}, e.Errors...)
+}
+
+// ToolBudgetError is returned when a run exceeds its ToolBudget. Tool is empty when the total
+// budget was exceeded.
+type ToolBudgetError struct {
+	Tool  string
+	Limit int
+}
+
+func (e *ToolBudgetError) Error() string {
+	if e.Tool == "" {
+		return fmt.Sprintf("%s: at most %d tool calls per run", ErrToolBudgetExceeded, e.Limit)
+	}
+	return fmt.Sprintf("%s: at most %d calls of tool %s per run", ErrToolBudgetExceeded, e.Limit, e.Tool)
+}
+
+func (e *ToolBudgetError) Unwrap() error {
+	return ErrToolBudgetExceeded
+}
+
+// RefusalError is returned when the model refused to answer (the message's refusal field) or the
+// provider's content filter cut its response off (finish_reason content_filter)
+type RefusalError struct {
+	// Refusal is the model's refusal text, or the filtered content when the provider sent any
+	Refusal      string
+	FinishReason string
+}
+
+func (e *RefusalError) Error() string {
+	if e.Refusal == "" {
+		return fmt.Sprintf("%s (finish_reason %s)", ErrRefusal, e.FinishReason)
+	}
+	return fmt.Sprintf("%s: %s", ErrRefusal, e.Refusal)
+}
+
+// Unwrap matches ErrRefusal, and ErrContentFiltered when the content filter was triggered
+func (e *RefusalError) Unwrap() []error {
+	if e.FinishReason == "content_filter" {
+		return []error{ErrRefusal, ErrContentFiltered}
+	}
+	return []error{ErrRefusal}
+}
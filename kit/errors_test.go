@@ -0,0 +1,52 @@
+package kit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/openai/openai-go/option"
+	"github.com/stretchr/testify/require"
+)
+
+var errDiskFull = errors.New("disk full")
+
+type failingTool struct {
+	BaseTool
+}
+
+func (f *failingTool) Execute(ctx *Context) (any, error) {
+	return nil, errDiskFull
+}
+
+func TestToolFailedError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"1","object":"chat.completion","choices":[{"index":0,"finish_reason":"tool_calls",` +
+			`"message":{"role":"assistant","tool_calls":[{"id":"call_1","type":"function",` +
+			`"function":{"name":"failing_tool","arguments":"{}"}}]}}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(
+		WithAPIKey("test"),
+		WithBaseURL(server.URL),
+		WithRequestOptions(option.WithMaxRetries(0)),
+	)
+	agent := CreateAgent(client, &failingTool{})
+
+	_, err := agent.Invoke(context.Background(), InvokeConfig{Prompt: "hi"})
+	require.ErrorIs(t, err, ErrToolFailed)
+	require.ErrorIs(t, err, errDiskFull)
+
+	var toolErr *ToolFailedError
+	require.ErrorAs(t, err, &toolErr)
+	require.Equal(t, "failing_tool", toolErr.Tool)
+}
+
+func TestOutputParseError(t *testing.T) {
+	_, err := parseOutput[struct{ Name string }]("not json")
+	require.ErrorIs(t, err, ErrOutputParse)
+}
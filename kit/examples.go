@@ -0,0 +1,98 @@
+package kit
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/openai/openai-go"
+)
+
+// ToolExample is a user→tool-call exchange injected as synthetic history before the real
+// conversation, showing small models when and how to call the tools
+type ToolExample struct {
+	// Prompt is the example user message
+	Prompt string
+
+	// Calls are the tool calls the assistant makes in response
+	Calls []ExampleCall
+
+	// Response is the final assistant answer after the tool results (optional)
+	Response string
+}
+
+// ExampleCall is one tool call of a ToolExample
+type ExampleCall struct {
+	// Tool is the name of one of the agent's tools
+	Tool string
+
+	// Arguments are marshaled to JSON as the call arguments
+	Arguments any
+
+	// Result is the tool result shown to the model
+	Result string
+}
+
+// WithToolExamples adds few-shot tool-call examples, sent after the system prompt in every run
+func (a *Agent[Output]) WithToolExamples(examples ...ToolExample) *Agent[Output] {
+	a.toolExamples = append(a.toolExamples, examples...)
+	return a
+}
+
+// exampleMessages renders the tool examples as history, in the ReAct text format in ReAct mode
+func (a *Agent[Output]) exampleMessages() ([]openai.ChatCompletionMessageParamUnion, error) {
+	var messages []openai.ChatCompletionMessageParamUnion
+	for i, example := range a.toolExamples {
+		messages = append(messages, openai.UserMessage(example.Prompt))
+
+		for j, call := range example.Calls {
+			if !a.hasTool(call.Tool) {
+				return nil, fmt.Errorf("tool example %d: %w: %s", i, ErrToolNotFound, call.Tool)
+			}
+			arguments, err := json.Marshal(call.Arguments)
+			if err != nil {
+				return nil, fmt.Errorf("tool example %d: failed to marshal arguments: %w", i, err)
+			}
+
+			if a.reactMode {
+				messages = append(messages,
+					openai.AssistantMessage(fmt.Sprintf("Action: %s\nAction Input: %s", call.Tool, arguments)),
+					openai.UserMessage("Observation: "+call.Result),
+				)
+				continue
+			}
+
+			callID := fmt.Sprintf("example_%d_%d", i, j)
+			messages = append(messages,
+				openai.ChatCompletionMessageParamUnion{OfAssistant: &openai.ChatCompletionAssistantMessageParam{
+					ToolCalls: []openai.ChatCompletionMessageToolCallParam{{
+						ID: callID,
+						Function: openai.ChatCompletionMessageToolCallFunctionParam{
+							Name:      call.Tool,
+							Arguments: string(arguments),
+						},
+					}},
+				}},
+				openai.ToolMessage(call.Result, callID),
+			)
+		}
+
+		if example.Response != "" {
+			response := example.Response
+			if a.reactMode {
+				response = "Final Answer: " + response
+			}
+			messages = append(messages, openai.AssistantMessage(response))
+		}
+	}
+	return messages, nil
+}
+
+// hasTool reports whether the agent has a tool with the given name
+func (a *Agent[Output]) hasTool(name string) bool {
+	for _, toolSchema := range a.schemas {
+		if toolSchema.Name == name {
+			return true
+		}
+	}
+	return false
+}
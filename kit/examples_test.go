@@ -0,0 +1,35 @@
+package kit
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestToolExampleMessages(t *testing.T) {
+	agent := CreateAgent(NewClient(WithAPIKey("test"))).
+		WithTools(&searchDocs{}).
+		WithToolExamples(ToolExample{
+			Prompt:   "How do I reset my password?",
+			Calls:    []ExampleCall{{Tool: "search_docs", Arguments: map[string]string{"query": "reset password"}, Result: "Use Settings > Security."}},
+			Response: "Go to Settings > Security.",
+		})
+
+	messages, err := agent.exampleMessages()
+	require.NoError(t, err)
+
+	body, err := json.Marshal(messages)
+	require.NoError(t, err)
+	require.JSONEq(t, `[
+		{"role": "user", "content": "How do I reset my password?"},
+		{"role": "assistant", "tool_calls": [{"id": "example_0_0", "type": "function",
+			"function": {"name": "search_docs", "arguments": "{\"query\":\"reset password\"}"}}]},
+		{"role": "tool", "tool_call_id": "example_0_0", "content": "Use Settings > Security."},
+		{"role": "assistant", "content": "Go to Settings > Security."}
+	]`, string(body))
+
+	agent.WithToolExamples(ToolExample{Prompt: "hi", Calls: []ExampleCall{{Tool: "missing"}}})
+	_, err = agent.exampleMessages()
+	require.ErrorContains(t, err, "tool not found: missing")
+}
@@ -0,0 +1,118 @@
+package kit
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"maps"
+	"math/rand/v2"
+)
+
+// Metadata keys the experiment and the arm of a run are recorded under
+const (
+	MetadataExperiment    = "experiment"
+	MetadataExperimentArm = "experiment_arm"
+)
+
+// ExperimentArm is a variant of an experiment
+type ExperimentArm struct {
+	// Name identifies the arm in the run metadata (required)
+	Name string
+
+	// Model of the runs assigned to the arm (optional, keeps the agent's model)
+	Model string
+
+	// SystemPrompt of the runs assigned to the arm (optional, keeps the agent's system prompt)
+	SystemPrompt string
+
+	// Weight is the relative share of the runs assigned to the arm (optional, defaults to 1)
+	Weight float64
+}
+
+// Experiment splits the runs of an agent between model and system prompt variants
+type Experiment struct {
+	// Name identifies the experiment in the run metadata (required)
+	Name string
+
+	// Arms the runs are assigned to in proportion to their weights (required)
+	Arms []ExperimentArm
+
+	// Sticky assigns every InvokeConfig.User to the same arm for as long as the arms don't
+	// change. Runs without a user are assigned at random.
+	Sticky bool
+}
+
+// WithExperiment assigns every run to an arm of the experiment, whose model and system prompt
+// replace the agent's. The invoke's own Model, SystemPrompt and SystemTemplate still take
+// precedence. The experiment and the arm are recorded in the run metadata under
+// MetadataExperiment and MetadataExperimentArm, and the arm in InvokeResult.ExperimentArm.
+func (a *Agent[Output]) WithExperiment(experiment Experiment) *Agent[Output] {
+	if experiment.Name == "" {
+		panic("kit: WithExperiment called without an experiment name")
+	}
+	if len(experiment.Arms) == 0 {
+		panic("kit: WithExperiment called without arms")
+	}
+	for _, arm := range experiment.Arms {
+		if arm.Name == "" || arm.Weight < 0 {
+			panic("kit: WithExperiment arms need a name and a non-negative weight")
+		}
+	}
+
+	a.experiment = &experiment
+	return a
+}
+
+// assignArm picks the arm of the run and applies its variant to config
+func (a *Agent[Output]) assignArm(config *InvokeConfig) string {
+	if a.experiment == nil {
+		return ""
+	}
+	arm := a.experiment.pick(config.User)
+
+	if config.Model == "" {
+		config.Model = arm.Model
+	}
+	if config.SystemPrompt == "" && config.SystemTemplate == nil {
+		config.SystemPrompt = arm.SystemPrompt
+	}
+
+	metadata := maps.Clone(config.Metadata)
+	if metadata == nil {
+		metadata = make(map[string]string, 2)
+	}
+	metadata[MetadataExperiment] = a.experiment.Name
+	metadata[MetadataExperimentArm] = arm.Name
+	config.Metadata = metadata
+
+	return arm.Name
+}
+
+// pick selects an arm by weight, from the hash of the user for sticky experiments
+func (e *Experiment) pick(user string) ExperimentArm {
+	total := 0.0
+	for _, arm := range e.Arms {
+		total += armWeight(arm)
+	}
+
+	point := rand.Float64()
+	if e.Sticky && user != "" {
+		sum := sha256.Sum256([]byte(e.Name + "\x00" + user))
+		point = float64(binary.BigEndian.Uint64(sum[:8])>>11) / (1 << 53)
+	}
+
+	point *= total
+	for _, arm := range e.Arms {
+		if point < armWeight(arm) {
+			return arm
+		}
+		point -= armWeight(arm)
+	}
+	return e.Arms[len(e.Arms)-1]
+}
+
+func armWeight(arm ExperimentArm) float64 {
+	if arm.Weight == 0 {
+		return 1
+	}
+	return arm.Weight
+}
@@ -0,0 +1,90 @@
+package kit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mhrlife/goai-kit/internal/callback"
+	"github.com/stretchr/testify/require"
+)
+
+type experimentRequest struct {
+	Model    string `json:"model"`
+	Messages []struct {
+		Content string `json:"content"`
+	} `json:"messages"`
+}
+
+type runStartRecorder struct {
+	callback.BaseCallback
+	metadata []map[string]string
+}
+
+func (r *runStartRecorder) Name() string { return "runStartRecorder" }
+
+func (r *runStartRecorder) OnRunStart(ctx map[string]interface{}) {
+	metadata, _ := ctx["metadata"].(map[string]string)
+	r.metadata = append(r.metadata, metadata)
+}
+
+func TestAgentExperiment(t *testing.T) {
+	var requests []experimentRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request experimentRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		requests = append(requests, request)
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"1","object":"chat.completion","choices":[{"index":0,"finish_reason":"stop",` +
+			`"message":{"role":"assistant","content":"hello"}}]}`))
+	}))
+	defer server.Close()
+
+	experiment := Experiment{
+		Name: "tone",
+		Arms: []ExperimentArm{
+			{Name: "formal", Model: "gpt-4o", SystemPrompt: "Be formal.", Weight: 3},
+			{Name: "casual", Model: "gpt-4o-mini", SystemPrompt: "Be casual."},
+		},
+		Sticky: true,
+	}
+	recorder := &runStartRecorder{}
+	agent := CreateAgent(NewClient(WithAPIKey("test"), WithBaseURL(server.URL))).
+		WithSystemPrompt("Default.").
+		WithCallbacks(recorder).
+		WithExperiment(experiment)
+
+	first, err := agent.InvokeDetailed(context.Background(), InvokeConfig{Prompt: "hi", User: "user-1"})
+	require.NoError(t, err)
+	second, err := agent.InvokeDetailed(context.Background(), InvokeConfig{Prompt: "hi", User: "user-1"})
+	require.NoError(t, err)
+
+	// The same user stays on the same arm, whose model and system prompt are used
+	require.NotEmpty(t, first.ExperimentArm)
+	require.Equal(t, first.ExperimentArm, second.ExperimentArm)
+	arm := experiment.Arms[0]
+	if first.ExperimentArm == "casual" {
+		arm = experiment.Arms[1]
+	}
+	require.Equal(t, arm.Model, requests[0].Model)
+	require.Equal(t, arm.SystemPrompt, requests[0].Messages[0].Content)
+	require.Equal(t, map[string]string{MetadataExperiment: "tone", MetadataExperimentArm: arm.Name}, recorder.metadata[0])
+
+	// The invoke's own system prompt takes precedence
+	_, err = agent.InvokeDetailed(context.Background(), InvokeConfig{Prompt: "hi", SystemPrompt: "Custom."})
+	require.NoError(t, err)
+	require.Equal(t, "Custom.", requests[2].Messages[0].Content)
+
+	// Users are split by weight
+	formal := 0
+	for i := 0; i < 2000; i++ {
+		if experiment.pick(fmt.Sprintf("user-%d", i)).Name == "formal" {
+			formal++
+		}
+	}
+	require.InDelta(t, 1500, formal, 100)
+}
@@ -0,0 +1,95 @@
+package kit
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/openai/openai-go/option"
+)
+
+// WithBackupAPIKeys configures API keys to fail over to when the provider rejects the current
+// key (401, 403 or insufficient_quota). The failed request is sent again with the next key,
+// which stays in use for subsequent requests. Agents report every failover to OnRetry with
+// the key_failover stage. The keys take precedence over WithAPIKeySource; a KeyResolver or
+// rotated credentials still override them.
+func WithBackupAPIKeys(keys ...string) ClientOption {
+	return func(c *Config) {
+		c.BackupAPIKeys = append(c.BackupAPIKeys, keys...)
+	}
+}
+
+// keyFailoverKey is the context key of the function notified of key failovers
+type keyFailoverKey struct{}
+
+// withKeyFailoverHook notifies hook of the key failovers of the requests sent with ctx
+func withKeyFailoverHook(ctx context.Context, hook func(err error, errorClass ErrorClass)) context.Context {
+	return context.WithValue(ctx, keyFailoverKey{}, hook)
+}
+
+// keyRing holds the client's API keys, the primary one first
+type keyRing struct {
+	keys    []string
+	current atomic.Int32
+}
+
+func newKeyRing(keys []string) *keyRing {
+	return &keyRing{keys: keys}
+}
+
+// advance moves past the key at index, unless a concurrent request already did
+func (r *keyRing) advance(index int32) {
+	r.current.CompareAndSwap(index, (index+1)%int32(len(r.keys)))
+}
+
+// middleware sends requests with the current key and retries rejected ones with the next
+// keys, each key being tried at most once per request
+func (r *keyRing) middleware() option.Middleware {
+	return func(request *http.Request, next option.MiddlewareNext) (*http.Response, error) {
+		for attempt := 1; ; attempt++ {
+			index := r.current.Load()
+			request.Header.Set("Authorization", "Bearer "+r.keys[index])
+
+			resp, err := next(request)
+			if err != nil || attempt == len(r.keys) || request.GetBody == nil {
+				return resp, err
+			}
+
+			errorClass, rejected := rejectedKey(resp)
+			if !rejected {
+				return resp, nil
+			}
+
+			body, err := request.GetBody()
+			if err != nil {
+				return resp, nil
+			}
+			_ = resp.Body.Close()
+			request.Body = body
+
+			r.advance(index)
+			if hook, ok := request.Context().Value(keyFailoverKey{}).(func(error, ErrorClass)); ok {
+				hook(fmt.Errorf("API key %d of %d rejected with status %d", index+1, len(r.keys), resp.StatusCode), errorClass)
+			}
+		}
+	}
+}
+
+// rejectedKey reports whether the response rejects the API key itself. The body of a 429 is
+// read to tell an exhausted quota from a rate limit, and restored.
+func rejectedKey(resp *http.Response) (ErrorClass, bool) {
+	switch resp.StatusCode {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return ErrorClassAuth, true
+	case http.StatusTooManyRequests:
+		data, err := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		resp.Body = io.NopCloser(bytes.NewReader(data))
+		return ErrorClassRateLimit, err == nil && bytes.Contains(data, []byte("insufficient_quota"))
+	default:
+		return "", false
+	}
+}
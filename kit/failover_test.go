@@ -0,0 +1,57 @@
+package kit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/openai/openai-go/option"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackupAPIKeys(t *testing.T) {
+	var keys []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get("Authorization"))
+
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Header.Get("Authorization") {
+		case "Bearer revoked":
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":{"message":"invalid api key","code":"invalid_api_key"}}`))
+		case "Bearer exhausted":
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"error":{"message":"quota exceeded","code":"insufficient_quota"}}`))
+		default:
+			_, _ = w.Write([]byte(`{"id":"1","object":"chat.completion","choices":[{"index":0,"finish_reason":"stop",` +
+				`"message":{"role":"assistant","content":"done"}}]}`))
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(
+		WithAPIKey("revoked"),
+		WithBackupAPIKeys("exhausted", "valid"),
+		WithBaseURL(server.URL),
+		WithRequestOptions(option.WithMaxRetries(0)),
+	)
+	recorder := &retryRecorder{}
+	agent := CreateAgent(client).WithCallbacks(recorder)
+
+	output, err := agent.Invoke(context.Background(), InvokeConfig{Prompt: "hi"})
+	require.NoError(t, err)
+	require.Equal(t, "done", output)
+	require.Equal(t, []string{"Bearer revoked", "Bearer exhausted", "Bearer valid"}, keys)
+
+	require.Len(t, recorder.retries, 2)
+	require.Equal(t, "key_failover", recorder.retries[0]["stage"])
+	require.Equal(t, string(ErrorClassAuth), recorder.retries[0]["error_class"])
+	require.Equal(t, string(ErrorClassRateLimit), recorder.retries[1]["error_class"])
+
+	// The working key stays in use
+	_, err = Ask(context.Background(), client, "hi", AskOptions{})
+	require.NoError(t, err)
+	require.Equal(t, "Bearer valid", keys[len(keys)-1])
+	require.Len(t, keys, 4)
+}
@@ -3,8 +3,13 @@ package kit
 import (
 	"encoding/base64"
 	"fmt"
+	"strings"
+
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/packages/param"
 )
 
+// File is a file passed to the model as a data URI
 type File struct {
 	DataURI string
 	Name    string
@@ -26,3 +31,76 @@ func FileImage(mime string, fileContent []byte) File {
 		Name:    "",
 	}
 }
+
+// FileAudio creates an audio file for audio-capable models; format is "wav" or "mp3"
+func FileAudio(format string, fileContent []byte) File {
+	mime := "audio/" + format
+	if format == "mp3" {
+		mime = "audio/mpeg"
+	}
+
+	base64Content := base64.StdEncoding.EncodeToString(fileContent)
+	return File{
+		DataURI: fmt.Sprintf("data:%s;base64,%s", mime, base64Content),
+		Name:    "",
+	}
+}
+
+// MimeType returns the media type of the data URI
+func (f File) MimeType() string {
+	mime, _, _ := strings.Cut(strings.TrimPrefix(f.DataURI, "data:"), ";")
+	return mime
+}
+
+// Data decodes the contents of the file
+func (f File) Data() ([]byte, error) {
+	_, data, ok := strings.Cut(f.DataURI, ";base64,")
+	if !ok {
+		return nil, fmt.Errorf("file is not a base64 data URI")
+	}
+	return base64.StdEncoding.DecodeString(data)
+}
+
+// contentPart converts the file into a user message content part: images become image_url
+// parts, audio input_audio parts and everything else a file part
+func (f File) contentPart() openai.ChatCompletionContentPartUnionParam {
+	mime := f.MimeType()
+	if strings.HasPrefix(mime, "image/") {
+		return openai.ImageContentPart(openai.ChatCompletionContentPartImageImageURLParam{URL: f.DataURI})
+	}
+
+	if strings.HasPrefix(mime, "audio/") {
+		format := strings.TrimPrefix(mime, "audio/")
+		if format == "mpeg" {
+			format = "mp3"
+		}
+		_, data, _ := strings.Cut(f.DataURI, ",")
+		return openai.InputAudioContentPart(openai.ChatCompletionContentPartInputAudioInputAudioParam{
+			Data:   data,
+			Format: format,
+		})
+	}
+
+	file := openai.ChatCompletionContentPartFileFileParam{FileData: param.NewOpt(f.DataURI)}
+	if f.Name != "" {
+		file.Filename = param.NewOpt(f.Name)
+	}
+	return openai.FileContentPart(file)
+}
+
+// PromptMessage builds the user message of a prompt with its attached files, as the agent does
+// for InvokeConfig.Prompt and Files
+func PromptMessage(prompt string, files []File) openai.ChatCompletionMessageParamUnion {
+	if len(files) == 0 {
+		return openai.UserMessage(prompt)
+	}
+
+	var parts []openai.ChatCompletionContentPartUnionParam
+	if prompt != "" {
+		parts = append(parts, openai.TextContentPart(prompt))
+	}
+	for _, file := range files {
+		parts = append(parts, file.contentPart())
+	}
+	return openai.UserMessage(parts)
+}
@@ -0,0 +1,18 @@
+package kit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPromptMessageWithAudio(t *testing.T) {
+	message := PromptMessage("Transcribe this", []File{FileAudio("mp3", []byte("audio"))})
+
+	parts := message.OfUser.Content.OfArrayOfContentParts
+	require.Len(t, parts, 2)
+	require.Equal(t, "Transcribe this", parts[0].OfText.Text)
+	require.Equal(t, "mp3", parts[1].OfInputAudio.InputAudio.Format)
+	require.Equal(t, "YXVkaW8=", parts[1].OfInputAudio.InputAudio.Data)
+	require.Equal(t, "Transcribe this", MessageText(message))
+}
@@ -0,0 +1,479 @@
+package kit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
+)
+
+// ModelGeminiFlash is the default model of NewGeminiClient
+const ModelGeminiFlash = "gemini-2.5-flash"
+
+// GeminiOptions configures a GeminiProvider. Set APIKey for Google AI Studio, or VertexProject
+// and VertexLocation for Vertex AI, authenticating through HTTPClient (e.g. an oauth2 client).
+type GeminiOptions struct {
+	APIKey string
+
+	// VertexProject and VertexLocation select the Vertex AI endpoint of the project (optional)
+	VertexProject  string
+	VertexLocation string
+
+	// BaseURL of the API (defaults to https://generativelanguage.googleapis.com, or the regional
+	// Vertex AI endpoint)
+	BaseURL string
+
+	// HTTPClient sends the requests (defaults to http.DefaultClient)
+	HTTPClient *http.Client
+}
+
+// GeminiProvider serves chat completions with the Gemini generateContent API. System messages
+// become the system instruction, tool calls and results map to function calls and responses, and
+// structured outputs use the JSON response schema, or a final_answer tool in runs with tools.
+type GeminiProvider struct {
+	opts GeminiOptions
+}
+
+// NewGeminiProvider creates a provider for the Gemini API
+func NewGeminiProvider(opts GeminiOptions) *GeminiProvider {
+	if opts.BaseURL == "" {
+		opts.BaseURL = "https://generativelanguage.googleapis.com"
+		if opts.VertexProject != "" {
+			opts.BaseURL = fmt.Sprintf("https://%s-aiplatform.googleapis.com", opts.VertexLocation)
+		}
+	}
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = http.DefaultClient
+	}
+	return &GeminiProvider{opts: opts}
+}
+
+// NewGeminiClient creates a Client for Google AI Studio, authenticated with GEMINI_API_KEY. The
+// options override the preset; WithAPIKey and WithBaseURL configure the provider.
+func NewGeminiClient(opts ...ClientOption) *Client {
+	presetOpt := func(c *Config) {
+		c.ApiBase = ""
+		c.ApiKey = os.Getenv("GEMINI_API_KEY")
+		c.DefaultModel = ModelGeminiFlash
+	}
+	providerOpt := func(c *Config) {
+		if c.Provider == nil {
+			c.Provider = NewGeminiProvider(GeminiOptions{APIKey: c.ApiKey, BaseURL: c.ApiBase})
+		}
+	}
+
+	options := append([]ClientOption{presetOpt}, opts...)
+	return NewClient(append(options, providerOpt)...)
+}
+
+// Complete translates the request to generateContent and its response back. The OpenAI
+// request options are ignored.
+func (p *GeminiProvider) Complete(
+	ctx context.Context,
+	params openai.ChatCompletionNewParams,
+	_ ...option.RequestOption,
+) (*openai.ChatCompletion, error) {
+	request, err := geminiRequest(params)
+	if err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal gemini request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.endpoint(params.Model), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if p.opts.APIKey != "" {
+		httpReq.Header.Set("x-goog-api-key", p.opts.APIKey)
+	}
+
+	resp, err := p.opts.HTTPClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		return nil, geminiError(httpReq, resp, data)
+	}
+
+	var response geminiResponse
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, fmt.Errorf("failed to decode gemini response: %w", err)
+	}
+	return response.completion(params.Model)
+}
+
+// endpoint returns the generateContent URL of the model
+func (p *GeminiProvider) endpoint(model string) string {
+	base := strings.TrimSuffix(p.opts.BaseURL, "/")
+	if p.opts.VertexProject != "" {
+		return fmt.Sprintf("%s/v1/projects/%s/locations/%s/publishers/google/models/%s:generateContent",
+			base, url.PathEscape(p.opts.VertexProject), url.PathEscape(p.opts.VertexLocation), url.PathEscape(model))
+	}
+	return fmt.Sprintf("%s/v1beta/models/%s:generateContent", base, url.PathEscape(model))
+}
+
+type geminiPart struct {
+	Text             string                  `json:"text,omitempty"`
+	Thought          bool                    `json:"thought,omitempty"`
+	InlineData       *geminiBlob             `json:"inlineData,omitempty"`
+	FileData         *geminiFileData         `json:"fileData,omitempty"`
+	FunctionCall     *geminiFunctionCall     `json:"functionCall,omitempty"`
+	FunctionResponse *geminiFunctionResponse `json:"functionResponse,omitempty"`
+}
+
+type geminiBlob struct {
+	MimeType string `json:"mimeType"`
+	Data     string `json:"data"`
+}
+
+type geminiFileData struct {
+	MimeType string `json:"mimeType,omitempty"`
+	FileURI  string `json:"fileUri"`
+}
+
+type geminiFunctionCall struct {
+	ID   string          `json:"id,omitempty"`
+	Name string          `json:"name"`
+	Args json.RawMessage `json:"args,omitempty"`
+}
+
+type geminiFunctionResponse struct {
+	ID       string         `json:"id,omitempty"`
+	Name     string         `json:"name"`
+	Response map[string]any `json:"response"`
+}
+
+type geminiContent struct {
+	Role  string       `json:"role,omitempty"`
+	Parts []geminiPart `json:"parts"`
+}
+
+type geminiFunctionDeclaration struct {
+	Name                 string `json:"name"`
+	Description          string `json:"description,omitempty"`
+	ParametersJSONSchema any    `json:"parametersJsonSchema,omitempty"`
+}
+
+type geminiTool struct {
+	FunctionDeclarations []geminiFunctionDeclaration `json:"functionDeclarations"`
+}
+
+type geminiFunctionCallingConfig struct {
+	Mode                 string   `json:"mode"`
+	AllowedFunctionNames []string `json:"allowedFunctionNames,omitempty"`
+}
+
+type geminiToolConfig struct {
+	FunctionCallingConfig geminiFunctionCallingConfig `json:"functionCallingConfig"`
+}
+
+type geminiGenerationConfig struct {
+	Temperature        *float64 `json:"temperature,omitempty"`
+	TopP               *float64 `json:"topP,omitempty"`
+	MaxOutputTokens    int64    `json:"maxOutputTokens,omitempty"`
+	StopSequences      []string `json:"stopSequences,omitempty"`
+	ResponseMimeType   string   `json:"responseMimeType,omitempty"`
+	ResponseJSONSchema any      `json:"responseJsonSchema,omitempty"`
+}
+
+type geminiGenerateRequest struct {
+	SystemInstruction *geminiContent          `json:"systemInstruction,omitempty"`
+	Contents          []geminiContent         `json:"contents"`
+	Tools             []geminiTool            `json:"tools,omitempty"`
+	ToolConfig        *geminiToolConfig       `json:"toolConfig,omitempty"`
+	GenerationConfig  *geminiGenerationConfig `json:"generationConfig,omitempty"`
+}
+
+// geminiRequest translates a chat completion request to generateContent
+func geminiRequest(params openai.ChatCompletionNewParams) (*geminiGenerateRequest, error) {
+	config := &geminiGenerationConfig{}
+	request := &geminiGenerateRequest{GenerationConfig: config}
+	switch {
+	case params.MaxCompletionTokens.Valid():
+		config.MaxOutputTokens = params.MaxCompletionTokens.Value
+	case params.MaxTokens.Valid():
+		config.MaxOutputTokens = params.MaxTokens.Value
+	}
+	if params.Temperature.Valid() {
+		config.Temperature = &params.Temperature.Value
+	}
+	if params.TopP.Valid() {
+		config.TopP = &params.TopP.Value
+	}
+	if params.Stop.OfString.Valid() {
+		config.StopSequences = []string{params.Stop.OfString.Value}
+	}
+	config.StopSequences = append(config.StopSequences, params.Stop.OfStringArray...)
+
+	// Function responses are matched to their calls by name
+	toolNames := make(map[string]string) // tool call ID -> function name
+	var system []geminiPart
+	for _, msg := range params.Messages {
+		switch {
+		case msg.OfSystem != nil, msg.OfDeveloper != nil:
+			system = append(system, geminiPart{Text: MessageText(msg)})
+		case msg.OfUser != nil:
+			request.addContent("user", geminiUserParts(msg.OfUser)...)
+		case msg.OfAssistant != nil:
+			var parts []geminiPart
+			if text := MessageText(msg); text != "" {
+				parts = append(parts, geminiPart{Text: text})
+			}
+			for _, call := range msg.OfAssistant.ToolCalls {
+				args := json.RawMessage(call.Function.Arguments)
+				if len(bytes.TrimSpace(args)) == 0 {
+					args = json.RawMessage("{}")
+				}
+				if !json.Valid(args) {
+					return nil, fmt.Errorf("invalid arguments of tool call %s", call.ID)
+				}
+				toolNames[call.ID] = call.Function.Name
+				parts = append(parts, geminiPart{FunctionCall: &geminiFunctionCall{Name: call.Function.Name, Args: args}})
+			}
+			request.addContent("model", parts...)
+		case msg.OfTool != nil:
+			request.addContent("user", geminiPart{FunctionResponse: &geminiFunctionResponse{
+				Name:     toolNames[msg.OfTool.ToolCallID],
+				Response: map[string]any{"result": geminiResult(MessageText(msg))},
+			}})
+		}
+	}
+	if len(system) > 0 {
+		request.SystemInstruction = &geminiContent{Parts: system}
+	}
+
+	var declarations []geminiFunctionDeclaration
+	for _, tool := range params.Tools {
+		declarations = append(declarations, geminiFunctionDeclaration{
+			Name:                 tool.Function.Name,
+			Description:          tool.Function.Description.Value,
+			ParametersJSONSchema: tool.Function.Parameters,
+		})
+	}
+
+	var toolConfig *geminiFunctionCallingConfig
+	switch {
+	case params.ToolChoice.OfChatCompletionNamedToolChoice != nil:
+		toolConfig = &geminiFunctionCallingConfig{
+			Mode:                 "ANY",
+			AllowedFunctionNames: []string{params.ToolChoice.OfChatCompletionNamedToolChoice.Function.Name},
+		}
+	case params.ToolChoice.OfAuto.Value == "required":
+		toolConfig = &geminiFunctionCallingConfig{Mode: "ANY"}
+	case params.ToolChoice.OfAuto.Value == "none":
+		toolConfig = &geminiFunctionCallingConfig{Mode: "NONE"}
+	}
+
+	// Structured outputs use the response schema, which can't be combined with function calling,
+	// so runs with tools request them through the output tool
+	if format := params.ResponseFormat.OfJSONSchema; format != nil {
+		if len(declarations) == 0 {
+			config.ResponseMimeType = "application/json"
+			config.ResponseJSONSchema = format.JSONSchema.Schema
+		} else {
+			declarations = append(declarations, geminiFunctionDeclaration{
+				Name:                 outputTool,
+				Description:          outputToolDescription,
+				ParametersJSONSchema: format.JSONSchema.Schema,
+			})
+			if toolConfig == nil {
+				toolConfig = &geminiFunctionCallingConfig{Mode: "ANY"}
+			}
+		}
+	} else if params.ResponseFormat.OfJSONObject != nil {
+		config.ResponseMimeType = "application/json"
+	}
+
+	if len(declarations) > 0 {
+		request.Tools = []geminiTool{{FunctionDeclarations: declarations}}
+	}
+	if toolConfig != nil {
+		request.ToolConfig = &geminiToolConfig{FunctionCallingConfig: *toolConfig}
+	}
+	return request, nil
+}
+
+// addContent appends the parts to the conversation, merging consecutive contents of the same
+// role so parallel function responses share one turn
+func (r *geminiGenerateRequest) addContent(role string, parts ...geminiPart) {
+	if len(parts) == 0 {
+		return
+	}
+	if n := len(r.Contents); n > 0 && r.Contents[n-1].Role == role {
+		r.Contents[n-1].Parts = append(r.Contents[n-1].Parts, parts...)
+		return
+	}
+	r.Contents = append(r.Contents, geminiContent{Role: role, Parts: parts})
+}
+
+// geminiResult is a tool result as function response: its JSON value, or the plain text
+func geminiResult(text string) any {
+	var value any
+	if err := json.Unmarshal([]byte(text), &value); err == nil {
+		return value
+	}
+	return text
+}
+
+func geminiUserParts(msg *openai.ChatCompletionUserMessageParam) []geminiPart {
+	if msg.Content.OfString.Valid() {
+		if msg.Content.OfString.Value == "" {
+			return nil
+		}
+		return []geminiPart{{Text: msg.Content.OfString.Value}}
+	}
+
+	var parts []geminiPart
+	for _, part := range msg.Content.OfArrayOfContentParts {
+		switch {
+		case part.OfText != nil && part.OfText.Text != "":
+			parts = append(parts, geminiPart{Text: part.OfText.Text})
+		case part.OfImageURL != nil:
+			parts = append(parts, geminiFilePart(part.OfImageURL.ImageURL.URL))
+		case part.OfFile != nil && part.OfFile.File.FileData.Valid():
+			parts = append(parts, geminiFilePart(part.OfFile.File.FileData.Value))
+		case part.OfInputAudio != nil:
+			parts = append(parts, geminiPart{InlineData: &geminiBlob{
+				MimeType: "audio/" + part.OfInputAudio.InputAudio.Format,
+				Data:     part.OfInputAudio.InputAudio.Data,
+			}})
+		}
+	}
+	return parts
+}
+
+// geminiFilePart converts a data URI into inline data; other URLs are passed by URI
+func geminiFilePart(uri string) geminiPart {
+	mimeType, data, ok := splitDataURI(uri)
+	if !ok {
+		return geminiPart{FileData: &geminiFileData{FileURI: uri}}
+	}
+	return geminiPart{InlineData: &geminiBlob{MimeType: mimeType, Data: data}}
+}
+
+// geminiResponse is a response of generateContent
+type geminiResponse struct {
+	ResponseID   string `json:"responseId"`
+	ModelVersion string `json:"modelVersion"`
+	Candidates   []struct {
+		Content      geminiContent `json:"content"`
+		FinishReason string        `json:"finishReason"`
+	} `json:"candidates"`
+	PromptFeedback struct {
+		BlockReason string `json:"blockReason"`
+	} `json:"promptFeedback"`
+	UsageMetadata struct {
+		PromptTokenCount        int64 `json:"promptTokenCount"`
+		CandidatesTokenCount    int64 `json:"candidatesTokenCount"`
+		CachedContentTokenCount int64 `json:"cachedContentTokenCount"`
+		ThoughtsTokenCount      int64 `json:"thoughtsTokenCount"`
+	} `json:"usageMetadata"`
+}
+
+// completion translates the response to a chat completion. Function calls get generated IDs,
+// a call of the output tool becomes the content of the response and generated images are
+// returned in the images field, like OpenRouter does.
+func (r *geminiResponse) completion(model string) (*openai.ChatCompletion, error) {
+	var text []string
+	var toolCalls, images []map[string]any
+	answered := false
+	finishReason := "stop"
+	if r.PromptFeedback.BlockReason != "" {
+		finishReason = "content_filter"
+	}
+
+	if len(r.Candidates) > 0 {
+		candidate := r.Candidates[0]
+		for _, part := range candidate.Content.Parts {
+			switch {
+			case part.Thought:
+			case part.FunctionCall != nil && part.FunctionCall.Name == outputTool:
+				text = []string{string(part.FunctionCall.Args)}
+				answered = true
+			case part.FunctionCall != nil:
+				id := part.FunctionCall.ID
+				if id == "" {
+					id = "call_" + uuid.NewString()
+				}
+				args := string(part.FunctionCall.Args)
+				if args == "" {
+					args = "{}"
+				}
+				toolCalls = append(toolCalls, toolCallJSON(id, part.FunctionCall.Name, args))
+			case part.InlineData != nil && strings.HasPrefix(part.InlineData.MimeType, "image/"):
+				url := fmt.Sprintf("data:%s;base64,%s", part.InlineData.MimeType, part.InlineData.Data)
+				images = append(images, map[string]any{"type": "image_url", "image_url": map[string]any{"url": url}})
+			case part.Text != "":
+				text = append(text, part.Text)
+			}
+		}
+
+		switch candidate.FinishReason {
+		case "MAX_TOKENS":
+			finishReason = "length"
+		case "SAFETY", "RECITATION", "BLOCKLIST", "PROHIBITED_CONTENT", "SPII", "IMAGE_SAFETY":
+			finishReason = "content_filter"
+		}
+	}
+	if answered {
+		toolCalls = nil
+	}
+
+	message := map[string]any{"role": "assistant", "content": strings.Join(text, "")}
+	if len(toolCalls) > 0 {
+		message["tool_calls"] = toolCalls
+		finishReason = "tool_calls"
+	}
+	if len(images) > 0 {
+		message["images"] = images
+	}
+
+	if r.ModelVersion != "" {
+		model = r.ModelVersion
+	}
+	usage := r.UsageMetadata
+	completionTokens := usage.CandidatesTokenCount + usage.ThoughtsTokenCount
+	return newCompletion(r.ResponseID, model, message, finishReason, map[string]any{
+		"prompt_tokens":             usage.PromptTokenCount,
+		"completion_tokens":         completionTokens,
+		"total_tokens":              usage.PromptTokenCount + completionTokens,
+		"prompt_tokens_details":     map[string]any{"cached_tokens": usage.CachedContentTokenCount},
+		"completion_tokens_details": map[string]any{"reasoning_tokens": usage.ThoughtsTokenCount},
+	})
+}
+
+// geminiError converts an error response of the Gemini API
+func geminiError(req *http.Request, resp *http.Response, data []byte) error {
+	var body struct {
+		Error struct {
+			Message string `json:"message"`
+			Status  string `json:"status"`
+		} `json:"error"`
+	}
+	_ = json.Unmarshal(data, &body)
+
+	code := body.Error.Status
+	if strings.Contains(strings.ToLower(body.Error.Message), "exceeds the maximum number of tokens") {
+		code = "context_length_exceeded"
+	}
+	return providerError(req, resp, code, body.Error.Message, body.Error.Status)
+}
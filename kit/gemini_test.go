@@ -0,0 +1,84 @@
+package kit
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/openai/openai-go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGeminiProvider(t *testing.T) {
+	var requests []geminiGenerateRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v1beta/models/"+ModelGeminiFlash+":generateContent", r.URL.Path)
+		require.Equal(t, "test", r.Header.Get("x-goog-api-key"))
+
+		var request geminiGenerateRequest
+		body, _ := io.ReadAll(r.Body)
+		require.NoError(t, json.Unmarshal(body, &request))
+		requests = append(requests, request)
+
+		w.Header().Set("Content-Type", "application/json")
+		if len(requests) == 1 {
+			_, _ = w.Write([]byte(`{"responseId":"r1","candidates":[{"finishReason":"STOP","content":{"role":"model","parts":[` +
+				`{"text":"thinking","thought":true},{"functionCall":{"name":"lookup_tool","args":{}}}]}}],` +
+				`"usageMetadata":{"promptTokenCount":10,"candidatesTokenCount":5,"thoughtsTokenCount":3}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"responseId":"r2","candidates":[{"finishReason":"STOP","content":{"role":"model","parts":[` +
+			`{"functionCall":{"name":"final_answer","args":{"answer":"nothing found"}}}]}}],` +
+			`"usageMetadata":{"promptTokenCount":20,"candidatesTokenCount":5}}`))
+	}))
+	defer server.Close()
+
+	type answer struct {
+		Answer string `json:"answer"`
+	}
+
+	client := NewGeminiClient(WithAPIKey("test"), WithBaseURL(server.URL))
+	result, err := CreateAgentWithOutput[answer](client, &lookupTool{}).
+		InvokeDetailed(context.Background(), InvokeConfig{Prompt: "find it", SystemPrompt: "Be brief."})
+	require.NoError(t, err)
+	require.Equal(t, "nothing found", result.Output.Answer)
+	require.Equal(t, int64(30), result.Usage.PromptTokens)
+	require.Equal(t, int64(13), result.Usage.CompletionTokens)
+	require.Equal(t, int64(3), result.Usage.CompletionTokensDetails.ReasoningTokens)
+
+	require.Len(t, requests, 2)
+	first := requests[0]
+	require.Equal(t, "Be brief.", first.SystemInstruction.Parts[0].Text)
+	declarations := first.Tools[0].FunctionDeclarations
+	require.Equal(t, []string{"lookup_tool", outputTool}, []string{declarations[0].Name, declarations[1].Name})
+	require.Equal(t, "ANY", first.ToolConfig.FunctionCallingConfig.Mode)
+	require.Empty(t, first.GenerationConfig.ResponseMimeType, "function calling can't be combined with a response schema")
+
+	// The function call and its response are matched by name
+	second := requests[1].Contents
+	require.Len(t, second, 3)
+	require.Equal(t, "model", second[1].Role)
+	require.Equal(t, "lookup_tool", second[1].Parts[0].FunctionCall.Name)
+	require.Equal(t, "user", second[2].Role)
+	require.Equal(t, &geminiFunctionResponse{Name: "lookup_tool", Response: map[string]any{"result": "nothing found"}},
+		second[2].Parts[0].FunctionResponse)
+}
+
+func TestGeminiResponseSchema(t *testing.T) {
+	type answer struct {
+		Answer string `json:"answer"`
+	}
+
+	request, err := geminiRequest(openai.ChatCompletionNewParams{
+		Model:          ModelGeminiFlash,
+		Messages:       []openai.ChatCompletionMessageParamUnion{openai.UserMessage("hi")},
+		ResponseFormat: responseFormat[answer](true),
+	})
+	require.NoError(t, err)
+	require.Equal(t, "application/json", request.GenerationConfig.ResponseMimeType)
+	require.NotNil(t, request.GenerationConfig.ResponseJSONSchema)
+	require.Nil(t, request.Tools)
+}
@@ -0,0 +1,83 @@
+package kit
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/openai/openai-go"
+)
+
+// Generate performs a single structured completion and returns the parsed T, without the agent loop.
+// When the response doesn't parse, the model is asked to repair it up to opts.MaxRetries times.
+func Generate[T any](ctx context.Context, client *Client, prompt string, opts AskOptions) (T, error) {
+	var zero T
+
+	params := askParams(client, prompt, opts)
+	if !isStringType(zero) {
+		outputFormat[T](client, &params)
+	}
+
+	maxRetries := opts.MaxRetries
+	switch {
+	case maxRetries == 0:
+		maxRetries = 1
+	case maxRetries < 0:
+		maxRetries = 0
+	}
+
+	var lastErr error
+	for attempt := 0; attempt <= maxRetries; attempt++ {
+		completion, err := client.complete(ctx, params)
+		if err != nil {
+			return zero, fmt.Errorf("OpenAI API error: %w", err)
+		}
+
+		if len(completion.Choices) == 0 {
+			return zero, fmt.Errorf("no choices in response")
+		}
+
+		content := completion.Choices[0].Message.Content
+		if isStringType(zero) {
+			return any(content).(T), nil
+		}
+
+		result, err := parseOutput[T](repairJSON(content))
+		if err == nil {
+			return result, nil
+		}
+		lastErr = err
+
+		// Ask the model to fix its previous answer
+		params.Messages = append(params.Messages,
+			openai.AssistantMessage(content),
+			openai.UserMessage(fmt.Sprintf(
+				"Your response could not be parsed (%s). Respond again with only valid JSON matching the schema.",
+				err,
+			)),
+		)
+	}
+
+	return zero, lastErr
+}
+
+// repairJSON strips common wrappers around a JSON value, such as markdown code fences
+// or text before the opening brace
+func repairJSON(content string) string {
+	trimmed := strings.TrimSpace(content)
+
+	if strings.HasPrefix(trimmed, "```") {
+		trimmed = strings.TrimPrefix(trimmed, "```json")
+		trimmed = strings.TrimPrefix(trimmed, "```")
+		trimmed = strings.TrimSuffix(strings.TrimSpace(trimmed), "```")
+		trimmed = strings.TrimSpace(trimmed)
+	}
+
+	start := strings.IndexAny(trimmed, "{[")
+	end := strings.LastIndexAny(trimmed, "}]")
+	if start >= 0 && end > start {
+		trimmed = trimmed[start : end+1]
+	}
+
+	return trimmed
+}
@@ -0,0 +1,51 @@
+package kit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/openai/openai-go/option"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepairJSON(t *testing.T) {
+	cases := map[string]string{
+		`{"a": 1}`:                     `{"a": 1}`,
+		"```json\n{\"a\": 1}\n```":     `{"a": 1}`,
+		"```\n[1, 2]\n```":             `[1, 2]`,
+		`Here you go: {"a": 1} cheers`: `{"a": 1}`,
+		`{"a": 1} cheers`:              `{"a": 1}`,
+		`  {"nested": {"b": true}}  `:  `{"nested": {"b": true}}`,
+		`no json here`:                 `no json here`,
+	}
+
+	for input, expected := range cases {
+		require.Equal(t, expected, repairJSON(input), "input: %q", input)
+	}
+}
+
+func TestGenerateMaxRetries(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"1","object":"chat.completion","choices":[` +
+			`{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":"not json"}}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("test"), WithBaseURL(server.URL), WithRequestOptions(option.WithMaxRetries(0)))
+	type answer struct {
+		A int `json:"a"`
+	}
+
+	for retries, expected := range map[int]int32{0: 2, 2: 3, -1: 1} {
+		requests.Store(0)
+		_, err := Generate[answer](context.Background(), client, "hi", AskOptions{MaxRetries: retries})
+		require.Error(t, err)
+		require.Equal(t, expected, requests.Load(), "max retries: %d", retries)
+	}
+}
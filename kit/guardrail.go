@@ -0,0 +1,193 @@
+package kit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/packages/param"
+)
+
+// ErrInputRejected is returned (wrapped) when an input guard blocks a run
+var ErrInputRejected = errors.New("input rejected by guardrail")
+
+// ErrOutputRejected is returned (wrapped) when an output guard blocks the final output
+var ErrOutputRejected = errors.New("output rejected by guardrail")
+
+// InputGuard inspects the messages of a run before the first generation.
+// Returning an error blocks the run before any tokens are spent.
+type InputGuard func(ctx context.Context, messages []openai.ChatCompletionMessageParamUnion) error
+
+// WithInputGuard adds guards that are checked before the agent loop starts
+func (a *Agent[Output]) WithInputGuard(guards ...InputGuard) *Agent[Output] {
+	a.inputGuards = append(a.inputGuards, guards...)
+	return a
+}
+
+// checkInput runs all input guards in order and stops at the first rejection
+func (a *Agent[Output]) checkInput(ctx context.Context, messages []openai.ChatCompletionMessageParamUnion) error {
+	for _, guard := range a.inputGuards {
+		if err := guard(ctx, messages); err != nil {
+			return fmt.Errorf("%w: %w", ErrInputRejected, err)
+		}
+	}
+	return nil
+}
+
+// ModerationGuard rejects input that is flagged by the OpenAI moderation endpoint.
+// The model is optional and defaults to omni-moderation-latest.
+func ModerationGuard(client *Client, model ...string) InputGuard {
+	moderationModel := openai.ModerationModelOmniModerationLatest
+	if len(model) > 0 && model[0] != "" {
+		moderationModel = model[0]
+	}
+
+	return func(ctx context.Context, messages []openai.ChatCompletionMessageParamUnion) error {
+		return moderate(ctx, client, moderationModel, userText(messages))
+	}
+}
+
+// moderate sends text to the moderation endpoint and returns an error listing flagged categories
+func moderate(ctx context.Context, client *Client, model, text string) error {
+	if text == "" {
+		return nil
+	}
+
+	resp, err := client.client.Moderations.New(ctx, openai.ModerationNewParams{
+		Input: openai.ModerationNewParamsInputUnion{OfString: param.NewOpt(text)},
+		Model: model,
+	})
+	if err != nil {
+		return fmt.Errorf("moderation request failed: %w", err)
+	}
+
+	for _, result := range resp.Results {
+		if !result.Flagged {
+			continue
+		}
+
+		var categories map[string]bool
+		_ = json.Unmarshal([]byte(result.Categories.RawJSON()), &categories)
+
+		flagged := make([]string, 0, len(categories))
+		for category, isFlagged := range categories {
+			if isFlagged {
+				flagged = append(flagged, category)
+			}
+		}
+		sort.Strings(flagged)
+
+		return fmt.Errorf("flagged by moderation: %s", strings.Join(flagged, ", "))
+	}
+
+	return nil
+}
+
+// DeniedTopicsGuard rejects input whose user messages mention any of the given topics.
+// Matching is case-insensitive substring matching.
+func DeniedTopicsGuard(topics ...string) InputGuard {
+	lowered := lowerTerms(topics)
+
+	return func(_ context.Context, messages []openai.ChatCompletionMessageParamUnion) error {
+		if term, found := containsAny(userText(messages), lowered); found {
+			return fmt.Errorf("denied topic: %q", term)
+		}
+		return nil
+	}
+}
+
+// OutputGuard inspects the final output of a run. It may return a transformed output,
+// or an error to reject it. Rejections can optionally be retried once with guidance.
+type OutputGuard[Output any] func(ctx context.Context, output Output) (Output, error)
+
+// WithOutputGuard adds guards that are checked against the final output
+func (a *Agent[Output]) WithOutputGuard(guards ...OutputGuard[Output]) *Agent[Output] {
+	a.outputGuards = append(a.outputGuards, guards...)
+	return a
+}
+
+// WithOutputGuardRetry makes the agent retry once when an output guard rejects the output,
+// sending the rejection reason back to the model as guidance
+func (a *Agent[Output]) WithOutputGuardRetry(retry bool) *Agent[Output] {
+	a.guardRetry = retry
+	return a
+}
+
+// checkOutput runs all output guards in order, threading transformed outputs through
+func (a *Agent[Output]) checkOutput(ctx context.Context, output Output) (Output, error) {
+	for _, guard := range a.outputGuards {
+		transformed, err := guard(ctx, output)
+		if err != nil {
+			return output, fmt.Errorf("%w: %w", ErrOutputRejected, err)
+		}
+		output = transformed
+	}
+	return output, nil
+}
+
+// outputGuidanceMessage builds the user message sent to the model after an output rejection
+func outputGuidanceMessage(err error) openai.ChatCompletionMessageParamUnion {
+	return openai.UserMessage(fmt.Sprintf(
+		"Your previous answer was rejected (%s). Please revise your answer so it complies, "+
+			"keeping the same format.",
+		err,
+	))
+}
+
+// ModerationOutputGuard rejects output that is flagged by the OpenAI moderation endpoint
+func ModerationOutputGuard[Output any](client *Client, model ...string) OutputGuard[Output] {
+	moderationModel := openai.ModerationModelOmniModerationLatest
+	if len(model) > 0 && model[0] != "" {
+		moderationModel = model[0]
+	}
+
+	return func(ctx context.Context, output Output) (Output, error) {
+		text, err := resultToString(output)
+		if err != nil {
+			return output, err
+		}
+		return output, moderate(ctx, client, moderationModel, text)
+	}
+}
+
+// BannedContentGuard rejects output containing any of the given terms (case-insensitive)
+func BannedContentGuard[Output any](terms ...string) OutputGuard[Output] {
+	lowered := lowerTerms(terms)
+
+	return func(_ context.Context, output Output) (Output, error) {
+		text, err := resultToString(output)
+		if err != nil {
+			return output, err
+		}
+		if term, found := containsAny(text, lowered); found {
+			return output, fmt.Errorf("banned content: %q", term)
+		}
+		return output, nil
+	}
+}
+
+// lowerTerms trims and lowercases terms, dropping empty ones
+func lowerTerms(terms []string) []string {
+	lowered := make([]string, 0, len(terms))
+	for _, term := range terms {
+		if term = strings.TrimSpace(term); term != "" {
+			lowered = append(lowered, strings.ToLower(term))
+		}
+	}
+	return lowered
+}
+
+// containsAny reports the first lowered term contained in text
+func containsAny(text string, lowered []string) (string, bool) {
+	text = strings.ToLower(text)
+	for _, term := range lowered {
+		if strings.Contains(text, term) {
+			return term, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,102 @@
+package kit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/openai/openai-go/option"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInputGuards(t *testing.T) {
+	var completions atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if strings.HasSuffix(r.URL.Path, "/moderations") {
+			_, _ = w.Write([]byte(`{"id":"modr-1","model":"omni-moderation-latest","results":[{"flagged":true,` +
+				`"categories":{"violence":true,"harassment":true,"hate":false}}]}`))
+			return
+		}
+		completions.Add(1)
+		_, _ = w.Write([]byte(`{"id":"1","object":"chat.completion","choices":[{"index":0,"finish_reason":"stop",` +
+			`"message":{"role":"assistant","content":"ok"}}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("test"), WithBaseURL(server.URL), WithRequestOptions(option.WithMaxRetries(0)))
+	recorder := &errorRecorder{}
+	agent := CreateAgent(client).WithInputGuard(DeniedTopicsGuard("Politics")).WithCallbacks(recorder)
+
+	output, err := agent.Invoke(context.Background(), InvokeConfig{Prompt: "tell me a joke"})
+	require.NoError(t, err)
+	require.Equal(t, "ok", output)
+
+	// Rejected runs stop before any tokens are spent
+	_, err = agent.Invoke(context.Background(), InvokeConfig{Prompt: "Let's talk POLITICS"})
+	require.ErrorIs(t, err, ErrInputRejected)
+	require.Contains(t, err.Error(), `denied topic: "politics"`)
+	require.Equal(t, int32(1), completions.Load())
+	require.Equal(t, "input_guard", recorder.errors[len(recorder.errors)-1]["stage"])
+
+	_, err = CreateAgent(client).WithInputGuard(ModerationGuard(client)).Invoke(context.Background(), InvokeConfig{Prompt: "hi"})
+	require.ErrorIs(t, err, ErrInputRejected)
+	require.Contains(t, err.Error(), "flagged by moderation: harassment, violence")
+	require.Equal(t, int32(1), completions.Load())
+}
+
+func TestOutputGuards(t *testing.T) {
+	var guidance []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Messages []struct {
+				Role    string `json:"role"`
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+
+		w.Header().Set("Content-Type", "application/json")
+		last := body.Messages[len(body.Messages)-1]
+		if last.Content == "hi" {
+			_, _ = w.Write([]byte(`{"id":"1","object":"chat.completion","choices":[{"index":0,"finish_reason":"stop",` +
+				`"message":{"role":"assistant","content":"the secret password"}}]}`))
+			return
+		}
+		guidance = append(guidance, last.Content)
+		_, _ = w.Write([]byte(`{"id":"1","object":"chat.completion","choices":[{"index":0,"finish_reason":"stop",` +
+			`"message":{"role":"assistant","content":"nothing to share"}}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("test"), WithBaseURL(server.URL), WithRequestOptions(option.WithMaxRetries(0)))
+	upper := func(_ context.Context, output string) (string, error) {
+		return strings.ToUpper(output), nil
+	}
+
+	// Without a retry the rejection ends the run
+	_, err := CreateAgent(client).
+		WithOutputGuard(BannedContentGuard[string]("Secret"), upper).
+		Invoke(context.Background(), InvokeConfig{Prompt: "hi"})
+	require.ErrorIs(t, err, ErrOutputRejected)
+	require.Contains(t, err.Error(), `banned content: "secret"`)
+	require.Empty(t, guidance)
+
+	// With a retry the model revises its answer, which the guards then transform
+	recorder := &retryRecorder{}
+	output, err := CreateAgent(client).
+		WithOutputGuard(BannedContentGuard[string]("Secret"), upper).
+		WithOutputGuardRetry(true).
+		WithCallbacks(recorder).
+		Invoke(context.Background(), InvokeConfig{Prompt: "hi"})
+	require.NoError(t, err)
+	require.Equal(t, "NOTHING TO SHARE", output)
+	require.Len(t, guidance, 1)
+	require.Contains(t, guidance[0], "Your previous answer was rejected")
+	require.Len(t, recorder.retries, 1)
+	require.Equal(t, "output_guard", recorder.retries[0]["stage"])
+}
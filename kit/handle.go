@@ -0,0 +1,200 @@
+package kit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrRunInProgress is returned by RunHandle.Result while the run is queued or executing
+var ErrRunInProgress = errors.New("run in progress")
+
+// RunStatus is the state of a background run
+type RunStatus string
+
+const (
+	RunStatusQueued    RunStatus = "queued" // waiting in a Pool
+	RunStatusRunning   RunStatus = "running"
+	RunStatusSucceeded RunStatus = "succeeded"
+	RunStatusFailed    RunStatus = "failed"
+	RunStatusCancelled RunStatus = "cancelled"
+)
+
+// subscriberBuffer is the number of events buffered per subscriber; events for a subscriber
+// that falls further behind are dropped
+const subscriberBuffer = 64
+
+// RunHandle tracks a run started in the background with Agent.Start
+type RunHandle[Output any] struct {
+	done      chan struct{}
+	canceller *Canceller
+
+	mu          sync.Mutex
+	status      RunStatus
+	progress    Progress
+	result      *InvokeResult[Output]
+	err         error
+	subscribers map[chan StreamEvent[Output]]struct{}
+}
+
+// Start executes the agent asynchronously and returns a handle to follow the run. It fails
+// only when the configuration is invalid; run errors are reported by the handle.
+func (a *Agent[Output]) Start(ctx context.Context, config InvokeConfig) (*RunHandle[Output], error) {
+	handle, err := newRunHandle[Output](&config, RunStatusRunning)
+	if err != nil {
+		return nil, err
+	}
+
+	go handle.run(ctx, a, config)
+	return handle, nil
+}
+
+// newRunHandle validates the configuration and creates the handle of a run, giving the run a
+// Canceller if it has none
+func newRunHandle[Output any](config *InvokeConfig, status RunStatus) (*RunHandle[Output], error) {
+	if config.Prompt != "" && len(config.Messages) > 0 {
+		return nil, fmt.Errorf("cannot specify both Prompt and Messages")
+	}
+	if config.Prompt == "" && len(config.Messages) == 0 {
+		return nil, fmt.Errorf("must specify either Prompt or Messages")
+	}
+
+	if config.Canceller == nil {
+		config.Canceller = NewCanceller()
+	}
+
+	return &RunHandle[Output]{
+		done:        make(chan struct{}),
+		canceller:   config.Canceller,
+		status:      status,
+		subscribers: make(map[chan StreamEvent[Output]]struct{}),
+	}, nil
+}
+
+// run executes the agent, publishing its events, and returns once the run has finished
+func (h *RunHandle[Output]) run(ctx context.Context, agent *Agent[Output], config InvokeConfig) {
+	h.mu.Lock()
+	h.status = RunStatusRunning
+	h.mu.Unlock()
+
+	defer h.finish(ctx)
+	for event := range agent.Stream(ctx, config) {
+		h.publish(event)
+	}
+}
+
+// finished reports whether the run has reached a final status; the caller holds mu
+func (h *RunHandle[Output]) finished() bool {
+	return h.status != RunStatusQueued && h.status != RunStatusRunning
+}
+
+// Cancel stops the run at the next safe point; Result then returns its partial state with a
+// CancelError
+func (h *RunHandle[Output]) Cancel(reason string) {
+	h.canceller.Cancel(reason)
+}
+
+// Status returns the current state of the run
+func (h *RunHandle[Output]) Status() RunStatus {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.status
+}
+
+// Progress returns the latest progress of the run
+func (h *RunHandle[Output]) Progress() Progress {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.progress
+}
+
+// Done is closed when the run has finished
+func (h *RunHandle[Output]) Done() <-chan struct{} {
+	return h.done
+}
+
+// Wait blocks until the run has finished or ctx is done and returns the run's result
+func (h *RunHandle[Output]) Wait(ctx context.Context) (*InvokeResult[Output], error) {
+	select {
+	case <-h.done:
+		return h.Result()
+	case <-ctx.Done():
+		return nil, context.Cause(ctx)
+	}
+}
+
+// Result returns the result of the finished run, or ErrRunInProgress while it is executing
+func (h *RunHandle[Output]) Result() (*InvokeResult[Output], error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !h.finished() {
+		return nil, ErrRunInProgress
+	}
+	return h.result, h.err
+}
+
+// Subscribe returns a channel receiving the events of the run from now on, closed when the run
+// has finished (immediately if it already has). Call unsubscribe to stop receiving earlier.
+func (h *RunHandle[Output]) Subscribe() (events <-chan StreamEvent[Output], unsubscribe func()) {
+	ch := make(chan StreamEvent[Output], subscriberBuffer)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.finished() {
+		close(ch)
+		return ch, func() {}
+	}
+	h.subscribers[ch] = struct{}{}
+
+	return ch, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if _, ok := h.subscribers[ch]; ok {
+			delete(h.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// publish records the outcome of terminal events and fans the event out to the subscribers
+func (h *RunHandle[Output]) publish(event StreamEvent[Output]) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	switch event.Type {
+	case StreamEventProgress:
+		h.progress = event.Progress
+	case StreamEventDone:
+		h.status, h.result = RunStatusSucceeded, event.Run
+		h.progress.Percent = 100
+	case StreamEventError:
+		h.status, h.result, h.err = RunStatusFailed, event.Run, event.Error
+		if errors.Is(event.Error, ErrRunCancelled) {
+			h.status = RunStatusCancelled
+		}
+	}
+
+	for ch := range h.subscribers {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// finish closes the subscriptions once the run's stream is drained
+func (h *RunHandle[Output]) finish(ctx context.Context) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	// The stream closes without a terminal event when its context is canceled
+	if !h.finished() {
+		h.status, h.err = RunStatusFailed, context.Cause(ctx)
+	}
+	for ch := range h.subscribers {
+		delete(h.subscribers, ch)
+		close(ch)
+	}
+	close(h.done)
+}
@@ -0,0 +1,28 @@
+package kit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunHandle(t *testing.T) {
+	agent := CreateAgent(streamingClient(t))
+
+	handle, err := agent.Start(context.Background(), InvokeConfig{Prompt: "hi"})
+	require.NoError(t, err)
+	result, err := handle.Wait(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "Hello", result.Output)
+	require.Equal(t, RunStatusSucceeded, handle.Status())
+
+	// Subscriptions to a finished run are closed right away
+	events, unsubscribe := handle.Subscribe()
+	defer unsubscribe()
+	_, open := <-events
+	require.False(t, open)
+
+	_, err = agent.Start(context.Background(), InvokeConfig{})
+	require.ErrorContains(t, err, "must specify either Prompt or Messages")
+}
@@ -0,0 +1,109 @@
+package kit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/packages/param"
+)
+
+// defaultHealthTimeout bounds a health check when the context has no earlier deadline
+const defaultHealthTimeout = 10 * time.Second
+
+// Health is the result of a health check of the provider
+type Health struct {
+	// Available is set when the provider answered the check successfully
+	Available bool `json:"available"`
+
+	// Latency of the check request
+	Latency time.Duration `json:"latency"`
+
+	// Model probed with a minimal completion, empty when the models were listed instead
+	Model string `json:"model,omitempty"`
+
+	// Models is the number of models listed by the provider
+	Models int `json:"models,omitempty"`
+
+	// Error of a failed check, classified by ClassifyError
+	Error      string     `json:"error,omitempty"`
+	ErrorClass ErrorClass `json:"error_class,omitempty"`
+
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// HealthCheck checks that the provider is reachable and accepts the client's credentials by
+// listing its models, the cheapest request that exercises both. The returned error is the
+// failure of an unavailable provider. Models are listed through the OpenAI API; clients with
+// another Provider should use HealthCheckModel.
+func (c *Client) HealthCheck(ctx context.Context) (Health, error) {
+	ctx, cancel := context.WithTimeout(ctx, defaultHealthTimeout)
+	defer cancel()
+
+	startedAt := time.Now()
+	page, err := c.client.Models.List(ctx)
+	health := Health{Latency: time.Since(startedAt), CheckedAt: startedAt}
+	if err != nil {
+		return health.failed(err)
+	}
+
+	health.Available = true
+	health.Models = len(page.Data)
+	return health, nil
+}
+
+// HealthCheckModel checks that model serves completions with a minimal one-token generation,
+// for providers without a models endpoint and for probing the models of a fallback chain
+func (c *Client) HealthCheckModel(ctx context.Context, model string) (Health, error) {
+	ctx, cancel := context.WithTimeout(ctx, defaultHealthTimeout)
+	defer cancel()
+
+	params := openai.ChatCompletionNewParams{
+		Model:    model,
+		Messages: []openai.ChatCompletionMessageParamUnion{openai.UserMessage("ping")},
+	}
+	if c.config.Quirks.LegacyMaxTokens {
+		params.MaxTokens = param.NewOpt[int64](1)
+	} else {
+		params.MaxCompletionTokens = param.NewOpt[int64](1)
+	}
+
+	startedAt := time.Now()
+	_, err := c.complete(ctx, params)
+	health := Health{Latency: time.Since(startedAt), Model: model, CheckedAt: startedAt}
+	if err != nil {
+		return health.failed(err)
+	}
+
+	health.Available = true
+	return health, nil
+}
+
+// failed records the error of a failed check
+func (h Health) failed(err error) (Health, error) {
+	h.Error = err.Error()
+	h.ErrorClass = ClassifyError(err)
+	return h, err
+}
+
+// HealthHandler serves the result of a health check as JSON for readiness probes: 200 while the
+// provider is available, 503 otherwise. An empty model lists the models, see HealthCheck.
+func HealthHandler(client *Client, model string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		check := client.HealthCheck
+		if model != "" {
+			check = func(ctx context.Context) (Health, error) {
+				return client.HealthCheckModel(ctx, model)
+			}
+		}
+
+		health, _ := check(r.Context())
+		w.Header().Set("Content-Type", "application/json")
+		if !health.Available {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		_ = json.NewEncoder(w).Encode(health)
+	})
+}
@@ -0,0 +1,79 @@
+package kit
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/openai/openai-go"
+)
+
+// minRawImageSize is the length from which content made only of base64 is checked for an image
+const minRawImageSize = 64
+
+var (
+	// markdownImage matches an image embedded in markdown as a data URI, e.g. ![chart](data:image/png;base64,...)
+	markdownImage = regexp.MustCompile(`!\[[^\]]*\]\((data:image/[\w.+-]+;base64,[A-Za-z0-9+/=\s]+)\)`)
+
+	// dataImage matches a bare image data URI
+	dataImage = regexp.MustCompile(`data:image/[\w.+-]+;base64,[A-Za-z0-9+/=]+`)
+
+	// rawBase64 matches content made only of base64
+	rawBase64 = regexp.MustCompile(`^[A-Za-z0-9+/\s]+={0,2}$`)
+)
+
+// collectImages adds the images generated in a message to the run and returns the content the
+// output is parsed from. The images come from the nonstandard images field of image models
+// served through gateways such as OpenRouter and, for string outputs, from the content, where
+// every inlined image is replaced by an "[image N]" reference to its position in the run's images.
+func (a *Agent[Output]) collectImages(run *InvokeResult[Output], message openai.ChatCompletionMessage) string {
+	var fields struct {
+		Images []struct {
+			ImageURL struct {
+				URL string `json:"url"`
+			} `json:"image_url"`
+		} `json:"images"`
+	}
+	if err := json.Unmarshal([]byte(message.RawJSON()), &fields); err == nil {
+		for _, image := range fields.Images {
+			if strings.HasPrefix(image.ImageURL.URL, "data:image/") {
+				run.Images = append(run.Images, File{DataURI: image.ImageURL.URL})
+			}
+		}
+	}
+
+	var outputType Output
+	if !isStringType(outputType) {
+		return message.Content
+	}
+	content, images := inlineImages(message.Content, len(run.Images))
+	run.Images = append(run.Images, images...)
+	return content
+}
+
+// inlineImages extracts the images inlined in content as data URIs or, when that is all the
+// content holds, as raw base64. Their references are numbered from offset+1.
+func inlineImages(content string, offset int) (string, []File) {
+	var images []File
+	reference := func(dataURI string) string {
+		images = append(images, File{DataURI: strings.Join(strings.Fields(dataURI), "")})
+		return fmt.Sprintf("[image %d]", offset+len(images))
+	}
+
+	content = markdownImage.ReplaceAllStringFunc(content, func(match string) string {
+		return reference(markdownImage.FindStringSubmatch(match)[1])
+	})
+	content = dataImage.ReplaceAllStringFunc(content, reference)
+
+	if trimmed := strings.TrimSpace(content); len(trimmed) >= minRawImageSize && rawBase64.MatchString(trimmed) {
+		data, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(trimmed), ""))
+		if mime := http.DetectContentType(data); err == nil && strings.HasPrefix(mime, "image/") {
+			return reference(fmt.Sprintf("data:%s;base64,%s", mime, base64.StdEncoding.EncodeToString(data))), images
+		}
+	}
+
+	return content, images
+}
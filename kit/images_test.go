@@ -0,0 +1,53 @@
+package kit
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/openai/openai-go/option"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInlineImages(t *testing.T) {
+	png := append([]byte("\x89PNG\r\n\x1a\n"), make([]byte, 64)...)
+	encoded := base64.StdEncoding.EncodeToString(png)
+
+	content, images := inlineImages("Here is the chart:\n![chart](data:image/png;base64,"+encoded+")\nand a logo data:image/gif;base64,R0lGOD==", 1)
+	require.Equal(t, "Here is the chart:\n[image 2]\nand a logo [image 3]", content)
+	require.Len(t, images, 2)
+	require.Equal(t, "image/png", images[0].MimeType())
+	data, err := images[0].Data()
+	require.NoError(t, err)
+	require.Equal(t, png, data)
+
+	// Content made only of base64 is decoded when it holds an image
+	content, images = inlineImages(encoded, 0)
+	require.Equal(t, "[image 1]", content)
+	require.Equal(t, "image/png", images[0].MimeType())
+
+	content, images = inlineImages("bm90IGFuIGltYWdlIGF0IGFsbCwganVzdCBzb21lIGJhc2U2NCBlbmNvZGVkIHRleHQgaGVyZQ==", 0)
+	require.Equal(t, "bm90IGFuIGltYWdlIGF0IGFsbCwganVzdCBzb21lIGJhc2U2NCBlbmNvZGVkIHRleHQgaGVyZQ==", content)
+	require.Empty(t, images)
+}
+
+func TestInvokeImages(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"1","object":"chat.completion","choices":[{"index":0,"finish_reason":"stop",` +
+			`"message":{"role":"assistant","content":"A cat: data:image/jpeg;base64,/9j/4AAQ",` +
+			`"images":[{"type":"image_url","image_url":{"url":"data:image/png;base64,iVBORw0KGgo="}}]}}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("test"), WithBaseURL(server.URL), WithRequestOptions(option.WithMaxRetries(0)))
+	result, err := CreateAgent(client).InvokeDetailed(context.Background(), InvokeConfig{Prompt: "draw a cat"})
+	require.NoError(t, err)
+	require.Equal(t, "A cat: [image 2]", result.Output)
+	require.Equal(t, []File{
+		{DataURI: "data:image/png;base64,iVBORw0KGgo="},
+		{DataURI: "data:image/jpeg;base64,/9j/4AAQ"},
+	}, result.Images)
+}
@@ -0,0 +1,130 @@
+package kit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
+)
+
+// ModelUsage is the usage of a model accumulated by a UsageLedger
+type ModelUsage struct {
+	Model string
+
+	// Requests is the number of completions
+	Requests int64
+
+	// Usage is the token usage summed over the completions
+	Usage openai.CompletionUsage
+
+	// Cost is the estimated cost in dollars, priced with the registered model when the
+	// completion was recorded (zero for unregistered models)
+	Cost float64
+}
+
+// UsageSnapshot is the content of a UsageLedger at a point in time
+type UsageSnapshot struct {
+	// Since is when the ledger was created or last reset, At when the snapshot was taken
+	Since time.Time
+	At    time.Time
+
+	// Models holds the usage of every model, by model name
+	Models map[string]ModelUsage
+}
+
+// TotalCost returns the estimated cost of all models in dollars
+func (s UsageSnapshot) TotalCost() float64 {
+	total := 0.0
+	for _, usage := range s.Models {
+		total += usage.Cost
+	}
+	return total
+}
+
+// UsageLedger accumulates the tokens and estimated cost of every completion of a Client, across
+// all agents and helpers sharing it. It is safe for concurrent use.
+type UsageLedger struct {
+	mu     sync.Mutex
+	since  time.Time
+	models map[string]*ModelUsage
+}
+
+func newUsageLedger() *UsageLedger {
+	return &UsageLedger{since: time.Now(), models: make(map[string]*ModelUsage)}
+}
+
+// Record adds the usage of a completion of the model
+func (l *UsageLedger) Record(model string, usage openai.CompletionUsage) {
+	var cost float64
+	if info, ok := LookupModel(model); ok {
+		cost = info.Pricing.Cost(usage)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entry, ok := l.models[model]
+	if !ok {
+		entry = &ModelUsage{Model: model}
+		l.models[model] = entry
+	}
+	entry.Requests++
+	addUsage(&entry.Usage, usage)
+	entry.Cost += cost
+}
+
+// Snapshot returns the usage accumulated since the ledger was created or last reset
+func (l *UsageLedger) Snapshot() UsageSnapshot {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.snapshot()
+}
+
+// Reset empties the ledger and returns its content until then, so periodic billing exports
+// neither miss nor double count a completion
+func (l *UsageLedger) Reset() UsageSnapshot {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	snapshot := l.snapshot()
+	l.since = snapshot.At
+	l.models = make(map[string]*ModelUsage)
+	return snapshot
+}
+
+func (l *UsageLedger) snapshot() UsageSnapshot {
+	models := make(map[string]ModelUsage, len(l.models))
+	for model, usage := range l.models {
+		models[model] = *usage
+	}
+	return UsageSnapshot{Since: l.since, At: time.Now(), Models: models}
+}
+
+// Usage returns the ledger of the usage of all the client's completions
+func (c *Client) Usage() *UsageLedger {
+	return c.ledger
+}
+
+// complete performs a chat completion with the client's provider and records its usage
+func (c *Client) complete(
+	ctx context.Context,
+	params openai.ChatCompletionNewParams,
+	opts ...option.RequestOption,
+) (*openai.ChatCompletion, error) {
+	completion, err := c.provider.Complete(ctx, params, opts...)
+	if err == nil {
+		c.recordUsage(params.Model, completion)
+	}
+	return completion, err
+}
+
+// recordUsage books the usage of a completion of the requested model, or of the model that
+// served it when none was requested
+func (c *Client) recordUsage(model string, completion *openai.ChatCompletion) {
+	if model == "" {
+		model = completion.Model
+	}
+	c.ledger.Record(model, completion.Usage)
+}
@@ -0,0 +1,64 @@
+package kit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/openai/openai-go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsageLedger(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"1","object":"chat.completion","model":"ledger-model-2025","choices":[{"index":0,` +
+			`"finish_reason":"stop","message":{"role":"assistant","content":"hello"}}],` +
+			`"usage":{"prompt_tokens":1000,"completion_tokens":500,"total_tokens":1500}}`))
+	}))
+	defer server.Close()
+
+	RegisterModel(ModelInfo{Name: "ledger-model", Pricing: Pricing{Input: 1, Output: 2}})
+	client := NewClient(WithAPIKey("test"), WithBaseURL(server.URL), WithDefaultModel("ledger-model"))
+
+	// Completions of concurrent agents and helpers sharing the client are all booked
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_, err := CreateAgent(client).Invoke(context.Background(), InvokeConfig{Prompt: "hi"})
+			errs <- err
+		}()
+		go func() {
+			defer wg.Done()
+			_, err := Ask(context.Background(), client, "hi", AskOptions{})
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		require.NoError(t, err)
+	}
+	client.Usage().Record("other-model", openai.CompletionUsage{TotalTokens: 7})
+
+	snapshot := client.Usage().Snapshot()
+	require.Len(t, snapshot.Models, 2)
+	usage := snapshot.Models["ledger-model"]
+	require.Equal(t, int64(20), usage.Requests)
+	require.Equal(t, int64(30_000), usage.Usage.TotalTokens)
+	// 20 * (1000 * $1 + 500 * $2) per million tokens
+	require.InDelta(t, 0.04, usage.Cost, 1e-9)
+	require.InDelta(t, 0.04, snapshot.TotalCost(), 1e-9)
+	require.Zero(t, snapshot.Models["other-model"].Cost)
+
+	// Reset returns the content until then and starts over
+	reset := client.Usage().Reset()
+	require.Equal(t, snapshot.Models, reset.Models)
+	require.Empty(t, client.Usage().Snapshot().Models)
+	require.Equal(t, reset.At, client.Usage().Snapshot().Since)
+}
@@ -0,0 +1,71 @@
+package kit
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/mhrlife/goai-kit/internal/schema"
+	"github.com/openai/openai-go"
+)
+
+// ModelOllamaLlama is the default model of the Ollama preset
+const ModelOllamaLlama = "llama3.2"
+
+// localQuirks are the quirks of OpenAI-compatible local servers, most of which reject strict
+// schemas and the json_schema response format
+var localQuirks = Quirks{NoStrictSchema: true, NoResponseFormat: true, LegacyMaxTokens: true}
+
+// WithLocalMode adapts requests to an OpenAI-compatible local server such as Ollama, vLLM or
+// llama.cpp: schemas are sent without strict mode and structured outputs are requested in the
+// prompt rather than through the response format.
+func WithLocalMode() ClientOption {
+	return func(c *Config) {
+		c.Quirks = localQuirks
+	}
+}
+
+// NewOllamaClient creates a Client for a local Ollama server in local mode. The options
+// override the preset.
+func NewOllamaClient(opts ...ClientOption) *Client {
+	return newPresetClient(providerPreset{
+		baseURL:      "http://localhost:11434/v1",
+		apiKeyEnv:    "OLLAMA_API_KEY",
+		defaultModel: ModelOllamaLlama,
+		quirks:       localQuirks,
+	}, opts)
+}
+
+// promptSchemas reports whether structured outputs are requested in the prompt
+func (c *Client) promptSchemas() bool {
+	return c.config.Quirks.NoResponseFormat
+}
+
+// outputFormat requests a structured output through the response format or, when the provider
+// doesn't support it, through a system message describing the schema
+func outputFormat[Output any](client *Client, params *openai.ChatCompletionNewParams) {
+	if !client.promptSchemas() {
+		params.ResponseFormat = responseFormat[Output](client.strictSchemas())
+		return
+	}
+	params.Messages = append([]openai.ChatCompletionMessageParamUnion{jsonInstructions[Output]()}, params.Messages...)
+}
+
+// jsonInstructions builds the system message asking for a JSON response matching the schema
+func jsonInstructions[Output any]() openai.ChatCompletionMessageParamUnion {
+	var outputType Output
+	outputSchema, _ := json.Marshal(schema.InferJSONSchema(outputType))
+	return openai.SystemMessage(fmt.Sprintf(
+		"When you give your final answer, respond with only a JSON object matching this schema, "+
+			"without any other text: %s",
+		outputSchema,
+	))
+}
+
+// extractJSON recovers the JSON output from a response to prompt-based instructions
+func extractJSON[Output any](content string) string {
+	var outputType Output
+	if isStringType(outputType) {
+		return content
+	}
+	return repairJSON(content)
+}
@@ -0,0 +1,56 @@
+package kit
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/openai/openai-go/option"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalMode(t *testing.T) {
+	var requests []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		data, _ := io.ReadAll(r.Body)
+		require.NoError(t, json.Unmarshal(data, &body))
+		requests = append(requests, body)
+
+		w.Header().Set("Content-Type", "application/json")
+		if len(requests) == 1 {
+			_, _ = w.Write([]byte(`{"id":"1","object":"chat.completion","choices":[{"index":0,"finish_reason":"tool_calls",` +
+				`"message":{"role":"assistant","content":"","tool_calls":[{"id":"call_1","type":"function",` +
+				`"function":{"name":"lookup_tool","arguments":"{}"}}]}}]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":"2","object":"chat.completion","choices":[{"index":0,"finish_reason":"stop",` +
+			`"message":{"role":"assistant","content":"Here you go:\n` + "```json" + `\n{\"answer\":\"nothing found\"}\n` + "```" + `"}}]}`))
+	}))
+	defer server.Close()
+
+	type answer struct {
+		Answer string `json:"answer"`
+	}
+
+	client := NewOllamaClient(WithBaseURL(server.URL), WithRequestOptions(option.WithMaxRetries(0)))
+	result, err := CreateAgentWithOutput[answer](client, &lookupTool{}).
+		Invoke(context.Background(), InvokeConfig{Prompt: "find it"})
+	require.NoError(t, err)
+	require.Equal(t, "nothing found", result.Answer)
+
+	require.Len(t, requests, 2)
+	first := requests[0]
+	require.Equal(t, ModelOllamaLlama, first["model"])
+	require.NotContains(t, first, "response_format")
+	function := first["tools"].([]any)[0].(map[string]any)["function"].(map[string]any)
+	require.Equal(t, false, function["strict"])
+
+	// The schema is described in a system message instead
+	instructions := first["messages"].([]any)[0].(map[string]any)
+	require.Equal(t, "system", instructions["role"])
+	require.Contains(t, instructions["content"], `"answer"`)
+}
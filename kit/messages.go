@@ -0,0 +1,89 @@
+package kit
+
+import (
+	"strings"
+
+	"github.com/openai/openai-go"
+)
+
+// MessageRole returns the role of a chat completion message param
+func MessageRole(msg openai.ChatCompletionMessageParamUnion) string {
+	switch {
+	case msg.OfDeveloper != nil:
+		return "developer"
+	case msg.OfSystem != nil:
+		return "system"
+	case msg.OfUser != nil:
+		return "user"
+	case msg.OfAssistant != nil:
+		return "assistant"
+	case msg.OfTool != nil:
+		return "tool"
+	case msg.OfFunction != nil:
+		return "function"
+	default:
+		return ""
+	}
+}
+
+// MessageText returns the concatenated text parts of a message, ignoring non-text content
+func MessageText(msg openai.ChatCompletionMessageParamUnion) string {
+	var parts []string
+
+	switch {
+	case msg.OfDeveloper != nil:
+		parts = append(parts, msg.OfDeveloper.Content.OfString.Value)
+		for _, p := range msg.OfDeveloper.Content.OfArrayOfContentParts {
+			parts = append(parts, p.Text)
+		}
+	case msg.OfSystem != nil:
+		parts = append(parts, msg.OfSystem.Content.OfString.Value)
+		for _, p := range msg.OfSystem.Content.OfArrayOfContentParts {
+			parts = append(parts, p.Text)
+		}
+	case msg.OfUser != nil:
+		parts = append(parts, msg.OfUser.Content.OfString.Value)
+		for _, p := range msg.OfUser.Content.OfArrayOfContentParts {
+			if p.OfText != nil {
+				parts = append(parts, p.OfText.Text)
+			}
+		}
+	case msg.OfAssistant != nil:
+		parts = append(parts, msg.OfAssistant.Content.OfString.Value)
+		for _, p := range msg.OfAssistant.Content.OfArrayOfContentParts {
+			if p.OfText != nil {
+				parts = append(parts, p.OfText.Text)
+			}
+		}
+	case msg.OfTool != nil:
+		parts = append(parts, msg.OfTool.Content.OfString.Value)
+		for _, p := range msg.OfTool.Content.OfArrayOfContentParts {
+			parts = append(parts, p.Text)
+		}
+	case msg.OfFunction != nil:
+		parts = append(parts, msg.OfFunction.Content.Value)
+	}
+
+	nonEmpty := parts[:0]
+	for _, p := range parts {
+		if p != "" {
+			nonEmpty = append(nonEmpty, p)
+		}
+	}
+
+	return strings.Join(nonEmpty, "\n")
+}
+
+// userText returns the text of all user messages joined by newlines
+func userText(messages []openai.ChatCompletionMessageParamUnion) string {
+	var texts []string
+	for _, msg := range messages {
+		if msg.OfUser == nil {
+			continue
+		}
+		if text := MessageText(msg); text != "" {
+			texts = append(texts, text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
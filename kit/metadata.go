@@ -0,0 +1,51 @@
+package kit
+
+import (
+	"context"
+	"maps"
+	"slices"
+)
+
+type runMetadataKey struct{}
+
+type runMetadata struct {
+	metadata map[string]string
+	tags     []string
+}
+
+// RunMetadata returns the metadata and tags of the run executing in ctx, e.g. inside a tool
+func RunMetadata(ctx context.Context) (map[string]string, []string) {
+	if current, ok := ctx.Value(runMetadataKey{}).(runMetadata); ok {
+		return maps.Clone(current.metadata), slices.Clone(current.tags)
+	}
+	return nil, nil
+}
+
+// withRunMetadata merges the run's metadata and tags into those inherited from ctx, the run's
+// values taking precedence, and returns a context carrying the result for nested runs
+func withRunMetadata(
+	ctx context.Context,
+	metadata map[string]string,
+	tags []string,
+) (context.Context, map[string]string, []string) {
+	inherited, _ := ctx.Value(runMetadataKey{}).(runMetadata)
+	if len(metadata) == 0 && len(tags) == 0 {
+		return ctx, inherited.metadata, inherited.tags
+	}
+
+	merged := maps.Clone(inherited.metadata)
+	if merged == nil {
+		merged = make(map[string]string, len(metadata))
+	}
+	maps.Copy(merged, metadata)
+
+	mergedTags := slices.Clone(inherited.tags)
+	for _, tag := range tags {
+		if !slices.Contains(mergedTags, tag) {
+			mergedTags = append(mergedTags, tag)
+		}
+	}
+
+	current := runMetadata{metadata: merged, tags: mergedTags}
+	return context.WithValue(ctx, runMetadataKey{}, current), current.metadata, current.tags
+}
@@ -0,0 +1,90 @@
+package kit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mhrlife/goai-kit/internal/callback"
+	"github.com/openai/openai-go/option"
+	"github.com/stretchr/testify/require"
+)
+
+type metadataRecorder struct {
+	callback.BaseCallback
+	runs  []map[string]interface{}
+	tools []map[string]interface{}
+}
+
+func (r *metadataRecorder) Name() string { return "metadataRecorder" }
+
+func (r *metadataRecorder) OnRunStart(ctx map[string]interface{}) { r.runs = append(r.runs, ctx) }
+
+func (r *metadataRecorder) OnToolCallStart(ctx map[string]interface{}) {
+	r.tools = append(r.tools, ctx)
+}
+
+// delegateTool starts a nested run with metadata of its own
+type delegateTool struct {
+	BaseTool
+
+	nested *Agent[string]
+	seen   *runMetadata
+}
+
+func (d *delegateTool) Execute(ctx *Context) (any, error) {
+	d.seen.metadata, d.seen.tags = RunMetadata(ctx)
+	return d.nested.Invoke(ctx, InvokeConfig{
+		Prompt:   "nested",
+		Metadata: map[string]string{"step": "research"},
+		Tags:     []string{"nested", "beta"},
+	})
+}
+
+func TestRunMetadataPropagated(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Messages []struct {
+				Role    string `json:"role"`
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+
+		w.Header().Set("Content-Type", "application/json")
+		if last := body.Messages[len(body.Messages)-1]; last.Role == "user" && last.Content == "hi" {
+			_, _ = w.Write([]byte(`{"id":"1","object":"chat.completion","choices":[{"index":0,"finish_reason":"tool_calls",` +
+				`"message":{"role":"assistant","tool_calls":[{"id":"call_1","type":"function",` +
+				`"function":{"name":"delegate_tool","arguments":"{}"}}]}}]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":"1","object":"chat.completion","choices":[{"index":0,"finish_reason":"stop",` +
+			`"message":{"role":"assistant","content":"done"}}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("test"), WithBaseURL(server.URL), WithRequestOptions(option.WithMaxRetries(0)))
+	recorder := &metadataRecorder{}
+	seen := &runMetadata{}
+	tool := &delegateTool{nested: CreateAgent(client).WithCallbacks(recorder), seen: seen}
+
+	_, err := CreateAgent(client, tool).WithCallbacks(recorder).Invoke(context.Background(), InvokeConfig{
+		Prompt:   "hi",
+		Metadata: map[string]string{"tenant": "acme", "step": "plan"},
+		Tags:     []string{"beta"},
+	})
+	require.NoError(t, err)
+
+	// Tools see the run's metadata and every event carries it
+	require.Equal(t, map[string]string{"tenant": "acme", "step": "plan"}, seen.metadata)
+	require.Equal(t, []string{"beta"}, seen.tags)
+	require.Equal(t, map[string]string{"tenant": "acme", "step": "plan"}, recorder.runs[0]["metadata"])
+	require.Equal(t, []string{"beta"}, recorder.tools[0]["tags"])
+
+	// Nested runs inherit it, their own values taking precedence
+	require.Len(t, recorder.runs, 2)
+	require.Equal(t, map[string]string{"tenant": "acme", "step": "research"}, recorder.runs[1]["metadata"])
+	require.Equal(t, []string{"beta", "nested"}, recorder.runs[1]["tags"])
+}
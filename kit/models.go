@@ -0,0 +1,235 @@
+package kit
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/openai/openai-go"
+)
+
+// ErrNoCapableModel is returned (wrapped) when no registered model satisfies a ModelPolicy
+var ErrNoCapableModel = errors.New("no registered model satisfies the requirements")
+
+// Capability is a feature a model supports
+type Capability string
+
+const (
+	CapabilityTools      Capability = "tools"
+	CapabilityVision     Capability = "vision"
+	CapabilityJSONSchema Capability = "json_schema"
+	CapabilityReasoning  Capability = "reasoning"
+)
+
+// ModelInfo describes a model for policy-based selection
+type ModelInfo struct {
+	Name    string
+	Pricing Pricing
+
+	// ContextWindow is the maximum number of tokens of a request, completion included
+	// (zero means unknown and satisfies any requirement)
+	ContextWindow int
+
+	// Capabilities the model supports
+	Capabilities []Capability
+
+	// Latency is the typical duration of a generation, used by SelectFastest
+	Latency time.Duration
+
+	// Tasks are the labels of the tasks the model is suited for, e.g. "classification"
+	Tasks []string
+}
+
+// Has reports whether the model supports all the capabilities
+func (m ModelInfo) Has(capabilities ...Capability) bool {
+	for _, capability := range capabilities {
+		if !slices.Contains(m.Capabilities, capability) {
+			return false
+		}
+	}
+	return true
+}
+
+var (
+	modelsMu sync.RWMutex
+	models   = make(map[string]ModelInfo)
+)
+
+// RegisterModel makes a model available to model policies, replacing an earlier registration
+// of the same name, e.g. to update its pricing
+func RegisterModel(info ModelInfo) {
+	modelsMu.Lock()
+	defer modelsMu.Unlock()
+
+	if info.Name == "" {
+		panic("kit: RegisterModel called without a model name")
+	}
+	models[info.Name] = info
+}
+
+// LookupModel returns the registered model of the given name
+func LookupModel(name string) (ModelInfo, bool) {
+	modelsMu.RLock()
+	defer modelsMu.RUnlock()
+
+	info, ok := models[name]
+	return info, ok
+}
+
+// RegisteredModels returns all registered models sorted by name
+func RegisteredModels() []ModelInfo {
+	modelsMu.RLock()
+	defer modelsMu.RUnlock()
+
+	infos := make([]ModelInfo, 0, len(models))
+	for _, info := range models {
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos
+}
+
+// SelectionStrategy picks the model among those that satisfy a policy's requirements
+type SelectionStrategy string
+
+const (
+	// SelectCheapest picks the model with the lowest input plus output price
+	SelectCheapest SelectionStrategy = "cheapest"
+
+	// SelectFastest picks the model with the lowest typical latency
+	SelectFastest SelectionStrategy = "fastest"
+)
+
+// ModelRequirements is what a run needs from its model
+type ModelRequirements struct {
+	// Capabilities the model must support
+	Capabilities []Capability
+
+	// MinContext is the minimum context window in tokens
+	MinContext int
+
+	// Task is the label the model must be suited for
+	Task string
+}
+
+// ModelPolicy selects the model of every invoke from the registered models, so code references
+// capabilities and tasks rather than model names
+type ModelPolicy struct {
+	// Strategy picks among the capable models (defaults to SelectCheapest)
+	Strategy SelectionStrategy
+
+	// Requirements every selected model must meet. The agent adds the capabilities the run
+	// needs (tools, structured output, images) and a context window fitting its messages.
+	Requirements ModelRequirements
+
+	// TaskModels maps task labels to the model to use for them, taking precedence over the
+	// registry (optional)
+	TaskModels map[string]string
+}
+
+// WithModelPolicy selects the model of every invoke that doesn't set InvokeConfig.Model with
+// policy. InvokeConfig.Task labels the task of an invoke.
+func (a *Agent[Output]) WithModelPolicy(policy ModelPolicy) *Agent[Output] {
+	a.modelPolicy = &policy
+	return a
+}
+
+// Select returns the name of the registered model that best satisfies the requirements
+func (p ModelPolicy) Select(requirements ModelRequirements) (string, error) {
+	if model, ok := p.TaskModels[requirements.Task]; ok && requirements.Task != "" {
+		return model, nil
+	}
+
+	var best *ModelInfo
+	for _, info := range RegisteredModels() {
+		if !info.satisfies(requirements) {
+			continue
+		}
+		if best == nil || p.better(info, *best) {
+			best = &info
+		}
+	}
+	if best == nil {
+		return "", fmt.Errorf("%w: %+v", ErrNoCapableModel, requirements)
+	}
+	return best.Name, nil
+}
+
+// satisfies reports whether the model meets the requirements
+func (m ModelInfo) satisfies(requirements ModelRequirements) bool {
+	if !m.Has(requirements.Capabilities...) {
+		return false
+	}
+	if m.ContextWindow > 0 && m.ContextWindow < requirements.MinContext {
+		return false
+	}
+	return requirements.Task == "" || slices.Contains(m.Tasks, requirements.Task)
+}
+
+// better reports whether candidate beats current under the policy's strategy
+func (p ModelPolicy) better(candidate, current ModelInfo) bool {
+	if p.Strategy == SelectFastest {
+		return candidate.Latency < current.Latency
+	}
+	return candidate.Pricing.Input+candidate.Pricing.Output < current.Pricing.Input+current.Pricing.Output
+}
+
+// merge combines the policy's requirements with those of a run
+func (r ModelRequirements) merge(run ModelRequirements) ModelRequirements {
+	merged := ModelRequirements{
+		Capabilities: slices.Clone(r.Capabilities),
+		MinContext:   max(r.MinContext, run.MinContext),
+		Task:         r.Task,
+	}
+	for _, capability := range run.Capabilities {
+		if !slices.Contains(merged.Capabilities, capability) {
+			merged.Capabilities = append(merged.Capabilities, capability)
+		}
+	}
+	if run.Task != "" {
+		merged.Task = run.Task
+	}
+	return merged
+}
+
+// selectModel sets the model of the invoke from the model policy, unless the invoke names one
+func (a *Agent[Output]) selectModel(config *InvokeConfig, messages []openai.ChatCompletionMessageParamUnion) error {
+	if a.modelPolicy == nil || config.Model != "" {
+		return nil
+	}
+
+	run := ModelRequirements{Task: config.Task}
+	if len(a.tools) > 0 && !a.reactMode {
+		run.Capabilities = append(run.Capabilities, CapabilityTools)
+	}
+	var outputType Output
+	if !isStringType(outputType) {
+		run.Capabilities = append(run.Capabilities, CapabilityJSONSchema)
+	}
+	for _, file := range config.Files {
+		if strings.HasPrefix(file.DataURI, "data:image/") {
+			run.Capabilities = append(run.Capabilities, CapabilityVision)
+			break
+		}
+	}
+	run.MinContext = CountTokens("", messages)
+
+	model, err := a.modelPolicy.Select(a.modelPolicy.Requirements.merge(run))
+	if err != nil {
+		return err
+	}
+	config.Model = model
+	return nil
+}
+
+// runModel returns the model of an invoke
+func (a *Agent[Output]) runModel(config InvokeConfig) string {
+	if config.Model != "" {
+		return config.Model
+	}
+	return a.model
+}
@@ -0,0 +1,78 @@
+package kit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/openai/openai-go/option"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModelPolicy(t *testing.T) {
+	RegisterModel(ModelInfo{
+		Name:         "test-mini",
+		Pricing:      Pricing{Input: 0.15, Output: 0.6},
+		Capabilities: []Capability{CapabilityTools, CapabilityJSONSchema},
+		Latency:      800 * time.Millisecond,
+		Tasks:        []string{"classification"},
+	})
+	RegisterModel(ModelInfo{
+		Name:          "test-large",
+		Pricing:       Pricing{Input: 2.5, Output: 10},
+		ContextWindow: 128_000,
+		Capabilities:  []Capability{CapabilityTools, CapabilityJSONSchema, CapabilityVision},
+		Latency:       400 * time.Millisecond,
+		Tasks:         []string{"classification", "writing"},
+	})
+
+	cheapest := ModelPolicy{}
+	model, err := cheapest.Select(ModelRequirements{Capabilities: []Capability{CapabilityTools}})
+	require.NoError(t, err)
+	require.Equal(t, "test-mini", model)
+
+	model, err = cheapest.Select(ModelRequirements{Capabilities: []Capability{CapabilityVision}})
+	require.NoError(t, err)
+	require.Equal(t, "test-large", model)
+
+	model, err = ModelPolicy{Strategy: SelectFastest}.Select(ModelRequirements{Task: "classification"})
+	require.NoError(t, err)
+	require.Equal(t, "test-large", model)
+
+	_, err = cheapest.Select(ModelRequirements{Task: "translation"})
+	require.ErrorIs(t, err, ErrNoCapableModel)
+
+	// The agent selects per invoke, by the invoke's task
+	var requested []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Model string `json:"model"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		requested = append(requested, body.Model)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"1","object":"chat.completion","choices":[` +
+			`{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":"ok"}}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(
+		WithAPIKey("test"),
+		WithBaseURL(server.URL),
+		WithRequestOptions(option.WithMaxRetries(0)),
+	)
+	agent := CreateAgent(client).WithModelPolicy(ModelPolicy{})
+
+	for _, config := range []InvokeConfig{
+		{Prompt: "label this", Task: "classification"},
+		{Prompt: "write a poem", Task: "writing"},
+		{Prompt: "hi", Model: "pinned"},
+	} {
+		_, err := agent.Invoke(context.Background(), config)
+		require.NoError(t, err)
+	}
+	require.Equal(t, []string{"test-mini", "test-large", "pinned"}, requested)
+}
@@ -0,0 +1,82 @@
+package kit
+
+import (
+	"github.com/openai/openai-go"
+)
+
+// OpenRouterOptions configures OpenRouter's request extensions, for agents whose client points
+// at https://openrouter.ai/api/v1. Other providers ignore or reject these fields.
+type OpenRouterOptions struct {
+	// Provider controls which upstream providers serve the request (optional)
+	Provider *OpenRouterProvider
+
+	// Models are fallback models tried in order when the agent's model is unavailable,
+	// rate limited or refuses the request (optional)
+	Models []string
+
+	// Transforms are prompt transforms applied by OpenRouter, e.g. "middle-out" to compress
+	// prompts that exceed the context window (optional)
+	Transforms []string
+}
+
+// OpenRouterProvider is OpenRouter's provider routing preferences
+type OpenRouterProvider struct {
+	// Order lists the providers to try first, e.g. "anthropic", "openai"
+	Order []string `json:"order,omitempty"`
+
+	// AllowFallbacks lets OpenRouter use providers outside Order (defaults to true)
+	AllowFallbacks *bool `json:"allow_fallbacks,omitempty"`
+
+	// RequireParameters only routes to providers supporting every request parameter
+	RequireParameters bool `json:"require_parameters,omitempty"`
+
+	// DataCollection is "allow" or "deny"; "deny" skips providers that store prompts
+	DataCollection string `json:"data_collection,omitempty"`
+
+	// Only and Ignore restrict the providers OpenRouter may use
+	Only   []string `json:"only,omitempty"`
+	Ignore []string `json:"ignore,omitempty"`
+
+	// Quantizations restricts the model quantizations, e.g. "fp8", "bf16"
+	Quantizations []string `json:"quantizations,omitempty"`
+
+	// Sort orders providers by "price", "throughput" or "latency" instead of load balancing
+	Sort string `json:"sort,omitempty"`
+}
+
+// WithOpenRouter sets OpenRouter's provider preferences, fallback models and transforms for
+// every generation of the agent
+func (a *Agent[Output]) WithOpenRouter(opts OpenRouterOptions) *Agent[Output] {
+	a.openRouter = opts
+	return a
+}
+
+// merge returns the options with the non-empty fields of override taking precedence
+func (o OpenRouterOptions) merge(override *OpenRouterOptions) OpenRouterOptions {
+	if override == nil {
+		return o
+	}
+	if override.Provider != nil {
+		o.Provider = override.Provider
+	}
+	if len(override.Models) > 0 {
+		o.Models = override.Models
+	}
+	if len(override.Transforms) > 0 {
+		o.Transforms = override.Transforms
+	}
+	return o
+}
+
+// apply adds the OpenRouter fields to the request
+func (o OpenRouterOptions) apply(params *openai.ChatCompletionNewParams) {
+	if o.Provider != nil {
+		setExtraField(params, "provider", o.Provider)
+	}
+	if len(o.Models) > 0 {
+		setExtraField(params, "models", o.Models)
+	}
+	if len(o.Transforms) > 0 {
+		setExtraField(params, "transforms", o.Transforms)
+	}
+}
@@ -0,0 +1,70 @@
+package kit
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenRouterOptionsApply(t *testing.T) {
+	params := openai.ChatCompletionNewParams{Model: "anthropic/claude-sonnet-4"}
+
+	allowFallbacks := false
+	OpenRouterOptions{
+		Provider:   &OpenRouterProvider{Order: []string{"anthropic"}, AllowFallbacks: &allowFallbacks, DataCollection: "deny"},
+		Models:     []string{"openai/gpt-4o"},
+		Transforms: []string{"middle-out"},
+	}.apply(&params)
+	PromptCache{Key: "support-v1"}.apply(&params)
+
+	body, err := json.Marshal(params)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"model": "anthropic/claude-sonnet-4",
+		"provider": {"order": ["anthropic"], "allow_fallbacks": false, "data_collection": "deny"},
+		"models": ["openai/gpt-4o"],
+		"transforms": ["middle-out"],
+		"prompt_cache_key": "support-v1"
+	}`, string(body))
+}
+
+func TestInvokeRoutingOverrides(t *testing.T) {
+	var body map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		require.NoError(t, json.Unmarshal(data, &body))
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"1","object":"chat.completion","choices":[{"index":0,"finish_reason":"stop",` +
+			`"message":{"role":"assistant","content":"done"}}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("test"), WithBaseURL(server.URL), WithRequestOptions(option.WithMaxRetries(0)))
+	agent := CreateAgent(client).
+		WithTemperature(0.2).
+		WithOpenRouter(OpenRouterOptions{
+			Provider: &OpenRouterProvider{Sort: "latency"},
+			Models:   []string{"openai/gpt-4o"},
+		}).
+		WithExtraBody(map[string]any{"usage": map[string]any{"include": true}, "top_k": 20})
+
+	_, err := agent.Invoke(context.Background(), InvokeConfig{
+		Prompt:     "hi",
+		OpenRouter: &OpenRouterOptions{Models: []string{"anthropic/claude-sonnet-4"}},
+		ExtraBody:  map[string]any{"top_k": 40, "temperature": 0.9},
+	})
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"sort": "latency"}, body["provider"])
+	require.Equal(t, []any{"anthropic/claude-sonnet-4"}, body["models"])
+	require.Equal(t, map[string]any{"include": true}, body["usage"])
+	require.EqualValues(t, 40, body["top_k"])
+	require.EqualValues(t, 0.9, body["temperature"])
+}
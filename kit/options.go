@@ -37,6 +37,13 @@ func WithRequestOptions(opts ...option.RequestOption) ClientOption {
 	}
 }
 
+// WithQuirks adapts requests to an OpenAI-compatible provider that deviates from the OpenAI API.
+func WithQuirks(quirks Quirks) ClientOption {
+	return func(c *Config) {
+		c.Quirks = quirks
+	}
+}
+
 // WithLogLevel sets the minimum log level for the lfClient's internal logging.
 func WithLogLevel(level slog.Level) ClientOption {
 	return func(c *Config) {
@@ -0,0 +1,185 @@
+package kit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+
+	"github.com/mhrlife/goai-kit/internal/callback"
+)
+
+// outputFieldsKey is the context key of the callback receiving the completed top-level fields
+// of a streamed structured output
+type outputFieldsKey struct{}
+
+// watchOutputFields reports the top-level fields of the structured output to OnOutputField as
+// they complete, when the run is streamed
+func (a *Agent[Output]) watchOutputFields(ctx context.Context, cbManager *callback.Manager) context.Context {
+	var outputType Output
+	if isStringType(outputType) || a.reactMode {
+		return ctx
+	}
+	if _, streamed := ctx.Value(streamSinkKey{}).(*streamSink); !streamed {
+		return ctx
+	}
+	return context.WithValue(ctx, outputFieldsKey{}, cbManager.OnOutputField)
+}
+
+// fieldScanPhase is the position of an outputFieldScanner within the top-level object
+type fieldScanPhase int
+
+const (
+	phaseKey    fieldScanPhase = iota // before a key or the closing brace
+	phaseColon                        // after a key
+	phaseValue                        // after the colon, before the value
+	phaseScalar                       // inside a number, boolean or null
+	phaseNested                       // inside a string, object or array value
+	phaseComma                        // after a value
+	phaseDone                         // the object is closed
+)
+
+// outputFieldScanner incrementally scans streamed JSON content, reporting every top-level field
+// of the object as soon as its value is complete. Strings, objects and arrays complete with
+// their closing character, scalars with the following comma or brace.
+type outputFieldScanner struct {
+	onField func(field string, value json.RawMessage)
+
+	buf      []byte
+	depth    int
+	phase    fieldScanPhase
+	inString bool
+	escaped  bool
+
+	key        string
+	keyStart   int
+	valueStart int
+}
+
+func newOutputFieldScanner(ctx context.Context) *outputFieldScanner {
+	onField, _ := ctx.Value(outputFieldsKey{}).(func(string, json.RawMessage))
+	if onField == nil {
+		return nil
+	}
+	return &outputFieldScanner{onField: onField}
+}
+
+// write scans a content delta; it is a no-op on a nil scanner
+func (s *outputFieldScanner) write(delta string) {
+	if s == nil {
+		return
+	}
+	for i := 0; i < len(delta); i++ {
+		s.buf = append(s.buf, delta[i])
+		s.scan(len(s.buf) - 1)
+	}
+}
+
+// scan advances the scanner over the byte at index i of the buffer
+func (s *outputFieldScanner) scan(i int) {
+	c := s.buf[i]
+
+	if s.inString {
+		switch {
+		case s.escaped:
+			s.escaped = false
+		case c == '\\':
+			s.escaped = true
+		case c == '"':
+			s.inString = false
+			if s.depth == 1 {
+				if s.phase == phaseKey {
+					s.key = ""
+					_ = json.Unmarshal(s.buf[s.keyStart:i+1], &s.key)
+					s.phase = phaseColon
+				} else {
+					s.emit(i + 1)
+				}
+			}
+		}
+		return
+	}
+
+	if s.depth == 0 {
+		if c == '{' && s.phase != phaseDone {
+			s.depth = 1
+			s.phase = phaseKey
+		}
+		return
+	}
+
+	if s.depth > 1 {
+		switch c {
+		case '"':
+			s.inString = true
+		case '{', '[':
+			s.depth++
+		case '}', ']':
+			s.depth--
+			if s.depth == 1 {
+				s.emit(i + 1)
+			}
+		}
+		return
+	}
+
+	switch s.phase {
+	case phaseKey:
+		switch c {
+		case '"':
+			s.inString = true
+			s.keyStart = i
+		case '}':
+			s.close()
+		}
+	case phaseColon:
+		if c == ':' {
+			s.phase = phaseValue
+		}
+	case phaseValue:
+		switch c {
+		case ' ', '\t', '\n', '\r':
+		case '"':
+			s.inString = true
+			s.valueStart = i
+			s.phase = phaseNested
+		case '{', '[':
+			s.depth++
+			s.valueStart = i
+			s.phase = phaseNested
+		default:
+			s.valueStart = i
+			s.phase = phaseScalar
+		}
+	case phaseScalar:
+		switch c {
+		case ',':
+			s.emit(i)
+			s.phase = phaseKey
+		case '}':
+			s.emit(i)
+			s.close()
+		}
+	case phaseComma:
+		switch c {
+		case ',':
+			s.phase = phaseKey
+		case '}':
+			s.close()
+		}
+	}
+}
+
+// emit reports the field whose value ends before index end
+func (s *outputFieldScanner) emit(end int) {
+	value := bytes.TrimSpace(s.buf[s.valueStart:end])
+	if json.Valid(value) {
+		s.onField(s.key, json.RawMessage(bytes.Clone(value)))
+	}
+	s.phase = phaseComma
+}
+
+// close ends the scan at the object's closing brace
+func (s *outputFieldScanner) close() {
+	s.depth = 0
+	s.phase = phaseDone
+}
@@ -0,0 +1,74 @@
+package kit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/openai/openai-go/option"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutputFieldScanner(t *testing.T) {
+	content := `{"title": "Go \"generics\" {intro}", "tags": ["a", ["b"]], "meta": {"x": {"y": 1}},` +
+		` "count": 42, "draft": false, "note": null}`
+
+	var fields []string
+	var values []string
+	scanner := &outputFieldScanner{onField: func(field string, value json.RawMessage) {
+		fields = append(fields, field)
+		values = append(values, string(value))
+	}}
+	for i := 0; i < len(content); i++ {
+		scanner.write(content[i : i+1])
+
+		// Strings and containers are reported as soon as they close
+		if content[:i+1] == `{"title": "Go \"generics\" {intro}"` {
+			require.Equal(t, []string{"title"}, fields)
+		}
+	}
+
+	require.Equal(t, []string{"title", "tags", "meta", "count", "draft", "note"}, fields)
+	require.Equal(t, []string{`"Go \"generics\" {intro}"`, `["a", ["b"]]`, `{"x": {"y": 1}}`, "42", "false", "null"}, values)
+}
+
+func TestStreamOutputFields(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, chunk := range []string{
+			`{"id":"1","object":"chat.completion.chunk","choices":[{"index":0,"delta":{"role":"assistant","content":"{\"title\":\"Hel"}}]}`,
+			`{"id":"1","object":"chat.completion.chunk","choices":[{"index":0,"delta":{"content":"lo\",\"body\":\"world\"}"},"finish_reason":"stop"}]}`,
+			`[DONE]`,
+		} {
+			_, _ = w.Write([]byte("data: " + chunk + "\n\n"))
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(
+		WithAPIKey("test"),
+		WithBaseURL(server.URL),
+		WithRequestOptions(option.WithMaxRetries(0)),
+	)
+
+	type article struct {
+		Title string `json:"title"`
+		Body  string `json:"body"`
+	}
+
+	var fields []string
+	for event := range CreateAgentWithOutput[article](client).Stream(context.Background(), InvokeConfig{Prompt: "hi"}) {
+		switch event.Type {
+		case StreamEventOutputField:
+			fields = append(fields, event.Field+"="+string(event.FieldValue))
+		case StreamEventDone:
+			require.Equal(t, article{Title: "Hello", Body: "world"}, event.Run.Output)
+		case StreamEventError:
+			require.NoError(t, event.Error)
+		}
+	}
+
+	require.Equal(t, []string{`title="Hello"`, `body="world"`}, fields)
+}
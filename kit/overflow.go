@@ -0,0 +1,81 @@
+package kit
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mhrlife/goai-kit/internal/callback"
+	"github.com/openai/openai-go"
+)
+
+// ContextOverflow configures how a run recovers from a generation rejected for exceeding the
+// model's context window (context_length_exceeded), instead of returning the provider's error
+type ContextOverflow struct {
+	// Strategy trims the history before the generation is retried (defaults to the agent's trim
+	// strategy). Use a summarizing strategy to keep the gist of the dropped messages.
+	Strategy TrimStrategy
+
+	// Model with a larger context window the rest of the run switches to (optional)
+	Model string
+
+	// MaxRetries per run (defaults to 1)
+	MaxRetries int
+}
+
+// WithContextOverflow retries generations that exceed the context window after trimming the
+// history and, optionally, switching to a larger-context model. Retries are reported to OnRetry.
+func (a *Agent[Output]) WithContextOverflow(policy ContextOverflow) *Agent[Output] {
+	a.overflow = &policy
+	return a
+}
+
+// recoverOverflow handles a generation error of the given model after the given number of
+// overflow retries. It returns the trimmed history and the model to retry with, ok is false
+// when err is no context overflow or the policy can't recover from it.
+func (a *Agent[Output]) recoverOverflow(
+	ctx context.Context,
+	err error,
+	messages []openai.ChatCompletionMessageParamUnion,
+	model string,
+	retries int,
+	cbManager *callback.Manager,
+) (trimmed []openai.ChatCompletionMessageParamUnion, nextModel string, ok bool, trimErr error) {
+	policy := a.overflow
+	if policy == nil || ClassifyError(err) != ErrorClassContextLength || retries >= max(policy.MaxRetries, 1) {
+		return messages, model, false, nil
+	}
+
+	strategy := policy.Strategy
+	if strategy == nil {
+		strategy = a.trimStrategy
+	}
+
+	nextModel = model
+	if policy.Model != "" {
+		nextModel = policy.Model
+	}
+
+	trimmed = messages
+	if strategy != nil {
+		trimmed, trimErr = trimWith(ctx, strategy, nextModel, messages, cbManager.OnHistoryTrim)
+		if trimErr != nil {
+			return messages, model, false, trimErr
+		}
+	}
+
+	// Retrying the same request with the same model would fail the same way
+	if len(trimmed) == len(messages) && nextModel == model {
+		return messages, model, false, nil
+	}
+
+	cbManager.OnRetry("context_overflow", err, string(ErrorClassContextLength), retries+1, 0, nextModel)
+	return trimmed, nextModel, true, nil
+}
+
+// overflowError reports that the history didn't fit the context window even after recovery
+func overflowError(err error, retries int) error {
+	if retries == 0 {
+		return err
+	}
+	return fmt.Errorf("context still exceeded after %d overflow retries: %w", retries, err)
+}
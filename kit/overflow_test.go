@@ -0,0 +1,80 @@
+package kit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContextOverflowRetriesTrimmed(t *testing.T) {
+	type request struct {
+		Model    string           `json:"model"`
+		Messages []map[string]any `json:"messages"`
+	}
+	var requests []request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body request
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		requests = append(requests, body)
+
+		w.Header().Set("Content-Type", "application/json")
+		if len(body.Messages) > 2 {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":{"message":"This model's maximum context length is 8192 tokens",` +
+				`"code":"context_length_exceeded"}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":"1","object":"chat.completion","choices":[` +
+			`{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":"fits"}}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(
+		WithAPIKey("test"),
+		WithBaseURL(server.URL),
+		WithRequestOptions(option.WithMaxRetries(0)),
+	)
+	recorder := &retryRecorder{}
+	agent := CreateAgent(client).
+		WithModel("small").
+		WithCallbacks(recorder).
+		WithContextOverflow(ContextOverflow{Strategy: LastN(1), Model: "large"})
+
+	result, err := agent.InvokeDetailed(context.Background(), InvokeConfig{
+		SystemPrompt: "be brief",
+		Messages: []openai.ChatCompletionMessageParamUnion{
+			openai.UserMessage("first"),
+			openai.AssistantMessage("answer"),
+			openai.UserMessage("second"),
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "fits", result.Output)
+	require.Equal(t, 1, result.Iterations)
+
+	require.Len(t, requests, 2)
+	require.Equal(t, "small", requests[0].Model)
+	require.Equal(t, "large", requests[1].Model)
+	require.Len(t, requests[1].Messages, 2)
+
+	require.Len(t, recorder.retries, 1)
+	require.Equal(t, "context_overflow", recorder.retries[0]["stage"])
+
+	// Without a policy the provider's error is returned
+	requests = nil
+	_, err = CreateAgent(client).Invoke(context.Background(), InvokeConfig{
+		Messages: []openai.ChatCompletionMessageParamUnion{
+			openai.UserMessage("first"),
+			openai.AssistantMessage("answer"),
+			openai.UserMessage("second"),
+		},
+	})
+	require.Equal(t, ErrorClassContextLength, ClassifyError(err))
+	require.Len(t, requests, 1)
+}
@@ -0,0 +1,138 @@
+package kit
+
+import (
+	"maps"
+
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/packages/param"
+	"github.com/openai/openai-go/shared"
+)
+
+// setExtraField adds a request body field that openai-go doesn't model, keeping the extra
+// fields set before
+func setExtraField(params *openai.ChatCompletionNewParams, key string, value any) {
+	extra := maps.Clone(params.ExtraFields())
+	if extra == nil {
+		extra = make(map[string]any)
+	}
+	extra[key] = value
+	params.SetExtraFields(extra)
+}
+
+// WithExtraBody adds provider-specific fields to the request body of every generation, e.g.
+// the routing fields of an OpenAI-compatible gateway. They override fields set by the kit;
+// InvokeConfig.ExtraBody overrides them per field.
+func (a *Agent[Output]) WithExtraBody(fields map[string]any) *Agent[Output] {
+	a.extraBody = fields
+	return a
+}
+
+// applyExtraBody adds the extra body fields of the agent and the invocation to the request
+func (a *Agent[Output]) applyExtraBody(params *openai.ChatCompletionNewParams, config InvokeConfig) {
+	for key, value := range a.extraBody {
+		setExtraField(params, key, value)
+	}
+	for key, value := range config.ExtraBody {
+		setExtraField(params, key, value)
+	}
+}
+
+// StopSequences are the stop sequences of the generations of a run, by phase
+type StopSequences struct {
+	// Tools apply to the generations that may call tools, e.g. "Observation:" in ReAct mode so
+	// the model doesn't make up the tool results
+	Tools []string
+
+	// Final apply to the generations that must answer: those of agents without tools, after
+	// the tool call budget wrapped up and continuations of truncated answers. Set the same
+	// sequences in Tools to cut control tokens leaked by the model from every generation.
+	Final []string
+}
+
+// WithStopSequences sets the stop sequences of the generations, which differ between the
+// tool calling iterations and the final answer
+func (a *Agent[Output]) WithStopSequences(stop StopSequences) *Agent[Output] {
+	a.stopSequences = stop
+	return a
+}
+
+// apply sets the stop sequences of the phase, replacing those of the other phase
+func (s StopSequences) apply(params *openai.ChatCompletionNewParams, toolPhase bool) {
+	stop := s.Final
+	if toolPhase {
+		stop = s.Tools
+	}
+	params.Stop = openai.ChatCompletionNewParamsStopUnion{OfStringArray: stop}
+}
+
+// ServiceTier selects the processing tier of the provider, trading cost against latency
+type ServiceTier string
+
+const (
+	ServiceTierAuto     ServiceTier = "auto"
+	ServiceTierDefault  ServiceTier = "default"
+	ServiceTierFlex     ServiceTier = "flex"     // cheaper and slower, for batch workloads
+	ServiceTierPriority ServiceTier = "priority" // faster and more expensive, for latency-sensitive workloads
+)
+
+// WithServiceTier sets the service_tier parameter for every generation of the agent
+func (a *Agent[Output]) WithServiceTier(tier ServiceTier) *Agent[Output] {
+	a.serviceTier = tier
+	return a
+}
+
+// WithStore sets OpenAI's store parameter, retaining the completions for the provider's
+// evals and distillation features
+func (a *Agent[Output]) WithStore(store bool) *Agent[Output] {
+	a.store = &store
+	return a
+}
+
+// WithCompletionMetadata sets OpenAI's metadata parameter, tagging stored completions so they
+// can be filtered in the provider's dashboard (up to 16 pairs)
+func (a *Agent[Output]) WithCompletionMetadata(metadata map[string]string) *Agent[Output] {
+	a.completionMeta = metadata
+	return a
+}
+
+// WithLogitBias biases the likelihood of tokens, keyed by token ID, from -100 (ban) to 100
+// (force). InvokeConfig.LogitBias overrides it per token.
+func (a *Agent[Output]) WithLogitBias(bias map[string]int) *Agent[Output] {
+	a.logitBias = bias
+	return a
+}
+
+// applyParams sets the optional completion parameters configured on the agent and the invocation
+func (a *Agent[Output]) applyParams(params *openai.ChatCompletionNewParams, config InvokeConfig) {
+	if a.store != nil {
+		params.Store = param.NewOpt(*a.store)
+	}
+	if len(a.completionMeta) > 0 {
+		params.Metadata = shared.Metadata(maps.Clone(a.completionMeta))
+	}
+	if a.serviceTier != "" {
+		params.ServiceTier = openai.ChatCompletionNewParamsServiceTier(a.serviceTier)
+	}
+	if config.User != "" {
+		params.User = param.NewOpt(config.User)
+	}
+	if bias := mergeLogitBias(a.logitBias, config.LogitBias); bias != nil {
+		params.LogitBias = bias
+	}
+}
+
+// mergeLogitBias combines the agent and invocation biases, the invocation taking precedence
+func mergeLogitBias(agentBias, invokeBias map[string]int) map[string]int64 {
+	if len(agentBias) == 0 && len(invokeBias) == 0 {
+		return nil
+	}
+
+	merged := make(map[string]int64, len(agentBias)+len(invokeBias))
+	for token, bias := range agentBias {
+		merged[token] = int64(bias)
+	}
+	for token, bias := range invokeBias {
+		merged[token] = int64(bias)
+	}
+	return merged
+}
@@ -0,0 +1,97 @@
+package kit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/openai/openai-go/option"
+	"github.com/stretchr/testify/require"
+)
+
+// paramsServer answers every generation with "ok", recording the request bodies
+func paramsServer(t *testing.T, requests *[]map[string]interface{}) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		*requests = append(*requests, body)
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"1","object":"chat.completion","service_tier":"flex","choices":[` +
+			`{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":"ok"}}]}`))
+	}))
+}
+
+func TestStoreAndCompletionMetadata(t *testing.T) {
+	var requests []map[string]interface{}
+	server := paramsServer(t, &requests)
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("test"), WithBaseURL(server.URL), WithRequestOptions(option.WithMaxRetries(0)))
+	metadata := map[string]string{"feature": "support"}
+
+	_, err := CreateAgent(client).
+		WithStore(true).
+		WithCompletionMetadata(metadata).
+		Invoke(context.Background(), InvokeConfig{Prompt: "hi", User: "alice"})
+	require.NoError(t, err)
+	require.Equal(t, true, requests[0]["store"])
+	require.Equal(t, map[string]interface{}{"feature": "support"}, requests[0]["metadata"])
+	require.Equal(t, "alice", requests[0]["user"])
+
+	// Unset parameters are left to the provider's defaults
+	_, err = CreateAgent(client).Invoke(context.Background(), InvokeConfig{Prompt: "hi"})
+	require.NoError(t, err)
+	require.NotContains(t, requests[1], "store")
+	require.NotContains(t, requests[1], "metadata")
+	require.NotContains(t, requests[1], "user")
+}
+
+func TestLogitBias(t *testing.T) {
+	var requests []map[string]interface{}
+	server := paramsServer(t, &requests)
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("test"), WithBaseURL(server.URL), WithRequestOptions(option.WithMaxRetries(0)))
+	agent := CreateAgent(client).WithLogitBias(map[string]int{"1734": -100, "2001": 20})
+
+	_, err := agent.Invoke(context.Background(), InvokeConfig{Prompt: "hi"})
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"1734": float64(-100), "2001": float64(20)}, requests[0]["logit_bias"])
+
+	// The invocation's bias overrides the agent's per token
+	_, err = agent.Invoke(context.Background(), InvokeConfig{Prompt: "hi", LogitBias: map[string]int{"2001": 100, "42": 5}})
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"1734": float64(-100), "2001": float64(100), "42": float64(5)}, requests[1]["logit_bias"])
+
+	_, err = CreateAgent(client).Invoke(context.Background(), InvokeConfig{Prompt: "hi"})
+	require.NoError(t, err)
+	require.NotContains(t, requests[2], "logit_bias")
+}
+
+func TestServiceTier(t *testing.T) {
+	var requests []map[string]interface{}
+	server := paramsServer(t, &requests)
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("test"), WithBaseURL(server.URL), WithRequestOptions(option.WithMaxRetries(0)))
+	recorder := &retryRecorder{}
+
+	_, err := CreateAgent(client).
+		WithServiceTier(ServiceTierFlex).
+		WithCallbacks(recorder).
+		Invoke(context.Background(), InvokeConfig{Prompt: "hi"})
+	require.NoError(t, err)
+	require.Equal(t, "flex", requests[0]["service_tier"])
+
+	// The tier that processed the request is reported with the generation
+	require.Equal(t, "flex", recorder.generations[0]["service_tier"])
+
+	agent, err := AgentFromDefinition[string](client, AgentDefinition{ServiceTier: "priority"}, nil)
+	require.NoError(t, err)
+	_, err = agent.Invoke(context.Background(), InvokeConfig{Prompt: "hi"})
+	require.NoError(t, err)
+	require.Equal(t, "priority", requests[1]["service_tier"])
+}
@@ -0,0 +1,18 @@
+package kit
+
+// WithPartialResults makes InvokeDetailed return the run's partial state alongside ErrMaxIterations
+// instead of discarding it. The result is flagged Partial and holds the last assistant content,
+// parsed into Output when it parses.
+func (a *Agent[Output]) WithPartialResults(enabled bool) *Agent[Output] {
+	a.partialOnLimit = enabled
+	return a
+}
+
+// markPartial flags run as partial and fills it with the last assistant content, best-effort
+func (r *InvokeResult[Output]) markPartial(content string) {
+	r.Partial = true
+	r.PartialContent = content
+	if output, err := parseOutput[Output](content); err == nil {
+		r.Output = output
+	}
+}
@@ -0,0 +1,51 @@
+package kit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/openai/openai-go/option"
+	"github.com/stretchr/testify/require"
+)
+
+type lookupTool struct {
+	BaseTool
+}
+
+func (l *lookupTool) Execute(ctx *Context) (any, error) {
+	return "nothing found", nil
+}
+
+func TestPartialResultOnMaxIterations(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"1","object":"chat.completion","choices":[{"index":0,"finish_reason":"tool_calls",` +
+			`"message":{"role":"assistant","content":"So far: no results","tool_calls":[{"id":"call_1","type":"function",` +
+			`"function":{"name":"lookup_tool","arguments":"{}"}}]}}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(
+		WithAPIKey("test"),
+		WithBaseURL(server.URL),
+		WithRequestOptions(option.WithMaxRetries(0)),
+	)
+	maxIterations := 2
+
+	// Disabled by default: nothing is returned
+	agent := CreateAgent(client, &lookupTool{})
+	result, err := agent.InvokeDetailed(context.Background(), InvokeConfig{Prompt: "hi", MaxIterations: &maxIterations})
+	require.ErrorIs(t, err, ErrMaxIterations)
+	require.Nil(t, result)
+
+	agent.WithPartialResults(true)
+	result, err = agent.InvokeDetailed(context.Background(), InvokeConfig{Prompt: "hi", MaxIterations: &maxIterations})
+	require.ErrorIs(t, err, ErrMaxIterations)
+	require.NotNil(t, result)
+	require.True(t, result.Partial)
+	require.Equal(t, "So far: no results", result.PartialContent)
+	require.Equal(t, "So far: no results", result.Output)
+	require.Equal(t, 2, result.Iterations)
+}
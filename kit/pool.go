@@ -0,0 +1,183 @@
+package kit
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrPoolClosed is returned when submitting to a closed Pool
+var ErrPoolClosed = errors.New("pool closed")
+
+// Priority orders the queued runs of a Pool; higher runs first, equal priorities in
+// submission order
+type Priority int
+
+const (
+	PriorityLow    Priority = -10
+	PriorityNormal Priority = 0
+	PriorityHigh   Priority = 10
+)
+
+// PoolOptions configures a Pool. All fields are optional.
+type PoolOptions struct {
+	// Concurrency is the number of runs executing at once (defaults to 4)
+	Concurrency int
+
+	// Client whose rate limits pace the pool: while its last response reported no remaining
+	// requests or tokens, no new run starts until the limit resets
+	Client *Client
+}
+
+// Pool executes queued agent runs with bounded concurrency and priorities, so many background
+// runs share the provider's rate limits instead of all firing at once
+type Pool struct {
+	client *Client
+
+	mu     sync.Mutex
+	cond   *sync.Cond
+	queue  poolQueue
+	seq    int
+	closed bool
+	wg     sync.WaitGroup
+}
+
+// NewPool creates a pool and starts its workers
+func NewPool(opts PoolOptions) *Pool {
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = 4
+	}
+
+	p := &Pool{client: opts.Client}
+	p.cond = sync.NewCond(&p.mu)
+
+	p.wg.Add(concurrency)
+	for i := 0; i < concurrency; i++ {
+		go p.work()
+	}
+	return p
+}
+
+// Submit queues a run of the agent and returns its handle, in the queued status until a worker
+// picks it up. Cancelling the handle or ctx while queued makes the run fail as soon as it starts.
+func Submit[Output any](
+	ctx context.Context,
+	p *Pool,
+	agent *Agent[Output],
+	config InvokeConfig,
+	priority Priority,
+) (*RunHandle[Output], error) {
+	handle, err := newRunHandle[Output](&config, RunStatusQueued)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return nil, ErrPoolClosed
+	}
+
+	p.seq++
+	heap.Push(&p.queue, &poolJob{
+		priority: priority,
+		seq:      p.seq,
+		run:      func() { handle.run(ctx, agent, config) },
+	})
+	p.cond.Signal()
+	return handle, nil
+}
+
+// Len returns the number of queued runs
+func (p *Pool) Len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.queue.Len()
+}
+
+// Close stops accepting runs and waits until the queued and executing ones have finished
+func (p *Pool) Close() {
+	p.mu.Lock()
+	p.closed = true
+	p.cond.Broadcast()
+	p.mu.Unlock()
+
+	p.wg.Wait()
+}
+
+// work executes queued runs until the pool is closed and drained
+func (p *Pool) work() {
+	defer p.wg.Done()
+
+	for {
+		p.mu.Lock()
+		for p.queue.Len() == 0 && !p.closed {
+			p.cond.Wait()
+		}
+		if p.queue.Len() == 0 {
+			p.mu.Unlock()
+			return
+		}
+		job := heap.Pop(&p.queue).(*poolJob)
+		p.mu.Unlock()
+
+		p.throttle()
+		job.run()
+	}
+}
+
+// throttle waits while the client's rate limits are exhausted
+func (p *Pool) throttle() {
+	if p.client == nil {
+		return
+	}
+
+	limits, ok := p.client.RateLimits()
+	if !ok {
+		return
+	}
+
+	var wait time.Duration
+	if limits.LimitRequests > 0 && limits.RemainingRequests == 0 {
+		wait = max(wait, limits.ResetRequests)
+	}
+	if limits.LimitTokens > 0 && limits.RemainingTokens == 0 {
+		wait = max(wait, limits.ResetTokens)
+	}
+	if remaining := time.Until(limits.ObservedAt.Add(wait)); remaining > 0 {
+		time.Sleep(remaining)
+	}
+}
+
+// poolJob is a queued run
+type poolJob struct {
+	priority Priority
+	seq      int
+	run      func()
+}
+
+// poolQueue is a heap of jobs, highest priority first and FIFO within a priority
+type poolQueue []*poolJob
+
+func (q poolQueue) Len() int { return len(q) }
+
+func (q poolQueue) Less(i, j int) bool {
+	if q[i].priority != q[j].priority {
+		return q[i].priority > q[j].priority
+	}
+	return q[i].seq < q[j].seq
+}
+
+func (q poolQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *poolQueue) Push(x any) { *q = append(*q, x.(*poolJob)) }
+
+func (q *poolQueue) Pop() any {
+	old := *q
+	job := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return job
+}
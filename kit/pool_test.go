@@ -0,0 +1,72 @@
+package kit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/openai/openai-go/option"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPoolRunsByPriority(t *testing.T) {
+	release := make(chan struct{})
+	var mu sync.Mutex
+	var prompts []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Messages []struct {
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		prompt := body.Messages[len(body.Messages)-1].Content
+
+		mu.Lock()
+		prompts = append(prompts, prompt)
+		mu.Unlock()
+		if prompt == "first" {
+			<-release
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte(`data: {"id":"1","object":"chat.completion.chunk","choices":[` +
+			`{"index":0,"finish_reason":"stop","delta":{"role":"assistant","content":"ok"}}]}` + "\n\ndata: [DONE]\n\n"))
+	}))
+	defer server.Close()
+
+	client := NewClient(
+		WithAPIKey("test"),
+		WithBaseURL(server.URL),
+		WithRequestOptions(option.WithMaxRetries(0)),
+	)
+	agent := CreateAgent(client)
+	pool := NewPool(PoolOptions{Concurrency: 1, Client: client})
+
+	ctx := context.Background()
+	first, err := Submit(ctx, pool, agent, InvokeConfig{Prompt: "first"}, PriorityNormal)
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return first.Status() == RunStatusRunning }, time.Second, time.Millisecond)
+
+	low, err := Submit(ctx, pool, agent, InvokeConfig{Prompt: "low"}, PriorityLow)
+	require.NoError(t, err)
+	_, err = Submit(ctx, pool, agent, InvokeConfig{Prompt: "high"}, PriorityHigh)
+	require.NoError(t, err)
+	require.Equal(t, RunStatusQueued, low.Status())
+	require.Equal(t, 2, pool.Len())
+
+	close(release)
+	pool.Close()
+
+	require.Equal(t, []string{"first", "high", "low"}, prompts)
+	_, lowErr := low.Result()
+	require.NoError(t, lowErr)
+	require.Equal(t, RunStatusSucceeded, low.Status())
+
+	_, err = Submit(ctx, pool, agent, InvokeConfig{Prompt: "late"}, PriorityNormal)
+	require.ErrorIs(t, err, ErrPoolClosed)
+}
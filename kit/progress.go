@@ -0,0 +1,39 @@
+package kit
+
+import (
+	"context"
+)
+
+// Progress is the progress of a run, reported at the start of every iteration and whenever a
+// tool calls Context.ReportProgress
+type Progress struct {
+	// Step is the current iteration and TotalSteps the iteration limit of the run
+	Step       int `json:"step"`
+	TotalSteps int `json:"total_steps"`
+
+	// Percent is the estimated completion, 0 to 100. For iterations it is based on the
+	// iteration limit, so runs usually finish early.
+	Percent float64 `json:"percent"`
+
+	Message string `json:"message,omitempty"`
+
+	// Tool is the name of the tool that reported the progress, empty for iteration progress
+	Tool string `json:"tool,omitempty"`
+}
+
+// progressReporterKey is the context key of the progress reporter of a tool call
+type progressReporterKey struct{}
+
+// WithProgressReporter returns a context whose tools' Context.ReportProgress calls report, for
+// executors running tools outside an agent, such as an MCP server
+func WithProgressReporter(ctx context.Context, report func(percent float64, message string)) context.Context {
+	return context.WithValue(ctx, progressReporterKey{}, report)
+}
+
+// ReportProgress reports the progress of a long tool call, percent from 0 to 100. It is
+// forwarded to the run's callbacks, stream and handle; without a reporter it is a no-op.
+func (c *Context) ReportProgress(percent float64, message string) {
+	if report, ok := c.Value(progressReporterKey{}).(func(float64, string)); ok {
+		report(percent, message)
+	}
+}
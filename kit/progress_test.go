@@ -0,0 +1,68 @@
+package kit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mhrlife/goai-kit/internal/callback"
+	"github.com/openai/openai-go/option"
+	"github.com/stretchr/testify/require"
+)
+
+type progressRecorder struct {
+	callback.BaseCallback
+	progress []map[string]interface{}
+}
+
+func (p *progressRecorder) Name() string { return "progressRecorder" }
+
+func (p *progressRecorder) OnProgress(ctx map[string]interface{}) {
+	p.progress = append(p.progress, ctx)
+}
+
+type indexDocs struct {
+	BaseTool
+}
+
+func (i *indexDocs) Execute(ctx *Context) (any, error) {
+	ctx.ReportProgress(50, "indexed 5 of 10 documents")
+	return "done", nil
+}
+
+func TestProgressReporting(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		if calls == 1 {
+			_, _ = w.Write([]byte(`{"id":"1","object":"chat.completion","choices":[{"index":0,"finish_reason":"tool_calls",` +
+				`"message":{"role":"assistant","tool_calls":[{"id":"call_1","type":"function",` +
+				`"function":{"name":"index_docs","arguments":"{}"}}]}}]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":"2","object":"chat.completion","choices":[` +
+			`{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":"indexed"}}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(
+		WithAPIKey("test"),
+		WithBaseURL(server.URL),
+		WithRequestOptions(option.WithMaxRetries(0)),
+	)
+	recorder := &progressRecorder{}
+	agent := CreateAgent(client, &indexDocs{}).WithMaxIterations(4).WithCallbacks(recorder)
+
+	_, err := agent.Invoke(context.Background(), InvokeConfig{Prompt: "index"})
+	require.NoError(t, err)
+
+	require.Len(t, recorder.progress, 3)
+	require.Equal(t, 1, recorder.progress[0]["step"])
+	require.Equal(t, 4, recorder.progress[0]["total_steps"])
+	require.Equal(t, "index_docs", recorder.progress[1]["tool_name"])
+	require.Equal(t, 50.0, recorder.progress[1]["percent"])
+	require.Equal(t, "indexed 5 of 10 documents", recorder.progress[1]["message"])
+	require.Equal(t, 25.0, recorder.progress[2]["percent"])
+}
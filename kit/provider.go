@@ -0,0 +1,130 @@
+package kit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
+	"github.com/openai/openai-go/packages/ssestream"
+)
+
+// Provider performs the chat completions of a Client. Requests and responses use the OpenAI
+// chat completion format; providers with another API, such as AnthropicProvider, translate
+// them, so tools and typed outputs work unchanged. The request options only apply to the
+// OpenAI API and may be ignored.
+type Provider interface {
+	Complete(ctx context.Context, params openai.ChatCompletionNewParams, opts ...option.RequestOption) (*openai.ChatCompletion, error)
+}
+
+// StreamingProvider is a Provider able to stream completions. Streamed runs on other providers
+// receive the content of every generation as a single token.
+type StreamingProvider interface {
+	Provider
+	CompleteStreaming(
+		ctx context.Context,
+		params openai.ChatCompletionNewParams,
+		opts ...option.RequestOption,
+	) *ssestream.Stream[openai.ChatCompletionChunk]
+}
+
+// WithProvider serves the client's chat completions with the provider instead of the OpenAI API
+func WithProvider(provider Provider) ClientOption {
+	return func(c *Config) {
+		c.Provider = provider
+	}
+}
+
+// openAIProvider is the default Provider, the OpenAI API or an OpenAI-compatible one
+type openAIProvider struct {
+	client *openai.Client
+}
+
+func (p openAIProvider) Complete(
+	ctx context.Context,
+	params openai.ChatCompletionNewParams,
+	opts ...option.RequestOption,
+) (*openai.ChatCompletion, error) {
+	return p.client.Chat.Completions.New(ctx, params, opts...)
+}
+
+func (p openAIProvider) CompleteStreaming(
+	ctx context.Context,
+	params openai.ChatCompletionNewParams,
+	opts ...option.RequestOption,
+) *ssestream.Stream[openai.ChatCompletionChunk] {
+	return p.client.Chat.Completions.NewStreaming(ctx, params, opts...)
+}
+
+// outputTool is the tool structured outputs are requested through by providers whose API can't
+// combine tool calling with a response format; the model's input to it is the output
+const outputTool = "final_answer"
+
+const outputToolDescription = "Give your final answer. Call this tool instead of answering in text."
+
+// toolCallJSON is a tool call of a translated response
+func toolCallJSON(id, name, arguments string) map[string]any {
+	return map[string]any{
+		"id":       id,
+		"type":     "function",
+		"function": map[string]any{"name": name, "arguments": arguments},
+	}
+}
+
+// newCompletion builds the chat completion of a response translated from another API. It goes
+// through JSON so the completion's field metadata is set as if the OpenAI API had sent it.
+func newCompletion(id, model string, message map[string]any, finishReason string, usage map[string]any) (*openai.ChatCompletion, error) {
+	data, err := json.Marshal(map[string]any{
+		"id":      id,
+		"object":  "chat.completion",
+		"created": time.Now().Unix(),
+		"model":   model,
+		"choices": []map[string]any{{"index": 0, "finish_reason": finishReason, "message": message}},
+		"usage":   usage,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var completion openai.ChatCompletion
+	if err := json.Unmarshal(data, &completion); err != nil {
+		return nil, fmt.Errorf("failed to convert %s response: %w", model, err)
+	}
+	return &completion, nil
+}
+
+// providerError converts an error response of another API into an *openai.Error, so
+// ClassifyError and the retry policy handle it like the OpenAI API's
+func providerError(req *http.Request, resp *http.Response, code, message, errType string) error {
+	raw, err := json.Marshal(map[string]string{
+		"code":    code,
+		"message": message,
+		"type":    errType,
+	})
+	if err != nil {
+		return err
+	}
+
+	apiErr := &openai.Error{}
+	if err := apiErr.UnmarshalJSON(raw); err != nil {
+		return fmt.Errorf("provider error %d: %s", resp.StatusCode, message)
+	}
+	apiErr.StatusCode = resp.StatusCode
+	apiErr.Request = req
+	apiErr.Response = resp
+	return apiErr
+}
+
+// splitDataURI returns the MIME type and base64 data of a base64 data URI
+func splitDataURI(uri string) (mimeType, data string, ok bool) {
+	rest, isData := strings.CutPrefix(uri, "data:")
+	header, data, hasData := strings.Cut(rest, ",")
+	if !isData || !hasData {
+		return "", "", false
+	}
+	return strings.TrimSuffix(header, ";base64"), data, true
+}
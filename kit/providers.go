@@ -0,0 +1,81 @@
+package kit
+
+import (
+	"os"
+)
+
+// Quirks describes where an OpenAI-compatible provider deviates from the OpenAI API
+type Quirks struct {
+	// NoStrictSchema sends tool and response schemas without strict mode, for providers that
+	// reject or ignore it
+	NoStrictSchema bool
+
+	// LegacyMaxTokens sends max_tokens instead of max_completion_tokens
+	LegacyMaxTokens bool
+
+	// NoResponseFormat omits the json_schema response format; the output schema is described in
+	// the prompt instead and the JSON extracted from the response
+	NoResponseFormat bool
+}
+
+// Model names of the presets' providers
+const (
+	ModelGroqLlama70B     = "llama-3.3-70b-versatile"
+	ModelGroqLlama8B      = "llama-3.1-8b-instant"
+	ModelTogetherLlama70B = "meta-llama/Llama-3.3-70B-Instruct-Turbo"
+	ModelTogetherQwen72B  = "Qwen/Qwen2.5-72B-Instruct-Turbo"
+	ModelDeepSeekChat     = "deepseek-chat"
+	ModelDeepSeekReasoner = "deepseek-reasoner"
+)
+
+// providerPreset is the configuration of an OpenAI-compatible provider
+type providerPreset struct {
+	baseURL      string
+	apiKeyEnv    string
+	defaultModel string
+	quirks       Quirks
+}
+
+// NewGroqClient creates a Client for Groq, authenticated with GROQ_API_KEY. The options
+// override the preset.
+func NewGroqClient(opts ...ClientOption) *Client {
+	return newPresetClient(providerPreset{
+		baseURL:      "https://api.groq.com/openai/v1",
+		apiKeyEnv:    "GROQ_API_KEY",
+		defaultModel: ModelGroqLlama70B,
+		quirks:       Quirks{NoStrictSchema: true},
+	}, opts)
+}
+
+// NewTogetherClient creates a Client for Together AI, authenticated with TOGETHER_API_KEY.
+// The options override the preset.
+func NewTogetherClient(opts ...ClientOption) *Client {
+	return newPresetClient(providerPreset{
+		baseURL:      "https://api.together.xyz/v1",
+		apiKeyEnv:    "TOGETHER_API_KEY",
+		defaultModel: ModelTogetherLlama70B,
+		quirks:       Quirks{NoStrictSchema: true, LegacyMaxTokens: true},
+	}, opts)
+}
+
+// NewDeepSeekClient creates a Client for DeepSeek, authenticated with DEEPSEEK_API_KEY.
+// The options override the preset.
+func NewDeepSeekClient(opts ...ClientOption) *Client {
+	return newPresetClient(providerPreset{
+		baseURL:      "https://api.deepseek.com/v1",
+		apiKeyEnv:    "DEEPSEEK_API_KEY",
+		defaultModel: ModelDeepSeekChat,
+		quirks:       Quirks{NoStrictSchema: true, LegacyMaxTokens: true},
+	}, opts)
+}
+
+// newPresetClient applies the preset before the caller's options
+func newPresetClient(preset providerPreset, opts []ClientOption) *Client {
+	presetOpt := func(c *Config) {
+		c.ApiBase = preset.baseURL
+		c.ApiKey = os.Getenv(preset.apiKeyEnv)
+		c.DefaultModel = preset.defaultModel
+		c.Quirks = preset.quirks
+	}
+	return NewClient(append([]ClientOption{presetOpt}, opts...)...)
+}
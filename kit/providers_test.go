@@ -0,0 +1,28 @@
+package kit
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPresetQuirks(t *testing.T) {
+	client := NewDeepSeekClient(WithAPIKey("test"))
+
+	type answer struct {
+		Value string `json:"value"`
+	}
+	params := askParams(client, "hi", AskOptions{MaxTokens: 100})
+	params.ResponseFormat = responseFormat[answer](client.strictSchemas())
+
+	body, err := json.Marshal(params)
+	require.NoError(t, err)
+
+	var fields map[string]any
+	require.NoError(t, json.Unmarshal(body, &fields))
+	require.Equal(t, ModelDeepSeekChat, fields["model"])
+	require.EqualValues(t, 100, fields["max_tokens"])
+	require.NotContains(t, fields, "max_completion_tokens")
+	require.Equal(t, false, fields["response_format"].(map[string]any)["json_schema"].(map[string]any)["strict"])
+}
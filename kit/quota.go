@@ -0,0 +1,41 @@
+package kit
+
+import (
+	"context"
+
+	"github.com/openai/openai-go"
+)
+
+// QuotaEnforcer enforces per-user quotas for runs identified by InvokeConfig.User.
+// See the quota package for a store-backed implementation.
+type QuotaEnforcer interface {
+	// Check reserves a run for the user, returning an error when they may not start one
+	Check(ctx context.Context, user string) error
+
+	// Record adds the usage of a finished run to the user's quota, completing its reservation
+	Record(ctx context.Context, user string, usage openai.CompletionUsage) error
+}
+
+// WithQuota enforces per-user quotas on every invocation that sets InvokeConfig.User
+func (a *Agent[Output]) WithQuota(enforcer QuotaEnforcer) *Agent[Output] {
+	a.quota = enforcer
+	return a
+}
+
+// checkQuota verifies the user may start a run
+func (a *Agent[Output]) checkQuota(ctx context.Context, user string) error {
+	if a.quota == nil || user == "" {
+		return nil
+	}
+	return a.quota.Check(ctx, user)
+}
+
+// recordQuota books the usage of a finished run; failures are logged only
+func (a *Agent[Output]) recordQuota(ctx context.Context, user string, result *InvokeResult[Output]) {
+	if a.quota == nil || user == "" || result == nil {
+		return
+	}
+	if err := a.quota.Record(context.WithoutCancel(ctx), user, result.Usage); err != nil {
+		a.client.Logger.Error("Failed to record quota usage", "user", user, "error", err)
+	}
+}
@@ -0,0 +1,56 @@
+package kit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/mhrlife/goai-kit/internal/runs"
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
+	"github.com/stretchr/testify/require"
+)
+
+var errQuotaExhausted = errors.New("quota exhausted")
+
+type rejectingQuota struct {
+	recorded atomic.Int32
+}
+
+func (q *rejectingQuota) Check(context.Context, string) error {
+	return errQuotaExhausted
+}
+
+func (q *rejectingQuota) Record(context.Context, string, openai.CompletionUsage) error {
+	q.recorded.Add(1)
+	return nil
+}
+
+func TestQuotaRejectionIsRecorded(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+	}))
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("test"), WithBaseURL(server.URL), WithRequestOptions(option.WithMaxRetries(0)))
+	store := runs.NewMemoryStore()
+	quota := &rejectingQuota{}
+	agent := CreateAgent(client).WithQuota(quota).WithRunStore(store)
+
+	_, err := agent.Invoke(context.Background(), InvokeConfig{Prompt: "hi", User: "alice"})
+	require.ErrorIs(t, err, errQuotaExhausted)
+	require.Zero(t, requests.Load())
+	require.Zero(t, quota.recorded.Load())
+
+	// The rejected run is kept like those rejected by input guardrails
+	found, err := store.Query(context.Background(), runs.Query{User: "alice"})
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	require.Equal(t, runs.StatusFailed, found[0].Status)
+	require.Equal(t, errQuotaExhausted.Error(), found[0].Error)
+	require.NotEmpty(t, found[0].Messages)
+}
@@ -0,0 +1,81 @@
+package kit
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/mhrlife/goai-kit/internal/callback"
+	"github.com/openai/openai-go/option"
+)
+
+// RateLimits is the provider's rate limit state from the x-ratelimit-* response headers
+type RateLimits = callback.RateLimits
+
+// RateLimits returns the rate limits reported with the most recent response, so schedulers can
+// throttle before hitting 429s. It returns false until a response carried rate limit headers.
+func (c *Client) RateLimits() (RateLimits, bool) {
+	return c.rateLimits.get()
+}
+
+// rateLimitTracker keeps the most recently reported rate limits of a client
+type rateLimitTracker struct {
+	mu       sync.RWMutex
+	limits   RateLimits
+	observed bool
+}
+
+func (t *rateLimitTracker) get() (RateLimits, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.limits, t.observed
+}
+
+func (t *rateLimitTracker) set(limits RateLimits) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.limits = limits
+	t.observed = true
+}
+
+// middleware records the rate limits of every response, including rejected ones
+func (t *rateLimitTracker) middleware() option.Middleware {
+	return func(request *http.Request, next option.MiddlewareNext) (*http.Response, error) {
+		resp, err := next(request)
+		if resp != nil {
+			if limits, ok := parseRateLimits(resp.Header, time.Now()); ok {
+				t.set(limits)
+			}
+		}
+		return resp, err
+	}
+}
+
+// parseRateLimits reads the x-ratelimit-* headers, reporting whether any was present
+func parseRateLimits(header http.Header, now time.Time) (RateLimits, bool) {
+	limits := RateLimits{ObservedAt: now}
+	found := false
+
+	parseInt := func(name string, dst *int64) {
+		if value, err := strconv.ParseInt(header.Get(name), 10, 64); err == nil {
+			*dst = value
+			found = true
+		}
+	}
+	parseDuration := func(name string, dst *time.Duration) {
+		if value, err := time.ParseDuration(header.Get(name)); err == nil {
+			*dst = value
+			found = true
+		}
+	}
+
+	parseInt("x-ratelimit-limit-requests", &limits.LimitRequests)
+	parseInt("x-ratelimit-remaining-requests", &limits.RemainingRequests)
+	parseDuration("x-ratelimit-reset-requests", &limits.ResetRequests)
+	parseInt("x-ratelimit-limit-tokens", &limits.LimitTokens)
+	parseInt("x-ratelimit-remaining-tokens", &limits.RemainingTokens)
+	parseDuration("x-ratelimit-reset-tokens", &limits.ResetTokens)
+
+	return limits, found
+}
@@ -0,0 +1,112 @@
+package kit
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/mhrlife/goai-kit/internal/schema"
+	"github.com/openai/openai-go"
+)
+
+// WithReActMode runs the tool loop as plain-text ReAct (Thought/Action/Observation) instead of the
+// tools API, for models and endpoints that don't support native tool calling. The same tools are
+// described in a system message and their calls are parsed from the generated text.
+func (a *Agent[Output]) WithReActMode(enabled bool) *Agent[Output] {
+	a.reactMode = enabled
+	return a
+}
+
+var (
+	reactFinalAnswer = regexp.MustCompile(`(?is)final answer\s*:\s*(.*)$`)
+	reactAction      = regexp.MustCompile(`(?im)^\s*action\s*:\s*(.+?)\s*$`)
+	reactActionInput = regexp.MustCompile(`(?is)action input\s*:\s*(.*?)(?:\n\s*observation\s*:|$)`)
+)
+
+// reactInstructions builds the system message describing the tools and the ReAct format
+func (a *Agent[Output]) reactInstructions(schemas []ToolSchema) openai.ChatCompletionMessageParamUnion {
+	var b strings.Builder
+	b.WriteString("You can use the following tools:\n\n")
+	for _, toolSchema := range schemas {
+		parameters, _ := json.Marshal(toolSchema.JSONSchema)
+		fmt.Fprintf(&b, "- %s: %s\n  Input schema: %s\n", toolSchema.Name, toolSchema.Description, parameters)
+	}
+
+	b.WriteString("\nUse exactly this format:\n\n" +
+		"Thought: reason about what to do next\n" +
+		"Action: the tool name\n" +
+		"Action Input: the tool input as a JSON object\n\n" +
+		"Then stop and wait; the result is sent back as \"Observation: ...\". " +
+		"Repeat as needed. When you know the answer, reply with:\n\n" +
+		"Thought: I know the answer\n" +
+		"Final Answer: the answer")
+
+	var outputType Output
+	if !isStringType(outputType) {
+		outputSchema, _ := json.Marshal(schema.InferJSONSchema(outputType))
+		fmt.Fprintf(&b, "\n\nThe final answer must be a JSON object matching this schema: %s", outputSchema)
+	}
+
+	return openai.SystemMessage(b.String())
+}
+
+// reactStep parses a ReAct generation into the tool call to execute or the final output content
+func (a *Agent[Output]) reactStep(content string, iteration int) ([]openai.ChatCompletionMessageToolCall, string) {
+	toolCalls, final := parseReAct(content, iteration)
+
+	var outputType Output
+	if toolCalls == nil && !isStringType(outputType) {
+		final = repairJSON(final)
+	}
+	return toolCalls, final
+}
+
+// parseReAct extracts the tool call or the final answer from a ReAct generation. Text without
+// either is taken as the final answer.
+func parseReAct(content string, iteration int) ([]openai.ChatCompletionMessageToolCall, string) {
+	action := reactAction.FindStringSubmatch(content)
+	final := reactFinalAnswer.FindStringSubmatchIndex(content)
+
+	// An action written before a final answer wins, the model hasn't observed its result yet
+	if action == nil || (final != nil && final[0] < strings.Index(content, action[0])) {
+		if final != nil {
+			return nil, strings.TrimSpace(content[final[2]:final[3]])
+		}
+		return nil, strings.TrimSpace(content)
+	}
+
+	input := "{}"
+	if match := reactActionInput.FindStringSubmatch(content); match != nil {
+		if trimmed := repairJSON(match[1]); trimmed != "" {
+			input = trimmed
+		}
+	}
+
+	return []openai.ChatCompletionMessageToolCall{{
+		ID:   fmt.Sprintf("react_%d", iteration),
+		Type: "function",
+		Function: openai.ChatCompletionMessageToolCallFunction{
+			Name:      strings.Trim(action[1], "`\"' "),
+			Arguments: input,
+		},
+	}}, ""
+}
+
+// reactObservations converts the tool messages of executed ReAct calls into user messages
+func reactObservations(messages []openai.ChatCompletionMessageParamUnion) []openai.ChatCompletionMessageParamUnion {
+	observations := make([]openai.ChatCompletionMessageParamUnion, 0, len(messages))
+	for _, msg := range messages {
+		if msg.OfTool == nil {
+			observations = append(observations, msg)
+			continue
+		}
+
+		text := msg.OfTool.Content.OfString.Value
+		for _, part := range msg.OfTool.Content.OfArrayOfContentParts {
+			text += part.Text
+		}
+		observations = append(observations, openai.UserMessage("Observation: "+text))
+	}
+	return observations
+}
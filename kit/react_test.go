@@ -0,0 +1,70 @@
+package kit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/openai/openai-go/option"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseReAct(t *testing.T) {
+	toolCalls, final := parseReAct("Thought: I need the weather\nAction: get_weather\n"+
+		"Action Input: ```json\n{\"city\": \"Paris\"}\n```\nObservation: sunny", 2)
+	require.Empty(t, final)
+	require.Len(t, toolCalls, 1)
+	require.Equal(t, "react_2", toolCalls[0].ID)
+	require.Equal(t, "get_weather", toolCalls[0].Function.Name)
+	require.JSONEq(t, `{"city": "Paris"}`, toolCalls[0].Function.Arguments)
+
+	toolCalls, final = parseReAct("Thought: I know the answer\nFinal Answer: It is sunny in Paris.", 3)
+	require.Nil(t, toolCalls)
+	require.Equal(t, "It is sunny in Paris.", final)
+
+	toolCalls, final = parseReAct("It is sunny.", 4)
+	require.Nil(t, toolCalls)
+	require.Equal(t, "It is sunny.", final)
+}
+
+func TestReActStopSequences(t *testing.T) {
+	var stops [][]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Stop []string `json:"stop"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		stops = append(stops, body.Stop)
+
+		content := "Thought: I know the answer\nFinal Answer: done"
+		if len(stops) == 1 {
+			content = "Thought: I need to look it up\nAction: lookup_tool\nAction Input: {}"
+		}
+		data, _ := json.Marshal(content)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"1","object":"chat.completion","choices":[{"index":0,"finish_reason":"stop",` +
+			`"message":{"role":"assistant","content":` + string(data) + `}}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("test"), WithBaseURL(server.URL), WithRequestOptions(option.WithMaxRetries(0)))
+	stop := StopSequences{Tools: []string{"Observation:"}, Final: []string{"<|im_end|>"}}
+
+	// The tool calling iteration stops before a made-up observation, the wrapped up answer on control tokens
+	output, err := CreateAgent(client, &lookupTool{}).
+		WithReActMode(true).
+		WithToolBudget(ToolBudget{MaxCalls: 1, WrapUp: true}).
+		WithStopSequences(stop).
+		Invoke(context.Background(), InvokeConfig{Prompt: "hi"})
+	require.NoError(t, err)
+	require.Equal(t, "done", output)
+	require.Equal(t, [][]string{stop.Tools, stop.Final}, stops)
+
+	// Agents without tools only generate final answers
+	stops = nil
+	_, err = CreateAgent(client).WithStopSequences(stop).Invoke(context.Background(), InvokeConfig{Prompt: "hi"})
+	require.NoError(t, err)
+	require.Equal(t, [][]string{stop.Final}, stops)
+}
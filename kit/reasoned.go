@@ -0,0 +1,60 @@
+package kit
+
+import (
+	"encoding/json"
+)
+
+// Reasoned wraps a structured output with a free-form reasoning field that the model fills
+// before the answer, standardizing the "think then answer" pattern.
+// Use it as the agent output: CreateAgentWithOutput[kit.Reasoned[MyOutput]](client)
+type Reasoned[T any] struct {
+	Reasoning string `json:"reasoning" jsonschema:"description=Step by step reasoning before giving the answer"`
+	Answer    T      `json:"answer" jsonschema:"description=The final answer"`
+}
+
+// reasoner is implemented by outputs that carry reasoning which can be stripped from traces
+type reasoner interface {
+	withoutReasoning() any
+}
+
+func (r Reasoned[T]) withoutReasoning() any {
+	return Reasoned[T]{Answer: r.Answer}
+}
+
+// WithStripReasoning removes the reasoning of Reasoned outputs and the reasoning_content of
+// reasoning models from everything sent to callbacks
+func (a *Agent[Output]) WithStripReasoning(strip bool) *Agent[Output] {
+	a.stripReasoning = strip
+	return a
+}
+
+// traceOutput returns the output as it should be reported to callbacks
+func (a *Agent[Output]) traceOutput(output Output) any {
+	if !a.stripReasoning {
+		return output
+	}
+	if r, ok := any(output).(reasoner); ok {
+		return r.withoutReasoning()
+	}
+	return output
+}
+
+// traceContent returns the raw generation content as it should be reported to callbacks
+func (a *Agent[Output]) traceContent(content string) string {
+	var zero Output
+	if _, ok := any(zero).(reasoner); !ok || !a.stripReasoning || content == "" {
+		return content
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(content), &fields); err != nil {
+		return content
+	}
+	delete(fields, "reasoning")
+
+	stripped, err := json.Marshal(fields)
+	if err != nil {
+		return content
+	}
+	return string(stripped)
+}
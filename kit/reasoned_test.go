@@ -0,0 +1,67 @@
+package kit
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mhrlife/goai-kit/internal/callback"
+	"github.com/openai/openai-go/option"
+	"github.com/stretchr/testify/require"
+)
+
+type traceRecorder struct {
+	callback.BaseCallback
+	contents []string
+	output   interface{}
+}
+
+func (r *traceRecorder) Name() string { return "traceRecorder" }
+
+func (r *traceRecorder) OnGenerationEnd(ctx map[string]interface{}) {
+	r.contents = append(r.contents, ctx["content"].(string))
+}
+
+func (r *traceRecorder) OnRunEnd(ctx map[string]interface{}) { r.output = ctx["output"] }
+
+func TestReasonedOutput(t *testing.T) {
+	var request string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		request = string(body)
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"1","object":"chat.completion","choices":[{"index":0,"finish_reason":"stop",` +
+			`"message":{"role":"assistant","content":"{\"reasoning\":\"2 plus 2 is 4\",\"answer\":{\"sum\":4}}"}}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("test"), WithBaseURL(server.URL), WithRequestOptions(option.WithMaxRetries(0)))
+	type sum struct {
+		Sum int `json:"sum"`
+	}
+
+	recorder := &traceRecorder{}
+	output, err := CreateAgentWithOutput[Reasoned[sum]](client).
+		WithCallbacks(recorder).
+		Invoke(context.Background(), InvokeConfig{Prompt: "2+2"})
+	require.NoError(t, err)
+	require.Equal(t, "2 plus 2 is 4", output.Reasoning)
+	require.Equal(t, 4, output.Answer.Sum)
+	require.Contains(t, request, `"reasoning"`, "the schema asks for the reasoning")
+	require.Contains(t, recorder.contents[0], "2 plus 2 is 4")
+
+	// Stripping keeps the reasoning in the output but out of the callbacks
+	recorder = &traceRecorder{}
+	output, err = CreateAgentWithOutput[Reasoned[sum]](client).
+		WithStripReasoning(true).
+		WithCallbacks(recorder).
+		Invoke(context.Background(), InvokeConfig{Prompt: "2+2"})
+	require.NoError(t, err)
+	require.Equal(t, "2 plus 2 is 4", output.Reasoning)
+	require.Equal(t, `{"answer":{"sum":4}}`, recorder.contents[0])
+	require.Equal(t, Reasoned[sum]{Answer: sum{Sum: 4}}, recorder.output)
+}
@@ -0,0 +1,49 @@
+package kit
+
+import (
+	"encoding/json"
+
+	"github.com/openai/openai-go"
+)
+
+// WithExcludeReasoningContent keeps the reasoning_content returned by reasoning models such as
+// DeepSeek-R1 out of the message history sent in subsequent generations. It is still reported to
+// callbacks and in InvokeResult.ReasoningContent.
+func (a *Agent[Output]) WithExcludeReasoningContent(exclude bool) *Agent[Output] {
+	a.dropReasoning = exclude
+	return a
+}
+
+// reasoningContent returns the nonstandard reasoning_content of a message, or the reasoning field
+// some OSS model servers and gateways use instead
+func reasoningContent(message openai.ChatCompletionMessage) string {
+	var fields struct {
+		ReasoningContent string `json:"reasoning_content"`
+		Reasoning        string `json:"reasoning"`
+	}
+	if err := json.Unmarshal([]byte(message.RawJSON()), &fields); err != nil {
+		return ""
+	}
+	if fields.ReasoningContent != "" {
+		return fields.ReasoningContent
+	}
+	return fields.Reasoning
+}
+
+// assistantMessage converts a generated message to its history entry, carrying the reasoning
+// content unless excluded
+func (a *Agent[Output]) assistantMessage(message openai.ChatCompletionMessage, reasoning string) openai.ChatCompletionMessageParamUnion {
+	param := message.ToParam()
+	if reasoning != "" && !a.dropReasoning {
+		param.OfAssistant.SetExtraFields(map[string]any{"reasoning_content": reasoning})
+	}
+	return param
+}
+
+// traceReasoningContent returns the reasoning content as it should be reported to callbacks
+func (a *Agent[Output]) traceReasoningContent(reasoning string) string {
+	if a.stripReasoning {
+		return ""
+	}
+	return reasoning
+}
@@ -0,0 +1,31 @@
+package kit
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/openai/openai-go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReasoningContentHistory(t *testing.T) {
+	var message openai.ChatCompletionMessage
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"role": "assistant",
+		"content": "42",
+		"reasoning_content": "The question asks for the answer."
+	}`), &message))
+
+	reasoning := reasoningContent(message)
+	require.Equal(t, "The question asks for the answer.", reasoning)
+
+	agent := &Agent[string]{}
+	body, err := json.Marshal(agent.assistantMessage(message, reasoning))
+	require.NoError(t, err)
+	require.JSONEq(t, `{"role": "assistant", "content": "42", "reasoning_content": "The question asks for the answer."}`, string(body))
+
+	agent.WithExcludeReasoningContent(true)
+	body, err = json.Marshal(agent.assistantMessage(message, reasoning))
+	require.NoError(t, err)
+	require.JSONEq(t, `{"role": "assistant", "content": "42"}`, string(body))
+}
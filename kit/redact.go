@@ -0,0 +1,13 @@
+package kit
+
+import (
+	"github.com/mhrlife/goai-kit/internal/callback"
+)
+
+// WithPayloadRedactor transforms inputs, outputs, messages and tool payloads before they are
+// sent to any callback or trace exporter, so sensitive user data never leaves the process
+// unprotected. See callback.RedactKeys, callback.HashPayloads and callback.EncryptPayloads.
+func (a *Agent[Output]) WithPayloadRedactor(redactor callback.Redactor) *Agent[Output] {
+	a.redactor = redactor
+	return a
+}
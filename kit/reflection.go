@@ -0,0 +1,98 @@
+package kit
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mhrlife/goai-kit/internal/callback"
+	"github.com/openai/openai-go"
+)
+
+// DefaultCriticPrompt is the system prompt of the critic when Reflection.Prompt is empty
+const DefaultCriticPrompt = "You review a draft answer written by an assistant. Check it against the " +
+	"instructions and the user's request: correctness, completeness and the required format. " +
+	"If it fully satisfies them, reply with exactly APPROVED. Otherwise list the concrete problems to fix."
+
+// approvedVerdict is the critic's reply for a draft that needs no revision
+const approvedVerdict = "APPROVED"
+
+// Reflection configures a self-critique phase: before returning, a critic reviews the final draft
+// against the instructions and, unless it approves, the agent revises the draft once
+type Reflection struct {
+	// Prompt is the critic's system prompt; the critic must reply APPROVED to accept the draft
+	// (optional, defaults to DefaultCriticPrompt)
+	Prompt string
+
+	// Model of the critic (optional, defaults to the agent's model)
+	Model string
+}
+
+// WithReflection enables the self-critique phase on the final output of every run
+func (a *Agent[Output]) WithReflection(reflection Reflection) *Agent[Output] {
+	a.reflection = &reflection
+	return a
+}
+
+// critique asks the critic to review the draft against the instructions of the run. It returns the
+// critique, empty when the draft is approved. The critic's request goes through the agent's
+// retry policy, admission control and key failover like any generation, but is never streamed.
+func (a *Agent[Output]) critique(
+	ctx context.Context,
+	messages []openai.ChatCompletionMessageParamUnion,
+	draft string,
+	cbManager *callback.Manager,
+) (string, openai.CompletionUsage, error) {
+	prompt := a.reflection.Prompt
+	if prompt == "" {
+		prompt = DefaultCriticPrompt
+	}
+	model := a.reflection.Model
+	if model == "" {
+		model = a.model
+	}
+
+	var instructions []string
+	for _, msg := range messages {
+		if msg.OfSystem == nil && msg.OfDeveloper == nil {
+			continue
+		}
+		if text := MessageText(msg); text != "" {
+			instructions = append(instructions, text)
+		}
+	}
+
+	review := fmt.Sprintf(
+		"Instructions:\n%s\n\nUser request:\n%s\n\nDraft answer:\n%s",
+		strings.Join(instructions, "\n"), userText(messages), draft,
+	)
+
+	completion, _, err := a.createCompletion(context.WithValue(ctx, streamSinkKey{}, nil), openai.ChatCompletionNewParams{
+		Model: model,
+		Messages: []openai.ChatCompletionMessageParamUnion{
+			openai.SystemMessage(prompt),
+			openai.UserMessage(review),
+		},
+	}, cbManager)
+	if err != nil {
+		return "", openai.CompletionUsage{}, fmt.Errorf("critic request failed: %w", err)
+	}
+	if len(completion.Choices) == 0 {
+		return "", completion.Usage, fmt.Errorf("no choices in critic response")
+	}
+
+	verdict := strings.TrimSpace(completion.Choices[0].Message.Content)
+	if strings.EqualFold(strings.Trim(verdict, ".!"), approvedVerdict) {
+		return "", completion.Usage, nil
+	}
+	return verdict, completion.Usage, nil
+}
+
+// revisionMessage builds the user message asking the model to revise its draft
+func revisionMessage(critique string) openai.ChatCompletionMessageParamUnion {
+	return openai.UserMessage(fmt.Sprintf(
+		"A reviewer found problems with your answer:\n%s\n\nPlease revise your answer to address them, "+
+			"keeping the same format.",
+		critique,
+	))
+}
@@ -0,0 +1,102 @@
+package kit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/openai/openai-go/option"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReflectionRevisesOnce(t *testing.T) {
+	var models []string
+	replies := map[string][]string{
+		"writer": {"draft", "revised"},
+		"critic": {"The answer is missing a greeting."},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Model string `json:"model"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		models = append(models, body.Model)
+
+		content := replies[body.Model][0]
+		replies[body.Model] = replies[body.Model][1:]
+
+		w.Header().Set("Content-Type", "application/json")
+		reply, _ := json.Marshal(content)
+		_, _ = w.Write([]byte(`{"id":"1","object":"chat.completion","choices":[` +
+			`{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":` + string(reply) + `}}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(
+		WithAPIKey("test"),
+		WithBaseURL(server.URL),
+		WithRequestOptions(option.WithMaxRetries(0)),
+	)
+	recorder := &retryRecorder{}
+	agent := CreateAgent(client).
+		WithModel("writer").
+		WithCallbacks(recorder).
+		WithReflection(Reflection{Model: "critic"})
+
+	output, err := agent.Invoke(context.Background(), InvokeConfig{Prompt: "hi"})
+	require.NoError(t, err)
+	require.Equal(t, "revised", output)
+	require.Equal(t, []string{"writer", "critic", "writer"}, models)
+
+	require.Len(t, recorder.retries, 1)
+	require.Equal(t, "reflection", recorder.retries[0]["stage"])
+}
+
+func TestReflectionRetriesCritic(t *testing.T) {
+	var models []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Model string `json:"model"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		models = append(models, body.Model)
+
+		// The critic's first request fails, its retry approves the draft
+		if body.Model == "critic" && len(models) == 2 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		content := "draft"
+		if body.Model == "critic" {
+			content = approvedVerdict
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"1","object":"chat.completion","choices":[` +
+			`{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":"` + content + `"}}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(
+		WithAPIKey("test"),
+		WithBaseURL(server.URL),
+		WithRequestOptions(option.WithMaxRetries(0)),
+	)
+	recorder := &retryRecorder{}
+	agent := CreateAgent(client).
+		WithModel("writer").
+		WithCallbacks(recorder).
+		WithRetry(RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond}).
+		WithReflection(Reflection{Model: "critic"})
+
+	output, err := agent.Invoke(context.Background(), InvokeConfig{Prompt: "hi"})
+	require.NoError(t, err)
+	require.Equal(t, "draft", output)
+	require.Equal(t, []string{"writer", "critic", "critic"}, models)
+
+	require.Len(t, recorder.retries, 1)
+	require.Equal(t, "generation", recorder.retries[0]["stage"])
+	require.Equal(t, "critic", recorder.retries[0]["model"])
+}
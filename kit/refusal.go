@@ -0,0 +1,54 @@
+package kit
+
+import (
+	"github.com/openai/openai-go"
+)
+
+// DefaultRephrasePrompt asks the model to answer again after a refusal
+const DefaultRephrasePrompt = "Your previous response was refused or filtered. Rephrase your answer so it " +
+	"complies with the content policy, keeping to the requested format."
+
+// RefusalPolicy retries refused responses. Without one, a refusal ends the run with a RefusalError.
+type RefusalPolicy struct {
+	// MaxRetries is the number of times per run the model is asked to rephrase its answer. Every
+	// retry uses an iteration and is reported to OnRetry.
+	MaxRetries int
+
+	// RephrasePrompt is the user message sent to retry (defaults to DefaultRephrasePrompt)
+	RephrasePrompt string
+}
+
+// WithRefusalPolicy sets how refusals and content-filtered responses are retried
+func (a *Agent[Output]) WithRefusalPolicy(policy RefusalPolicy) *Agent[Output] {
+	a.refusal = policy
+	return a
+}
+
+// refusalError returns the RefusalError of a final response the model refused or the content
+// filter cut off, nil otherwise
+func refusalError(message openai.ChatCompletionMessage, finishReason string) *RefusalError {
+	if len(message.ToolCalls) > 0 {
+		return nil
+	}
+	if message.Refusal != "" {
+		return &RefusalError{Refusal: message.Refusal, FinishReason: finishReason}
+	}
+	if finishReason == "content_filter" {
+		return &RefusalError{Refusal: message.Content, FinishReason: finishReason}
+	}
+	return nil
+}
+
+// rephraseMessage returns the message retrying a refusal after the given number of retries, or
+// false once the retries are used up
+func (p RefusalPolicy) rephraseMessage(retries int) (openai.ChatCompletionMessageParamUnion, bool) {
+	if retries >= p.MaxRetries {
+		return openai.ChatCompletionMessageParamUnion{}, false
+	}
+
+	prompt := p.RephrasePrompt
+	if prompt == "" {
+		prompt = DefaultRephrasePrompt
+	}
+	return openai.UserMessage(prompt), true
+}
@@ -0,0 +1,59 @@
+package kit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/mhrlife/goai-kit/internal/callback"
+	"github.com/openai/openai-go/option"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRefusal(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if requests.Add(1)%2 == 1 {
+			_, _ = w.Write([]byte(`{"id":"1","object":"chat.completion","choices":[{"index":0,"finish_reason":"stop",` +
+				`"message":{"role":"assistant","content":"","refusal":"I can't help with that."}}]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":"1","object":"chat.completion","choices":[{"index":0,"finish_reason":"stop",` +
+			`"message":{"role":"assistant","content":"{\"answer\":\"ok\"}"}}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(
+		WithAPIKey("test"),
+		WithBaseURL(server.URL),
+		WithRequestOptions(option.WithMaxRetries(0)),
+	)
+
+	type answer struct {
+		Answer string `json:"answer"`
+	}
+
+	// The refusal is surfaced instead of failing to parse the empty content
+	_, err := CreateAgentWithOutput[answer](client).Invoke(context.Background(), InvokeConfig{Prompt: "hi"})
+	var refusal *RefusalError
+	require.ErrorAs(t, err, &refusal)
+	require.ErrorIs(t, err, ErrRefusal)
+	require.NotErrorIs(t, err, ErrOutputParse)
+	require.Equal(t, "I can't help with that.", refusal.Refusal)
+	require.Equal(t, ErrorClassContentFilter, ClassifyError(err))
+
+	// Asked to rephrase, the model answers
+	requests.Store(0)
+	recorder := &retryRecorder{}
+	result, err := CreateAgentWithOutput[answer](client).
+		WithRefusalPolicy(RefusalPolicy{MaxRetries: 1}).
+		InvokeDetailed(context.Background(), InvokeConfig{Prompt: "hi", Callbacks: []callback.AgentCallback{recorder}})
+	require.NoError(t, err)
+	require.Equal(t, "ok", result.Output.Answer)
+	require.Equal(t, 2, result.Iterations)
+	require.Len(t, recorder.retries, 1)
+	require.Equal(t, "refusal", recorder.retries[0]["stage"])
+}
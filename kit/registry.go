@@ -0,0 +1,66 @@
+package kit
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// ToolFactory creates a fresh instance of a registered tool
+type ToolFactory func() ToolExecutor
+
+var (
+	toolFactoriesMu sync.RWMutex
+	toolFactories   = make(map[string]ToolFactory)
+)
+
+// RegisterTool makes a tool available by name to config-driven agents and the goai CLI.
+// It is meant to be called from init functions and panics if the name is already taken.
+func RegisterTool(name string, factory ToolFactory) {
+	toolFactoriesMu.Lock()
+	defer toolFactoriesMu.Unlock()
+
+	if factory == nil {
+		panic("kit: RegisterTool factory is nil")
+	}
+	if _, exists := toolFactories[name]; exists {
+		panic(fmt.Sprintf("kit: RegisterTool called twice for tool %q", name))
+	}
+	toolFactories[name] = factory
+}
+
+// LookupTool creates the tool registered under name
+func LookupTool(name string) (ToolExecutor, bool) {
+	toolFactoriesMu.RLock()
+	factory, ok := toolFactories[name]
+	toolFactoriesMu.RUnlock()
+
+	if !ok {
+		return nil, false
+	}
+	return factory(), true
+}
+
+// RegisteredTools returns the sorted names of all registered tools
+func RegisteredTools() []string {
+	toolFactoriesMu.RLock()
+	defer toolFactoriesMu.RUnlock()
+
+	names := make([]string, 0, len(toolFactories))
+	for name := range toolFactories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// RegisteredToolRegistry returns a ToolRegistry holding a fresh instance of every registered tool
+func RegisteredToolRegistry() ToolRegistry {
+	registry := make(ToolRegistry)
+	for _, name := range RegisteredTools() {
+		if tool, ok := LookupTool(name); ok {
+			registry[name] = tool
+		}
+	}
+	return registry
+}
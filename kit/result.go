@@ -0,0 +1,94 @@
+package kit
+
+import (
+	"github.com/openai/openai-go"
+)
+
+// InvokeResult contains the output of a run together with the details of how it was produced
+type InvokeResult[Output any] struct {
+	// Output is the parsed final output
+	Output Output
+
+	// RunID is the run ID reported to callbacks
+	RunID string
+
+	// Messages is the full message history of the run, including the final assistant message
+	Messages []openai.ChatCompletionMessageParamUnion
+
+	// Generated are the messages appended during the run (assistant, tool and guidance messages)
+	Generated []openai.ChatCompletionMessageParamUnion
+
+	// Iterations is the number of generations performed
+	Iterations int
+
+	// Usage is the token usage accumulated across all generations
+	Usage openai.CompletionUsage
+
+	// Citations are the sources cited by the provider across all generations
+	Citations []Citation
+
+	// Images are the images generated across all generations, decoded from the images field of
+	// the messages or the data URIs in their content. A string output references them as
+	// "[image N]", N being the position in Images starting at 1
+	Images []File
+
+	// ReasoningContent is the nonstandard reasoning_content of each generation that returned one,
+	// e.g. from DeepSeek-R1-style models
+	ReasoningContent []string
+
+	// ExperimentArm is the arm of the agent's experiment the run was assigned to
+	ExperimentArm string
+
+	// Partial is set when the run hit its iteration limit and WithPartialResults is enabled;
+	// Output then holds PartialContent if it parsed
+	Partial bool
+
+	// PartialContent is the last non-empty assistant content of a partial run
+	PartialContent string
+
+	// guidance holds the positions in Generated of the messages the loop wrote on the user's
+	// behalf, e.g. nudges and revision requests
+	guidance map[int]bool
+}
+
+// appendMessages adds messages produced during the run to the history
+func (r *InvokeResult[Output]) appendMessages(messages ...openai.ChatCompletionMessageParamUnion) {
+	r.Messages = append(r.Messages, messages...)
+	r.Generated = append(r.Generated, messages...)
+}
+
+// appendGuidance adds messages the loop writes on the user's behalf to the history
+func (r *InvokeResult[Output]) appendGuidance(messages ...openai.ChatCompletionMessageParamUnion) {
+	if r.guidance == nil {
+		r.guidance = make(map[int]bool)
+	}
+	for i := range messages {
+		r.guidance[len(r.Generated)+i] = true
+	}
+	r.appendMessages(messages...)
+}
+
+// TurnMessages returns the generated messages without the guidance messages the loop wrote on
+// the user's behalf, i.e. the messages to persist as the run's turn of a conversation
+func (r *InvokeResult[Output]) TurnMessages() []openai.ChatCompletionMessageParamUnion {
+	messages := make([]openai.ChatCompletionMessageParamUnion, 0, len(r.Generated))
+	for i, msg := range r.Generated {
+		if !r.guidance[i] {
+			messages = append(messages, msg)
+		}
+	}
+	return messages
+}
+
+// addUsage adds the token counts of delta, including cached and reasoning token details, to total
+func addUsage(total *openai.CompletionUsage, delta openai.CompletionUsage) {
+	total.PromptTokens += delta.PromptTokens
+	total.CompletionTokens += delta.CompletionTokens
+	total.TotalTokens += delta.TotalTokens
+	total.PromptTokensDetails.CachedTokens += delta.PromptTokensDetails.CachedTokens
+	total.PromptTokensDetails.AudioTokens += delta.PromptTokensDetails.AudioTokens
+	total.CompletionTokensDetails.ReasoningTokens += delta.CompletionTokensDetails.ReasoningTokens
+	total.CompletionTokensDetails.AudioTokens += delta.CompletionTokensDetails.AudioTokens
+	total.CompletionTokensDetails.AcceptedPredictionTokens += delta.CompletionTokensDetails.AcceptedPredictionTokens
+	total.CompletionTokensDetails.RejectedPredictionTokens += delta.CompletionTokensDetails.RejectedPredictionTokens
+}
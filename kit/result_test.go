@@ -0,0 +1,63 @@
+package kit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/mhrlife/goai-kit/internal/callback"
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
+	"github.com/stretchr/testify/require"
+)
+
+type usageRecorder struct {
+	callback.BaseCallback
+	runEnd map[string]interface{}
+}
+
+func (r *usageRecorder) Name() string { return "usageRecorder" }
+
+func (r *usageRecorder) OnRunEnd(ctx map[string]interface{}) { r.runEnd = ctx }
+
+func TestRunUsageAggregated(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if requests.Add(1) == 1 {
+			_, _ = w.Write([]byte(`{"id":"1","object":"chat.completion","choices":[{"index":0,"finish_reason":"tool_calls",` +
+				`"message":{"role":"assistant","tool_calls":[{"id":"call_1","type":"function",` +
+				`"function":{"name":"lookup_tool","arguments":"{}"}}]}}],` +
+				`"usage":{"prompt_tokens":100,"completion_tokens":20,"total_tokens":120,` +
+				`"completion_tokens_details":{"reasoning_tokens":5}}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":"1","object":"chat.completion","choices":[{"index":0,"finish_reason":"stop",` +
+			`"message":{"role":"assistant","content":"done"}}],` +
+			`"usage":{"prompt_tokens":150,"completion_tokens":10,"total_tokens":160,` +
+			`"prompt_tokens_details":{"cached_tokens":100}}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("test"), WithBaseURL(server.URL), WithRequestOptions(option.WithMaxRetries(0)))
+	recorder := &usageRecorder{}
+
+	result, err := CreateAgent(client, &lookupTool{}).
+		WithCallbacks(recorder).
+		InvokeDetailed(context.Background(), InvokeConfig{Prompt: "hi"})
+	require.NoError(t, err)
+
+	// Usage is summed over every generation of the run
+	require.Equal(t, int64(250), result.Usage.PromptTokens)
+	require.Equal(t, int64(30), result.Usage.CompletionTokens)
+	require.Equal(t, int64(280), result.Usage.TotalTokens)
+	require.Equal(t, int64(100), result.Usage.PromptTokensDetails.CachedTokens)
+	require.Equal(t, int64(5), result.Usage.CompletionTokensDetails.ReasoningTokens)
+
+	usage := recorder.runEnd["usage"].(*openai.CompletionUsage)
+	require.Equal(t, int64(280), usage.TotalTokens)
+	require.Equal(t, int64(100), recorder.runEnd["cached_tokens"])
+	require.Equal(t, 2, recorder.runEnd["total_iterations"])
+}
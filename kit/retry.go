@@ -0,0 +1,136 @@
+package kit
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/mhrlife/goai-kit/internal/callback"
+	"github.com/openai/openai-go"
+)
+
+// RetryPolicy retries failed generations with exponential backoff and falls back to other
+// models once the primary model exhausted its attempts. Every retry is reported to OnRetry.
+type RetryPolicy struct {
+	// MaxAttempts per model, including the first one (defaults to 1)
+	MaxAttempts int
+
+	// InitialBackoff before the second attempt, doubled on every further attempt (defaults to 500ms)
+	InitialBackoff time.Duration
+
+	// MaxBackoff caps the backoff (defaults to 10s)
+	MaxBackoff time.Duration
+
+	// FallbackModels are tried in order after the agent's model failed (optional)
+	FallbackModels []string
+}
+
+// WithRetry sets the retry policy used for generations
+func (a *Agent[Output]) WithRetry(policy RetryPolicy) *Agent[Output] {
+	a.retry = policy
+	return a
+}
+
+// backoff returns the delay before the attempt following the given failed attempt
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.InitialBackoff
+	if delay <= 0 {
+		delay = 500 * time.Millisecond
+	}
+	maxDelay := p.MaxBackoff
+	if maxDelay <= 0 {
+		maxDelay = 10 * time.Second
+	}
+
+	for i := 1; i < attempt && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxDelay)
+}
+
+// createCompletion calls the chat completion API applying the agent's retry policy, starting
+// with params.Model, which is replaced by the model of every attempt. The returned info
+// describes the successful attempt, its latency covers all attempts.
+func (a *Agent[Output]) createCompletion(
+	ctx context.Context,
+	params openai.ChatCompletionNewParams,
+	cbManager *callback.Manager,
+) (*openai.ChatCompletion, callback.GenerationInfo, error) {
+	models := append([]string{params.Model}, a.retry.FallbackModels...)
+	maxAttempts := max(a.retry.MaxAttempts, 1)
+	startedAt := time.Now()
+
+	attempt := 0
+	for i := 0; ; i++ {
+		model := models[i]
+		for modelAttempt := 1; ; modelAttempt++ {
+			attempt++
+			params.Model = model
+
+			// Wait for the tokens-per-minute limit to admit the request
+			if err := a.client.admission.admit(ctx, estimateRequestTokens(params)); err != nil {
+				return nil, callback.GenerationInfo{}, err
+			}
+
+			// Report the failovers to backup API keys of this attempt
+			attemptCtx := ctx
+			if len(a.client.config.BackupAPIKeys) > 0 {
+				attemptCtx = withKeyFailoverHook(ctx, func(err error, errorClass ErrorClass) {
+					cbManager.OnRetry("key_failover", err, string(errorClass), attempt, 0, model)
+				})
+			}
+
+			completion, httpResp, err := a.completeCoalesced(attemptCtx, params)
+			if err == nil {
+				info := generationInfo(model, time.Since(startedAt), httpResp)
+				info.ServiceTier = string(completion.ServiceTier)
+				return completion, info, nil
+			}
+
+			errorClass := ClassifyError(err)
+			if !errorClass.Retryable() || ctx.Err() != nil {
+				return nil, callback.GenerationInfo{}, err
+			}
+
+			if modelAttempt < maxAttempts {
+				delay := a.retry.backoff(modelAttempt)
+				cbManager.OnRetry("generation", err, string(errorClass), attempt, delay, model)
+
+				select {
+				case <-ctx.Done():
+					return nil, callback.GenerationInfo{}, context.Cause(ctx)
+				case <-time.After(delay):
+				}
+				continue
+			}
+
+			if i == len(models)-1 {
+				return nil, callback.GenerationInfo{}, err
+			}
+
+			cbManager.OnRetry("generation", err, string(errorClass), attempt, 0, models[i+1])
+			break
+		}
+	}
+}
+
+// generationInfo extracts the request details reported to OnGenerationEnd from the response
+func generationInfo(model string, latency time.Duration, resp *http.Response) callback.GenerationInfo {
+	info := callback.GenerationInfo{
+		Model:   model,
+		Latency: latency,
+	}
+	if resp == nil {
+		return info
+	}
+
+	info.RequestID = resp.Header.Get("x-request-id")
+	if limits, ok := parseRateLimits(resp.Header, time.Now()); ok {
+		info.RateLimits = &limits
+	}
+	if ms, err := strconv.ParseInt(resp.Header.Get("openai-processing-ms"), 10, 64); err == nil {
+		info.ProcessingTime = time.Duration(ms) * time.Millisecond
+	}
+	return info
+}
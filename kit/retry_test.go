@@ -0,0 +1,91 @@
+package kit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mhrlife/goai-kit/internal/callback"
+	"github.com/openai/openai-go/option"
+	"github.com/stretchr/testify/require"
+)
+
+type retryRecorder struct {
+	callback.BaseCallback
+	retries     []map[string]interface{}
+	generations []map[string]interface{}
+}
+
+func (r *retryRecorder) Name() string { return "retryRecorder" }
+
+func (r *retryRecorder) OnRetry(ctx map[string]interface{}) { r.retries = append(r.retries, ctx) }
+
+func (r *retryRecorder) OnGenerationEnd(ctx map[string]interface{}) {
+	r.generations = append(r.generations, ctx)
+}
+
+func TestRetryFallsBackToNextModel(t *testing.T) {
+	var models []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Model string `json:"model"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		models = append(models, body.Model)
+
+		w.Header().Set("Content-Type", "application/json")
+		if body.Model == "primary" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(`{"error":{"message":"overloaded"}}`))
+			return
+		}
+		w.Header().Set("x-request-id", "req_123")
+		w.Header().Set("openai-processing-ms", "42")
+		w.Header().Set("x-ratelimit-remaining-requests", "99")
+		w.Header().Set("x-ratelimit-reset-tokens", "6m0s")
+		_, _ = w.Write([]byte(`{"id":"1","object":"chat.completion","model":"fallback","choices":[` +
+			`{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":"done"}}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(
+		WithAPIKey("test"),
+		WithBaseURL(server.URL),
+		WithRequestOptions(option.WithMaxRetries(0)),
+	)
+	recorder := &retryRecorder{}
+	agent := CreateAgent(client).
+		WithModel("primary").
+		WithCallbacks(recorder).
+		WithRetry(RetryPolicy{
+			MaxAttempts:    2,
+			InitialBackoff: time.Millisecond,
+			FallbackModels: []string{"fallback"},
+		})
+
+	output, err := agent.Invoke(context.Background(), InvokeConfig{Prompt: "hi"})
+	require.NoError(t, err)
+	require.Equal(t, "done", output)
+	require.Equal(t, []string{"primary", "primary", "fallback"}, models)
+
+	require.Len(t, recorder.retries, 2)
+	require.Equal(t, "server_error", recorder.retries[0]["error_class"])
+	require.Equal(t, 1, recorder.retries[0]["attempt"])
+	require.Equal(t, "primary", recorder.retries[0]["model"])
+	require.Equal(t, 2, recorder.retries[1]["attempt"])
+	require.Equal(t, "fallback", recorder.retries[1]["model"])
+
+	require.Len(t, recorder.generations, 1)
+	require.Equal(t, "fallback", recorder.generations[0]["model"])
+	require.Equal(t, "req_123", recorder.generations[0]["request_id"])
+	require.Equal(t, int64(42), recorder.generations[0]["processing_ms"])
+
+	limits, ok := client.RateLimits()
+	require.True(t, ok)
+	require.Equal(t, int64(99), limits.RemainingRequests)
+	require.Equal(t, 6*time.Minute, limits.ResetTokens)
+	require.Equal(t, int64(99), recorder.generations[0]["rate_limits"].(*RateLimits).RemainingRequests)
+}
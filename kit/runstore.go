@@ -0,0 +1,165 @@
+package kit
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/mhrlife/goai-kit/internal/runs"
+)
+
+// WithRunStore records every run of the agent (config, messages, usage and outcome) in the store
+func (a *Agent[Output]) WithRunStore(store runs.Store) *Agent[Output] {
+	a.runStore = store
+	return a
+}
+
+// recordRun saves the run in the run store, if one is configured.
+// Persistence failures are logged and never fail the run itself.
+func (a *Agent[Output]) recordRun(
+	ctx context.Context,
+	config InvokeConfig,
+	startedAt time.Time,
+	result *InvokeResult[Output],
+	runErr error,
+) {
+	if a.runStore == nil {
+		return
+	}
+
+	maxIter := a.maxIterations
+	if config.MaxIterations != nil {
+		maxIter = *config.MaxIterations
+	}
+
+	run := &runs.Run{
+		ID:             result.RunID,
+		ConversationID: config.ConversationID,
+		User:           config.User,
+		IdempotencyKey: config.IdempotencyKey,
+		Agent:          a.name,
+		Model:          a.runModel(config),
+		SystemPrompt:   config.SystemPrompt,
+		MaxIterations:  maxIter,
+		Messages:       result.Messages,
+		Status:         runs.StatusSucceeded,
+		Iterations:     result.Iterations,
+		Usage:          result.Usage,
+		StartedAt:      startedAt,
+		EndedAt:        time.Now(),
+	}
+
+	if config.ParentRunID != nil {
+		run.ParentID = *config.ParentRunID
+	}
+
+	if runErr != nil {
+		run.Status = runs.StatusFailed
+		run.Error = runErr.Error()
+	} else if output, err := a.encodeOutput(result.Output); err == nil {
+		run.Output = output
+	}
+
+	// Use a context that outlives a cancelled or timed out run so failures are still recorded
+	if err := a.runStore.Save(context.WithoutCancel(ctx), run); err != nil {
+		a.client.Logger.Error("Failed to record run", "run_id", run.ID, "error", err)
+	}
+}
+
+// idempotencyKeys reserves the idempotency keys of the runs in flight, so that concurrent
+// submissions with the same key wait for the first one instead of running the agent again
+type idempotencyKeys struct {
+	mu       sync.Mutex
+	inflight map[string]chan struct{} // closed when the run holding the key ends
+}
+
+// reserveIdempotencyKey returns the result of the last successful run recorded with the key or,
+// when there is none, reserves the key until the returned release function is called. Callers
+// with a key already reserved by a run of the agent wait for it to end first.
+func (a *Agent[Output]) reserveIdempotencyKey(
+	ctx context.Context,
+	idempotencyKey string,
+) (*InvokeResult[Output], func(), error) {
+	if idempotencyKey == "" || a.runStore == nil {
+		return nil, func() {}, nil
+	}
+
+	keys := a.idempotency
+	for {
+		keys.mu.Lock()
+		done, inflight := keys.inflight[idempotencyKey]
+		if !inflight {
+			done = make(chan struct{})
+			keys.inflight[idempotencyKey] = done
+		}
+		keys.mu.Unlock()
+		if !inflight {
+			break
+		}
+
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		}
+	}
+
+	release := func() {
+		keys.mu.Lock()
+		defer keys.mu.Unlock()
+
+		close(keys.inflight[idempotencyKey])
+		delete(keys.inflight, idempotencyKey)
+	}
+
+	previous, err := a.previousRun(ctx, idempotencyKey)
+	if err != nil || previous != nil {
+		release()
+		return previous, nil, err
+	}
+	return nil, release, nil
+}
+
+// previousRun returns the result of the last successful run of the agent recorded with the
+// idempotency key, so agents sharing a store keep their keys apart by their names. It returns
+// nil when there is no key, no run store or no matching run.
+func (a *Agent[Output]) previousRun(ctx context.Context, idempotencyKey string) (*InvokeResult[Output], error) {
+	if idempotencyKey == "" || a.runStore == nil {
+		return nil, nil
+	}
+
+	found, err := a.runStore.Query(ctx, runs.Query{
+		IdempotencyKey: idempotencyKey,
+		Agent:          a.name,
+		Status:         runs.StatusSucceeded,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up idempotency key: %w", err)
+	}
+
+	// Runs are returned oldest first; an empty agent name doesn't filter the query, so the runs
+	// of named agents are skipped here for unnamed ones
+	found = slices.DeleteFunc(found, func(run *runs.Run) bool { return run.Agent != a.name })
+	if len(found) == 0 {
+		return nil, nil
+	}
+
+	run := found[len(found)-1]
+	result := &InvokeResult[Output]{
+		RunID:      run.ID,
+		Messages:   run.Messages,
+		Iterations: run.Iterations,
+		Usage:      run.Usage,
+	}
+	if err := a.decodeOutput(run.Output, &result.Output); err != nil {
+		return nil, fmt.Errorf("failed to decode stored output for idempotency key: %w", err)
+	}
+
+	a.client.Logger.Debug("Returning stored run for idempotency key",
+		"idempotency_key", idempotencyKey,
+		"run_id", run.ID,
+	)
+	return result, nil
+}
@@ -0,0 +1,104 @@
+package kit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mhrlife/goai-kit/internal/runs"
+	"github.com/openai/openai-go/option"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdempotencyKeyReturnsNewestRun(t *testing.T) {
+	store := runs.NewMemoryStore()
+	now := time.Now()
+	for i, output := range []string{"first", "second"} {
+		data, _ := json.Marshal(output)
+		require.NoError(t, store.Save(context.Background(), &runs.Run{
+			ID:             output,
+			IdempotencyKey: "order-1",
+			Status:         runs.StatusSucceeded,
+			Output:         data,
+			StartedAt:      now.Add(time.Duration(i) * time.Second),
+		}))
+	}
+
+	client := NewClient(WithAPIKey("test"))
+	result, err := CreateAgent(client).WithRunStore(store).
+		InvokeDetailed(context.Background(), InvokeConfig{Prompt: "hi", IdempotencyKey: "order-1"})
+	require.NoError(t, err)
+	require.Equal(t, "second", result.RunID)
+	require.Equal(t, "second", result.Output)
+
+	// Agents sharing the store only get their own runs for the key
+	data, _ := json.Marshal("billed")
+	require.NoError(t, store.Save(context.Background(), &runs.Run{
+		ID:             "billed",
+		IdempotencyKey: "order-1",
+		Agent:          "billing",
+		Status:         runs.StatusSucceeded,
+		Output:         data,
+		StartedAt:      now.Add(2 * time.Second),
+	}))
+	result, err = CreateAgent(client).WithName("billing").WithRunStore(store).
+		InvokeDetailed(context.Background(), InvokeConfig{Prompt: "hi", IdempotencyKey: "order-1"})
+	require.NoError(t, err)
+	require.Equal(t, "billed", result.Output)
+
+	result, err = CreateAgent(client).WithRunStore(store).
+		InvokeDetailed(context.Background(), InvokeConfig{Prompt: "hi", IdempotencyKey: "order-1"})
+	require.NoError(t, err)
+	require.Equal(t, "second", result.Output)
+}
+
+func TestIdempotencyKeyConcurrentSubmissions(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		time.Sleep(50 * time.Millisecond)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"1","object":"chat.completion","choices":[` +
+			`{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":"charged"}}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("test"), WithBaseURL(server.URL), WithRequestOptions(option.WithMaxRetries(0)))
+	agent := CreateAgent(client).WithRunStore(runs.NewMemoryStore())
+
+	// Submissions racing the first one wait for it and return its result
+	var wg sync.WaitGroup
+	runIDs := make(chan string, 5)
+	errs := make(chan error, 5)
+	for range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := agent.InvokeDetailed(context.Background(), InvokeConfig{Prompt: "charge", IdempotencyKey: "order-1"})
+			if err != nil {
+				errs <- err
+				return
+			}
+			runIDs <- result.RunID
+		}()
+	}
+	wg.Wait()
+	close(runIDs)
+	close(errs)
+
+	for err := range errs {
+		require.NoError(t, err)
+	}
+	require.Equal(t, int32(1), requests.Load())
+
+	seen := map[string]bool{}
+	for id := range runIDs {
+		seen[id] = true
+	}
+	require.Len(t, seen, 1)
+}
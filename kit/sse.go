@@ -0,0 +1,138 @@
+package kit
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// SSEOptions configures WriteSSE. All fields are optional.
+type SSEOptions struct {
+	// Heartbeat is the interval of the keep-alive comments sent while the run is idle, e.g.
+	// during long tool calls (defaults to 15s, negative disables them)
+	Heartbeat time.Duration
+}
+
+// sseToken, sseToolCall, sseDone and sseError are the data payloads of the SSE events
+type sseToken struct {
+	Delta string `json:"delta"`
+}
+
+type sseToolCallDelta struct {
+	ToolName   string `json:"tool_name"`
+	ToolCallID string `json:"tool_call_id"`
+	Delta      string `json:"delta"`
+}
+
+type sseToolCall struct {
+	ToolName   string                 `json:"tool_name"`
+	ToolCallID string                 `json:"tool_call_id"`
+	Arguments  map[string]interface{} `json:"arguments,omitempty"`
+	Result     interface{}            `json:"result,omitempty"`
+	Error      string                 `json:"error,omitempty"`
+}
+
+type sseOutputField struct {
+	Field string          `json:"field"`
+	Value json.RawMessage `json:"value"`
+}
+
+type sseDone[Output any] struct {
+	RunID  string `json:"run_id"`
+	Output Output `json:"output"`
+}
+
+type sseError struct {
+	Error string `json:"error"`
+}
+
+// WriteSSE writes the events of a streamed run as Server-Sent Events, one event per StreamEvent
+// named after its type (token, tool_call_delta, tool_call_start, tool_call_end, progress,
+// output_field, done, error) with a JSON payload.
+// It returns when the stream is closed or the client is gone.
+func WriteSSE[Output any](w http.ResponseWriter, events <-chan StreamEvent[Output], opts SSEOptions) error {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return fmt.Errorf("response writer does not support flushing")
+	}
+
+	header := w.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	header.Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	interval := opts.Heartbeat
+	if interval == 0 {
+		interval = 15 * time.Second
+	}
+	var heartbeat <-chan time.Time
+	if interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		heartbeat = ticker.C
+	}
+
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return nil
+			}
+			if err := writeSSEEvent(w, event); err != nil {
+				return err
+			}
+		case <-heartbeat:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return err
+			}
+		}
+		flusher.Flush()
+	}
+}
+
+// ServeSSE streams a run of the agent to the client as Server-Sent Events. The run is canceled
+// when the client disconnects.
+func (a *Agent[Output]) ServeSSE(w http.ResponseWriter, r *http.Request, config InvokeConfig) error {
+	return WriteSSE(w, a.Stream(r.Context(), config), SSEOptions{})
+}
+
+// writeSSEEvent writes a single event
+func writeSSEEvent[Output any](w http.ResponseWriter, event StreamEvent[Output]) error {
+	var data any
+	switch event.Type {
+	case StreamEventToken:
+		data = sseToken{Delta: event.Delta}
+	case StreamEventToolCallDelta:
+		data = sseToolCallDelta{ToolName: event.ToolName, ToolCallID: event.ToolCallID, Delta: event.Delta}
+	case StreamEventToolCallStart, StreamEventToolCallEnd:
+		call := sseToolCall{
+			ToolName:   event.ToolName,
+			ToolCallID: event.ToolCallID,
+			Arguments:  event.Arguments,
+			Result:     event.ToolResult,
+		}
+		if event.Error != nil {
+			call.Error = event.Error.Error()
+		}
+		data = call
+	case StreamEventProgress:
+		data = event.Progress
+	case StreamEventOutputField:
+		data = sseOutputField{Field: event.Field, Value: event.FieldValue}
+	case StreamEventDone:
+		data = sseDone[Output]{RunID: event.Run.RunID, Output: event.Run.Output}
+	case StreamEventError:
+		data = sseError{Error: event.Error.Error()}
+	}
+
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s event: %w", event.Type, err)
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, payload)
+	return err
+}
@@ -0,0 +1,46 @@
+package kit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/openai/openai-go/option"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServeSSE(t *testing.T) {
+	agent := CreateAgent(streamingClient(t))
+
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPost, "/chat", nil)
+	require.NoError(t, agent.ServeSSE(recorder, request, InvokeConfig{Prompt: "hi"}))
+
+	require.Equal(t, "text/event-stream", recorder.Header().Get("Content-Type"))
+	body := recorder.Body.String()
+	require.Contains(t, body, "event: token\ndata: {\"delta\":\"Hel\"}\n\n")
+	require.Contains(t, body, "event: token\ndata: {\"delta\":\"lo\"}\n\n")
+	require.True(t, strings.HasSuffix(body, "\"output\":\"Hello\"}\n\n"), body)
+}
+
+// streamingClient returns a client whose completions stream "Hel" and "lo"
+func streamingClient(t *testing.T) *Client {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, chunk := range []string{
+			`{"id":"1","object":"chat.completion.chunk","choices":[{"index":0,"delta":{"role":"assistant","content":"Hel"}}]}`,
+			`{"id":"1","object":"chat.completion.chunk","choices":[{"index":0,"delta":{"content":"lo"},"finish_reason":"stop"}]}`,
+			`[DONE]`,
+		} {
+			_, _ = w.Write([]byte("data: " + chunk + "\n\n"))
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return NewClient(
+		WithAPIKey("test"),
+		WithBaseURL(server.URL),
+		WithRequestOptions(option.WithMaxRetries(0)),
+	)
+}
@@ -0,0 +1,79 @@
+package kit
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/openai/openai-go"
+)
+
+// ErrStopped is returned (wrapped) when a stop condition ends the run before a parseable output exists
+var ErrStopped = errors.New("run stopped by stop condition")
+
+// LoopState is a snapshot of the agent loop passed to stop conditions after each iteration
+type LoopState struct {
+	// Iteration is the 1-based number of the iteration that just completed
+	Iteration int
+
+	// Messages is the full message history so far, tool results included
+	Messages []openai.ChatCompletionMessageParamUnion
+
+	// Content is the assistant content of the last generation
+	Content string
+
+	// ToolCalls are the tool calls requested in the last generation
+	ToolCalls []openai.ChatCompletionMessageToolCall
+
+	// Usage is the accumulated token usage of the run so far
+	Usage openai.CompletionUsage
+}
+
+// StopCondition decides whether the loop should stop after an iteration
+type StopCondition func(state LoopState) bool
+
+// WithStopCondition adds conditions evaluated after each tool calling iteration.
+// The run stops as soon as any condition returns true.
+func (a *Agent[Output]) WithStopCondition(conditions ...StopCondition) *Agent[Output] {
+	a.stopConds = append(a.stopConds, conditions...)
+	return a
+}
+
+// shouldStop reports whether any registered stop condition is met
+func (a *Agent[Output]) shouldStop(state LoopState) bool {
+	for _, condition := range a.stopConds {
+		if condition(state) {
+			return true
+		}
+	}
+	return false
+}
+
+// stopOutput builds the output of a stopped run from the last assistant content
+func stopOutput[Output any](content string) (Output, error) {
+	result, err := parseOutput[Output](content)
+	if err != nil {
+		return result, fmt.Errorf("%w: %w", ErrStopped, err)
+	}
+	return result, nil
+}
+
+// StopOnTool stops the run after any of the named tools has been called
+func StopOnTool(names ...string) StopCondition {
+	return func(state LoopState) bool {
+		for _, call := range state.ToolCalls {
+			for _, name := range names {
+				if call.Function.Name == name {
+					return true
+				}
+			}
+		}
+		return false
+	}
+}
+
+// StopOnTokens stops the run once the accumulated total tokens reach the limit
+func StopOnTokens(limit int64) StopCondition {
+	return func(state LoopState) bool {
+		return state.Usage.TotalTokens >= limit
+	}
+}
@@ -0,0 +1,64 @@
+package kit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/openai/openai-go/option"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStopConditions(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"1","object":"chat.completion","choices":[{"index":0,"finish_reason":"tool_calls",` +
+			`"message":{"role":"assistant","content":"still looking","tool_calls":[{"id":"call_1","type":"function",` +
+			`"function":{"name":"lookup_tool","arguments":"{}"}}]}}],` +
+			`"usage":{"prompt_tokens":30,"completion_tokens":10,"total_tokens":40}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("test"), WithBaseURL(server.URL), WithRequestOptions(option.WithMaxRetries(0)))
+	invoke := func(conditions ...StopCondition) (*InvokeResult[string], error) {
+		requests.Store(0)
+		return CreateAgent(client, &lookupTool{}).
+			WithMaxIterations(10).
+			WithStopCondition(conditions...).
+			InvokeDetailed(context.Background(), InvokeConfig{Prompt: "hi"})
+	}
+
+	// The tool calls of the stopping iteration are still executed
+	result, err := invoke(StopOnTool("other_tool", "lookup_tool"))
+	require.NoError(t, err)
+	require.Equal(t, "still looking", result.Output)
+	require.Equal(t, int32(1), requests.Load())
+	require.NotNil(t, result.Messages[len(result.Messages)-1].OfTool)
+
+	_, err = invoke(StopOnTokens(100))
+	require.NoError(t, err)
+	require.Equal(t, int32(3), requests.Load())
+
+	var states []LoopState
+	_, err = invoke(func(state LoopState) bool {
+		states = append(states, state)
+		return state.Iteration == 2
+	})
+	require.NoError(t, err)
+	require.Len(t, states, 2)
+	require.Equal(t, int64(80), states[1].Usage.TotalTokens)
+	require.Equal(t, "lookup_tool", states[1].ToolCalls[0].Function.Name)
+
+	// A typed run stopped without a parseable output fails
+	type answer struct {
+		Found bool `json:"found"`
+	}
+	_, err = CreateAgentWithOutput[answer](client, &lookupTool{}).
+		WithStopCondition(StopOnTool("lookup_tool")).
+		Invoke(context.Background(), InvokeConfig{Prompt: "hi"})
+	require.ErrorIs(t, err, ErrStopped)
+}
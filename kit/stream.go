@@ -0,0 +1,242 @@
+package kit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/mhrlife/goai-kit/internal/callback"
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
+)
+
+// StreamEventType identifies the kind of a StreamEvent
+type StreamEventType string
+
+const (
+	StreamEventToken         StreamEventType = "token"           // a content delta of a generation
+	StreamEventToolCallDelta StreamEventType = "tool_call_delta" // a fragment of a tool call's arguments as generated
+	StreamEventToolCallStart StreamEventType = "tool_call_start" // a tool is about to run
+	StreamEventToolCallEnd   StreamEventType = "tool_call_end"   // a tool finished, possibly with an error
+	StreamEventProgress      StreamEventType = "progress"        // an iteration started or a tool reported progress
+	StreamEventOutputField   StreamEventType = "output_field"    // a top-level field of the structured output is complete
+	StreamEventDone          StreamEventType = "done"            // the run completed; always the last event on success
+	StreamEventError         StreamEventType = "error"           // the run failed; always the last event on failure
+)
+
+// StreamEvent is an event of a streamed run
+type StreamEvent[Output any] struct {
+	Type StreamEventType
+
+	// Delta is the content delta (token) or the arguments fragment (tool_call_delta)
+	Delta string
+
+	// ToolName, ToolCallID and Arguments describe the tool call (tool_call_delta without Arguments,
+	// tool_call_start, tool_call_end)
+	ToolName   string
+	ToolCallID string
+	Arguments  map[string]interface{}
+
+	// ToolResult is the tool's result (tool_call_end)
+	ToolResult interface{}
+
+	// Progress is the progress of the run (progress)
+	Progress Progress
+
+	// Field and FieldValue are the name and JSON value of a completed output field (output_field)
+	Field      string
+	FieldValue json.RawMessage
+
+	// Error is the tool error (tool_call_end) or the run error (error)
+	Error error
+
+	// Run is the result of the completed run (done), or the partial state of a cancelled one (error)
+	Run *InvokeResult[Output]
+}
+
+// Stream executes the agent like InvokeDetailed, streaming the generated tokens and tool calls.
+// The channel is closed after the done or error event. Cancel ctx to abandon the run; the
+// events are then dropped.
+func (a *Agent[Output]) Stream(ctx context.Context, config InvokeConfig) <-chan StreamEvent[Output] {
+	events := make(chan StreamEvent[Output], 16)
+	send := func(event StreamEvent[Output]) {
+		select {
+		case events <- event:
+		case <-ctx.Done():
+		}
+	}
+
+	config.Callbacks = append(append([]callback.AgentCallback(nil), config.Callbacks...), &streamCallback[Output]{send: send})
+	streamCtx := context.WithValue(ctx, streamSinkKey{}, &streamSink{
+		token: func(delta string) {
+			send(StreamEvent[Output]{Type: StreamEventToken, Delta: delta})
+		},
+		toolCall: func(toolCallID, toolName, delta string) {
+			send(StreamEvent[Output]{Type: StreamEventToolCallDelta, ToolCallID: toolCallID, ToolName: toolName, Delta: delta})
+		},
+	})
+
+	go func() {
+		defer close(events)
+
+		result, err := a.InvokeDetailed(streamCtx, config)
+		if err != nil {
+			send(StreamEvent[Output]{Type: StreamEventError, Error: err, Run: result})
+			return
+		}
+		send(StreamEvent[Output]{Type: StreamEventDone, Run: result})
+	}()
+
+	return events
+}
+
+// InvokeStream executes the agent through the streaming completions endpoint, like Stream: the
+// channel delivers the content deltas, tool call fragments and tool calls as they are generated,
+// and the done event carries the final parsed Output.
+func (a *Agent[Output]) InvokeStream(ctx context.Context, config InvokeConfig) <-chan StreamEvent[Output] {
+	return a.Stream(ctx, config)
+}
+
+// streamSinkKey is the context key of the streamSink of a streamed run
+type streamSinkKey struct{}
+
+// streamSink receives the deltas of the generations of a streamed run
+type streamSink struct {
+	token    func(delta string)
+	toolCall func(toolCallID, toolName, delta string)
+}
+
+// streamCallback forwards the tool calls of a streamed run as events
+type streamCallback[Output any] struct {
+	callback.BaseCallback
+	send func(StreamEvent[Output])
+}
+
+func (s *streamCallback[Output]) Name() string {
+	return "StreamCallback"
+}
+
+func (s *streamCallback[Output]) OnToolCallStart(ctx map[string]interface{}) {
+	s.send(StreamEvent[Output]{
+		Type:       StreamEventToolCallStart,
+		ToolName:   stringValue(ctx["tool_name"]),
+		ToolCallID: stringValue(ctx["tool_call_id"]),
+		Arguments:  mapValue(ctx["arguments"]),
+	})
+}
+
+func (s *streamCallback[Output]) OnToolCallEnd(ctx map[string]interface{}) {
+	// Callbacks receive the message of the error, which is turned back into an error for the event
+	var err error
+	if message := stringValue(ctx["error"]); message != "" {
+		err = errors.New(message)
+	}
+	s.send(StreamEvent[Output]{
+		Type:       StreamEventToolCallEnd,
+		ToolName:   stringValue(ctx["tool_name"]),
+		ToolCallID: stringValue(ctx["tool_call_id"]),
+		Arguments:  mapValue(ctx["arguments"]),
+		ToolResult: ctx["result"],
+		Error:      err,
+	})
+}
+
+func (s *streamCallback[Output]) OnProgress(ctx map[string]interface{}) {
+	step, _ := ctx["step"].(int)
+	totalSteps, _ := ctx["total_steps"].(int)
+	percent, _ := ctx["percent"].(float64)
+	s.send(StreamEvent[Output]{
+		Type: StreamEventProgress,
+		Progress: Progress{
+			Step:       step,
+			TotalSteps: totalSteps,
+			Percent:    percent,
+			Message:    stringValue(ctx["message"]),
+			Tool:       stringValue(ctx["tool_name"]),
+		},
+	})
+}
+
+func (s *streamCallback[Output]) OnOutputField(ctx map[string]interface{}) {
+	value, _ := ctx["value"].(json.RawMessage)
+	s.send(StreamEvent[Output]{
+		Type:       StreamEventOutputField,
+		Field:      stringValue(ctx["field"]),
+		FieldValue: value,
+	})
+}
+
+func stringValue(v interface{}) string {
+	s, _ := v.(string)
+	return s
+}
+
+func mapValue(v interface{}) map[string]interface{} {
+	m, _ := v.(map[string]interface{})
+	return m
+}
+
+// complete performs a single chat completion, streaming the content deltas to the run's token
+// sink when the run is streamed
+func (a *Agent[Output]) complete(
+	ctx context.Context,
+	params openai.ChatCompletionNewParams,
+	opts ...option.RequestOption,
+) (*openai.ChatCompletion, error) {
+	sink, ok := ctx.Value(streamSinkKey{}).(*streamSink)
+	if !ok {
+		return a.client.complete(ctx, params, opts...)
+	}
+
+	provider, ok := a.client.provider.(StreamingProvider)
+	if !ok {
+		completion, err := a.client.complete(ctx, params, opts...)
+		if err == nil && len(completion.Choices) > 0 && completion.Choices[0].Message.Content != "" {
+			sink.token(completion.Choices[0].Message.Content)
+			newOutputFieldScanner(ctx).write(completion.Choices[0].Message.Content)
+		}
+		return completion, err
+	}
+
+	params.StreamOptions = openai.ChatCompletionStreamOptionsParam{IncludeUsage: openai.Bool(true)}
+	stream := provider.CompleteStreaming(ctx, params, opts...)
+	defer stream.Close()
+
+	var acc openai.ChatCompletionAccumulator
+	fields := newOutputFieldScanner(ctx)
+	toolCalls := make(map[int64]openai.ChatCompletionChunkChoiceDeltaToolCall) // index -> first fragment
+	for stream.Next() {
+		chunk := stream.Current()
+		acc.AddChunk(chunk)
+		for _, choice := range chunk.Choices {
+			if choice.Index != 0 {
+				continue
+			}
+			if choice.Delta.Content != "" {
+				sink.token(choice.Delta.Content)
+				fields.write(choice.Delta.Content)
+			}
+
+			// Only the first fragment of a tool call carries its ID and name
+			for _, fragment := range choice.Delta.ToolCalls {
+				first, seen := toolCalls[fragment.Index]
+				if !seen {
+					first = fragment
+					toolCalls[fragment.Index] = first
+				}
+				if fragment.Function.Arguments != "" {
+					sink.toolCall(first.ID, first.Function.Name, fragment.Function.Arguments)
+				}
+			}
+		}
+	}
+	if err := stream.Err(); err != nil {
+		return nil, err
+	}
+	if len(acc.Choices) == 0 {
+		return nil, fmt.Errorf("empty completion stream")
+	}
+	a.client.recordUsage(params.Model, &acc.ChatCompletion)
+	return &acc.ChatCompletion, nil
+}
@@ -0,0 +1,101 @@
+package kit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/openai/openai-go/option"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvokeStreamToolCallFragments(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		chunks := []string{
+			`{"id":"1","object":"chat.completion.chunk","choices":[{"index":0,"delta":{"role":"assistant","content":"Done"}}]}`,
+			`{"id":"1","object":"chat.completion.chunk","choices":[{"index":0,"delta":{"content":"."},"finish_reason":"stop"}]}`,
+		}
+		if requests.Add(1) == 1 {
+			chunks = []string{
+				`{"id":"1","object":"chat.completion.chunk","choices":[{"index":0,"delta":{"role":"assistant","tool_calls":` +
+					`[{"index":0,"id":"call_1","type":"function","function":{"name":"lookup_tool","arguments":"{\"q\":"}}]}}]}`,
+				`{"id":"1","object":"chat.completion.chunk","choices":[{"index":0,"delta":{"tool_calls":` +
+					`[{"index":0,"function":{"arguments":"\"go\"}"}}]},"finish_reason":"tool_calls"}]}`,
+			}
+		}
+		for _, chunk := range append(chunks, `[DONE]`) {
+			_, _ = w.Write([]byte("data: " + chunk + "\n\n"))
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(
+		WithAPIKey("test"),
+		WithBaseURL(server.URL),
+		WithRequestOptions(option.WithMaxRetries(0)),
+	)
+
+	var fragments, tokens string
+	var done *InvokeResult[string]
+	for event := range CreateAgent(client, &lookupTool{}).InvokeStream(context.Background(), InvokeConfig{Prompt: "hi"}) {
+		switch event.Type {
+		case StreamEventToolCallDelta:
+			require.Equal(t, "call_1", event.ToolCallID)
+			require.Equal(t, "lookup_tool", event.ToolName)
+			fragments += event.Delta
+		case StreamEventToken:
+			tokens += event.Delta
+		case StreamEventDone:
+			done = event.Run
+		case StreamEventError:
+			require.NoError(t, event.Error)
+		}
+	}
+
+	require.Equal(t, `{"q":"go"}`, fragments)
+	require.Equal(t, "Done.", tokens)
+	require.NotNil(t, done)
+	require.Equal(t, "Done.", done.Output)
+}
+
+func TestInvokeStreamToolCallError(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		chunks := []string{
+			`{"id":"1","object":"chat.completion.chunk","choices":[{"index":0,"delta":{"role":"assistant","content":"Sorry"},"finish_reason":"stop"}]}`,
+		}
+		if requests.Add(1) == 1 {
+			chunks = []string{
+				`{"id":"1","object":"chat.completion.chunk","choices":[{"index":0,"delta":{"role":"assistant","tool_calls":` +
+					`[{"index":0,"id":"call_1","type":"function","function":{"name":"failing_tool","arguments":"{}"}}]},` +
+					`"finish_reason":"tool_calls"}]}`,
+			}
+		}
+		for _, chunk := range append(chunks, `[DONE]`) {
+			_, _ = w.Write([]byte("data: " + chunk + "\n\n"))
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("test"), WithBaseURL(server.URL), WithRequestOptions(option.WithMaxRetries(0)))
+	agent := CreateAgent(client, &failingTool{}).WithToolErrorHandling(ReturnToModel)
+
+	var ends []StreamEvent[string]
+	for event := range agent.InvokeStream(context.Background(), InvokeConfig{Prompt: "hi"}) {
+		switch event.Type {
+		case StreamEventToolCallEnd:
+			ends = append(ends, event)
+		case StreamEventError:
+			require.NoError(t, event.Error)
+		}
+	}
+
+	require.Len(t, ends, 1)
+	require.Equal(t, "failing_tool", ends[0].ToolName)
+	require.EqualError(t, ends[0].Error, "disk full")
+}
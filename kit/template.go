@@ -0,0 +1,96 @@
+package kit
+
+import (
+	"fmt"
+
+	"github.com/mhrlife/goai-kit/internal/prompt"
+)
+
+// SystemTemplate selects a registered template to render as the system prompt
+type SystemTemplate struct {
+	// Name of the template (the file name, with or without extension)
+	Name string
+
+	// Data is exposed to the template as .Data. A prompt.Render value is used as is.
+	Data any
+}
+
+// TemplateRenderer renders named templates; see TemplatesFrom for the prompt package adapter
+type TemplateRenderer interface {
+	Render(name string, data any) (string, error)
+}
+
+// TemplatesFrom adapts a loaded prompt.Template into a TemplateRenderer.
+// templateContext is exposed as .Context unless the data is already a prompt.Render.
+func TemplatesFrom[Context any](tpl prompt.Template[Context], templateContext Context) TemplateRenderer {
+	return &promptRenderer[Context]{
+		tpl:     tpl,
+		context: templateContext,
+	}
+}
+
+type promptRenderer[Context any] struct {
+	tpl     prompt.Template[Context]
+	context Context
+}
+
+func (r *promptRenderer[Context]) Render(name string, data any) (string, error) {
+	if render, ok := data.(prompt.Render[Context]); ok {
+		return r.tpl.Execute(name, render)
+	}
+
+	return r.tpl.Execute(name, prompt.Render[Context]{
+		Context: r.context,
+		Data:    data,
+	})
+}
+
+// WithTemplates registers the templates used to render InvokeConfig.SystemTemplate
+func (a *Agent[Output]) WithTemplates(templates TemplateRenderer) *Agent[Output] {
+	a.templates = templates
+	return a
+}
+
+// WithSystemPrompt sets the system prompt used when an invocation doesn't provide one
+func (a *Agent[Output]) WithSystemPrompt(systemPrompt string) *Agent[Output] {
+	a.systemPrompt = systemPrompt
+	return a
+}
+
+// WithSystemTemplate sets the system template used when an invocation doesn't provide a
+// system prompt or template
+func (a *Agent[Output]) WithSystemTemplate(template SystemTemplate) *Agent[Output] {
+	a.systemTemplate = &template
+	return a
+}
+
+// resolveSystemTemplate applies the agent's system defaults and renders config.SystemTemplate
+// into config.SystemPrompt
+func (a *Agent[Output]) resolveSystemTemplate(config *InvokeConfig) error {
+	if config.SystemPrompt == "" && config.SystemTemplate == nil {
+		config.SystemPrompt = a.systemPrompt
+		if config.SystemPrompt == "" {
+			config.SystemTemplate = a.systemTemplate
+		}
+	}
+
+	if config.SystemTemplate == nil {
+		return nil
+	}
+
+	if config.SystemPrompt != "" {
+		return fmt.Errorf("cannot specify both SystemPrompt and SystemTemplate")
+	}
+
+	if a.templates == nil {
+		return fmt.Errorf("system template %q requested but no templates are registered", config.SystemTemplate.Name)
+	}
+
+	rendered, err := a.templates.Render(config.SystemTemplate.Name, config.SystemTemplate.Data)
+	if err != nil {
+		return fmt.Errorf("failed to render system template %q: %w", config.SystemTemplate.Name, err)
+	}
+
+	config.SystemPrompt = rendered
+	return nil
+}
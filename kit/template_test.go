@@ -0,0 +1,71 @@
+package kit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/openai/openai-go/option"
+	"github.com/stretchr/testify/require"
+)
+
+type greetingTemplates struct{}
+
+func (greetingTemplates) Render(name string, data any) (string, error) {
+	if name != "greeter" {
+		return "", fmt.Errorf("template %q not found", name)
+	}
+	return fmt.Sprintf("You greet %v.", data), nil
+}
+
+func TestSystemTemplate(t *testing.T) {
+	var systemPrompts []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Messages []struct {
+				Role    string `json:"role"`
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		require.Equal(t, "system", body.Messages[0].Role)
+		systemPrompts = append(systemPrompts, body.Messages[0].Content)
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"1","object":"chat.completion","choices":[{"index":0,"finish_reason":"stop",` +
+			`"message":{"role":"assistant","content":"hello"}}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("test"), WithBaseURL(server.URL), WithRequestOptions(option.WithMaxRetries(0)))
+	agent := CreateAgent(client).
+		WithTemplates(greetingTemplates{}).
+		WithSystemTemplate(SystemTemplate{Name: "greeter", Data: "everyone"})
+
+	_, err := agent.Invoke(context.Background(), InvokeConfig{Prompt: "hi"})
+	require.NoError(t, err)
+	_, err = agent.Invoke(context.Background(), InvokeConfig{Prompt: "hi", SystemTemplate: &SystemTemplate{Name: "greeter", Data: "Alice"}})
+	require.NoError(t, err)
+
+	// An explicit system prompt wins over the agent's template
+	_, err = agent.Invoke(context.Background(), InvokeConfig{Prompt: "hi", SystemPrompt: "You are terse."})
+	require.NoError(t, err)
+	require.Equal(t, []string{"You greet everyone.", "You greet Alice.", "You are terse."}, systemPrompts)
+
+	_, err = agent.Invoke(context.Background(), InvokeConfig{
+		Prompt:         "hi",
+		SystemPrompt:   "You are terse.",
+		SystemTemplate: &SystemTemplate{Name: "greeter"},
+	})
+	require.ErrorContains(t, err, "cannot specify both SystemPrompt and SystemTemplate")
+
+	_, err = agent.Invoke(context.Background(), InvokeConfig{Prompt: "hi", SystemTemplate: &SystemTemplate{Name: "missing"}})
+	require.ErrorContains(t, err, `failed to render system template "missing"`)
+
+	_, err = CreateAgent(client).Invoke(context.Background(), InvokeConfig{Prompt: "hi", SystemTemplate: &SystemTemplate{Name: "greeter"}})
+	require.ErrorContains(t, err, "no templates are registered")
+	require.Len(t, systemPrompts, 3)
+}
@@ -0,0 +1,38 @@
+package kit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// TimeoutError is returned when a run exceeds InvokeConfig.Timeout
+type TimeoutError struct {
+	Timeout    time.Duration
+	Iterations int
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("invoke timeout (%s) exceeded after %d iterations", e.Timeout, e.Iterations)
+}
+
+// Unwrap lets callers match the error with errors.Is(err, context.DeadlineExceeded)
+func (e *TimeoutError) Unwrap() error {
+	return context.DeadlineExceeded
+}
+
+// timeoutCause replaces err with a TimeoutError when the run context expired because of
+// the invoke timeout rather than the caller's own context
+func timeoutCause(parent, runCtx context.Context, iterations int, err error) error {
+	if parent.Err() != nil {
+		return err
+	}
+
+	var timeoutErr *TimeoutError
+	if !errors.As(context.Cause(runCtx), &timeoutErr) {
+		return err
+	}
+
+	return &TimeoutError{Timeout: timeoutErr.Timeout, Iterations: iterations}
+}
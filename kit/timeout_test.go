@@ -0,0 +1,46 @@
+package kit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/openai/openai-go/option"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvokeTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(30 * time.Millisecond)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"1","object":"chat.completion","choices":[{"index":0,"finish_reason":"tool_calls",` +
+			`"message":{"role":"assistant","tool_calls":[{"id":"call_1","type":"function",` +
+			`"function":{"name":"lookup_tool","arguments":"{}"}}]}}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("test"), WithBaseURL(server.URL), WithRequestOptions(option.WithMaxRetries(0)))
+	agent := CreateAgent(client, &lookupTool{}).WithMaxIterations(100)
+
+	// The timeout bounds the whole run, not a single generation
+	startedAt := time.Now()
+	_, err := agent.Invoke(context.Background(), InvokeConfig{Prompt: "hi", Timeout: 100 * time.Millisecond})
+	require.Less(t, time.Since(startedAt), time.Second)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Equal(t, ErrorClassTimeout, ClassifyError(err))
+
+	var timeoutErr *TimeoutError
+	require.ErrorAs(t, err, &timeoutErr)
+	require.Equal(t, 100*time.Millisecond, timeoutErr.Timeout)
+	require.GreaterOrEqual(t, timeoutErr.Iterations, 1)
+
+	// The caller's own deadline isn't reported as the invoke timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = agent.Invoke(ctx, InvokeConfig{Prompt: "hi", Timeout: time.Minute})
+	require.Error(t, err)
+	require.False(t, errors.As(err, &timeoutErr))
+}
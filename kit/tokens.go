@@ -0,0 +1,133 @@
+package kit
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/openai/openai-go"
+)
+
+// Tokenizer counts the tokens of a text in the vocabulary of a model. The kit ships no model
+// vocabularies: token counts are estimates unless a tokenizer is registered for the model.
+type Tokenizer interface {
+	CountTokens(text string) int
+}
+
+// TokenizerFunc adapts a function to a Tokenizer, e.g. an encoding of a BPE library such as
+// tiktoken-go, which the application depends on itself:
+//
+//	encoding, _ := tiktoken.GetEncoding("o200k_base")
+//	kit.RegisterTokenizer("gpt-4o", kit.TokenizerFunc(func(text string) int {
+//		return len(encoding.Encode(text, nil, nil))
+//	}))
+type TokenizerFunc func(text string) int
+
+func (f TokenizerFunc) CountTokens(text string) int {
+	return f(text)
+}
+
+var (
+	tokenizersMu sync.RWMutex
+	tokenizers   = make(map[string]Tokenizer)
+)
+
+// RegisterTokenizer makes CountTokens and the token-based trimming of agents count the tokens
+// of the models whose name starts with prefix with tokenizer. The longest matching prefix
+// wins; models without a tokenizer are counted with approximateTokens, a heuristic that can be
+// off by tens of percent, so register a real tokenizer where exact counts matter.
+func RegisterTokenizer(prefix string, tokenizer Tokenizer) {
+	tokenizersMu.Lock()
+	defer tokenizersMu.Unlock()
+
+	tokenizers[prefix] = tokenizer
+}
+
+// tokenizerFor returns the tokenizer registered for the model, the heuristic if none is
+func tokenizerFor(model string) Tokenizer {
+	tokenizersMu.RLock()
+	defer tokenizersMu.RUnlock()
+
+	var tokenizer Tokenizer = TokenizerFunc(approximateTokens)
+	matched := -1
+	for _, name := range []string{model, model[strings.LastIndex(model, "/")+1:]} {
+		for prefix, registered := range tokenizers {
+			if strings.HasPrefix(name, prefix) && len(prefix) > matched {
+				tokenizer, matched = registered, len(prefix)
+			}
+		}
+	}
+	return tokenizer
+}
+
+// CountTokens estimates the prompt tokens of messages sent to model, the per-message overhead of
+// the chat format included, for budgeting prompts and estimating their cost before Invoke. The
+// count is only as exact as the tokenizer registered for the model, see RegisterTokenizer.
+func CountTokens(model string, messages []openai.ChatCompletionMessageParamUnion) int {
+	tokenizer := tokenizerFor(model)
+
+	tokens := 3 // every reply is primed with the assistant role
+	for _, msg := range messages {
+		tokens += messageTokens(tokenizer, msg)
+	}
+	return tokens
+}
+
+// estimateTokens counts the tokens of a single message sent to model
+func estimateTokens(model string, msg openai.ChatCompletionMessageParamUnion) int {
+	return messageTokens(tokenizerFor(model), msg)
+}
+
+// messageTokens counts the tokens of a message: its content, the tool calls it requests and
+// the role and separators of the chat format
+func messageTokens(tokenizer Tokenizer, msg openai.ChatCompletionMessageParamUnion) int {
+	tokens := 3 + tokenizer.CountTokens(MessageText(msg))
+	if msg.OfAssistant != nil {
+		for _, call := range msg.OfAssistant.ToolCalls {
+			tokens += 3 + tokenizer.CountTokens(call.Function.Name) + tokenizer.CountTokens(call.Function.Arguments)
+		}
+	}
+	return tokens
+}
+
+// approximateTokens roughly estimates the tokens of text, no vocabulary involved: ASCII words
+// of up to six characters are one token and longer ones a token per six characters, other
+// scripts take a token per two letters and every symbol is a token of its own
+func approximateTokens(text string) int {
+	tokens, ascii, other := 0, 0, 0
+	flush := func() {
+		tokens += (ascii+5)/6 + (other+1)/2
+		ascii, other = 0, 0
+	}
+
+	for _, r := range text {
+		switch {
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			ascii++
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r):
+			other++
+		case unicode.IsSpace(r):
+			flush()
+		default:
+			flush()
+			tokens++
+		}
+	}
+	flush()
+	return tokens
+}
+
+// tokenModelKey is the context key of the model the trim strategies count tokens for
+type tokenModelKey struct{}
+
+// withTokenModel makes the trim strategies running with ctx count the tokens of model
+func withTokenModel(ctx context.Context, model string) context.Context {
+	return context.WithValue(ctx, tokenModelKey{}, model)
+}
+
+// tokenModel returns the model the trim strategies running with ctx count tokens for
+func tokenModel(ctx context.Context) string {
+	model, _ := ctx.Value(tokenModelKey{}).(string)
+	return model
+}
@@ -0,0 +1,41 @@
+package kit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/openai/openai-go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCountTokens(t *testing.T) {
+	require.Equal(t, 1, approximateTokens("word"))
+	require.Equal(t, 2, approximateTokens("tokenizer"))
+	require.Equal(t, 4, approximateTokens("Hello, world!"))
+	require.Equal(t, 2, approximateTokens("سلام"))
+
+	messages := []openai.ChatCompletionMessageParamUnion{
+		openai.SystemMessage("Be brief."),
+		openai.UserMessage("Hello, world!"),
+	}
+	// Priming, then role and separators of each message with its content
+	require.Equal(t, 3+(3+3)+(3+4), CountTokens("gpt-4o", messages))
+
+	// A registered tokenizer counts the models of its prefix, gateway vendors included
+	RegisterTokenizer("test-chars", TokenizerFunc(func(text string) int { return len(text) }))
+	require.Equal(t, 3+(3+9)+(3+13), CountTokens("vendor/test-chars-1", messages))
+
+	// And the trim strategies count the tokens of the run's model
+	history := []openai.ChatCompletionMessageParamUnion{
+		openai.UserMessage("Hello, world!"),
+		openai.AssistantMessage("Hi."),
+		openai.UserMessage("How are you?"),
+	}
+	trimmed, err := trimWith(context.Background(), TokenWindow(20), "test-chars-1", history, func(string, int, int) {})
+	require.NoError(t, err)
+	require.Len(t, trimmed, 1)
+
+	trimmed, err = trimWith(context.Background(), TokenWindow(20), "gpt-4o", history, func(string, int, int) {})
+	require.NoError(t, err)
+	require.Len(t, trimmed, 3)
+}
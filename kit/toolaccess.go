@@ -0,0 +1,42 @@
+package kit
+
+import (
+	"context"
+	"slices"
+)
+
+// ToolPolicy decides whether a run may use a tool, e.g. by the role of the caller identified in
+// config (User, Metadata). Tools it denies are neither offered to the model nor executed.
+type ToolPolicy func(ctx context.Context, config InvokeConfig, tool AgentToolInfo) bool
+
+// WithToolPolicy restricts the tools of every run to those the policy allows
+func (a *Agent[Output]) WithToolPolicy(policy ToolPolicy) *Agent[Output] {
+	a.toolPolicy = policy
+	return a
+}
+
+// runSchemas returns the schemas of the tools the run may use, ordered by tool name: those in
+// config.AllowedTools, if set, and allowed by the tool policy
+func (a *Agent[Output]) runSchemas(ctx context.Context, config InvokeConfig) []ToolSchema {
+	schemas := a.sortedSchemas()
+	if config.AllowedTools == nil && a.toolPolicy == nil {
+		return schemas
+	}
+
+	return slices.DeleteFunc(schemas, func(toolSchema ToolSchema) bool {
+		if config.AllowedTools != nil && !slices.Contains(config.AllowedTools, toolSchema.Name) {
+			return true
+		}
+		info := AgentToolInfo{Name: toolSchema.Name, Description: toolSchema.Description}
+		return a.toolPolicy != nil && !a.toolPolicy(ctx, config, info)
+	})
+}
+
+// schemaNames returns the names of the tool schemas
+func schemaNames(schemas []ToolSchema) []string {
+	names := make([]string, 0, len(schemas))
+	for _, toolSchema := range schemas {
+		names = append(names, toolSchema.Name)
+	}
+	return names
+}
@@ -0,0 +1,74 @@
+package kit
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/openai/openai-go/option"
+	"github.com/stretchr/testify/require"
+)
+
+type adminTool struct {
+	BaseTool
+}
+
+func (a *adminTool) Execute(ctx *Context) (any, error) {
+	return "deleted", nil
+}
+
+func TestToolAccessControl(t *testing.T) {
+	var offered []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Tools []struct {
+				Function struct {
+					Name string `json:"name"`
+				} `json:"function"`
+			} `json:"tools"`
+		}
+		data, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(data, &body)
+		offered = nil
+		for _, tool := range body.Tools {
+			offered = append(offered, tool.Function.Name)
+		}
+
+		// The model calls the admin tool regardless of what it was offered
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"1","object":"chat.completion","choices":[{"index":0,"finish_reason":"tool_calls",` +
+			`"message":{"role":"assistant","content":"","tool_calls":[{"id":"call_1","type":"function",` +
+			`"function":{"name":"admin_tool","arguments":"{}"}}]}}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(
+		WithAPIKey("test"),
+		WithBaseURL(server.URL),
+		WithRequestOptions(option.WithMaxRetries(0)),
+	)
+	agent := CreateAgent(client, &lookupTool{}, &adminTool{}).
+		WithToolPolicy(func(ctx context.Context, config InvokeConfig, tool AgentToolInfo) bool {
+			return tool.Name != "admin_tool" || config.Metadata["role"] == "admin"
+		})
+
+	_, err := agent.Invoke(context.Background(), InvokeConfig{Prompt: "hi", Metadata: map[string]string{"role": "viewer"}})
+	require.ErrorIs(t, err, ErrToolNotAllowed)
+	require.Equal(t, []string{"lookup_tool"}, offered)
+
+	_, err = agent.Invoke(context.Background(), InvokeConfig{Prompt: "hi", AllowedTools: []string{}})
+	require.ErrorIs(t, err, ErrToolNotAllowed)
+	require.Empty(t, offered)
+
+	maxIterations := 1
+	_, err = agent.Invoke(context.Background(), InvokeConfig{
+		Prompt:        "hi",
+		Metadata:      map[string]string{"role": "admin"},
+		MaxIterations: &maxIterations,
+	})
+	require.ErrorIs(t, err, ErrMaxIterations, "the admin tool ran")
+	require.Equal(t, []string{"admin_tool", "lookup_tool"}, offered)
+}
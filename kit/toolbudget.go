@@ -0,0 +1,87 @@
+package kit
+
+import (
+	"fmt"
+
+	"github.com/openai/openai-go"
+)
+
+// DefaultWrapUpPrompt asks the model for its final answer once the tool call budget is used up
+const DefaultWrapUpPrompt = "You have used up your tool call budget. Do not call any more tools; " +
+	"give your final answer based on the information you have."
+
+// ToolBudget limits the tool calls of a run, independently of the iteration limit
+type ToolBudget struct {
+	// MaxCalls is the total number of tool calls per run (0 means unlimited)
+	MaxCalls int
+
+	// PerTool limits the calls per run of individual tools, by tool name (optional)
+	PerTool map[string]int
+
+	// WrapUp rejects calls over the budget with a tool message instead of failing the run with a
+	// ToolBudgetError. Once the total budget is used up, tools are no longer offered and the
+	// model is asked for its final answer.
+	WrapUp bool
+
+	// WrapUpPrompt is the instruction sent once the total budget is used up (optional, defaults
+	// to DefaultWrapUpPrompt)
+	WrapUpPrompt string
+}
+
+// WithMaxToolCalls limits the total number of tool calls per run; a run exceeding it fails with
+// a ToolBudgetError unless the tool budget wraps up
+func (a *Agent[Output]) WithMaxToolCalls(n int) *Agent[Output] {
+	a.toolBudget.MaxCalls = n
+	return a
+}
+
+// WithToolBudget limits the tool calls per run, in total and per tool
+func (a *Agent[Output]) WithToolBudget(budget ToolBudget) *Agent[Output] {
+	a.toolBudget = budget
+	return a
+}
+
+// toolUsage counts the tool calls of a run
+type toolUsage struct {
+	total   int
+	perTool map[string]int
+
+	// failures are the errors of the consecutive failed calls returned to the model
+	failures []error
+}
+
+// use counts a call of the tool, or returns the ToolBudgetError if it is over the budget
+func (b ToolBudget) use(usage *toolUsage, toolName string) error {
+	if b.MaxCalls > 0 && usage.total >= b.MaxCalls {
+		return &ToolBudgetError{Limit: b.MaxCalls}
+	}
+	if limit, ok := b.PerTool[toolName]; ok && usage.perTool[toolName] >= limit {
+		return &ToolBudgetError{Tool: toolName, Limit: limit}
+	}
+
+	if usage.perTool == nil {
+		usage.perTool = make(map[string]int)
+	}
+	usage.total++
+	usage.perTool[toolName]++
+	return nil
+}
+
+// exhausted reports whether the run used up its total budget
+func (b ToolBudget) exhausted(usage *toolUsage) bool {
+	return b.MaxCalls > 0 && usage.total >= b.MaxCalls
+}
+
+// rejectedCall is the tool message answering a call over the budget when wrapping up
+func rejectedCall(err error, toolCallID string) openai.ChatCompletionMessageParamUnion {
+	return openai.ToolMessage(fmt.Sprintf("Not executed: %v. Continue without this call.", err), toolCallID)
+}
+
+// wrapUpMessage asks the model for its final answer
+func (b ToolBudget) wrapUpMessage() openai.ChatCompletionMessageParamUnion {
+	prompt := b.WrapUpPrompt
+	if prompt == "" {
+		prompt = DefaultWrapUpPrompt
+	}
+	return openai.UserMessage(prompt)
+}
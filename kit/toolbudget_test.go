@@ -0,0 +1,65 @@
+package kit
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/openai/openai-go/option"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToolBudget(t *testing.T) {
+	var last map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		last = nil
+		_ = json.Unmarshal(body, &last)
+
+		w.Header().Set("Content-Type", "application/json")
+		if _, hasTools := last["tools"]; !hasTools {
+			_, _ = w.Write([]byte(`{"id":"1","object":"chat.completion","choices":[{"index":0,"finish_reason":"stop",` +
+				`"message":{"role":"assistant","content":"final answer"}}]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":"1","object":"chat.completion","choices":[{"index":0,"finish_reason":"tool_calls",` +
+			`"message":{"role":"assistant","content":"","tool_calls":[{"id":"call_1","type":"function",` +
+			`"function":{"name":"lookup_tool","arguments":"{}"}}]}}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(
+		WithAPIKey("test"),
+		WithBaseURL(server.URL),
+		WithRequestOptions(option.WithMaxRetries(0)),
+	)
+
+	// Over the total budget the run fails
+	_, err := CreateAgent(client, &lookupTool{}).WithMaxToolCalls(2).Invoke(context.Background(), InvokeConfig{Prompt: "hi"})
+	var budgetErr *ToolBudgetError
+	require.ErrorAs(t, err, &budgetErr)
+	require.ErrorIs(t, err, ErrToolBudgetExceeded)
+	require.Equal(t, 2, budgetErr.Limit)
+	require.Equal(t, ErrorClassBudget, ClassifyError(err))
+
+	// So does a call over a tool's own budget
+	_, err = CreateAgent(client, &lookupTool{}).
+		WithToolBudget(ToolBudget{PerTool: map[string]int{"lookup_tool": 1}}).
+		Invoke(context.Background(), InvokeConfig{Prompt: "hi"})
+	require.ErrorAs(t, err, &budgetErr)
+	require.Equal(t, "lookup_tool", budgetErr.Tool)
+
+	// Wrapping up asks for the final answer without offering the tools
+	result, err := CreateAgent(client, &lookupTool{}).
+		WithToolBudget(ToolBudget{MaxCalls: 2, WrapUp: true}).
+		InvokeDetailed(context.Background(), InvokeConfig{Prompt: "hi"})
+	require.NoError(t, err)
+	require.Equal(t, "final answer", result.Output)
+	require.Equal(t, 3, result.Iterations)
+
+	messages := last["messages"].([]any)
+	require.Equal(t, DefaultWrapUpPrompt, messages[len(messages)-1].(map[string]any)["content"])
+}
@@ -0,0 +1,103 @@
+package kit
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/mhrlife/goai-kit/internal/toolcache"
+)
+
+// DefaultToolCacheTTL is the TTL of cached tool results when ToolCache.TTL is not set
+const DefaultToolCacheTTL = 5 * time.Minute
+
+// ToolCache caches the results of idempotent tools, keyed by tool name and canonicalized
+// arguments, so they aren't executed again across iterations and runs. Failed calls and rich
+// results (files, ToolContent) are never cached. Agents sharing a store share their results,
+// so tools whose result depends on more than their arguments should not be cached.
+type ToolCache struct {
+	// Store holds the cached results, e.g. toolcache.NewMemoryStore()
+	Store toolcache.Store
+
+	// Tools lists the names of the tools to cache; tools implementing CacheableTool are cached
+	// as well
+	Tools []string
+
+	// TTL of the cached results (optional, defaults to DefaultToolCacheTTL)
+	TTL time.Duration
+}
+
+// CacheableTool is implemented by tools that opt into the agent's tool cache, overriding its
+// TTL; a zero TTL caches the results without expiry
+type CacheableTool interface {
+	ToolExecutor
+	CacheTTL() time.Duration
+}
+
+// WithToolCache caches the results of the listed tools and of tools implementing CacheableTool
+func (a *Agent[Output]) WithToolCache(cache ToolCache) *Agent[Output] {
+	a.toolCache = &cache
+	return a
+}
+
+// key returns the cache key and TTL of the tool call, and whether the tool is cached at all
+func (c *ToolCache) key(tool ToolExecutor, toolName, arguments string) (string, time.Duration, bool) {
+	if c == nil || c.Store == nil {
+		return "", 0, false
+	}
+
+	ttl, ok := c.ttl(tool, toolName)
+	if !ok {
+		return "", 0, false
+	}
+
+	key, err := toolcache.Key(toolName, arguments)
+	if err != nil {
+		return "", 0, false
+	}
+	return key, ttl, true
+}
+
+func (c *ToolCache) ttl(tool ToolExecutor, toolName string) (time.Duration, bool) {
+	if cacheable, ok := tool.(CacheableTool); ok {
+		return cacheable.CacheTTL(), true
+	}
+	if !slices.Contains(c.Tools, toolName) {
+		return 0, false
+	}
+	if c.TTL > 0 {
+		return c.TTL, true
+	}
+	return DefaultToolCacheTTL, true
+}
+
+// cachedToolResult returns the cached result of the tool call, if any.
+// Store failures are logged and treated as misses.
+func (a *Agent[Output]) cachedToolResult(ctx context.Context, key string) (string, bool) {
+	value, err := a.toolCache.Store.Get(ctx, key)
+	if err != nil {
+		if !errors.Is(err, toolcache.ErrNotFound) {
+			a.client.Logger.Error("Failed to read cached tool result", "key", key, "error", err)
+		}
+		return "", false
+	}
+	return string(value), true
+}
+
+// cacheToolResult stores the result of a successful tool call. Rich results are skipped since
+// their files can't be restored from the tool message.
+func (a *Agent[Output]) cacheToolResult(ctx context.Context, key string, result any, ttl time.Duration) {
+	if _, _, rich := richToolResult(result); rich {
+		return
+	}
+
+	resultStr, err := resultToString(result)
+	if err != nil {
+		return
+	}
+
+	if err := a.toolCache.Store.Set(context.WithoutCancel(ctx), key, []byte(resultStr), ttl); err != nil {
+		a.client.Logger.Error("Failed to cache tool result", "key", key, "error", err)
+	}
+}
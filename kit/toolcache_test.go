@@ -0,0 +1,71 @@
+package kit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/mhrlife/goai-kit/internal/toolcache"
+	"github.com/openai/openai-go/option"
+	"github.com/stretchr/testify/require"
+)
+
+type searchTool struct {
+	BaseTool
+	Query string `json:"query"`
+	Limit int    `json:"limit"`
+
+	executions *atomic.Int32
+}
+
+func (s *searchTool) Execute(ctx *Context) (any, error) {
+	s.executions.Add(1)
+	return map[string]any{"query": s.Query, "hits": s.Limit}, nil
+}
+
+func TestToolCache(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch requests.Add(1) % 3 {
+		case 1:
+			_, _ = w.Write([]byte(toolCallResponse("call_1", `{\"query\":\"go\",\"limit\":1}`)))
+		case 2:
+			_, _ = w.Write([]byte(toolCallResponse("call_2", `{\"limit\": 1, \"query\": \"go\"}`)))
+		default:
+			_, _ = w.Write([]byte(`{"id":"1","object":"chat.completion","choices":[{"index":0,"finish_reason":"stop",` +
+				`"message":{"role":"assistant","content":"done"}}]}`))
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(
+		WithAPIKey("test"),
+		WithBaseURL(server.URL),
+		WithRequestOptions(option.WithMaxRetries(0)),
+	)
+
+	executions := &atomic.Int32{}
+	agent := CreateAgent(client, &searchTool{executions: executions}).
+		WithToolCache(ToolCache{Store: toolcache.NewMemoryStore(), Tools: []string{"search_tool"}})
+
+	// The second call, with reordered arguments, is served from the cache
+	result, err := agent.InvokeDetailed(context.Background(), InvokeConfig{Prompt: "hi"})
+	require.NoError(t, err)
+	require.Equal(t, "done", result.Output)
+	require.Equal(t, int32(1), executions.Load())
+	require.Equal(t, `{"hits":1,"query":"go"}`, MessageText(result.Messages[len(result.Messages)-2]))
+
+	// The cache is shared across runs
+	_, err = agent.InvokeDetailed(context.Background(), InvokeConfig{Prompt: "hi"})
+	require.NoError(t, err)
+	require.Equal(t, int32(1), executions.Load())
+}
+
+func toolCallResponse(id, arguments string) string {
+	return `{"id":"1","object":"chat.completion","choices":[{"index":0,"finish_reason":"tool_calls",` +
+		`"message":{"role":"assistant","content":"","tool_calls":[{"id":"` + id + `","type":"function",` +
+		`"function":{"name":"search_tool","arguments":"` + arguments + `"}}]}}]}`
+}
@@ -0,0 +1,61 @@
+package kit
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mhrlife/goai-kit/internal/callback"
+	"github.com/openai/openai-go"
+)
+
+// ToolErrorHandling decides what a failing tool call does to the run
+type ToolErrorHandling string
+
+const (
+	// AbortRun fails the run with the error of the tool call (the default)
+	AbortRun ToolErrorHandling = "abort"
+
+	// ReturnToModel sends the error back as the result of the tool call, so the model can retry
+	// with different arguments or choose another tool
+	ReturnToModel ToolErrorHandling = "return_to_model"
+)
+
+// WithToolErrorHandling sets what happens when a tool call fails: its tool returns an error,
+// its arguments don't parse or it names an unknown or disallowed tool. Calls over the tool
+// budget follow ToolBudget.WrapUp instead, and a cancelled run always stops.
+func (a *Agent[Output]) WithToolErrorHandling(mode ToolErrorHandling) *Agent[Output] {
+	a.toolErrors = mode
+	return a
+}
+
+// WithMaxToolFailures returns failed tool calls to the model, as ReturnToModel does, until n
+// calls in a row failed; the run then fails with a ToolFailuresError holding their errors. A
+// successful call resets the count. Every failure returned to the model is reported to OnRetry
+// with the tool stage, attempt being the number of consecutive failures so far.
+func (a *Agent[Output]) WithMaxToolFailures(n int) *Agent[Output] {
+	a.toolFailures = n
+	return a
+}
+
+// toolFailed handles a failed tool call whose error, failure, would end the run. It returns the
+// tool message reporting err, the call's own error, to the model instead, or the error ending
+// the run. failure is err itself unless the call site wraps it, e.g. in a ToolFailedError.
+func (a *Agent[Output]) toolFailed(
+	ctx context.Context,
+	err, failure error,
+	toolCallID string,
+	usage *toolUsage,
+	cbManager *callback.Manager,
+) (openai.ChatCompletionMessageParamUnion, error) {
+	if (a.toolErrors != ReturnToModel && a.toolFailures <= 0) || ctx.Err() != nil {
+		return openai.ChatCompletionMessageParamUnion{}, failure
+	}
+
+	usage.failures = append(usage.failures, failure)
+	if a.toolFailures > 0 && len(usage.failures) >= a.toolFailures {
+		return openai.ChatCompletionMessageParamUnion{}, &ToolFailuresError{Errors: usage.failures}
+	}
+
+	cbManager.OnRetry("tool", failure, string(ClassifyError(failure)), len(usage.failures), 0, "")
+	return openai.ToolMessage(fmt.Sprintf("Error: %v. Fix the arguments and try again, or use another tool.", err), toolCallID), nil
+}
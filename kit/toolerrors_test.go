@@ -0,0 +1,125 @@
+package kit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/mhrlife/goai-kit/internal/toolcache"
+	"github.com/openai/openai-go/option"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToolErrorsReturnedToModel(t *testing.T) {
+	var toolResults []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Messages []struct {
+				Role    string `json:"role"`
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+
+		w.Header().Set("Content-Type", "application/json")
+		if len(body.Messages) == 1 {
+			_, _ = w.Write([]byte(`{"id":"1","object":"chat.completion","choices":[{"index":0,"finish_reason":"tool_calls",` +
+				`"message":{"role":"assistant","tool_calls":[` +
+				`{"id":"call_1","type":"function","function":{"name":"failing_tool","arguments":"{}"}},` +
+				`{"id":"call_2","type":"function","function":{"name":"missing_tool","arguments":"{}"}},` +
+				`{"id":"call_3","type":"function","function":{"name":"lookup_tool","arguments":"{not json"}}]}}]}`))
+			return
+		}
+
+		for _, message := range body.Messages {
+			if message.Role == "tool" {
+				toolResults = append(toolResults, message.Content)
+			}
+		}
+		_, _ = w.Write([]byte(`{"id":"1","object":"chat.completion","choices":[{"index":0,"finish_reason":"stop",` +
+			`"message":{"role":"assistant","content":"recovered"}}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("test"), WithBaseURL(server.URL), WithRequestOptions(option.WithMaxRetries(0)))
+
+	output, err := CreateAgent(client, &failingTool{}, &lookupTool{}).
+		WithToolErrorHandling(ReturnToModel).
+		Invoke(context.Background(), InvokeConfig{Prompt: "hi"})
+	require.NoError(t, err)
+	require.Equal(t, "recovered", output)
+	require.Len(t, toolResults, 3)
+	require.Equal(t, "Error: disk full. Fix the arguments and try again, or use another tool.", toolResults[0],
+		"the model sees the tool's own error")
+	require.Contains(t, toolResults[1], ErrToolNotFound.Error())
+	require.Contains(t, toolResults[2], "failed to parse tool arguments")
+
+	// By default the first failure ends the run
+	_, err = CreateAgent(client, &failingTool{}, &lookupTool{}).Invoke(context.Background(), InvokeConfig{Prompt: "hi"})
+	require.ErrorIs(t, err, errDiskFull)
+}
+
+func TestMaxToolFailures(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"1","object":"chat.completion","choices":[{"index":0,"finish_reason":"tool_calls",` +
+			`"message":{"role":"assistant","tool_calls":[{"id":"call_1","type":"function",` +
+			`"function":{"name":"failing_tool","arguments":"{}"}}]}}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("test"), WithBaseURL(server.URL), WithRequestOptions(option.WithMaxRetries(0)))
+	recorder := &retryRecorder{}
+
+	// The failures are returned to the model until the limit is reached
+	result, err := CreateAgent(client, &failingTool{}).
+		WithMaxToolFailures(3).
+		WithCallbacks(recorder).
+		InvokeDetailed(context.Background(), InvokeConfig{Prompt: "hi"})
+	require.Nil(t, result)
+	require.ErrorIs(t, err, ErrTooManyToolFailures)
+	require.ErrorIs(t, err, errDiskFull)
+	require.Equal(t, ErrorClassBudget, ClassifyError(err))
+
+	var failuresErr *ToolFailuresError
+	require.ErrorAs(t, err, &failuresErr)
+	require.Len(t, failuresErr.Errors, 3)
+	require.Len(t, recorder.retries, 2)
+	require.Equal(t, "tool", recorder.retries[1]["stage"])
+	require.Equal(t, 2, recorder.retries[1]["attempt"])
+	require.Contains(t, recorder.retries[1]["error"], "disk full")
+}
+
+func TestMaxToolFailuresResetByCacheHit(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch requests.Add(1) {
+		case 1, 3:
+			_, _ = w.Write([]byte(toolCallResponse("call_search", `{\"query\":\"go\",\"limit\":1}`)))
+		case 2, 4:
+			_, _ = w.Write([]byte(`{"id":"1","object":"chat.completion","choices":[{"index":0,"finish_reason":"tool_calls",` +
+				`"message":{"role":"assistant","tool_calls":[{"id":"call_fail","type":"function",` +
+				`"function":{"name":"failing_tool","arguments":"{}"}}]}}]}`))
+		default:
+			_, _ = w.Write([]byte(`{"id":"1","object":"chat.completion","choices":[{"index":0,"finish_reason":"stop",` +
+				`"message":{"role":"assistant","content":"done"}}]}`))
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("test"), WithBaseURL(server.URL), WithRequestOptions(option.WithMaxRetries(0)))
+	executions := &atomic.Int32{}
+
+	// The cached search between the two failures counts as a successful call
+	output, err := CreateAgent(client, &failingTool{}, &searchTool{executions: executions}).
+		WithMaxToolFailures(2).
+		WithToolCache(ToolCache{Store: toolcache.NewMemoryStore(), Tools: []string{"search_tool"}}).
+		Invoke(context.Background(), InvokeConfig{Prompt: "hi"})
+	require.NoError(t, err)
+	require.Equal(t, "done", output)
+	require.Equal(t, int32(1), executions.Load())
+}
@@ -0,0 +1,92 @@
+package kit
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/openai/openai-go"
+)
+
+// ContentPart is one part of a multi-part tool result, either text or a file
+type ContentPart struct {
+	Text string
+	File *File
+}
+
+// TextPart creates a text content part
+func TextPart(text string) ContentPart {
+	return ContentPart{Text: text}
+}
+
+// FilePart creates a file content part, e.g. FilePart(FileImage("image/png", screenshot))
+func FilePart(file File) ContentPart {
+	return ContentPart{File: &file}
+}
+
+// ToolContent is a rich tool result made of several parts. A tool can also return a File or
+// []File directly. Tool messages only accept text, so the text parts form the tool message and
+// the files are attached in a user message following the tool messages.
+type ToolContent []ContentPart
+
+// richToolResult splits a File, []File or ToolContent result into its text and files
+func richToolResult(result any) (text []string, files []File, ok bool) {
+	var parts ToolContent
+	switch v := result.(type) {
+	case ToolContent:
+		parts = v
+	case []ContentPart:
+		parts = v
+	case File:
+		parts = ToolContent{FilePart(v)}
+	case *File:
+		if v == nil {
+			return nil, nil, false
+		}
+		parts = ToolContent{FilePart(*v)}
+	case []File:
+		for _, file := range v {
+			parts = append(parts, FilePart(file))
+		}
+	default:
+		return nil, nil, false
+	}
+
+	for _, part := range parts {
+		if part.File != nil {
+			files = append(files, *part.File)
+		}
+		if part.Text != "" {
+			text = append(text, part.Text)
+		}
+	}
+	return text, files, true
+}
+
+// toolResultMessage converts a tool result into its tool message and the content parts to
+// attach in a user message, if any
+func toolResultMessage(
+	result any,
+	toolCallID string,
+) (openai.ChatCompletionMessageParamUnion, []openai.ChatCompletionContentPartUnionParam, error) {
+	text, files, ok := richToolResult(result)
+	if !ok {
+		resultStr, err := resultToString(result)
+		if err != nil {
+			return openai.ChatCompletionMessageParamUnion{}, nil, fmt.Errorf("failed to convert tool result to string: %w", err)
+		}
+		return openai.ToolMessage(resultStr, toolCallID), nil, nil
+	}
+
+	var attachments []openai.ChatCompletionContentPartUnionParam
+	if len(files) > 0 {
+		attachments = append(attachments, openai.TextContentPart(
+			fmt.Sprintf("Attachments returned by tool call %s:", toolCallID),
+		))
+		for _, file := range files {
+			attachments = append(attachments, file.contentPart())
+		}
+		text = append(text, fmt.Sprintf("[%d attachment(s) follow in the next message]", len(files)))
+	}
+
+	return openai.ToolMessage(strings.Join(text, "\n"), toolCallID), attachments, nil
+}
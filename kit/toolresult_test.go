@@ -0,0 +1,30 @@
+package kit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestToolResultMessage(t *testing.T) {
+	screenshot := FileImage("image/png", []byte("png"))
+	message, attachments, err := toolResultMessage(ToolContent{
+		TextPart("Screenshot of the checkout page"),
+		FilePart(screenshot),
+		FilePart(FilePDF("invoice.pdf", []byte("pdf"))),
+	}, "call_1")
+	require.NoError(t, err)
+
+	require.Equal(t, "tool", MessageRole(message))
+	require.Equal(t, "Screenshot of the checkout page\n[2 attachment(s) follow in the next message]", MessageText(message))
+
+	require.Len(t, attachments, 3)
+	require.Equal(t, screenshot.DataURI, attachments[1].OfImageURL.ImageURL.URL)
+	require.Equal(t, "invoice.pdf", attachments[2].OfFile.File.Filename.Value)
+
+	// Plain results are still sent as text
+	message, attachments, err = toolResultMessage(map[string]int{"count": 2}, "call_2")
+	require.NoError(t, err)
+	require.Equal(t, `{"count":2}`, MessageText(message))
+	require.Empty(t, attachments)
+}
@@ -0,0 +1,70 @@
+package kit
+
+import (
+	"time"
+
+	"github.com/mhrlife/goai-kit/internal/callback"
+)
+
+// ToolRetry retries the failed calls of a tool with exponential backoff before the failure is
+// handled like any failing tool call (see WithToolErrorHandling)
+type ToolRetry struct {
+	// MaxAttempts of a call, including the first one (defaults to 1)
+	MaxAttempts int
+
+	// InitialBackoff before the second attempt, doubled on every further attempt (defaults to 500ms)
+	InitialBackoff time.Duration
+
+	// MaxBackoff caps the backoff (defaults to 10s)
+	MaxBackoff time.Duration
+
+	// Retryable reports whether a call failing with err is tried again (optional, every error is
+	// retried by default)
+	Retryable func(err error) bool
+}
+
+// RetryableTool is implemented by tools wrapping flaky services, declaring how their failed
+// calls are retried. Every retry is reported to OnRetry with the tool_retry stage.
+type RetryableTool interface {
+	ToolExecutor
+	ToolRetry() ToolRetry
+}
+
+// executeWithRetry executes the tool call, trying it again as declared by the tool. Every
+// attempt runs within the timeout; a cancelled run is never retried.
+func executeWithRetry(
+	ctx *Context,
+	tool ToolExecutor,
+	toolName string,
+	timeout time.Duration,
+	cbManager *callback.Manager,
+) (any, error) {
+	retryable, ok := tool.(RetryableTool)
+	if !ok {
+		return executeTool(ctx, tool, toolName, timeout)
+	}
+
+	policy := retryable.ToolRetry()
+	backoff := RetryPolicy{InitialBackoff: policy.InitialBackoff, MaxBackoff: policy.MaxBackoff}
+	parent := ctx.Context
+
+	for attempt := 1; ; attempt++ {
+		ctx.Context = parent
+		result, err := executeTool(ctx, tool, toolName, timeout)
+		if err == nil || attempt >= policy.MaxAttempts || parent.Err() != nil {
+			return result, err
+		}
+		if policy.Retryable != nil && !policy.Retryable(err) {
+			return result, err
+		}
+
+		delay := backoff.backoff(attempt)
+		cbManager.OnRetry("tool_retry", err, string(ClassifyError(err)), attempt, delay, "")
+
+		select {
+		case <-time.After(delay):
+		case <-parent.Done():
+			return nil, parent.Err()
+		}
+	}
+}
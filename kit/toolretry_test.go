@@ -0,0 +1,95 @@
+package kit
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openai/openai-go/option"
+	"github.com/stretchr/testify/require"
+)
+
+var errUnavailable = errors.New("service unavailable")
+
+// flakyTool fails with Err until it was called Failures times
+type flakyTool struct {
+	BaseTool
+
+	failures int
+	err      error
+	calls    *int
+	retry    ToolRetry
+}
+
+func (f *flakyTool) ToolRetry() ToolRetry { return f.retry }
+
+func (f *flakyTool) Execute(ctx *Context) (any, error) {
+	*f.calls++
+	if *f.calls <= f.failures {
+		return nil, f.err
+	}
+	return "ok", nil
+}
+
+func TestToolRetry(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		body, _ := io.ReadAll(r.Body)
+		if !strings.Contains(string(body), `"role":"tool"`) {
+			_, _ = w.Write([]byte(`{"id":"1","object":"chat.completion","choices":[{"index":0,"finish_reason":"tool_calls",` +
+				`"message":{"role":"assistant","tool_calls":[{"id":"call_1","type":"function",` +
+				`"function":{"name":"flaky_tool","arguments":"{}"}}]}}]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":"1","object":"chat.completion","choices":[{"index":0,"finish_reason":"stop",` +
+			`"message":{"role":"assistant","content":"done"}}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("test"), WithBaseURL(server.URL), WithRequestOptions(option.WithMaxRetries(0)))
+	retryable := func(err error) bool { return errors.Is(err, errUnavailable) }
+
+	// Calls are retried until they succeed
+	calls := 0
+	recorder := &retryRecorder{}
+	output, err := CreateAgent(client, &flakyTool{
+		failures: 2,
+		err:      errUnavailable,
+		calls:    &calls,
+		retry:    ToolRetry{MaxAttempts: 3, InitialBackoff: time.Millisecond, Retryable: retryable},
+	}).WithCallbacks(recorder).Invoke(context.Background(), InvokeConfig{Prompt: "hi"})
+	require.NoError(t, err)
+	require.Equal(t, "done", output)
+	require.Equal(t, 3, calls)
+	require.Len(t, recorder.retries, 2)
+	require.Equal(t, "tool_retry", recorder.retries[0]["stage"])
+	require.Equal(t, 2, recorder.retries[1]["attempt"])
+	require.Equal(t, int64(1), recorder.retries[0]["backoff_ms"])
+
+	// The last failure ends the run once the attempts are exhausted
+	calls = 0
+	_, err = CreateAgent(client, &flakyTool{
+		failures: 5,
+		err:      errUnavailable,
+		calls:    &calls,
+		retry:    ToolRetry{MaxAttempts: 2, InitialBackoff: time.Millisecond},
+	}).Invoke(context.Background(), InvokeConfig{Prompt: "hi"})
+	require.ErrorIs(t, err, errUnavailable)
+	require.Equal(t, 2, calls)
+
+	// Errors the matcher rejects are not retried
+	calls = 0
+	_, err = CreateAgent(client, &flakyTool{
+		failures: 1,
+		err:      errDiskFull,
+		calls:    &calls,
+		retry:    ToolRetry{MaxAttempts: 3, InitialBackoff: time.Millisecond, Retryable: retryable},
+	}).Invoke(context.Background(), InvokeConfig{Prompt: "hi"})
+	require.ErrorIs(t, err, errDiskFull)
+	require.Equal(t, 1, calls)
+}
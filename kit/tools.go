@@ -1,6 +1,7 @@
 package kit
 
 import (
+	"encoding/json"
 	"reflect"
 	"strings"
 
@@ -38,16 +39,21 @@ func GetAgentToolInfo(tool ToolExecutor) AgentToolInfo {
 
 	// If name is empty, generate it from type name using reflection
 	if info.Name == "" {
-		t := reflect.TypeOf(tool)
-		if t.Kind() == reflect.Ptr {
-			t = t.Elem()
-		}
-		info.Name = typeNameToToolName(t.Name())
+		info.Name = toolTypeName(tool)
 	}
 
 	return info
 }
 
+// toolTypeName generates a tool name from the type name of the tool
+func toolTypeName(tool any) string {
+	t := reflect.TypeOf(tool)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return typeNameToToolName(t.Name())
+}
+
 // typeNameToToolName converts a Go type name to a tool name
 // Examples: MyTool -> my_tool, HTTPClient -> http_client
 func typeNameToToolName(typeName string) string {
@@ -72,12 +78,24 @@ func typeNameToToolName(typeName string) string {
 	return strings.ToLower(result.String())
 }
 
+// RawSchemaTool is implemented by tools whose parameters are described by a JSON schema known
+// only at runtime, such as tools imported from MCP servers. The call arguments are unmarshalled
+// into the tool, so it should implement json.Unmarshaler.
+type RawSchemaTool interface {
+	ToolExecutor
+	ToolJSONSchema() map[string]any
+}
+
 // ToolSchema represents tool metadata and parameters
 type ToolSchema struct {
 	Name        string
 	ID          string
 	Description string
 	JSONSchema  map[string]any
+
+	// Strict enables strict schema adherence; schemas of RawSchemaTool are not guaranteed to
+	// satisfy its requirements, so it is only set for struct based tools
+	Strict bool
 }
 
 // BuildToolSchema creates schema metadata for a tool
@@ -85,10 +103,67 @@ func BuildToolSchema(tool ToolExecutor) ToolSchema {
 	info := GetAgentToolInfo(tool)
 	toolID := strings.ToLower(strings.NewReplacer(" ", "_", "-", "_").Replace(info.Name))
 
+	if raw, ok := tool.(RawSchemaTool); ok {
+		return ToolSchema{
+			Name:        info.Name,
+			ID:          toolID,
+			Description: info.Description,
+			JSONSchema:  raw.ToolJSONSchema(),
+		}
+	}
+
+	var parameters any = tool
+	if typed, ok := tool.(argumentsTool); ok {
+		parameters = typed.arguments()
+	}
+
 	return ToolSchema{
 		Name:        info.Name,
 		ID:          toolID,
 		Description: info.Description,
-		JSONSchema:  schema.MarshalToSchema(tool),
+		JSONSchema:  schema.MarshalToSchema(parameters),
+		Strict:      true,
+	}
+}
+
+// toolInstance returns the tool to execute for a call: the tool bound to the decoded arguments
+// or, for struct based tools, a copy of the tool with the arguments unmarshalled into it
+func toolInstance(tool ToolExecutor, arguments string) (ToolExecutor, error) {
+	if typed, ok := tool.(argumentsTool); ok {
+		return typed.bind([]byte(arguments))
+	}
+
+	// Create a copy of the tool struct to unmarshal args into
+	toolValue := reflect.ValueOf(tool)
+	if toolValue.Kind() == reflect.Ptr {
+		toolValue = toolValue.Elem()
+	}
+
+	// Create a new instance of the tool, keeping the configuration of the registered tool
+	copyValue := reflect.New(toolValue.Type())
+	copyConfiguration(copyValue.Elem(), toolValue)
+	toolCopy := copyValue.Interface().(ToolExecutor)
+
+	// Unmarshal args into the tool copy
+	if err := json.Unmarshal([]byte(arguments), toolCopy); err != nil {
+		return nil, err
+	}
+	return toolCopy, nil
+}
+
+// copyConfiguration copies the fields of a struct tool that the call arguments can't set, i.e.
+// unexported fields and fields tagged json:"-", such as clients and settings the tool was
+// registered with. Argument fields start from their zero value on every call, so the arguments
+// of a call never leak into the next one, not even through maps or slices the JSON decoder
+// would otherwise reuse.
+func copyConfiguration(dst, src reflect.Value) {
+	dst.Set(src)
+	if dst.Kind() != reflect.Struct {
+		return
+	}
+	for i := 0; i < dst.NumField(); i++ {
+		if field := dst.Type().Field(i); field.IsExported() && field.Tag.Get("json") != "-" {
+			dst.Field(i).SetZero()
+		}
 	}
 }
@@ -0,0 +1,124 @@
+package kit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/openai/openai-go/option"
+	"github.com/stretchr/testify/require"
+)
+
+// configuredTool is registered with configuration the call arguments can't set
+type configuredTool struct {
+	BaseTool
+	Tags    []string          `json:"tags"`
+	Options map[string]string `json:"options"`
+	Region  string            `json:"-"`
+
+	endpoint string
+}
+
+func (c *configuredTool) Execute(ctx *Context) (any, error) { return nil, nil }
+
+func TestToolInstance(t *testing.T) {
+	registered := &configuredTool{Region: "eu", endpoint: "https://api.example.com"}
+
+	first, err := toolInstance(registered, `{"tags":["a","b"],"options":{"mode":"fast"}}`)
+	require.NoError(t, err)
+	call := first.(*configuredTool)
+	require.Equal(t, []string{"a", "b"}, call.Tags)
+	require.Equal(t, map[string]string{"mode": "fast"}, call.Options)
+
+	// The configuration survives, for every call
+	require.Equal(t, "eu", call.Region)
+	require.Equal(t, "https://api.example.com", call.endpoint)
+
+	// The arguments of a call don't leak into the next one
+	second, err := toolInstance(registered, `{"options":{"limit":"1"}}`)
+	require.NoError(t, err)
+	call = second.(*configuredTool)
+	require.Nil(t, call.Tags)
+	require.Equal(t, map[string]string{"limit": "1"}, call.Options)
+	require.Equal(t, "eu", call.Region)
+
+	// Nor into the registered tool, even when it was registered with arguments set
+	registered.Options = map[string]string{"default": "x"}
+	third, err := toolInstance(registered, `{"options":{"mode":"slow"}}`)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"mode": "slow"}, third.(*configuredTool).Options)
+	require.Equal(t, map[string]string{"default": "x"}, registered.Options)
+}
+
+// remoteSchemaTool describes its parameters with a schema known only at runtime, like the tools
+// imported from MCP servers
+type remoteSchemaTool struct {
+	arguments map[string]any
+	calls     *[]map[string]any
+}
+
+func (r *remoteSchemaTool) AgentToolInfo() AgentToolInfo {
+	return AgentToolInfo{Name: "remote_weather", Description: "Weather of a city"}
+}
+
+func (r *remoteSchemaTool) ToolJSONSchema() map[string]any {
+	return map[string]any{
+		"type":       "object",
+		"properties": map[string]any{"city": map[string]any{"type": "string"}},
+	}
+}
+
+func (r *remoteSchemaTool) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &r.arguments)
+}
+
+func (r *remoteSchemaTool) Execute(ctx *Context) (any, error) {
+	*r.calls = append(*r.calls, r.arguments)
+	return "sunny", nil
+}
+
+func TestRawSchemaTool(t *testing.T) {
+	var tools []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Tools    []map[string]any `json:"tools"`
+			Messages []any            `json:"messages"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+
+		w.Header().Set("Content-Type", "application/json")
+		if len(body.Messages) == 1 {
+			tools = body.Tools
+			_, _ = w.Write([]byte(`{"id":"1","object":"chat.completion","choices":[{"index":0,"finish_reason":"tool_calls",` +
+				`"message":{"role":"assistant","tool_calls":[{"id":"call_1","type":"function",` +
+				`"function":{"name":"remote_weather","arguments":"{\"city\":\"Oslo\"}"}}]}}]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":"1","object":"chat.completion","choices":[{"index":0,"finish_reason":"stop",` +
+			`"message":{"role":"assistant","content":"done"}}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("test"), WithBaseURL(server.URL), WithRequestOptions(option.WithMaxRetries(0)))
+	var calls []map[string]any
+
+	_, err := CreateAgent(client, &remoteSchemaTool{calls: &calls}, &lookupTool{}).
+		Invoke(context.Background(), InvokeConfig{Prompt: "weather in Oslo?"})
+	require.NoError(t, err)
+	require.Equal(t, []map[string]any{{"city": "Oslo"}}, calls)
+
+	// The runtime schema is sent as is, without strict mode it may not satisfy
+	functions := map[string]map[string]any{}
+	for _, tool := range tools {
+		function := tool["function"].(map[string]any)
+		functions[function["name"].(string)] = function
+	}
+	require.Equal(t, false, functions["remote_weather"]["strict"])
+	require.Equal(t, map[string]any{
+		"type":       "object",
+		"properties": map[string]any{"city": map[string]any{"type": "string"}},
+	}, functions["remote_weather"]["parameters"])
+	require.Equal(t, true, functions["lookup_tool"]["strict"])
+}
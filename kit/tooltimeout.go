@@ -0,0 +1,81 @@
+package kit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// TimeoutTool is implemented by tools declaring how long a call may run, overriding the
+// agent's WithToolTimeout; a zero timeout lets the tool run without a deadline
+type TimeoutTool interface {
+	ToolExecutor
+	ToolTimeout() time.Duration
+}
+
+// ToolTimeoutError is the error of a tool call that exceeded its timeout
+type ToolTimeoutError struct {
+	Tool    string
+	Timeout time.Duration
+}
+
+func (e *ToolTimeoutError) Error() string {
+	return fmt.Sprintf("tool %s timed out after %s", e.Tool, e.Timeout)
+}
+
+// Unwrap lets callers match the error with errors.Is(err, context.DeadlineExceeded)
+func (e *ToolTimeoutError) Unwrap() error {
+	return context.DeadlineExceeded
+}
+
+// WithToolTimeout bounds every tool call to timeout, for tools not implementing TimeoutTool.
+// The tool's context is cancelled at the deadline and the call fails with a ToolTimeoutError,
+// handled like any failing tool call (see WithToolErrorHandling); tools ignoring their context
+// are left running in the background instead of holding up the run.
+func (a *Agent[Output]) WithToolTimeout(timeout time.Duration) *Agent[Output] {
+	a.toolTimeout = timeout
+	return a
+}
+
+// timeoutFor returns the timeout of calls to the tool, zero when they have none
+func (a *Agent[Output]) timeoutFor(tool ToolExecutor) time.Duration {
+	if timed, ok := tool.(TimeoutTool); ok {
+		return timed.ToolTimeout()
+	}
+	return a.toolTimeout
+}
+
+// executeTool executes the tool call, failing it with a ToolTimeoutError once it runs longer
+// than timeout
+func executeTool(ctx *Context, tool ToolExecutor, toolName string, timeout time.Duration) (any, error) {
+	if timeout <= 0 {
+		return tool.Execute(ctx)
+	}
+
+	toolCtx, cancel := context.WithTimeoutCause(ctx.Context, timeout, &ToolTimeoutError{Tool: toolName, Timeout: timeout})
+	defer cancel()
+	ctx.Context = toolCtx
+
+	type outcome struct {
+		result any
+		err    error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		result, err := tool.Execute(ctx)
+		done <- outcome{result, err}
+	}()
+
+	var timeoutErr *ToolTimeoutError
+	select {
+	case out := <-done:
+		// A tool returning the error of its expired context timed out all the same
+		if out.err != nil && errors.As(context.Cause(toolCtx), &timeoutErr) && errors.Is(out.err, context.DeadlineExceeded) {
+			return nil, timeoutErr
+		}
+		return out.result, out.err
+	case <-toolCtx.Done():
+		return nil, context.Cause(toolCtx)
+	}
+}
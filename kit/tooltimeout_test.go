@@ -0,0 +1,61 @@
+package kit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/openai/openai-go/option"
+	"github.com/stretchr/testify/require"
+)
+
+// hangingTool blocks until its context is done
+type hangingTool struct {
+	BaseTool
+}
+
+func (h *hangingTool) Execute(ctx *Context) (any, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+// quickTool declares a timeout of its own
+type quickTool struct {
+	hangingTool
+}
+
+func (q *quickTool) ToolTimeout() time.Duration { return 10 * time.Millisecond }
+
+func TestToolTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"1","object":"chat.completion","choices":[{"index":0,"finish_reason":"tool_calls",` +
+			`"message":{"role":"assistant","tool_calls":[{"id":"call_1","type":"function",` +
+			`"function":{"name":"hanging_tool","arguments":"{}"}}]}}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("test"), WithBaseURL(server.URL), WithRequestOptions(option.WithMaxRetries(0)))
+
+	// A hanging tool doesn't hold up the run past the timeout
+	started := time.Now()
+	_, err := CreateAgent(client, &hangingTool{}).
+		WithToolTimeout(20*time.Millisecond).
+		Invoke(context.Background(), InvokeConfig{Prompt: "hi"})
+	require.Less(t, time.Since(started), time.Second)
+	require.ErrorIs(t, err, ErrToolFailed)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Equal(t, ErrorClassTimeout, ClassifyError(err))
+
+	var timeoutErr *ToolTimeoutError
+	require.ErrorAs(t, err, &timeoutErr)
+	require.Equal(t, "hanging_tool", timeoutErr.Tool)
+	require.Equal(t, 20*time.Millisecond, timeoutErr.Timeout)
+
+	// The timeout declared by the tool takes precedence over the agent's
+	agent := CreateAgent(client, &quickTool{}).WithToolTimeout(time.Hour)
+	require.Equal(t, 10*time.Millisecond, agent.timeoutFor(&quickTool{}))
+	require.Equal(t, time.Hour, agent.timeoutFor(&lookupTool{}))
+}
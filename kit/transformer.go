@@ -0,0 +1,29 @@
+package kit
+
+import (
+	"github.com/openai/openai-go"
+)
+
+// MessageTransformer rewrites the message list right before it is sent to the LLM.
+// It runs on every iteration and only affects the request, not the stored history,
+// so it can be used for trimming, adding caching markers or injecting dynamic context.
+type MessageTransformer func(messages []openai.ChatCompletionMessageParamUnion) []openai.ChatCompletionMessageParamUnion
+
+// transformMessages applies all registered transformers in order
+func (a *Agent[Output]) transformMessages(
+	messages []openai.ChatCompletionMessageParamUnion,
+) []openai.ChatCompletionMessageParamUnion {
+	if len(a.transformers) == 0 {
+		return messages
+	}
+
+	// Work on a copy so transformers can't mutate the agent's history in place
+	result := make([]openai.ChatCompletionMessageParamUnion, len(messages))
+	copy(result, messages)
+
+	for _, transform := range a.transformers {
+		result = transform(result)
+	}
+
+	return result
+}
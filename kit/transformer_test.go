@@ -0,0 +1,73 @@
+package kit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessageTransformers(t *testing.T) {
+	var requests [][]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Messages []struct {
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		var contents []string
+		for _, message := range body.Messages {
+			contents = append(contents, message.Content)
+		}
+		requests = append(requests, contents)
+
+		w.Header().Set("Content-Type", "application/json")
+		if len(requests) == 1 {
+			_, _ = w.Write([]byte(`{"id":"1","object":"chat.completion","choices":[{"index":0,"finish_reason":"tool_calls",` +
+				`"message":{"role":"assistant","tool_calls":[{"id":"call_1","type":"function",` +
+				`"function":{"name":"lookup_tool","arguments":"{}"}}]}}]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":"1","object":"chat.completion","choices":[{"index":0,"finish_reason":"stop",` +
+			`"message":{"role":"assistant","content":"done"}}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("test"), WithBaseURL(server.URL), WithRequestOptions(option.WithMaxRetries(0)))
+	var order []string
+	inject := func(messages []openai.ChatCompletionMessageParamUnion) []openai.ChatCompletionMessageParamUnion {
+		order = append(order, "inject")
+		return append(messages, openai.UserMessage("injected context"))
+	}
+	count := func(messages []openai.ChatCompletionMessageParamUnion) []openai.ChatCompletionMessageParamUnion {
+		order = append(order, "count")
+		require.Equal(t, "injected context", messages[len(messages)-1].OfUser.Content.OfString.Value)
+		return messages
+	}
+
+	result, err := CreateAgent(client, &lookupTool{}).
+		WithMessageTransformer(inject, count).
+		InvokeDetailed(context.Background(), InvokeConfig{Prompt: "hi"})
+	require.NoError(t, err)
+	require.Equal(t, "done", result.Output)
+
+	// Transformers run in order before every generation
+	require.Equal(t, []string{"inject", "count", "inject", "count"}, order)
+	require.Len(t, requests, 2)
+	for _, contents := range requests {
+		require.Equal(t, "injected context", contents[len(contents)-1])
+	}
+
+	// The history is left untouched
+	for _, message := range result.Messages {
+		if message.OfUser != nil {
+			require.Equal(t, "hi", message.OfUser.Content.OfString.Value)
+		}
+	}
+}
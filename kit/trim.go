@@ -0,0 +1,275 @@
+package kit
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/openai/openai-go"
+)
+
+// TrimStrategy shortens the message history before a generation.
+// Strategies run on the run's history, so whatever they remove stays removed for later iterations.
+// Token budgets are estimated with CountTokens for the run's model, see RegisterTokenizer.
+type TrimStrategy interface {
+	// Name identifies the strategy in callbacks
+	Name() string
+
+	// Trim returns the messages to keep; returning the input unchanged means no trim
+	Trim(ctx context.Context, messages []openai.ChatCompletionMessageParamUnion) (
+		[]openai.ChatCompletionMessageParamUnion, error)
+}
+
+// WithTrimStrategy sets the strategy used to trim the history before each generation
+func (a *Agent[Output]) WithTrimStrategy(strategy TrimStrategy) *Agent[Output] {
+	a.trimStrategy = strategy
+	return a
+}
+
+// trimHistory applies the trim strategy for the model and reports the decision to callbacks
+func (a *Agent[Output]) trimHistory(
+	ctx context.Context,
+	model string,
+	messages []openai.ChatCompletionMessageParamUnion,
+	report func(strategy string, before, after int),
+) ([]openai.ChatCompletionMessageParamUnion, error) {
+	if a.trimStrategy == nil {
+		return messages, nil
+	}
+	return trimWith(ctx, a.trimStrategy, model, messages, report)
+}
+
+// trimWith applies strategy for the model and reports the decision to callbacks
+func trimWith(
+	ctx context.Context,
+	strategy TrimStrategy,
+	model string,
+	messages []openai.ChatCompletionMessageParamUnion,
+	report func(strategy string, before, after int),
+) ([]openai.ChatCompletionMessageParamUnion, error) {
+	trimmed, err := strategy.Trim(withTokenModel(ctx, model), messages)
+	if err != nil {
+		return messages, fmt.Errorf("trim strategy %s failed: %w", strategy.Name(), err)
+	}
+
+	if len(trimmed) != len(messages) {
+		report(strategy.Name(), len(messages), len(trimmed))
+	}
+	return trimmed, nil
+}
+
+// splitSystem splits the leading system/developer messages from the rest of the history
+func splitSystem(
+	messages []openai.ChatCompletionMessageParamUnion,
+) (system, rest []openai.ChatCompletionMessageParamUnion) {
+	i := 0
+	for i < len(messages) && (messages[i].OfSystem != nil || messages[i].OfDeveloper != nil) {
+		i++
+	}
+	return messages[:i], messages[i:]
+}
+
+// dropOrphanToolMessages removes leading tool messages whose assistant tool call was trimmed away
+func dropOrphanToolMessages(
+	messages []openai.ChatCompletionMessageParamUnion,
+) []openai.ChatCompletionMessageParamUnion {
+	for len(messages) > 0 && messages[0].OfTool != nil {
+		messages = messages[1:]
+	}
+	return messages
+}
+
+// joinHistory concatenates system messages and the kept tail into a new slice
+func joinHistory(
+	system, tail []openai.ChatCompletionMessageParamUnion,
+) []openai.ChatCompletionMessageParamUnion {
+	result := make([]openai.ChatCompletionMessageParamUnion, 0, len(system)+len(tail))
+	result = append(result, system...)
+	return append(result, tail...)
+}
+
+// LastN keeps the system messages and the last n other messages
+func LastN(n int) TrimStrategy {
+	return &lastNStrategy{n: n}
+}
+
+type lastNStrategy struct {
+	n int
+}
+
+func (s *lastNStrategy) Name() string {
+	return fmt.Sprintf("last_%d", s.n)
+}
+
+func (s *lastNStrategy) Trim(
+	_ context.Context,
+	messages []openai.ChatCompletionMessageParamUnion,
+) ([]openai.ChatCompletionMessageParamUnion, error) {
+	system, rest := splitSystem(messages)
+	if len(rest) <= s.n {
+		return messages, nil
+	}
+
+	return joinHistory(system, dropOrphanToolMessages(rest[len(rest)-s.n:])), nil
+}
+
+// TokenWindow keeps the system messages and as many of the newest messages as fit in maxTokens
+func TokenWindow(maxTokens int) TrimStrategy {
+	return &tokenWindowStrategy{maxTokens: maxTokens}
+}
+
+type tokenWindowStrategy struct {
+	maxTokens int
+}
+
+func (s *tokenWindowStrategy) Name() string {
+	return "token_window"
+}
+
+func (s *tokenWindowStrategy) Trim(
+	ctx context.Context,
+	messages []openai.ChatCompletionMessageParamUnion,
+) ([]openai.ChatCompletionMessageParamUnion, error) {
+	system, rest := splitSystem(messages)
+	model := tokenModel(ctx)
+
+	budget := s.maxTokens
+	for _, msg := range system {
+		budget -= estimateTokens(model, msg)
+	}
+
+	// Walk backwards from the newest message until the budget runs out,
+	// always keeping at least the newest message
+	start := len(rest)
+	for start > 0 {
+		cost := estimateTokens(model, rest[start-1])
+		if budget-cost < 0 && start < len(rest) {
+			break
+		}
+		budget -= cost
+		start--
+	}
+
+	if start == 0 {
+		return messages, nil
+	}
+	return joinHistory(system, dropOrphanToolMessages(rest[start:])), nil
+}
+
+// SummarizeConfig configures the summarize-middle trim strategy
+type SummarizeConfig struct {
+	// Client used for the summarization call (required)
+	Client *Client
+
+	// Model used for summarization (optional, defaults to the client's default model)
+	Model string
+
+	// MaxMessages is the number of non-system messages that triggers summarization (required
+	// unless MaxTokens is set)
+	MaxMessages int
+
+	// MaxTokens is the estimated size of the whole history that triggers summarization, so
+	// long sessions are compacted before they overflow the context window (optional)
+	MaxTokens int
+
+	// KeepLast is the number of recent messages kept verbatim (optional, defaults to 4)
+	KeepLast int
+
+	// Prompt overrides the summarization instructions (optional)
+	Prompt string
+}
+
+// summaryPrefix starts the summary message that replaces the summarized messages
+const summaryPrefix = "Summary of the earlier conversation:\n"
+
+// SummarizeMiddle keeps the system messages and the most recent messages, replacing
+// everything in between with a single summary message once the history grows too long.
+// A previous summary is summarized again with the newer messages, so the history holds
+// one rolling summary however long the session runs.
+func SummarizeMiddle(config SummarizeConfig) TrimStrategy {
+	if config.Client == nil {
+		panic("Client is required")
+	}
+	if config.KeepLast <= 0 {
+		config.KeepLast = 4
+	}
+	if config.Prompt == "" {
+		config.Prompt = "Summarize the following conversation between a user and an assistant. " +
+			"Keep every fact, decision, tool result and open question needed to continue it. " +
+			"Reply with the summary only."
+	}
+
+	return &summarizeStrategy{config: config}
+}
+
+type summarizeStrategy struct {
+	config SummarizeConfig
+}
+
+func (s *summarizeStrategy) Name() string {
+	return "summarize_middle"
+}
+
+func (s *summarizeStrategy) Trim(
+	ctx context.Context,
+	messages []openai.ChatCompletionMessageParamUnion,
+) ([]openai.ChatCompletionMessageParamUnion, error) {
+	system, rest := splitSystem(messages)
+	if !s.exceeded(tokenModel(ctx), messages, rest) || len(rest) <= s.config.KeepLast {
+		return messages, nil
+	}
+
+	tail := dropOrphanToolMessages(rest[len(rest)-s.config.KeepLast:])
+	middle := rest[:len(rest)-len(tail)]
+	if n := len(system); n > 0 && strings.HasPrefix(MessageText(system[n-1]), summaryPrefix) {
+		middle = append(system[n-1:n:n], middle...)
+		system = system[:n-1]
+	}
+
+	summary, err := summarizeMessages(ctx, s.config.Client, s.config.Model, s.config.Prompt, middle)
+	if err != nil {
+		return nil, err
+	}
+
+	summaryMessage := openai.SystemMessage(summaryPrefix + summary)
+	return joinHistory(append(system[:len(system):len(system)], summaryMessage), tail), nil
+}
+
+// exceeded reports whether the history reached the message or token threshold
+func (s *summarizeStrategy) exceeded(model string, messages, rest []openai.ChatCompletionMessageParamUnion) bool {
+	if s.config.MaxTokens <= 0 {
+		return len(rest) > s.config.MaxMessages
+	}
+	if s.config.MaxMessages > 0 && len(rest) > s.config.MaxMessages {
+		return true
+	}
+
+	return CountTokens(model, messages) > s.config.MaxTokens
+}
+
+// summarizeMessages renders messages as a transcript and asks the model to summarize it
+func summarizeMessages(
+	ctx context.Context,
+	client *Client,
+	model, instructions string,
+	messages []openai.ChatCompletionMessageParamUnion,
+) (string, error) {
+	var transcript strings.Builder
+	for _, msg := range messages {
+		text := MessageText(msg)
+		if msg.OfAssistant != nil {
+			for _, call := range msg.OfAssistant.ToolCalls {
+				text += fmt.Sprintf("\n[called %s(%s)]", call.Function.Name, call.Function.Arguments)
+			}
+		}
+		if text == "" {
+			continue
+		}
+		fmt.Fprintf(&transcript, "%s: %s\n\n", MessageRole(msg), text)
+	}
+
+	return Ask(ctx, client, transcript.String(), AskOptions{
+		Model:        model,
+		SystemPrompt: instructions,
+	})
+}
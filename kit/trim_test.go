@@ -0,0 +1,112 @@
+package kit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLastN(t *testing.T) {
+	toolCall := openai.ChatCompletionMessageToolCallParam{
+		ID:       "call_1",
+		Function: openai.ChatCompletionMessageToolCallFunctionParam{Name: "search", Arguments: "{}"},
+	}
+	assistantWithCall := openai.AssistantMessage("")
+	assistantWithCall.OfAssistant.ToolCalls = []openai.ChatCompletionMessageToolCallParam{toolCall}
+
+	messages := []openai.ChatCompletionMessageParamUnion{
+		openai.SystemMessage("system"),
+		openai.UserMessage("first"),
+		assistantWithCall,
+		openai.ToolMessage("result", "call_1"),
+		openai.AssistantMessage("answer"),
+		openai.UserMessage("second"),
+	}
+
+	trimmed, err := LastN(3).Trim(context.Background(), messages)
+	require.NoError(t, err)
+
+	// The orphaned tool message is dropped together with its tool call
+	require.Len(t, trimmed, 3)
+	require.Equal(t, "system", MessageText(trimmed[0]))
+	require.Equal(t, "answer", MessageText(trimmed[1]))
+	require.Equal(t, "second", MessageText(trimmed[2]))
+
+	untouched, err := LastN(10).Trim(context.Background(), messages)
+	require.NoError(t, err)
+	require.Len(t, untouched, len(messages))
+}
+
+func TestTokenWindow(t *testing.T) {
+	long := strings.Repeat("word ", 100)
+	messages := []openai.ChatCompletionMessageParamUnion{
+		openai.SystemMessage("system"),
+		openai.UserMessage(long),
+		openai.AssistantMessage(long),
+		openai.UserMessage("short question"),
+	}
+
+	trimmed, err := TokenWindow(150).Trim(context.Background(), messages)
+	require.NoError(t, err)
+	require.Len(t, trimmed, 3)
+	require.Equal(t, "system", MessageText(trimmed[0]))
+	require.Equal(t, "short question", MessageText(trimmed[2]))
+}
+
+func TestSummarizeMiddleTokenThreshold(t *testing.T) {
+	var transcripts []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Model    string `json:"model"`
+			Messages []struct {
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		require.Equal(t, "gpt-4o-mini", body.Model)
+		transcripts = append(transcripts, body.Messages[len(body.Messages)-1].Content)
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"id":"1","object":"chat.completion","choices":[{"index":0,"finish_reason":"stop",`+
+			`"message":{"role":"assistant","content":"summary %d"}}]}`, len(transcripts))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("test"), WithBaseURL(server.URL), WithRequestOptions(option.WithMaxRetries(0)))
+	strategy := SummarizeMiddle(SummarizeConfig{Client: client, Model: "gpt-4o-mini", MaxTokens: 200, KeepLast: 1})
+
+	long := strings.Repeat("word ", 100)
+	messages := []openai.ChatCompletionMessageParamUnion{
+		openai.SystemMessage("system"),
+		openai.UserMessage("short question"),
+		openai.AssistantMessage("short answer"),
+	}
+
+	// Below the threshold, the history is kept
+	trimmed, err := strategy.Trim(context.Background(), messages)
+	require.NoError(t, err)
+	require.Len(t, trimmed, 3)
+	require.Empty(t, transcripts)
+
+	trimmed, err = strategy.Trim(context.Background(), append(messages, openai.UserMessage(long), openai.AssistantMessage(long)))
+	require.NoError(t, err)
+	require.Len(t, trimmed, 3)
+	require.Equal(t, summaryPrefix+"summary 1", MessageText(trimmed[1]))
+
+	// The next compaction folds the previous summary into a single new one
+	trimmed, err = strategy.Trim(context.Background(), append(trimmed, openai.UserMessage(long), openai.AssistantMessage(long)))
+	require.NoError(t, err)
+	require.Len(t, trimmed, 3)
+	require.Equal(t, "system", MessageText(trimmed[0]))
+	require.Equal(t, summaryPrefix+"summary 2", MessageText(trimmed[1]))
+	require.Equal(t, long, MessageText(trimmed[2]))
+	require.Contains(t, transcripts[1], "summary 1")
+}
@@ -0,0 +1,61 @@
+package kit
+
+import (
+	"encoding/json"
+)
+
+// TypedToolExecutor is a tool with compile-time checked arguments and result. The call
+// arguments are decoded into Args, whose JSON schema describes the tool's parameters, rather
+// than unmarshalled into a copy of the tool. Register it with TypedTool.
+type TypedToolExecutor[Args, Result any] interface {
+	AgentToolInfo() AgentToolInfo
+	Execute(ctx *Context, args Args) (Result, error)
+}
+
+// TypedTool adapts a TypedToolExecutor into a ToolExecutor. The name is generated from the
+// tool's type name when AgentToolInfo leaves it empty.
+func TypedTool[Args, Result any](tool TypedToolExecutor[Args, Result]) ToolExecutor {
+	return &typedTool[Args, Result]{tool: tool}
+}
+
+// argumentsTool is implemented by tools that decode the call arguments themselves
+type argumentsTool interface {
+	ToolExecutor
+
+	// arguments returns a value whose JSON schema describes the tool's parameters
+	arguments() any
+
+	// bind returns the tool to execute with the decoded arguments
+	bind(arguments []byte) (ToolExecutor, error)
+}
+
+// typedTool is the ToolExecutor of a TypedToolExecutor, bound to the arguments of a call
+type typedTool[Args, Result any] struct {
+	tool TypedToolExecutor[Args, Result]
+	args Args
+}
+
+func (t *typedTool[Args, Result]) AgentToolInfo() AgentToolInfo {
+	info := t.tool.AgentToolInfo()
+	if info.Name == "" {
+		info.Name = toolTypeName(t.tool)
+	}
+	return info
+}
+
+func (t *typedTool[Args, Result]) Execute(ctx *Context) (any, error) {
+	return t.tool.Execute(ctx, t.args)
+}
+
+func (t *typedTool[Args, Result]) arguments() any {
+	var args Args
+	return args
+}
+
+func (t *typedTool[Args, Result]) bind(arguments []byte) (ToolExecutor, error) {
+	bound := &typedTool[Args, Result]{tool: t.tool}
+	if err := json.Unmarshal(arguments, &bound.args); err != nil {
+		return nil, err
+	}
+	return bound, nil
+}
@@ -0,0 +1,66 @@
+package kit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/openai/openai-go/option"
+	"github.com/stretchr/testify/require"
+)
+
+type searchArgs struct {
+	Query string `json:"query"`
+	Limit int    `json:"limit"`
+}
+
+type searchHits struct {
+	Query string `json:"query"`
+	Hits  int    `json:"hits"`
+}
+
+type typedSearchTool struct {
+	prefix string
+}
+
+func (s *typedSearchTool) AgentToolInfo() AgentToolInfo {
+	return AgentToolInfo{Name: "search_tool", Description: "Searches the index"}
+}
+
+func (s *typedSearchTool) Execute(ctx *Context, args searchArgs) (searchHits, error) {
+	return searchHits{Query: s.prefix + args.Query, Hits: args.Limit}, nil
+}
+
+func TestTypedTool(t *testing.T) {
+	tool := TypedTool[searchArgs, searchHits](&typedSearchTool{prefix: "site:go.dev "})
+
+	toolSchema := BuildToolSchema(tool)
+	require.Equal(t, "search_tool", toolSchema.Name)
+	require.True(t, toolSchema.Strict)
+	require.Contains(t, toolSchema.JSONSchema["properties"], "query")
+	require.Contains(t, toolSchema.JSONSchema["properties"], "limit")
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if requests.Add(1) == 1 {
+			_, _ = w.Write([]byte(toolCallResponse("call_1", `{\"query\":\"generics\",\"limit\":3}`)))
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":"1","object":"chat.completion","choices":[{"index":0,"finish_reason":"stop",` +
+			`"message":{"role":"assistant","content":"done"}}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(
+		WithAPIKey("test"),
+		WithBaseURL(server.URL),
+		WithRequestOptions(option.WithMaxRetries(0)),
+	)
+
+	result, err := CreateAgent(client, tool).InvokeDetailed(context.Background(), InvokeConfig{Prompt: "hi"})
+	require.NoError(t, err)
+	require.Equal(t, `{"query":"site:go.dev generics","hits":3}`, MessageText(result.Messages[len(result.Messages)-2]))
+}
@@ -0,0 +1,138 @@
+package kit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// OutputVersionField is the JSON field OutputVersions stores the schema version of an output in
+const OutputVersionField = "schema_version"
+
+// ErrUnknownOutputVersion is returned (wrapped) when a stored output has a version that isn't registered
+var ErrUnknownOutputVersion = errors.New("unknown output schema version")
+
+// OutputVersions decodes outputs stored with older versions of an Output struct, e.g. in a run
+// store or cache, by migrating them to the current version. Register the older versions with
+// AddOutputVersion. Outputs stored without a version are taken to be of the oldest version.
+type OutputVersions[Output any] struct {
+	current int
+	older   map[int]func(data []byte) (Output, error)
+	oldest  int
+}
+
+// NewOutputVersions creates the versions of an Output struct whose current version is current
+func NewOutputVersions[Output any](current int) *OutputVersions[Output] {
+	return &OutputVersions[Output]{
+		current: current,
+		older:   make(map[int]func(data []byte) (Output, error)),
+		oldest:  current,
+	}
+}
+
+// AddOutputVersion registers an older version of the output, stored as Old and migrated with
+// migrate. Migrations convert straight to the current version; chain them in migrate to reuse
+// the conversion of a newer older version.
+func AddOutputVersion[Old, Output any](
+	versions *OutputVersions[Output],
+	version int,
+	migrate func(old Old) (Output, error),
+) *OutputVersions[Output] {
+	if version >= versions.current {
+		panic(fmt.Sprintf("kit: AddOutputVersion version %d is not older than the current version %d", version, versions.current))
+	}
+
+	versions.older[version] = func(data []byte) (Output, error) {
+		var old Old
+		if err := json.Unmarshal(data, &old); err != nil {
+			var zero Output
+			return zero, fmt.Errorf("%w: %w", ErrOutputParse, err)
+		}
+		return migrate(old)
+	}
+	versions.oldest = min(versions.oldest, version)
+	return versions
+}
+
+// Current returns the current version
+func (v *OutputVersions[Output]) Current() int {
+	return v.current
+}
+
+// Encode marshals the output with the current version in OutputVersionField. Outputs that
+// don't marshal to a JSON object are stored as is.
+func (v *OutputVersions[Output]) Encode(output Output) ([]byte, error) {
+	data, err := json.Marshal(output)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil || fields == nil {
+		return data, nil
+	}
+	fields[OutputVersionField] = json.RawMessage(fmt.Sprint(v.current))
+	return json.Marshal(fields)
+}
+
+// Decode unmarshals a stored output of any registered version, migrating it to the current
+// one, and returns the version it was stored with
+func (v *OutputVersions[Output]) Decode(data []byte) (Output, int, error) {
+	version := v.oldest
+	var header map[string]json.RawMessage
+	if err := json.Unmarshal(data, &header); err == nil {
+		if raw, ok := header[OutputVersionField]; ok {
+			if err := json.Unmarshal(raw, &version); err != nil {
+				var zero Output
+				return zero, 0, fmt.Errorf("invalid %s: %w", OutputVersionField, err)
+			}
+		}
+	}
+
+	if version == v.current {
+		var output Output
+		if err := json.Unmarshal(data, &output); err != nil {
+			return output, version, fmt.Errorf("%w: %w", ErrOutputParse, err)
+		}
+		return output, version, nil
+	}
+
+	decode, ok := v.older[version]
+	if !ok {
+		var zero Output
+		return zero, version, fmt.Errorf("%w: %d", ErrUnknownOutputVersion, version)
+	}
+	output, err := decode(data)
+	if err != nil {
+		return output, version, fmt.Errorf("failed to migrate output from version %d: %w", version, err)
+	}
+	return output, version, nil
+}
+
+// WithOutputVersions stores the outputs of the agent's runs with their schema version and
+// migrates outputs of older versions when they are read back, e.g. for idempotent submissions
+func (a *Agent[Output]) WithOutputVersions(versions *OutputVersions[Output]) *Agent[Output] {
+	a.outputVersions = versions
+	return a
+}
+
+// encodeOutput marshals a run's output for storage
+func (a *Agent[Output]) encodeOutput(output Output) ([]byte, error) {
+	if a.outputVersions == nil {
+		return json.Marshal(output)
+	}
+	return a.outputVersions.Encode(output)
+}
+
+// decodeOutput unmarshals a stored output, migrating it to the current version
+func (a *Agent[Output]) decodeOutput(data []byte, output *Output) error {
+	if a.outputVersions == nil {
+		return json.Unmarshal(data, output)
+	}
+	decoded, _, err := a.outputVersions.Decode(data)
+	if err != nil {
+		return err
+	}
+	*output = decoded
+	return nil
+}
@@ -0,0 +1,44 @@
+package kit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type ticketV1 struct {
+	Priority string `json:"priority"`
+}
+
+type ticketV2 struct {
+	Priority int    `json:"priority"`
+	Team     string `json:"team"`
+}
+
+func TestOutputVersions(t *testing.T) {
+	versions := AddOutputVersion(NewOutputVersions[ticketV2](2), 1, func(old ticketV1) (ticketV2, error) {
+		priority := 3
+		if old.Priority == "high" {
+			priority = 1
+		}
+		return ticketV2{Priority: priority, Team: "triage"}, nil
+	})
+
+	// Outputs stored before versioning are of the oldest version
+	output, version, err := versions.Decode([]byte(`{"priority":"high"}`))
+	require.NoError(t, err)
+	require.Equal(t, 1, version)
+	require.Equal(t, ticketV2{Priority: 1, Team: "triage"}, output)
+
+	data, err := versions.Encode(ticketV2{Priority: 2, Team: "billing"})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"priority":2,"team":"billing","schema_version":2}`, string(data))
+
+	output, version, err = versions.Decode(data)
+	require.NoError(t, err)
+	require.Equal(t, 2, version)
+	require.Equal(t, ticketV2{Priority: 2, Team: "billing"}, output)
+
+	_, _, err = versions.Decode([]byte(`{"priority":2,"schema_version":7}`))
+	require.ErrorIs(t, err, ErrUnknownOutputVersion)
+}
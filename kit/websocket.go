@@ -0,0 +1,179 @@
+package kit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"sync"
+
+	"github.com/openai/openai-go"
+	"golang.org/x/net/websocket"
+)
+
+// WebSocketOptions configures WebSocketHandler. All fields are optional.
+type WebSocketOptions struct {
+	// Config returns the base configuration of the runs of a connection, e.g. the user and
+	// metadata derived from the request. Prompt and Messages are set by the handler.
+	Config func(r *http.Request) InvokeConfig
+}
+
+// wsClientMessage is a message sent by the client: {"type": "message", "content": "..."} starts
+// a run, {"type": "cancel"} cancels the current one
+type wsClientMessage struct {
+	Type    string `json:"type"`
+	Content string `json:"content,omitempty"`
+}
+
+// wsServerMessage is a message sent to the client; Type is one of the StreamEventType values
+type wsServerMessage struct {
+	Type       StreamEventType        `json:"type"`
+	Delta      string                 `json:"delta,omitempty"`
+	ToolName   string                 `json:"tool_name,omitempty"`
+	ToolCallID string                 `json:"tool_call_id,omitempty"`
+	Arguments  map[string]interface{} `json:"arguments,omitempty"`
+	Result     interface{}            `json:"result,omitempty"`
+	Progress   *Progress              `json:"progress,omitempty"`
+	Field      string                 `json:"field,omitempty"`
+	FieldValue json.RawMessage        `json:"value,omitempty"`
+	RunID      string                 `json:"run_id,omitempty"`
+	Output     interface{}            `json:"output,omitempty"`
+	Error      string                 `json:"error,omitempty"`
+}
+
+// WebSocketHandler serves a chat over WebSocket, holding one conversation per connection. Every
+// client message starts a run on the conversation so far, streaming tokens and tool status back;
+// a run is canceled on a cancel message or when the client disconnects.
+func WebSocketHandler[Output any](agent *Agent[Output], opts WebSocketOptions) http.Handler {
+	return websocket.Handler(func(conn *websocket.Conn) {
+		defer conn.Close()
+
+		ctx, cancel := context.WithCancel(conn.Request().Context())
+		defer cancel()
+
+		base := InvokeConfig{}
+		if opts.Config != nil {
+			base = opts.Config(conn.Request())
+		}
+
+		session := &wsSession[Output]{agent: agent, conn: conn, base: base}
+		defer session.wait()
+
+		for {
+			var msg wsClientMessage
+			if err := websocket.JSON.Receive(conn, &msg); err != nil {
+				// The client is gone, abandon the current run
+				return
+			}
+
+			switch msg.Type {
+			case "message":
+				if !session.start(ctx, msg.Content) {
+					session.send(wsServerMessage{Type: StreamEventError, Error: "a run is already in progress"})
+				}
+			case "cancel":
+				session.cancelRun()
+			default:
+				session.send(wsServerMessage{Type: StreamEventError, Error: "unknown message type: " + msg.Type})
+			}
+		}
+	})
+}
+
+// wsSession is the conversation of a WebSocket connection
+type wsSession[Output any] struct {
+	agent *Agent[Output]
+	conn  *websocket.Conn
+	base  InvokeConfig
+
+	writeMu sync.Mutex
+	mu      sync.Mutex
+	history []openai.ChatCompletionMessageParamUnion
+	cancel  context.CancelFunc
+	running sync.WaitGroup
+}
+
+// start runs the agent on the conversation and the new message, unless a run is in progress
+func (s *wsSession[Output]) start(ctx context.Context, content string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cancel != nil {
+		return false
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	s.cancel = cancel
+
+	config := s.base
+	config.Messages = append(append([]openai.ChatCompletionMessageParamUnion(nil), s.history...), openai.UserMessage(content))
+
+	s.running.Add(1)
+	go func() {
+		defer s.running.Done()
+		defer s.finish(nil)
+
+		for event := range s.agent.Stream(runCtx, config) {
+			msg := wsServerMessage{
+				Type:       event.Type,
+				Delta:      event.Delta,
+				ToolName:   event.ToolName,
+				ToolCallID: event.ToolCallID,
+				Arguments:  event.Arguments,
+				Result:     event.ToolResult,
+				Field:      event.Field,
+				FieldValue: event.FieldValue,
+			}
+			if event.Error != nil {
+				msg.Error = event.Error.Error()
+			}
+			switch event.Type {
+			case StreamEventProgress:
+				msg.Progress = &event.Progress
+			case StreamEventDone:
+				msg.RunID = event.Run.RunID
+				msg.Output = event.Run.Output
+				s.finish(append(slices.Clone(config.Messages), event.Run.TurnMessages()...))
+			case StreamEventError:
+				s.finish(nil)
+			}
+			s.send(msg)
+		}
+	}()
+	return true
+}
+
+// cancelRun cancels the run in progress, if any
+func (s *wsSession[Output]) cancelRun() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cancel != nil {
+		s.cancel()
+	}
+}
+
+// finish releases the run, before its final event is sent so the client can start the next one
+// right away. A completed run's history becomes the conversation.
+func (s *wsSession[Output]) finish(history []openai.ChatCompletionMessageParamUnion) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if history != nil {
+		s.history = history
+	}
+	if s.cancel != nil {
+		s.cancel()
+		s.cancel = nil
+	}
+}
+
+// wait blocks until the run in progress, if any, has stopped
+func (s *wsSession[Output]) wait() {
+	s.cancelRun()
+	s.running.Wait()
+}
+
+// send writes a message to the client, ignoring write errors of a closed connection
+func (s *wsSession[Output]) send(msg wsServerMessage) {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	_ = websocket.JSON.Send(s.conn, msg)
+}
@@ -0,0 +1,91 @@
+package kit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/openai/openai-go/option"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
+)
+
+func TestWebSocketHandler(t *testing.T) {
+	server := httptest.NewServer(WebSocketHandler(CreateAgent(streamingClient(t)), WebSocketOptions{}))
+	defer server.Close()
+
+	conn, err := websocket.Dial("ws"+strings.TrimPrefix(server.URL, "http"), "", server.URL)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	for turn := 0; turn < 2; turn++ {
+		require.NoError(t, websocket.JSON.Send(conn, wsClientMessage{Type: "message", Content: "hi"}))
+
+		var types []StreamEventType
+		var reply wsServerMessage
+		for reply.Type != StreamEventDone {
+			reply = wsServerMessage{}
+			require.NoError(t, websocket.JSON.Receive(conn, &reply))
+			types = append(types, reply.Type)
+		}
+		require.Equal(t, []StreamEventType{StreamEventProgress, StreamEventToken, StreamEventToken, StreamEventDone}, types)
+		require.Equal(t, "Hello", reply.Output)
+	}
+}
+
+func TestWebSocketHistorySkipsGuidance(t *testing.T) {
+	var requests [][]string
+	llm := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Messages []struct {
+				Role string `json:"role"`
+			} `json:"messages"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		var roles []string
+		for _, message := range body.Messages {
+			roles = append(roles, message.Role)
+		}
+		requests = append(requests, roles)
+
+		// The first generation comes back empty and is nudged
+		content := "Hello"
+		if len(requests) == 1 {
+			content = ""
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, chunk := range []string{
+			`{"id":"1","object":"chat.completion.chunk","choices":[{"index":0,"delta":{"role":"assistant","content":"` +
+				content + `"},"finish_reason":"stop"}]}`,
+			`[DONE]`,
+		} {
+			_, _ = w.Write([]byte("data: " + chunk + "\n\n"))
+		}
+	}))
+	defer llm.Close()
+
+	client := NewClient(WithAPIKey("test"), WithBaseURL(llm.URL), WithRequestOptions(option.WithMaxRetries(0)))
+	agent := CreateAgent(client).WithEmptyResponsePolicy(EmptyResponsePolicy{Nudge: true})
+	server := httptest.NewServer(WebSocketHandler(agent, WebSocketOptions{}))
+	defer server.Close()
+
+	conn, err := websocket.Dial("ws"+strings.TrimPrefix(server.URL, "http"), "", server.URL)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	for turn := 0; turn < 2; turn++ {
+		require.NoError(t, websocket.JSON.Send(conn, wsClientMessage{Type: "message", Content: "hi"}))
+		var reply wsServerMessage
+		for reply.Type != StreamEventDone {
+			reply = wsServerMessage{}
+			require.NoError(t, websocket.JSON.Receive(conn, &reply))
+			require.Empty(t, reply.Error)
+		}
+	}
+
+	// The second turn replays the first without its nudge
+	require.Len(t, requests, 3)
+	require.Equal(t, []string{"user", "assistant", "user"}, requests[2])
+}
@@ -0,0 +1,263 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mhrlife/goai-kit/internal/kit"
+)
+
+// HubRoute is one MCP server listed by a hub started with StartSSEServerWithRoutes
+type HubRoute struct {
+	BasePath        string `json:"base_path"`
+	SSEEndpoint     string `json:"sse_endpoint"`
+	MessageEndpoint string `json:"message_endpoint"`
+}
+
+// HubConfig configures the connection to an MCP server hub
+type HubConfig struct {
+	// URL of the hub, e.g. http://localhost:8080 (required)
+	URL string
+
+	// Headers are sent with every request to the hub (optional)
+	Headers map[string]string
+
+	// PrefixTools names the tools <route>_<tool>, so routes may expose tools with the same
+	// name (optional, by default duplicate names are an error)
+	PrefixTools bool
+
+	// HTTPClient used for the route listing and the SSE connections (optional)
+	HTTPClient *http.Client
+}
+
+// HubTools holds the tools imported from a hub and the connections they call through.
+// Close it once the agents using the tools are done.
+type HubTools struct {
+	Tools   []kit.ToolExecutor
+	clients []*client.Client
+}
+
+// Close closes the connections to all routes
+func (h *HubTools) Close() error {
+	var firstErr error
+	for _, c := range h.clients {
+		if err := c.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// ListHubRoutes fetches the route listing served at the root of the hub
+func ListHubRoutes(ctx context.Context, config HubConfig) ([]HubRoute, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(config.URL, "/")+"/", nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range config.Headers {
+		req.Header.Set(key, value)
+	}
+
+	httpClient := config.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list hub routes: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to list hub routes: unexpected status %s", resp.Status)
+	}
+
+	var listing struct {
+		Routes []HubRoute `json:"routes"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&listing); err != nil {
+		return nil, fmt.Errorf("failed to decode hub routes: %w", err)
+	}
+
+	return listing.Routes, nil
+}
+
+// ImportHubTools connects to every route of the hub and imports all of their tools
+func ImportHubTools(ctx context.Context, config HubConfig) (*HubTools, error) {
+	routes, err := ListHubRoutes(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+
+	hub := &HubTools{}
+	owners := make(map[string]string) // tool name -> base path of the route exposing it
+	for _, route := range routes {
+		mcpClient, err := connectRoute(ctx, config, route)
+		if err != nil {
+			_ = hub.Close()
+			return nil, err
+		}
+		hub.clients = append(hub.clients, mcpClient)
+
+		listed, err := mcpClient.ListTools(ctx, mcp.ListToolsRequest{})
+		if err != nil {
+			_ = hub.Close()
+			return nil, fmt.Errorf("failed to list tools of route %s: %w", route.BasePath, err)
+		}
+
+		for _, tool := range listed.Tools {
+			remote, err := newRemoteTool(mcpClient, tool)
+			if err != nil {
+				_ = hub.Close()
+				return nil, fmt.Errorf("failed to import tool %s of route %s: %w", tool.Name, route.BasePath, err)
+			}
+
+			if config.PrefixTools {
+				remote.name = routeName(route.BasePath) + "_" + tool.Name
+			}
+			if owner, exists := owners[remote.name]; exists {
+				_ = hub.Close()
+				return nil, fmt.Errorf(
+					"tool %s is exposed by routes %s and %s, set PrefixTools to import both",
+					remote.name, owner, route.BasePath,
+				)
+			}
+			owners[remote.name] = route.BasePath
+
+			hub.Tools = append(hub.Tools, remote)
+		}
+	}
+
+	return hub, nil
+}
+
+// NewHubAgent builds an agent exposing every tool of every route of the hub
+func NewHubAgent(ctx context.Context, kitClient *kit.Client, config HubConfig) (*kit.Agent[string], *HubTools, error) {
+	hub, err := ImportHubTools(ctx, config)
+	if err != nil {
+		return nil, nil, err
+	}
+	return kit.CreateAgent(kitClient, hub.Tools...), hub, nil
+}
+
+// connectRoute opens and initializes an SSE client session to a route
+func connectRoute(ctx context.Context, config HubConfig, route HubRoute) (*client.Client, error) {
+	var options []transport.ClientOption
+	if len(config.Headers) > 0 {
+		options = append(options, client.WithHeaders(config.Headers))
+	}
+	if config.HTTPClient != nil {
+		options = append(options, client.WithHTTPClient(config.HTTPClient))
+	}
+
+	mcpClient, err := client.NewSSEMCPClient(strings.TrimSuffix(config.URL, "/")+route.SSEEndpoint, options...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client for route %s: %w", route.BasePath, err)
+	}
+
+	if err := mcpClient.Start(ctx); err != nil {
+		return nil, fmt.Errorf("failed to connect to route %s: %w", route.BasePath, err)
+	}
+
+	initRequest := mcp.InitializeRequest{}
+	initRequest.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	initRequest.Params.ClientInfo = mcp.Implementation{Name: "goai-kit", Version: "1.0.0"}
+	if _, err := mcpClient.Initialize(ctx, initRequest); err != nil {
+		_ = mcpClient.Close()
+		return nil, fmt.Errorf("failed to initialize route %s: %w", route.BasePath, err)
+	}
+
+	return mcpClient, nil
+}
+
+// routeName turns a route base path like /weather/v1 into weather_v1
+func routeName(basePath string) string {
+	return strings.ReplaceAll(strings.Trim(basePath, "/"), "/", "_")
+}
+
+// remoteTool exposes a tool of a remote MCP server to agents
+type remoteTool struct {
+	client      *client.Client
+	name        string
+	remoteName  string
+	description string
+	schema      map[string]any
+	arguments   map[string]any
+}
+
+var _ kit.RawSchemaTool = &remoteTool{}
+
+func newRemoteTool(mcpClient *client.Client, tool mcp.Tool) (*remoteTool, error) {
+	rawSchema := tool.RawInputSchema
+	if len(rawSchema) == 0 {
+		var err error
+		if rawSchema, err = json.Marshal(tool.InputSchema); err != nil {
+			return nil, err
+		}
+	}
+
+	var schema map[string]any
+	if err := json.Unmarshal(rawSchema, &schema); err != nil {
+		return nil, fmt.Errorf("invalid input schema: %w", err)
+	}
+	if _, ok := schema["properties"]; !ok {
+		schema["properties"] = map[string]any{}
+	}
+
+	return &remoteTool{
+		client:      mcpClient,
+		name:        tool.Name,
+		remoteName:  tool.Name,
+		description: tool.Description,
+		schema:      schema,
+	}, nil
+}
+
+func (t *remoteTool) AgentToolInfo() kit.AgentToolInfo {
+	return kit.AgentToolInfo{
+		Name:        t.name,
+		Description: t.description,
+	}
+}
+
+func (t *remoteTool) ToolJSONSchema() map[string]any {
+	return t.schema
+}
+
+// UnmarshalJSON receives the call arguments chosen by the model
+func (t *remoteTool) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &t.arguments)
+}
+
+func (t *remoteTool) Execute(ctx *kit.Context) (any, error) {
+	request := mcp.CallToolRequest{}
+	request.Params.Name = t.remoteName
+	request.Params.Arguments = t.arguments
+
+	result, err := t.client.CallTool(ctx, request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call remote tool %s: %w", t.remoteName, err)
+	}
+
+	var texts []string
+	for _, content := range result.Content {
+		if text, ok := content.(mcp.TextContent); ok {
+			texts = append(texts, text.Text)
+		}
+	}
+
+	if result.IsError {
+		return nil, fmt.Errorf("remote tool %s failed: %s", t.remoteName, strings.Join(texts, "\n"))
+	}
+	if len(texts) == 0 && result.StructuredContent != nil {
+		return result.StructuredContent, nil
+	}
+	return strings.Join(texts, "\n"), nil
+}
@@ -0,0 +1,74 @@
+package mcp
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mhrlife/goai-kit/internal/kit"
+	"github.com/stretchr/testify/require"
+)
+
+type forecastTool struct {
+	kit.BaseTool
+	City string `json:"city"`
+}
+
+func (t *forecastTool) AgentToolInfo() kit.AgentToolInfo {
+	return kit.AgentToolInfo{Name: "forecast", Description: "Forecasts the weather of a city"}
+}
+
+func (t *forecastTool) Execute(ctx *kit.Context) (any, error) {
+	return "sunny in " + t.City, nil
+}
+
+// hubServer serves a hub whose routes all expose the forecast tool
+func hubServer(t *testing.T, paths ...string) *httptest.Server {
+	client := kit.NewClient(kit.WithAPIKey("test"))
+
+	var routes []ServerRoute
+	for _, path := range paths {
+		mcpServer, err := NewMCPServer(client, path, "1.0.0", &forecastTool{})
+		require.NoError(t, err)
+		routes = append(routes, ServerRoute{Path: path, Server: mcpServer})
+	}
+
+	handler, err := hubHandler(routes...)
+	require.NoError(t, err)
+	hub := httptest.NewServer(handler)
+	t.Cleanup(hub.Close)
+	return hub
+}
+
+func TestImportHubTools(t *testing.T) {
+	ctx := context.Background()
+	hub := hubServer(t, "/weather", "/maps/v1/")
+
+	routes, err := ListHubRoutes(ctx, HubConfig{URL: hub.URL})
+	require.NoError(t, err)
+	require.Equal(t, []HubRoute{
+		{BasePath: "/weather", SSEEndpoint: "/weather/sse", MessageEndpoint: "/weather/message"},
+		{BasePath: "/maps/v1", SSEEndpoint: "/maps/v1/sse", MessageEndpoint: "/maps/v1/message"},
+	}, routes)
+
+	// Both routes expose the same tool, so it can only be imported with prefixes
+	_, err = ImportHubTools(ctx, HubConfig{URL: hub.URL})
+	require.ErrorContains(t, err, "tool forecast is exposed by routes /weather and /maps/v1")
+
+	tools, err := ImportHubTools(ctx, HubConfig{URL: hub.URL, PrefixTools: true})
+	require.NoError(t, err)
+	defer tools.Close()
+
+	require.Len(t, tools.Tools, 2)
+	schema := kit.BuildToolSchema(tools.Tools[1])
+	require.Equal(t, "maps_v1_forecast", schema.Name)
+	require.Equal(t, "Forecasts the weather of a city", schema.Description)
+	require.Contains(t, schema.JSONSchema["properties"], "city")
+
+	// Calls go to the tool of the route, under its remote name
+	remote := tools.Tools[1].(*remoteTool)
+	require.NoError(t, remote.UnmarshalJSON([]byte(`{"city":"Paris"}`)))
+	result, err := remote.Execute(&kit.Context{Context: ctx})
+	require.NoError(t, err)
+	require.Equal(t, "sunny in Paris", result)
+}
@@ -30,7 +30,7 @@ func NewMCPServer(client *kit.Client, name, version string, tools ...kit.ToolExe
 	for _, tool := range tools {
 		if err := addGenericToolToMCP(client, s, tool); err != nil {
 			schema := kit.BuildToolSchema(tool)
-			client.logger.Error("Failed to add tool",
+			client.Logger.Error("Failed to add tool",
 				"tool_name", schema.ID,
 				"error", err,
 			)
@@ -38,8 +38,8 @@ func NewMCPServer(client *kit.Client, name, version string, tools ...kit.ToolExe
 			return nil, err
 		}
 
-		schema := BuildToolSchema(tool)
-		client.logger.Info("Added MCP tool",
+		schema := kit.BuildToolSchema(tool)
+		client.Logger.Info("Added MCP tool",
 			"server_name", name,
 			"tool_name", schema.ID,
 			"tool_description", schema.Description,
@@ -49,8 +49,8 @@ func NewMCPServer(client *kit.Client, name, version string, tools ...kit.ToolExe
 	return s, nil
 }
 
-func addGenericToolToMCP(client *Client, s *server.MCPServer, tool ToolExecutor) error {
-	schema := BuildToolSchema(tool)
+func addGenericToolToMCP(client *kit.Client, s *server.MCPServer, tool kit.ToolExecutor) error {
+	schema := kit.BuildToolSchema(tool)
 
 	schemaJSON, err := json.Marshal(schema.JSONSchema)
 	if err != nil {
@@ -74,17 +74,27 @@ func addGenericToolToMCP(client *Client, s *server.MCPServer, tool ToolExecutor)
 			}
 
 			// Create new instance and unmarshal args
-			toolCopy := reflect.New(toolValue.Type()).Interface().(ToolExecutor)
+			toolCopy := reflect.New(toolValue.Type()).Interface().(kit.ToolExecutor)
 			if err := json.Unmarshal(argsJSON, toolCopy); err != nil {
 				return nil, fmt.Errorf("failed to unmarshal tool arguments: %w", err)
 			}
 
-			// Execute tool
-			ctxWrapper := &Context{
-				Context: ctx,
-				logger:  client.logger,
+			// Forward the tool's progress as MCP progress notifications when the client asked for them
+			if request.Params.Meta != nil && request.Params.Meta.ProgressToken != nil {
+				token := request.Params.Meta.ProgressToken
+				ctx = kit.WithProgressReporter(ctx, func(percent float64, message string) {
+					_ = s.SendNotificationToClient(ctx, "notifications/progress", map[string]any{
+						"progressToken": token,
+						"progress":      percent,
+						"total":         100,
+						"message":       message,
+					})
+				})
 			}
 
+			// Execute tool
+			ctxWrapper := &kit.Context{Context: ctx}
+
 			result, err := toolCopy.Execute(ctxWrapper)
 			if err != nil {
 				return nil, fmt.Errorf("tool execution failed: %w", err)
@@ -120,15 +130,26 @@ type ServerRoute struct {
 }
 
 func StartSSEServerWithRoutes(addr string, routes ...ServerRoute) error {
+	mux, err := hubHandler(routes...)
+	if err != nil {
+		return err
+	}
+
+	slog.Info("Starting MCP server hub",
+		"address", addr,
+		"routes_count", len(routes),
+	)
+
+	return http.ListenAndServe(addr, mux)
+}
+
+// hubHandler serves every route's SSE server under its path and the route listing at the root
+func hubHandler(routes ...ServerRoute) (http.Handler, error) {
 	if len(routes) == 0 {
-		return fmt.Errorf("at least one server route is required")
+		return nil, fmt.Errorf("at least one server route is required")
 	}
 
 	mux := http.NewServeMux()
-	httpSrv := &http.Server{
-		Addr:    addr,
-		Handler: mux,
-	}
 
 	for _, route := range routes {
 		basePath := route.Path
@@ -142,14 +163,13 @@ func StartSSEServerWithRoutes(addr string, routes ...ServerRoute) error {
 
 		sseServer := server.NewSSEServer(
 			route.Server,
-			server.WithHTTPServer(httpSrv),
 			server.WithStaticBasePath(basePath),
 			server.WithSSEEndpoint("/sse"),
 			server.WithMessageEndpoint("/message"),
 		)
 
 		sseEndpointPath := basePath + "/sse"
-		mux.Handle("/default/sse", sseServer.SSEHandler())
+		mux.Handle(sseEndpointPath, sseServer.SSEHandler())
 
 		messageEndpointPath := basePath + "/message"
 		mux.Handle(messageEndpointPath, sseServer.MessageHandler())
@@ -196,12 +216,7 @@ func StartSSEServerWithRoutes(addr string, routes ...ServerRoute) error {
 		http.NotFound(w, r)
 	})
 
-	slog.Info("Starting MCP server hub",
-		"address", addr,
-		"routes_count", len(routes),
-	)
-
-	return http.ListenAndServe(addr, mux)
+	return mux, nil
 }
 
 // StartSSEServer - keep the original function for backward compatibility
@@ -0,0 +1,84 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/openai/openai-go"
+)
+
+// Store persists conversation histories keyed by conversation ID
+type Store interface {
+	// Load returns the messages of the conversation, oldest first (empty when unknown)
+	Load(ctx context.Context, conversationID string) ([]openai.ChatCompletionMessageParamUnion, error)
+
+	// Append adds messages to the end of the conversation
+	Append(ctx context.Context, conversationID string, messages ...openai.ChatCompletionMessageParamUnion) error
+
+	// Delete removes the conversation
+	Delete(ctx context.Context, conversationID string) error
+}
+
+// Turn describes the run whose input and generated messages were appended to a conversation
+type Turn struct {
+	RunID     string
+	Model     string
+	Usage     openai.CompletionUsage
+	CreatedAt time.Time
+}
+
+// TurnStore is implemented by stores that also record the run of every appended turn, e.g.
+// for audit trails. Agents call AppendTurn instead of Append on such stores.
+type TurnStore interface {
+	Store
+
+	// AppendTurn adds the messages of a run to the end of the conversation
+	AppendTurn(ctx context.Context, conversationID string, turn Turn, messages ...openai.ChatCompletionMessageParamUnion) error
+}
+
+// InMemoryStore keeps conversations in process memory, useful for tests and single-instance apps
+type InMemoryStore struct {
+	mu            sync.RWMutex
+	conversations map[string][]openai.ChatCompletionMessageParamUnion
+}
+
+// NewInMemoryStore creates an empty in-memory conversation store
+func NewInMemoryStore() *InMemoryStore {
+	return &InMemoryStore{
+		conversations: make(map[string][]openai.ChatCompletionMessageParamUnion),
+	}
+}
+
+func (s *InMemoryStore) Load(
+	_ context.Context,
+	conversationID string,
+) ([]openai.ChatCompletionMessageParamUnion, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	stored := s.conversations[conversationID]
+	messages := make([]openai.ChatCompletionMessageParamUnion, len(stored))
+	copy(messages, stored)
+	return messages, nil
+}
+
+func (s *InMemoryStore) Append(
+	_ context.Context,
+	conversationID string,
+	messages ...openai.ChatCompletionMessageParamUnion,
+) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.conversations[conversationID] = append(s.conversations[conversationID], messages...)
+	return nil
+}
+
+func (s *InMemoryStore) Delete(_ context.Context, conversationID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.conversations, conversationID)
+	return nil
+}
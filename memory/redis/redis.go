@@ -0,0 +1,138 @@
+// Package redis provides a Redis backed conversation store, so conversations can be resumed
+// by any instance of a multi-instance chat backend.
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/openai/openai-go"
+)
+
+// Client runs a Redis command and returns its reply, bulk strings as string or []byte and
+// arrays as []any. A go-redis client is adapted with
+//
+//	redis.ClientFunc(func(ctx context.Context, args ...any) (any, error) {
+//		return rdb.Do(ctx, args...).Result()
+//	})
+type Client interface {
+	Do(ctx context.Context, args ...any) (any, error)
+}
+
+// ClientFunc adapts a function to a Client
+type ClientFunc func(ctx context.Context, args ...any) (any, error)
+
+func (f ClientFunc) Do(ctx context.Context, args ...any) (any, error) {
+	return f(ctx, args...)
+}
+
+// StoreConfig configures a Redis backed conversation store
+type StoreConfig struct {
+	// Client runs the Redis commands (required)
+	Client Client
+
+	// Prefix of the list keys, followed by the conversation ID (optional, defaults to
+	// "goai:conversation:")
+	Prefix string
+
+	// TTL expires conversations not appended to for this long (optional, 0 keeps them forever)
+	TTL time.Duration
+}
+
+// Store keeps every conversation in a Redis list of JSON encoded messages. It implements
+// memory.Store.
+type Store struct {
+	client Client
+	prefix string
+	ttl    time.Duration
+}
+
+// NewStore creates a Redis backed conversation store
+func NewStore(config StoreConfig) *Store {
+	if config.Client == nil {
+		panic("Client is required")
+	}
+
+	prefix := config.Prefix
+	if prefix == "" {
+		prefix = "goai:conversation:"
+	}
+
+	return &Store{
+		client: config.Client,
+		prefix: prefix,
+		ttl:    config.TTL,
+	}
+}
+
+func (s *Store) key(conversationID string) string {
+	return s.prefix + conversationID
+}
+
+func (s *Store) Load(ctx context.Context, conversationID string) ([]openai.ChatCompletionMessageParamUnion, error) {
+	reply, err := s.client.Do(ctx, "LRANGE", s.key(conversationID), 0, -1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load conversation: %w", err)
+	}
+
+	items, ok := reply.([]any)
+	if !ok && reply != nil {
+		return nil, fmt.Errorf("unexpected LRANGE reply %T", reply)
+	}
+
+	messages := make([]openai.ChatCompletionMessageParamUnion, 0, len(items))
+	for _, item := range items {
+		var data []byte
+		switch value := item.(type) {
+		case string:
+			data = []byte(value)
+		case []byte:
+			data = value
+		default:
+			return nil, fmt.Errorf("unexpected message type %T", item)
+		}
+
+		var message openai.ChatCompletionMessageParamUnion
+		if err := json.Unmarshal(data, &message); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal message: %w", err)
+		}
+		messages = append(messages, message)
+	}
+	return messages, nil
+}
+
+// Append adds the messages and, with a TTL, restarts the expiry of the conversation
+func (s *Store) Append(ctx context.Context, conversationID string, messages ...openai.ChatCompletionMessageParamUnion) error {
+	if len(messages) == 0 {
+		return nil
+	}
+
+	key := s.key(conversationID)
+	args := []any{"RPUSH", key}
+	for _, message := range messages {
+		data, err := json.Marshal(message)
+		if err != nil {
+			return fmt.Errorf("failed to marshal message: %w", err)
+		}
+		args = append(args, string(data))
+	}
+
+	if _, err := s.client.Do(ctx, args...); err != nil {
+		return fmt.Errorf("failed to append messages: %w", err)
+	}
+	if s.ttl > 0 {
+		if _, err := s.client.Do(ctx, "PEXPIRE", key, s.ttl.Milliseconds()); err != nil {
+			return fmt.Errorf("failed to set conversation expiry: %w", err)
+		}
+	}
+	return nil
+}
+
+func (s *Store) Delete(ctx context.Context, conversationID string) error {
+	if _, err := s.client.Do(ctx, "DEL", s.key(conversationID)); err != nil {
+		return fmt.Errorf("failed to delete conversation: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/openai/openai-go"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRedis implements the list commands used by the store
+type fakeRedis struct {
+	lists   map[string][]string
+	expires map[string]int64
+}
+
+func (f *fakeRedis) Do(_ context.Context, args ...any) (any, error) {
+	key := args[1].(string)
+	switch args[0] {
+	case "RPUSH":
+		for _, value := range args[2:] {
+			f.lists[key] = append(f.lists[key], value.(string))
+		}
+		return int64(len(f.lists[key])), nil
+	case "LRANGE":
+		items := make([]any, 0, len(f.lists[key]))
+		for _, value := range f.lists[key] {
+			items = append(items, value)
+		}
+		return items, nil
+	case "PEXPIRE":
+		f.expires[key] = args[2].(int64)
+		return int64(1), nil
+	case "DEL":
+		delete(f.lists, key)
+		return int64(1), nil
+	}
+	return nil, nil
+}
+
+func TestStore(t *testing.T) {
+	ctx := context.Background()
+	fake := &fakeRedis{lists: map[string][]string{}, expires: map[string]int64{}}
+	store := NewStore(StoreConfig{Client: fake, TTL: time.Hour})
+
+	require.NoError(t, store.Append(ctx, "c1", openai.UserMessage("hi"), openai.AssistantMessage("hello")))
+	require.NoError(t, store.Append(ctx, "c1", openai.UserMessage("bye")))
+	require.Equal(t, time.Hour.Milliseconds(), fake.expires["goai:conversation:c1"])
+
+	messages, err := store.Load(ctx, "c1")
+	require.NoError(t, err)
+	require.Len(t, messages, 3)
+	require.Equal(t, "hello", messages[1].OfAssistant.Content.OfString.Value)
+	require.Equal(t, "bye", messages[2].OfUser.Content.OfString.Value)
+
+	require.NoError(t, store.Delete(ctx, "c1"))
+	messages, err = store.Load(ctx, "c1")
+	require.NoError(t, err)
+	require.Empty(t, messages)
+}
@@ -0,0 +1,308 @@
+// Package sql provides a database/sql backed conversation store that also records the run,
+// tool calls and token usage of every turn, for audit trails and conversation replay.
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mhrlife/goai-kit/internal/memory"
+	"github.com/openai/openai-go"
+)
+
+// Dialect selects the SQL placeholder style used by Store
+type Dialect string
+
+const (
+	DialectPostgres Dialect = "postgres"
+	DialectMySQL    Dialect = "mysql"
+	DialectSQLite   Dialect = "sqlite"
+)
+
+// StoreConfig configures a database/sql backed conversation store
+type StoreConfig struct {
+	// DB is the database handle (required)
+	DB *sql.DB
+
+	// Dialect of the database (optional, defaults to postgres)
+	Dialect Dialect
+
+	// TablePrefix of the messages and turns tables (optional, defaults to "goai_conversation")
+	TablePrefix string
+}
+
+// Store persists conversations in two tables: <prefix>_messages holds every message in order,
+// with the run that produced it and the tool calls it requested or answers, and <prefix>_turns
+// the model and token usage of every run. It implements memory.TurnStore.
+type Store struct {
+	db       *sql.DB
+	dialect  Dialect
+	messages string
+	turns    string
+}
+
+// NewStore creates a SQL backed conversation store. Call Migrate to create the tables.
+func NewStore(config StoreConfig) *Store {
+	if config.DB == nil {
+		panic("DB is required")
+	}
+
+	dialect := config.Dialect
+	if dialect == "" {
+		dialect = DialectPostgres
+	}
+
+	prefix := config.TablePrefix
+	if prefix == "" {
+		prefix = "goai_conversation"
+	}
+
+	return &Store{
+		db:       config.DB,
+		dialect:  dialect,
+		messages: prefix + "_messages",
+		turns:    prefix + "_turns",
+	}
+}
+
+// Migrate creates the tables and their indexes if they don't exist
+func (s *Store) Migrate(ctx context.Context) error {
+	statements := []string{
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	conversation_id VARCHAR(255) NOT NULL,
+	position INTEGER NOT NULL,
+	run_id VARCHAR(64),
+	role VARCHAR(32),
+	tool_call_id VARCHAR(255),
+	tool_calls TEXT,
+	data TEXT,
+	created_at TIMESTAMP,
+	PRIMARY KEY (conversation_id, position)
+)`, s.messages),
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	run_id VARCHAR(64) PRIMARY KEY,
+	conversation_id VARCHAR(255) NOT NULL,
+	model VARCHAR(255),
+	prompt_tokens BIGINT,
+	completion_tokens BIGINT,
+	total_tokens BIGINT,
+	usage_details TEXT,
+	created_at TIMESTAMP
+)`, s.turns),
+		fmt.Sprintf(`CREATE INDEX %s idx_%s_run ON %s (run_id)`, s.ifNotExists(), s.messages, s.messages),
+		fmt.Sprintf(`CREATE INDEX %s idx_%s_conversation ON %s (conversation_id, created_at)`,
+			s.ifNotExists(), s.turns, s.turns),
+	}
+
+	for _, statement := range statements {
+		if _, err := s.db.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("failed to migrate conversation tables: %w", err)
+		}
+	}
+	return nil
+}
+
+// ifNotExists returns the index creation guard supported by the dialect
+func (s *Store) ifNotExists() string {
+	if s.dialect == DialectMySQL {
+		return ""
+	}
+	return "IF NOT EXISTS"
+}
+
+// placeholder returns the n-th (1-based) bind parameter for the dialect
+func (s *Store) placeholder(n int) string {
+	if s.dialect == DialectPostgres {
+		return fmt.Sprintf("$%d", n)
+	}
+	return "?"
+}
+
+// placeholders returns the bind parameters from the n-th (1-based) on for count values
+func (s *Store) placeholders(n, count int) string {
+	result := make([]string, count)
+	for i := range result {
+		result[i] = s.placeholder(n + i)
+	}
+	return strings.Join(result, ", ")
+}
+
+func (s *Store) Load(ctx context.Context, conversationID string) ([]openai.ChatCompletionMessageParamUnion, error) {
+	query := fmt.Sprintf("SELECT data FROM %s WHERE conversation_id = %s ORDER BY position",
+		s.messages, s.placeholder(1))
+	return s.queryMessages(ctx, query, conversationID)
+}
+
+// Append adds messages that belong to no recorded run
+func (s *Store) Append(ctx context.Context, conversationID string, messages ...openai.ChatCompletionMessageParamUnion) error {
+	return s.append(ctx, conversationID, nil, messages)
+}
+
+// AppendTurn adds the messages of a run and records its model and usage
+func (s *Store) AppendTurn(
+	ctx context.Context,
+	conversationID string,
+	turn memory.Turn,
+	messages ...openai.ChatCompletionMessageParamUnion,
+) error {
+	return s.append(ctx, conversationID, &turn, messages)
+}
+
+func (s *Store) append(
+	ctx context.Context,
+	conversationID string,
+	turn *memory.Turn,
+	messages []openai.ChatCompletionMessageParamUnion,
+) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	// Number the messages after the last stored one
+	var last sql.NullInt64
+	maxQuery := fmt.Sprintf("SELECT MAX(position) FROM %s WHERE conversation_id = %s", s.messages, s.placeholder(1))
+	if err := tx.QueryRowContext(ctx, maxQuery, conversationID).Scan(&last); err != nil {
+		return fmt.Errorf("failed to append messages: %w", err)
+	}
+	position := 0
+	if last.Valid {
+		position = int(last.Int64) + 1
+	}
+
+	createdAt := time.Now()
+	var runID string
+	if turn != nil {
+		runID = turn.RunID
+		if !turn.CreatedAt.IsZero() {
+			createdAt = turn.CreatedAt
+		}
+	}
+
+	insertMessage := fmt.Sprintf(`INSERT INTO %s (
+	conversation_id, position, run_id, role, tool_call_id, tool_calls, data, created_at
+) VALUES (%s)`, s.messages, s.placeholders(1, 8))
+	for i, message := range messages {
+		data, err := json.Marshal(message)
+		if err != nil {
+			return fmt.Errorf("failed to marshal message: %w", err)
+		}
+		role, toolCallID, toolCalls := messageColumns(data)
+
+		_, err = tx.ExecContext(ctx, insertMessage,
+			conversationID, position+i, runID, role, toolCallID, toolCalls, string(data), createdAt.UTC(),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to append messages: %w", err)
+		}
+	}
+
+	if turn != nil {
+		usage, err := json.Marshal(turn.Usage)
+		if err != nil {
+			return fmt.Errorf("failed to marshal usage: %w", err)
+		}
+
+		insertTurn := fmt.Sprintf(`INSERT INTO %s (
+	run_id, conversation_id, model, prompt_tokens, completion_tokens, total_tokens, usage_details, created_at
+) VALUES (%s)`, s.turns, s.placeholders(1, 8))
+		_, err = tx.ExecContext(ctx, insertTurn,
+			turn.RunID, conversationID, turn.Model, turn.Usage.PromptTokens, turn.Usage.CompletionTokens,
+			turn.Usage.TotalTokens, string(usage), createdAt.UTC(),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to record turn: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// messageColumns extracts the indexed columns of an encoded message: its role, the tool call a
+// tool message answers and the tool calls an assistant message requested, as JSON
+func messageColumns(data []byte) (role, toolCallID, toolCalls string) {
+	var fields struct {
+		Role       string          `json:"role"`
+		ToolCallID string          `json:"tool_call_id"`
+		ToolCalls  json.RawMessage `json:"tool_calls"`
+	}
+	_ = json.Unmarshal(data, &fields)
+	return fields.Role, fields.ToolCallID, string(fields.ToolCalls)
+}
+
+func (s *Store) Delete(ctx context.Context, conversationID string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	for _, table := range []string{s.messages, s.turns} {
+		query := fmt.Sprintf("DELETE FROM %s WHERE conversation_id = %s", table, s.placeholder(1))
+		if _, err := tx.ExecContext(ctx, query, conversationID); err != nil {
+			return fmt.Errorf("failed to delete conversation: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// Turns returns the recorded runs of the conversation, oldest first
+func (s *Store) Turns(ctx context.Context, conversationID string) ([]memory.Turn, error) {
+	query := fmt.Sprintf("SELECT run_id, model, usage_details, created_at FROM %s WHERE conversation_id = %s ORDER BY created_at",
+		s.turns, s.placeholder(1))
+
+	rows, err := s.db.QueryContext(ctx, query, conversationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query turns: %w", err)
+	}
+	defer rows.Close()
+
+	turns := make([]memory.Turn, 0)
+	for rows.Next() {
+		var turn memory.Turn
+		var usage string
+		if err := rows.Scan(&turn.RunID, &turn.Model, &usage, &turn.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan turn: %w", err)
+		}
+		if err := json.Unmarshal([]byte(usage), &turn.Usage); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal usage: %w", err)
+		}
+		turns = append(turns, turn)
+	}
+	return turns, rows.Err()
+}
+
+// RunMessages returns the messages appended by a run, for replaying a single turn
+func (s *Store) RunMessages(ctx context.Context, runID string) ([]openai.ChatCompletionMessageParamUnion, error) {
+	query := fmt.Sprintf("SELECT data FROM %s WHERE run_id = %s ORDER BY position", s.messages, s.placeholder(1))
+	return s.queryMessages(ctx, query, runID)
+}
+
+// queryMessages decodes the data column of the selected messages
+func (s *Store) queryMessages(ctx context.Context, query string, arg any) ([]openai.ChatCompletionMessageParamUnion, error) {
+	rows, err := s.db.QueryContext(ctx, query, arg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query messages: %w", err)
+	}
+	defer rows.Close()
+
+	messages := make([]openai.ChatCompletionMessageParamUnion, 0)
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("failed to scan message: %w", err)
+		}
+
+		var message openai.ChatCompletionMessageParamUnion
+		if err := json.Unmarshal([]byte(data), &message); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal message: %w", err)
+		}
+		messages = append(messages, message)
+	}
+	return messages, rows.Err()
+}
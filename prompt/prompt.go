@@ -9,6 +9,8 @@ import (
 	"log/slog"
 	"path/filepath"
 	"text/template"
+
+	"github.com/mhrlife/goai-kit/internal/schema"
 )
 
 type Render[Context any] struct {
@@ -107,7 +109,7 @@ func toJSONwSchema(v interface{}) string {
 		return "Error converting to JSON: " + err.Error()
 	}
 
-	jsonschema := MarshalToSchema(v)
+	jsonschema := schema.MarshalToSchema(v)
 	jsonSchemaBytes, err := json.MarshalIndent(jsonschema, "", "  ")
 	if err != nil {
 		return "Error converting schema to JSON: " + err.Error()
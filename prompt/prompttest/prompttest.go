@@ -0,0 +1,160 @@
+package prompttest
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mhrlife/goai-kit/internal/prompt"
+)
+
+// update rewrites the golden files with the current renderings instead of comparing them:
+// go test ./... -update-golden (or PROMPTTEST_UPDATE=1)
+var update = flag.Bool("update-golden", false, "rewrite prompt golden files")
+
+// Case renders a named template and compares the result with a golden file
+type Case[Context any] struct {
+	// Name of the subtest (defaults to Template)
+	Name string
+
+	// Template is the name of the template to render
+	Template string
+
+	// Render is the data the template is rendered with
+	Render prompt.Render[Context]
+
+	// Fixture is a JSON file decoded into Render.Data when Data is nil (optional)
+	Fixture string
+
+	// Golden is the path of the golden file (defaults to testdata/<Name>.golden)
+	Golden string
+}
+
+// Golden renders every case as a subtest and fails it with a line diff when the rendering
+// differs from its golden file. Missing golden files are created on update only.
+func Golden[Context any](t *testing.T, tpl prompt.Template[Context], cases ...Case[Context]) {
+	t.Helper()
+
+	for _, c := range cases {
+		name := c.Name
+		if name == "" {
+			name = c.Template
+		}
+
+		t.Run(name, func(t *testing.T) {
+			t.Helper()
+
+			render := c.Render
+			if render.Data == nil && c.Fixture != "" {
+				render.Data = loadFixture(t, c.Fixture)
+			}
+
+			got, err := tpl.Execute(c.Template, render)
+			if err != nil {
+				t.Fatalf("rendering template %q: %v", c.Template, err)
+			}
+
+			golden := c.Golden
+			if golden == "" {
+				golden = filepath.Join("testdata", name+".golden")
+			}
+			Compare(t, golden, got)
+		})
+	}
+}
+
+// Compare compares got with the golden file at path, or rewrites the file on update
+func Compare(t testing.TB, path, got string) {
+	t.Helper()
+
+	if updating() {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("creating golden directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatalf("writing golden file: %v", err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading golden file (run with -update-golden to create it): %v", err)
+	}
+	if string(want) != got {
+		t.Errorf("rendering differs from %s (run with -update-golden to accept it):\n%s", path, Diff(string(want), got))
+	}
+}
+
+func updating() bool {
+	return *update || os.Getenv("PROMPTTEST_UPDATE") == "1"
+}
+
+// loadFixture decodes a JSON fixture file
+func loadFixture(t testing.TB, path string) any {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading fixture: %v", err)
+	}
+	var fixture any
+	if err := json.Unmarshal(data, &fixture); err != nil {
+		t.Fatalf("decoding fixture %s: %v", path, err)
+	}
+	return fixture
+}
+
+// Diff returns a line diff of want and got: removed lines prefixed with "-", added lines with
+// "+" and unchanged lines with " ", each with its line number in want or got. Whitespace at
+// the end of changed lines is made visible.
+func Diff(want, got string) string {
+	wantLines := strings.Split(want, "\n")
+	gotLines := strings.Split(got, "\n")
+
+	// Longest common subsequence of the lines, from the end
+	lcs := make([][]int, len(wantLines)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(gotLines)+1)
+	}
+	for i := len(wantLines) - 1; i >= 0; i-- {
+		for j := len(gotLines) - 1; j >= 0; j-- {
+			if wantLines[i] == gotLines[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var b strings.Builder
+	i, j := 0, 0
+	for i < len(wantLines) || j < len(gotLines) {
+		switch {
+		case i < len(wantLines) && j < len(gotLines) && wantLines[i] == gotLines[j]:
+			fmt.Fprintf(&b, "  %3d  %s\n", i+1, wantLines[i])
+			i++
+			j++
+		case j < len(gotLines) && (i == len(wantLines) || lcs[i][j+1] >= lcs[i+1][j]):
+			fmt.Fprintf(&b, "+ %3d  %s\n", j+1, visible(gotLines[j]))
+			j++
+		default:
+			fmt.Fprintf(&b, "- %3d  %s\n", i+1, visible(wantLines[i]))
+			i++
+		}
+	}
+	return b.String()
+}
+
+// visible marks trailing whitespace, which is otherwise invisible in a diff
+func visible(line string) string {
+	trimmed := strings.TrimRight(line, " \t")
+	if trimmed == line {
+		return line
+	}
+	return trimmed + strings.NewReplacer(" ", "·", "\t", "→").Replace(line[len(trimmed):])
+}
@@ -0,0 +1,36 @@
+package prompttest
+
+import (
+	"embed"
+	"testing"
+
+	"github.com/mhrlife/goai-kit/internal/prompt"
+	"github.com/stretchr/testify/require"
+)
+
+//go:embed testdata/*.tpl
+var templates embed.FS
+
+func TestGolden(t *testing.T) {
+	type Context struct {
+		Formal bool
+	}
+
+	tpl := prompt.NewTemplate[Context]()
+	require.NoError(t, tpl.Load(templates))
+
+	Golden(t, tpl, Case[Context]{
+		Name:     "formal",
+		Template: "greeting",
+		Render:   prompt.Render[Context]{Context: Context{Formal: true}},
+		Fixture:  "testdata/greeting.json",
+	})
+}
+
+func TestDiff(t *testing.T) {
+	diff := Diff("Hello\nKind regards,\nSupport", "Hello\nCheers, \nSupport")
+	require.Equal(t, "    1  Hello\n"+
+		"+   2  Cheers,·\n"+
+		"-   2  Kind regards,\n"+
+		"    3  Support\n", diff)
+}
@@ -0,0 +1,3 @@
+Hello {{ .Data.Name }}!
+{{if .Context.Formal}}Kind regards{{else}}Cheers{{end}},
+{{ .Data.Team }}
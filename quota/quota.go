@@ -0,0 +1,174 @@
+package quota
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mhrlife/goai-kit/internal/kit"
+	"github.com/openai/openai-go"
+)
+
+// Limits are the daily allowances of a user. Zero values mean unlimited.
+type Limits struct {
+	RequestsPerDay int64
+	TokensPerDay   int64
+}
+
+// Usage is what a user consumed within a window
+type Usage struct {
+	Requests int64
+	Tokens   int64
+}
+
+// Store keeps per-user usage counters for daily windows
+type Store interface {
+	Get(ctx context.Context, user string, window time.Time) (Usage, error)
+
+	// Add atomically adds usage, which may be negative, to the counters and returns their new
+	// values, so concurrent runs can't reserve the same remaining allowance
+	Add(ctx context.Context, user string, window time.Time, usage Usage) (Usage, error)
+}
+
+// ExceededError is returned when a user has used up one of their limits
+type ExceededError struct {
+	User string
+
+	// Limit is the exhausted limit: "requests_per_day" or "tokens_per_day"
+	Limit string
+
+	Used    int64
+	Max     int64
+	ResetAt time.Time
+}
+
+func (e *ExceededError) Error() string {
+	return fmt.Sprintf("quota %s exceeded for user %s (%d/%d), resets at %s",
+		e.Limit, e.User, e.Used, e.Max, e.ResetAt.Format(time.RFC3339))
+}
+
+// Unwrap lets callers match the error with errors.Is(err, kit.ErrBudgetExceeded)
+func (e *ExceededError) Unwrap() error {
+	return kit.ErrBudgetExceeded
+}
+
+// EnforcerConfig configures a quota enforcer
+type EnforcerConfig struct {
+	// Store keeps the usage counters (required)
+	Store Store
+
+	// Limits returns the limits of a user (required)
+	Limits func(user string) Limits
+
+	// Now overrides the clock (optional, defaults to time.Now)
+	Now func() time.Time
+}
+
+// Enforcer checks and records per-user daily quotas
+type Enforcer struct {
+	store  Store
+	limits func(user string) Limits
+	now    func() time.Time
+}
+
+// NewEnforcer creates a quota enforcer
+func NewEnforcer(config EnforcerConfig) *Enforcer {
+	if config.Store == nil || config.Limits == nil {
+		panic("Store and Limits are required")
+	}
+
+	now := config.Now
+	if now == nil {
+		now = time.Now
+	}
+
+	return &Enforcer{
+		store:  config.Store,
+		limits: config.Limits,
+		now:    now,
+	}
+}
+
+// window returns the start of the current daily window (UTC midnight)
+func (e *Enforcer) window() time.Time {
+	return e.now().UTC().Truncate(24 * time.Hour)
+}
+
+// Check reserves a run for the user, returning an *ExceededError when they can't start another
+// one. The reservation is booked atomically, so concurrent runs can't both take the last
+// request of the day; Record completes it with the run's tokens.
+func (e *Enforcer) Check(ctx context.Context, user string) error {
+	limits := e.limits(user)
+	window := e.window()
+
+	usage, err := e.store.Add(ctx, user, window, Usage{Requests: 1})
+	if err != nil {
+		return fmt.Errorf("failed to reserve quota usage: %w", err)
+	}
+
+	exceeded := func(limit string, used, max int64) error {
+		// Give the reservation back, the run doesn't start
+		if _, err := e.store.Add(ctx, user, window, Usage{Requests: -1}); err != nil {
+			return fmt.Errorf("failed to release quota usage: %w", err)
+		}
+		return &ExceededError{
+			User:    user,
+			Limit:   limit,
+			Used:    used,
+			Max:     max,
+			ResetAt: window.Add(24 * time.Hour),
+		}
+	}
+
+	if limits.RequestsPerDay > 0 && usage.Requests > limits.RequestsPerDay {
+		return exceeded("requests_per_day", usage.Requests-1, limits.RequestsPerDay)
+	}
+	if limits.TokensPerDay > 0 && usage.Tokens >= limits.TokensPerDay {
+		return exceeded("tokens_per_day", usage.Tokens, limits.TokensPerDay)
+	}
+	return nil
+}
+
+// Record adds the token usage of a finished run to the user's counters, completing the request
+// Check reserved for it
+func (e *Enforcer) Record(ctx context.Context, user string, usage openai.CompletionUsage) error {
+	_, err := e.store.Add(ctx, user, e.window(), Usage{Tokens: usage.TotalTokens})
+	return err
+}
+
+// MemoryStore keeps usage counters in process memory
+type MemoryStore struct {
+	mu     sync.Mutex
+	usages map[string]Usage
+}
+
+// NewMemoryStore creates an empty in-memory quota store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		usages: make(map[string]Usage),
+	}
+}
+
+func (s *MemoryStore) key(user string, window time.Time) string {
+	return user + "|" + window.Format(time.RFC3339)
+}
+
+func (s *MemoryStore) Get(_ context.Context, user string, window time.Time) (Usage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.usages[s.key(user, window)], nil
+}
+
+func (s *MemoryStore) Add(_ context.Context, user string, window time.Time, usage Usage) (Usage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := s.key(user, window)
+	current := s.usages[key]
+	current.Requests += usage.Requests
+	current.Tokens += usage.Tokens
+	s.usages[key] = current
+	return current, nil
+}
@@ -0,0 +1,79 @@
+package quota
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mhrlife/goai-kit/internal/kit"
+	"github.com/openai/openai-go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnforcer(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 3, 10, 15, 0, 0, 0, time.UTC)
+
+	enforcer := NewEnforcer(EnforcerConfig{
+		Store:  NewMemoryStore(),
+		Limits: func(string) Limits { return Limits{RequestsPerDay: 2, TokensPerDay: 1000} },
+		Now:    func() time.Time { return now },
+	})
+
+	require.NoError(t, enforcer.Check(ctx, "alice"))
+	require.NoError(t, enforcer.Record(ctx, "alice", openai.CompletionUsage{TotalTokens: 1200}))
+
+	var exceeded *ExceededError
+	require.True(t, errors.As(enforcer.Check(ctx, "alice"), &exceeded))
+	require.Equal(t, "tokens_per_day", exceeded.Limit)
+	require.Equal(t, time.Date(2025, 3, 11, 0, 0, 0, 0, time.UTC), exceeded.ResetAt)
+	require.ErrorIs(t, exceeded, kit.ErrBudgetExceeded)
+
+	// Other users and the next day are not affected
+	require.NoError(t, enforcer.Check(ctx, "bob"))
+	now = now.Add(24 * time.Hour)
+	require.NoError(t, enforcer.Check(ctx, "alice"))
+}
+
+func TestEnforcerReservesConcurrentRuns(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	enforcer := NewEnforcer(EnforcerConfig{
+		Store:  store,
+		Limits: func(string) Limits { return Limits{RequestsPerDay: 3} },
+	})
+
+	// Runs checked at the same time can't all take the remaining requests
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		accepted int
+	)
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := enforcer.Check(ctx, "alice")
+			var exceeded *ExceededError
+			if err != nil && !errors.As(err, &exceeded) {
+				t.Error(err)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err == nil {
+				accepted++
+			}
+		}()
+	}
+	wg.Wait()
+	require.Equal(t, 3, accepted)
+
+	// Rejected runs give their reservation back and Record only adds the tokens
+	require.NoError(t, enforcer.Record(ctx, "alice", openai.CompletionUsage{TotalTokens: 50}))
+	usage, err := store.Get(ctx, "alice", enforcer.window())
+	require.NoError(t, err)
+	require.Equal(t, Usage{Requests: 3, Tokens: 50}, usage)
+}
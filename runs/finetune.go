@@ -0,0 +1,105 @@
+package runs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/openai/openai-go"
+)
+
+// FineTuneConfig selects the runs exported as fine-tuning examples
+type FineTuneConfig struct {
+	// Query selects the runs from the store (optional, Status defaults to StatusSucceeded)
+	Query Query
+
+	// IncludeFailed exports failed runs too, when Query doesn't filter by status
+	IncludeFailed bool
+
+	// Tools restricts the export to runs calling only the named tools; runs without tool calls
+	// are exported too (optional, nil exports runs calling any tool)
+	Tools []string
+
+	// ToolDefinitions are written as the tools of every example that calls tools, so the
+	// fine-tuned model learns when to call them (optional)
+	ToolDefinitions []openai.ChatCompletionToolParam
+
+	// Filter drops the runs it returns false for, e.g. those rated poorly (optional)
+	Filter func(run *Run) bool
+}
+
+// fineTuneExample is a line of the OpenAI chat fine-tuning format
+type fineTuneExample struct {
+	Messages []openai.ChatCompletionMessageParamUnion `json:"messages"`
+	Tools    []openai.ChatCompletionToolParam         `json:"tools,omitempty"`
+}
+
+// ExportFineTuning writes the selected runs to w in the JSONL format of OpenAI chat
+// fine-tuning, one conversation per line, and returns the number of examples written. Runs
+// that don't end with an assistant answer are skipped.
+func ExportFineTuning(ctx context.Context, store Store, w io.Writer, config FineTuneConfig) (int, error) {
+	query := config.Query
+	if query.Status == "" && !config.IncludeFailed {
+		query.Status = StatusSucceeded
+	}
+
+	found, err := store.Query(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query runs: %w", err)
+	}
+
+	encoder := json.NewEncoder(w)
+	written := 0
+	for _, run := range found {
+		if !config.exports(run) {
+			continue
+		}
+
+		example := fineTuneExample{Messages: run.Messages}
+		if callsTools(run) {
+			example.Tools = config.ToolDefinitions
+		}
+		if err := encoder.Encode(example); err != nil {
+			return written, fmt.Errorf("failed to write run %s: %w", run.ID, err)
+		}
+		written++
+	}
+	return written, nil
+}
+
+// exports reports whether the run passes the filters and makes a complete example
+func (c FineTuneConfig) exports(run *Run) bool {
+	if n := len(run.Messages); n == 0 || run.Messages[n-1].OfAssistant == nil {
+		return false
+	}
+	if c.Filter != nil && !c.Filter(run) {
+		return false
+	}
+	if c.Tools == nil {
+		return true
+	}
+
+	for _, msg := range run.Messages {
+		if msg.OfAssistant == nil {
+			continue
+		}
+		for _, call := range msg.OfAssistant.ToolCalls {
+			if !slices.Contains(c.Tools, call.Function.Name) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// callsTools reports whether the run called any tool
+func callsTools(run *Run) bool {
+	for _, msg := range run.Messages {
+		if msg.OfAssistant != nil && len(msg.OfAssistant.ToolCalls) > 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+package runs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/shared"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportFineTuning(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	toolCall := func(name string) openai.ChatCompletionMessageParamUnion {
+		return openai.ChatCompletionMessageParamUnion{OfAssistant: &openai.ChatCompletionAssistantMessageParam{
+			ToolCalls: []openai.ChatCompletionMessageToolCallParam{{
+				ID:       "call_1",
+				Function: openai.ChatCompletionMessageToolCallFunctionParam{Name: name, Arguments: "{}"},
+			}},
+		}}
+	}
+	conversation := func(tool string) []openai.ChatCompletionMessageParamUnion {
+		messages := []openai.ChatCompletionMessageParamUnion{openai.SystemMessage("Be helpful."), openai.UserMessage("hi")}
+		if tool != "" {
+			messages = append(messages, toolCall(tool), openai.ToolMessage("result", "call_1"))
+		}
+		return append(messages, openai.AssistantMessage("hello"))
+	}
+
+	started := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time { return started.Add(time.Duration(minutes) * time.Minute) }
+	require.NoError(t, store.Save(ctx, &Run{ID: "plain", StartedAt: at(0), Status: StatusSucceeded, Messages: conversation("")}))
+	require.NoError(t, store.Save(ctx, &Run{ID: "search", StartedAt: at(1), Status: StatusSucceeded, Messages: conversation("search")}))
+	require.NoError(t, store.Save(ctx, &Run{ID: "admin", StartedAt: at(2), Status: StatusSucceeded, Messages: conversation("admin")}))
+	require.NoError(t, store.Save(ctx, &Run{ID: "failed", StartedAt: at(3), Status: StatusFailed, Messages: conversation("")}))
+	require.NoError(t, store.Save(ctx, &Run{ID: "unanswered", StartedAt: at(4), Status: StatusSucceeded,
+		Messages: []openai.ChatCompletionMessageParamUnion{openai.UserMessage("hi")}}))
+
+	tools := []openai.ChatCompletionToolParam{{Function: shared.FunctionDefinitionParam{Name: "search"}}}
+	var buf bytes.Buffer
+	written, err := ExportFineTuning(ctx, store, &buf, FineTuneConfig{Tools: []string{"search"}, ToolDefinitions: tools})
+	require.NoError(t, err)
+	require.Equal(t, 2, written)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+
+	var example struct {
+		Messages []map[string]any `json:"messages"`
+		Tools    []map[string]any `json:"tools"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &example))
+	require.Len(t, example.Messages, 3)
+	require.Empty(t, example.Tools)
+
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &example))
+	require.Len(t, example.Messages, 5)
+	require.Equal(t, "tool", example.Messages[3]["role"])
+	require.Equal(t, "search", example.Tools[0]["function"].(map[string]any)["name"])
+
+	// Failed runs and custom filters
+	buf.Reset()
+	written, err = ExportFineTuning(ctx, store, &buf, FineTuneConfig{
+		IncludeFailed: true,
+		Filter:        func(run *Run) bool { return run.ID != "admin" },
+	})
+	require.NoError(t, err)
+	require.Equal(t, 3, written)
+}
@@ -0,0 +1,68 @@
+package runs
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// MemoryStore is an in-process Store, useful for tests and local development
+type MemoryStore struct {
+	mu   sync.RWMutex
+	runs map[string]*Run
+}
+
+// NewMemoryStore creates an empty in-memory run store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		runs: make(map[string]*Run),
+	}
+}
+
+func (s *MemoryStore) Save(_ context.Context, run *Run) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored := *run
+	s.runs[run.ID] = &stored
+	return nil
+}
+
+func (s *MemoryStore) Get(_ context.Context, id string) (*Run, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	run, ok := s.runs[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	found := *run
+	return &found, nil
+}
+
+func (s *MemoryStore) Query(_ context.Context, query Query) ([]*Run, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]*Run, 0)
+	for _, run := range s.runs {
+		if query.Matches(run) {
+			found := *run
+			result = append(result, &found)
+		}
+	}
+
+	// Runs started at the same time are ordered by ID, so the order doesn't depend on the map
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].StartedAt.Equal(result[j].StartedAt) {
+			return result[i].StartedAt.Before(result[j].StartedAt)
+		}
+		return result[i].ID < result[j].ID
+	})
+
+	if query.Limit > 0 && len(result) > query.Limit {
+		result = result[:query.Limit]
+	}
+	return result, nil
+}
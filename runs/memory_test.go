@@ -0,0 +1,38 @@
+package runs
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStoreQuery(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	require.NoError(t, store.Save(ctx, &Run{ID: "a", ConversationID: "c1", User: "u1", StartedAt: base}))
+	require.NoError(t, store.Save(ctx, &Run{ID: "b", ConversationID: "c1", User: "u2", StartedAt: base.Add(time.Hour)}))
+	require.NoError(t, store.Save(ctx, &Run{ID: "c", ConversationID: "c2", User: "u1", StartedAt: base.Add(2 * time.Hour)}))
+
+	found, err := store.Query(ctx, Query{ConversationID: "c1"})
+	require.NoError(t, err)
+	require.Len(t, found, 2)
+	require.Equal(t, "a", found[0].ID)
+	require.Equal(t, "b", found[1].ID)
+
+	found, err = store.Query(ctx, Query{User: "u1", From: base.Add(time.Minute)})
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	require.Equal(t, "c", found[0].ID)
+
+	found, err = store.Query(ctx, Query{To: base.Add(2 * time.Hour), Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	require.Equal(t, "a", found[0].ID)
+
+	_, err = store.Get(ctx, "missing")
+	require.ErrorIs(t, err, ErrNotFound)
+}
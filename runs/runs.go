@@ -0,0 +1,98 @@
+package runs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/openai/openai-go"
+)
+
+// ErrNotFound is returned when a run does not exist in the store
+var ErrNotFound = errors.New("run not found")
+
+// Status is the outcome of a run
+type Status string
+
+const (
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+)
+
+// Run is the persisted record of a single agent invocation
+type Run struct {
+	ID             string `json:"id"`
+	ParentID       string `json:"parent_id,omitempty"`
+	ConversationID string `json:"conversation_id,omitempty"`
+	User           string `json:"user,omitempty"`
+	IdempotencyKey string `json:"idempotency_key,omitempty"`
+
+	// Agent is the name of the agent that ran (empty for unnamed agents)
+	Agent string `json:"agent,omitempty"`
+
+	// Configuration the run was executed with
+	Model         string `json:"model"`
+	SystemPrompt  string `json:"system_prompt,omitempty"`
+	MaxIterations int    `json:"max_iterations"`
+
+	// Messages is the full message history, including the final assistant message
+	Messages []openai.ChatCompletionMessageParamUnion `json:"messages"`
+
+	// Output is the JSON encoded final output (empty when the run failed)
+	Output json.RawMessage `json:"output,omitempty"`
+
+	Status     Status                 `json:"status"`
+	Error      string                 `json:"error,omitempty"`
+	Iterations int                    `json:"iterations"`
+	Usage      openai.CompletionUsage `json:"usage"`
+
+	StartedAt time.Time `json:"started_at"`
+	EndedAt   time.Time `json:"ended_at"`
+}
+
+// Query filters runs. Zero fields are ignored.
+type Query struct {
+	ConversationID string
+	User           string
+	IdempotencyKey string
+	Agent          string
+	Status         Status
+
+	// From and To bound StartedAt (From inclusive, To exclusive)
+	From time.Time
+	To   time.Time
+
+	// Limit caps the number of returned runs (0 means no limit)
+	Limit int
+}
+
+// Store persists runs and allows querying them.
+// Query returns runs ordered by StartedAt, oldest first.
+type Store interface {
+	Save(ctx context.Context, run *Run) error
+	Get(ctx context.Context, id string) (*Run, error)
+	Query(ctx context.Context, query Query) ([]*Run, error)
+}
+
+// Matches reports whether the run satisfies the query filters (Limit is ignored)
+func (q Query) Matches(run *Run) bool {
+	switch {
+	case q.ConversationID != "" && run.ConversationID != q.ConversationID:
+		return false
+	case q.User != "" && run.User != q.User:
+		return false
+	case q.IdempotencyKey != "" && run.IdempotencyKey != q.IdempotencyKey:
+		return false
+	case q.Agent != "" && run.Agent != q.Agent:
+		return false
+	case q.Status != "" && run.Status != q.Status:
+		return false
+	case !q.From.IsZero() && run.StartedAt.Before(q.From):
+		return false
+	case !q.To.IsZero() && !run.StartedAt.Before(q.To):
+		return false
+	default:
+		return true
+	}
+}
@@ -0,0 +1,275 @@
+package runs
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Dialect selects the SQL placeholder style used by SQLStore
+type Dialect string
+
+const (
+	DialectPostgres Dialect = "postgres"
+	DialectMySQL    Dialect = "mysql"
+	DialectSQLite   Dialect = "sqlite"
+)
+
+// SQLStoreConfig configures a database/sql backed run store
+type SQLStoreConfig struct {
+	// DB is the database handle (required)
+	DB *sql.DB
+
+	// Dialect of the database (optional, defaults to postgres)
+	Dialect Dialect
+
+	// Table name (optional, defaults to "goai_runs")
+	Table string
+}
+
+// SQLStore persists runs in a SQL table. Indexed columns back the query API,
+// while the complete record is kept as JSON in the data column.
+type SQLStore struct {
+	db      *sql.DB
+	dialect Dialect
+	table   string
+}
+
+// NewSQLStore creates a SQL backed run store. Call Migrate to create the table.
+func NewSQLStore(config SQLStoreConfig) *SQLStore {
+	if config.DB == nil {
+		panic("DB is required")
+	}
+
+	dialect := config.Dialect
+	if dialect == "" {
+		dialect = DialectPostgres
+	}
+
+	table := config.Table
+	if table == "" {
+		table = "goai_runs"
+	}
+
+	return &SQLStore{
+		db:      config.DB,
+		dialect: dialect,
+		table:   table,
+	}
+}
+
+// Migrate creates the runs table and its indexes if they don't exist
+func (s *SQLStore) Migrate(ctx context.Context) error {
+	// MySQL caps TEXT at 64KB, too little for the messages of a long run
+	dataType := "TEXT"
+	if s.dialect == DialectMySQL {
+		dataType = "LONGTEXT"
+	}
+
+	if _, err := s.db.ExecContext(ctx, fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	id VARCHAR(64) PRIMARY KEY,
+	parent_id VARCHAR(64),
+	conversation_id VARCHAR(255),
+	user_id VARCHAR(255),
+	idempotency_key VARCHAR(255),
+	agent VARCHAR(255),
+	model VARCHAR(255),
+	status VARCHAR(32),
+	error TEXT,
+	iterations INTEGER,
+	prompt_tokens BIGINT,
+	completion_tokens BIGINT,
+	total_tokens BIGINT,
+	started_at TIMESTAMP,
+	ended_at TIMESTAMP,
+	data %s
+)`, s.table, dataType)); err != nil {
+		return fmt.Errorf("failed to migrate runs table: %w", err)
+	}
+
+	indexes := []struct{ name, columns string }{
+		{"conversation", "conversation_id, started_at"},
+		{"user", "user_id, started_at"},
+		{"started", "started_at"},
+		{"idempotency", "idempotency_key"},
+	}
+	for _, index := range indexes {
+		name := fmt.Sprintf("idx_%s_%s", s.table, index.name)
+		exists, err := s.indexExists(ctx, name)
+		if err != nil {
+			return fmt.Errorf("failed to migrate runs table: %w", err)
+		}
+		if exists {
+			continue
+		}
+
+		statement := fmt.Sprintf(`CREATE INDEX %s %s ON %s (%s)`, s.ifNotExists(), name, s.table, index.columns)
+		if _, err := s.db.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("failed to migrate runs table: %w", err)
+		}
+	}
+	return nil
+}
+
+// indexExists reports whether the index exists on MySQL, which has no CREATE INDEX IF NOT
+// EXISTS; the other dialects guard the statement itself and always report false
+func (s *SQLStore) indexExists(ctx context.Context, name string) (bool, error) {
+	if s.dialect != DialectMySQL {
+		return false, nil
+	}
+
+	var count int
+	err := s.db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM information_schema.statistics WHERE table_schema = DATABASE() AND table_name = ? AND index_name = ?`,
+		s.table, name,
+	).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// ifNotExists returns the index creation guard supported by the dialect
+func (s *SQLStore) ifNotExists() string {
+	if s.dialect == DialectMySQL {
+		return ""
+	}
+	return "IF NOT EXISTS"
+}
+
+// placeholder returns the n-th (1-based) bind parameter for the dialect
+func (s *SQLStore) placeholder(n int) string {
+	if s.dialect == DialectPostgres {
+		return fmt.Sprintf("$%d", n)
+	}
+	return "?"
+}
+
+func (s *SQLStore) Save(ctx context.Context, run *Run) error {
+	data, err := json.Marshal(run)
+	if err != nil {
+		return fmt.Errorf("failed to marshal run: %w", err)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	// Delete and insert keeps saving portable across dialects without upsert syntax
+	deleteQuery := fmt.Sprintf("DELETE FROM %s WHERE id = %s", s.table, s.placeholder(1))
+	if _, err := tx.ExecContext(ctx, deleteQuery, run.ID); err != nil {
+		return fmt.Errorf("failed to save run: %w", err)
+	}
+
+	placeholders := make([]string, 16)
+	for i := range placeholders {
+		placeholders[i] = s.placeholder(i + 1)
+	}
+
+	insertQuery := fmt.Sprintf(`INSERT INTO %s (
+	id, parent_id, conversation_id, user_id, idempotency_key, agent, model, status, error, iterations,
+	prompt_tokens, completion_tokens, total_tokens, started_at, ended_at, data
+) VALUES (%s)`, s.table, strings.Join(placeholders, ", "))
+
+	_, err = tx.ExecContext(ctx, insertQuery,
+		run.ID, run.ParentID, run.ConversationID, run.User, run.IdempotencyKey, run.Agent, run.Model, string(run.Status), run.Error,
+		run.Iterations, run.Usage.PromptTokens, run.Usage.CompletionTokens, run.Usage.TotalTokens,
+		run.StartedAt.UTC(), run.EndedAt.UTC(), string(data),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save run: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+func (s *SQLStore) Get(ctx context.Context, id string) (*Run, error) {
+	query := fmt.Sprintf("SELECT data FROM %s WHERE id = %s", s.table, s.placeholder(1))
+
+	var data string
+	if err := s.db.QueryRowContext(ctx, query, id).Scan(&data); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get run: %w", err)
+	}
+
+	return decodeRun(data)
+}
+
+func (s *SQLStore) Query(ctx context.Context, query Query) ([]*Run, error) {
+	var conditions []string
+	var args []any
+
+	addCondition := func(column, operator string, value any) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf("%s %s %s", column, operator, s.placeholder(len(args))))
+	}
+
+	if query.ConversationID != "" {
+		addCondition("conversation_id", "=", query.ConversationID)
+	}
+	if query.User != "" {
+		addCondition("user_id", "=", query.User)
+	}
+	if query.IdempotencyKey != "" {
+		addCondition("idempotency_key", "=", query.IdempotencyKey)
+	}
+	if query.Agent != "" {
+		addCondition("agent", "=", query.Agent)
+	}
+	if query.Status != "" {
+		addCondition("status", "=", string(query.Status))
+	}
+	if !query.From.IsZero() {
+		addCondition("started_at", ">=", query.From.UTC())
+	}
+	if !query.To.IsZero() {
+		addCondition("started_at", "<", query.To.UTC())
+	}
+
+	statement := fmt.Sprintf("SELECT data FROM %s", s.table)
+	if len(conditions) > 0 {
+		statement += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	statement += " ORDER BY started_at, id"
+	if query.Limit > 0 {
+		statement += fmt.Sprintf(" LIMIT %d", query.Limit)
+	}
+
+	rows, err := s.db.QueryContext(ctx, statement, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query runs: %w", err)
+	}
+	defer rows.Close()
+
+	result := make([]*Run, 0)
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("failed to scan run: %w", err)
+		}
+
+		run, err := decodeRun(data)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, run)
+	}
+
+	return result, rows.Err()
+}
+
+// decodeRun unmarshals the JSON data column into a Run
+func decodeRun(data string) (*Run, error) {
+	var run Run
+	if err := json.Unmarshal([]byte(data), &run); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal run: %w", err)
+	}
+	return &run, nil
+}
@@ -0,0 +1,100 @@
+package runs
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeMySQL is a database/sql driver that mimics how MySQL treats the migration: CREATE INDEX
+// fails on an existing index and information_schema reports the indexes created so far
+type fakeMySQL struct {
+	mu         sync.Mutex
+	statements []string
+	indexes    map[string]bool
+}
+
+var createIndex = regexp.MustCompile(`CREATE INDEX\s+(\S+) ON`)
+
+func (d *fakeMySQL) Open(string) (driver.Conn, error) { return &fakeConn{db: d}, nil }
+
+// The driver is its own connector, so tests open it with sql.OpenDB instead of registering it
+func (d *fakeMySQL) Connect(context.Context) (driver.Conn, error) { return d.Open("") }
+func (d *fakeMySQL) Driver() driver.Driver                        { return d }
+
+type fakeConn struct{ db *fakeMySQL }
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (c *fakeConn) Close() error                        { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+func (c *fakeConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+
+	c.db.statements = append(c.db.statements, query)
+	if match := createIndex.FindStringSubmatch(query); match != nil {
+		if c.db.indexes[match[1]] {
+			return nil, errors.New("Error 1061: Duplicate key name '" + match[1] + "'")
+		}
+		c.db.indexes[match[1]] = true
+	}
+	return driver.RowsAffected(0), nil
+}
+
+func (c *fakeConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+
+	count := int64(0)
+	if strings.Contains(query, "information_schema.statistics") && c.db.indexes[args[1].Value.(string)] {
+		count = 1
+	}
+	return &countRows{count: count}, nil
+}
+
+type countRows struct {
+	count int64
+	read  bool
+}
+
+func (r *countRows) Columns() []string { return []string{"count"} }
+func (r *countRows) Close() error      { return nil }
+
+func (r *countRows) Next(dest []driver.Value) error {
+	if r.read {
+		return io.EOF
+	}
+	r.read = true
+	dest[0] = r.count
+	return nil
+}
+
+func TestSQLStoreMigrateMySQL(t *testing.T) {
+	fake := &fakeMySQL{indexes: make(map[string]bool)}
+	db := sql.OpenDB(fake)
+	defer db.Close()
+
+	store := NewSQLStore(SQLStoreConfig{DB: db, Dialect: DialectMySQL})
+	require.NoError(t, store.Migrate(context.Background()))
+	require.Len(t, fake.indexes, 4)
+	require.Contains(t, fake.statements[0], "data LONGTEXT")
+
+	// Migrating again skips the existing indexes
+	require.NoError(t, store.Migrate(context.Background()))
+	creates := 0
+	for _, statement := range fake.statements {
+		if strings.HasPrefix(statement, "CREATE INDEX") {
+			creates++
+		}
+	}
+	require.Equal(t, 4, creates)
+}
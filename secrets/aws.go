@@ -0,0 +1,188 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AWSOptions locates a secret in AWS Secrets Manager
+type AWSOptions struct {
+	// SecretID is the name or ARN of the secret
+	SecretID string
+
+	// Field of the secret holding the value, when the secret string is a JSON object (optional)
+	Field string
+
+	// Region of the secret (defaults to the AWS_REGION environment variable)
+	Region string
+
+	// Credentials default to the AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN
+	// environment variables
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+
+	// Endpoint overrides the regional Secrets Manager endpoint (optional)
+	Endpoint string
+
+	// TTL the fetched value is cached for (defaults to DefaultTTL, negative disables caching)
+	TTL time.Duration
+
+	// HTTPClient used for requests (defaults to http.DefaultClient)
+	HTTPClient *http.Client
+}
+
+// AWSSecretsManager reads the secret from AWS Secrets Manager using its HTTP API, signing
+// requests with Signature Version 4
+func AWSSecretsManager(opts AWSOptions) Source {
+	if opts.Region == "" {
+		opts.Region = os.Getenv("AWS_REGION")
+	}
+	if opts.AccessKeyID == "" {
+		opts.AccessKeyID = os.Getenv("AWS_ACCESS_KEY_ID")
+		opts.SecretAccessKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+		opts.SessionToken = os.Getenv("AWS_SESSION_TOKEN")
+	}
+	if opts.Endpoint == "" {
+		opts.Endpoint = fmt.Sprintf("https://secretsmanager.%s.amazonaws.com/", opts.Region)
+	}
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = http.DefaultClient
+	}
+
+	return withTTL(SourceFunc(func(ctx context.Context) (string, error) {
+		return awsSecret(ctx, opts, time.Now().UTC())
+	}), opts.TTL)
+}
+
+func awsSecret(ctx context.Context, opts AWSOptions, now time.Time) (string, error) {
+	body, err := json.Marshal(map[string]string{"SecretId": opts.SecretID})
+	if err != nil {
+		return "", err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, opts.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	request.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	request.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+	signV4(request, body, opts, now)
+
+	resp, err := opts.HTTPClient.Do(request)
+	if err != nil {
+		return "", fmt.Errorf("secrets manager request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read secrets manager response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Type    string `json:"__type"`
+			Message string `json:"message"`
+		}
+		_ = json.Unmarshal(data, &apiErr)
+		if strings.HasSuffix(apiErr.Type, "ResourceNotFoundException") {
+			return "", fmt.Errorf("%w: secret %s", ErrNotFound, opts.SecretID)
+		}
+		return "", fmt.Errorf("secrets manager request failed with status %d: %s %s", resp.StatusCode, apiErr.Type, apiErr.Message)
+	}
+
+	var secret struct {
+		SecretString string `json:"SecretString"`
+	}
+	if err := json.Unmarshal(data, &secret); err != nil {
+		return "", fmt.Errorf("failed to decode secrets manager response: %w", err)
+	}
+	if secret.SecretString == "" {
+		return "", fmt.Errorf("%w: secret %s has no string value", ErrNotFound, opts.SecretID)
+	}
+	return field(secret.SecretString, opts.Field)
+}
+
+// signV4 adds the Signature Version 4 authorization headers for the secretsmanager service
+func signV4(request *http.Request, body []byte, opts AWSOptions, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+
+	request.Header.Set("X-Amz-Date", amzDate)
+	if opts.SessionToken != "" {
+		request.Header.Set("X-Amz-Security-Token", opts.SessionToken)
+	}
+
+	headers := map[string]string{"host": request.URL.Host}
+	for name, values := range request.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := request.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	canonicalRequest := strings.Join([]string{
+		request.Method,
+		path,
+		canonicalQuery(request.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + opts.Region + "/secretsmanager/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+opts.SecretAccessKey), date)
+	key = hmacSHA256(key, opts.Region)
+	key = hmacSHA256(key, "secretsmanager")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	request.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		opts.AccessKeyID, scope, signedHeaders, signature,
+	))
+}
+
+// canonicalQuery encodes the query with sorted keys, as Signature Version 4 requires
+func canonicalQuery(query url.Values) string {
+	return strings.ReplaceAll(query.Encode(), "+", "%20")
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,112 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNotFound is returned when a source holds no value for the secret
+var ErrNotFound = errors.New("secret not found")
+
+// DefaultTTL is how long remote sources cache a fetched secret
+const DefaultTTL = 5 * time.Minute
+
+// Source provides a secret, e.g. an API key, resolved when it is needed rather than passed
+// around as a plain string. kit.WithAPIKeySource accepts any Source.
+type Source interface {
+	Secret(ctx context.Context) (string, error)
+}
+
+// SourceFunc adapts a function to a Source
+type SourceFunc func(ctx context.Context) (string, error)
+
+func (f SourceFunc) Secret(ctx context.Context) (string, error) {
+	return f(ctx)
+}
+
+// Env reads the secret from an environment variable on every call
+func Env(name string) Source {
+	return SourceFunc(func(context.Context) (string, error) {
+		value, ok := os.LookupEnv(name)
+		if !ok || value == "" {
+			return "", fmt.Errorf("%w: environment variable %s", ErrNotFound, name)
+		}
+		return value, nil
+	})
+}
+
+// File reads the secret from a file, e.g. a mounted Kubernetes or Docker secret, on every call
+// so rotated files are picked up. Surrounding whitespace is trimmed.
+func File(path string) Source {
+	return SourceFunc(func(context.Context) (string, error) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return "", fmt.Errorf("%w: file %s", ErrNotFound, path)
+			}
+			return "", fmt.Errorf("failed to read secret file: %w", err)
+		}
+
+		value := strings.TrimSpace(string(data))
+		if value == "" {
+			return "", fmt.Errorf("%w: file %s is empty", ErrNotFound, path)
+		}
+		return value, nil
+	})
+}
+
+// Cached caches the secret of source for ttl, so remote stores aren't queried on every request.
+// Failed lookups are not cached.
+func Cached(source Source, ttl time.Duration) Source {
+	return &cachedSource{source: source, ttl: ttl, now: time.Now}
+}
+
+type cachedSource struct {
+	source Source
+	ttl    time.Duration
+	now    func() time.Time
+
+	mu        sync.Mutex
+	value     string
+	fetchedAt time.Time
+}
+
+func (c *cachedSource) Secret(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.value != "" && c.now().Sub(c.fetchedAt) < c.ttl {
+		return c.value, nil
+	}
+
+	value, err := c.source.Secret(ctx)
+	if err != nil {
+		return "", err
+	}
+	c.value = value
+	c.fetchedAt = c.now()
+	return value, nil
+}
+
+// field returns the secret itself, or one field of it when the secret is a JSON object
+func field(secret, name string) (string, error) {
+	if name == "" {
+		return secret, nil
+	}
+
+	var fields map[string]any
+	if err := json.Unmarshal([]byte(secret), &fields); err != nil {
+		return "", fmt.Errorf("secret is not a JSON object: %w", err)
+	}
+	value, ok := fields[name].(string)
+	if !ok || value == "" {
+		return "", fmt.Errorf("%w: field %s", ErrNotFound, name)
+	}
+	return value, nil
+}
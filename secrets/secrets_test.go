@@ -0,0 +1,86 @@
+package secrets
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvAndFile(t *testing.T) {
+	ctx := context.Background()
+	t.Setenv("GOAI_TEST_KEY", "sk-env")
+
+	value, err := Env("GOAI_TEST_KEY").Secret(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "sk-env", value)
+
+	_, err = Env("GOAI_TEST_MISSING").Secret(ctx)
+	require.ErrorIs(t, err, ErrNotFound)
+
+	path := filepath.Join(t.TempDir(), "key")
+	require.NoError(t, os.WriteFile(path, []byte("sk-file\n"), 0o600))
+	value, err = File(path).Secret(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "sk-file", value)
+}
+
+func TestCached(t *testing.T) {
+	calls := 0
+	source := Cached(SourceFunc(func(context.Context) (string, error) {
+		calls++
+		return "sk-cached", nil
+	}), time.Minute)
+
+	for range 3 {
+		value, err := source.Secret(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, "sk-cached", value)
+	}
+	require.Equal(t, 1, calls)
+}
+
+func TestVault(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v1/secret/data/goai/openai", r.URL.Path)
+		require.Equal(t, "vault-token", r.Header.Get("X-Vault-Token"))
+		_, _ = w.Write([]byte(`{"data":{"data":{"api_key":"sk-vault"},"metadata":{"version":3}}}`))
+	}))
+	defer server.Close()
+
+	value, err := Vault(VaultOptions{
+		Address: server.URL,
+		Token:   "vault-token",
+		Path:    "goai/openai",
+		Field:   "api_key",
+	}).Secret(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "sk-vault", value)
+}
+
+func TestAWSSecretsManager(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "secretsmanager.GetSecretValue", r.Header.Get("X-Amz-Target"))
+		require.True(t, strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/"))
+		require.Contains(t, r.Header.Get("Authorization"), "/eu-west-1/secretsmanager/aws4_request")
+		_, _ = w.Write([]byte(`{"Name":"goai","SecretString":"{\"OPENAI_API_KEY\":\"sk-aws\"}"}`))
+	}))
+	defer server.Close()
+
+	value, err := AWSSecretsManager(AWSOptions{
+		SecretID:        "goai",
+		Field:           "OPENAI_API_KEY",
+		Region:          "eu-west-1",
+		AccessKeyID:     "AKID",
+		SecretAccessKey: "secret",
+		Endpoint:        server.URL,
+	}).Secret(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "sk-aws", value)
+}
@@ -0,0 +1,114 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// VaultOptions locates a secret in a HashiCorp Vault KV version 2 secrets engine
+type VaultOptions struct {
+	// Address of the Vault server (defaults to the VAULT_ADDR environment variable)
+	Address string
+
+	// Token to authenticate with (defaults to the VAULT_TOKEN environment variable)
+	Token string
+
+	// Mount path of the KV engine (defaults to secret)
+	Mount string
+
+	// Path of the secret within the engine, e.g. goai/openai
+	Path string
+
+	// Field of the secret holding the value
+	Field string
+
+	// Namespace for Vault Enterprise (optional)
+	Namespace string
+
+	// TTL the fetched value is cached for (defaults to DefaultTTL, negative disables caching)
+	TTL time.Duration
+
+	// HTTPClient used for requests (defaults to http.DefaultClient)
+	HTTPClient *http.Client
+}
+
+// Vault reads the secret from HashiCorp Vault using its HTTP API
+func Vault(opts VaultOptions) Source {
+	if opts.Address == "" {
+		opts.Address = os.Getenv("VAULT_ADDR")
+	}
+	if opts.Token == "" {
+		opts.Token = os.Getenv("VAULT_TOKEN")
+	}
+	if opts.Mount == "" {
+		opts.Mount = "secret"
+	}
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = http.DefaultClient
+	}
+
+	return withTTL(SourceFunc(func(ctx context.Context) (string, error) {
+		return vaultSecret(ctx, opts)
+	}), opts.TTL)
+}
+
+func vaultSecret(ctx context.Context, opts VaultOptions) (string, error) {
+	endpoint, err := url.JoinPath(opts.Address, "v1", strings.Trim(opts.Mount, "/"), "data", strings.Trim(opts.Path, "/"))
+	if err != nil {
+		return "", fmt.Errorf("invalid vault address: %w", err)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return "", err
+	}
+	request.Header.Set("X-Vault-Token", opts.Token)
+	if opts.Namespace != "" {
+		request.Header.Set("X-Vault-Namespace", opts.Namespace)
+	}
+
+	resp, err := opts.HTTPClient.Do(request)
+	if err != nil {
+		return "", fmt.Errorf("vault request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return "", fmt.Errorf("%w: vault path %s", ErrNotFound, opts.Path)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("vault request failed with status %d", resp.StatusCode)
+	}
+
+	var body struct {
+		Data struct {
+			Data map[string]any `json:"data"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("failed to decode vault response: %w", err)
+	}
+
+	value, ok := body.Data.Data[opts.Field].(string)
+	if !ok || value == "" {
+		return "", fmt.Errorf("%w: field %s of vault path %s", ErrNotFound, opts.Field, opts.Path)
+	}
+	return value, nil
+}
+
+// withTTL caches source for ttl, DefaultTTL when zero and not at all when negative
+func withTTL(source Source, ttl time.Duration) Source {
+	if ttl < 0 {
+		return source
+	}
+	if ttl == 0 {
+		ttl = DefaultTTL
+	}
+	return Cached(source, ttl)
+}
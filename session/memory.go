@@ -0,0 +1,63 @@
+package session
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryStore is an in-process Store, useful for tests and single-instance apps
+type MemoryStore struct {
+	mu       sync.RWMutex
+	sessions map[string]*Session
+}
+
+// NewMemoryStore creates an empty in-memory session store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		sessions: make(map[string]*Session),
+	}
+}
+
+func (s *MemoryStore) Save(_ context.Context, session *Session) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored := *session
+	s.sessions[session.ID] = &stored
+	return nil
+}
+
+func (s *MemoryStore) Get(_ context.Context, id string) (*Session, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	session, ok := s.sessions[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	found := *session
+	return &found, nil
+}
+
+func (s *MemoryStore) Delete(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.sessions, id)
+	return nil
+}
+
+func (s *MemoryStore) Idle(_ context.Context, before time.Time) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var ids []string
+	for id, session := range s.sessions {
+		if session.LastActiveAt.Before(before) {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
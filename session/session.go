@@ -0,0 +1,233 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mhrlife/goai-kit/internal/kit"
+	"github.com/mhrlife/goai-kit/internal/memory"
+	"github.com/openai/openai-go"
+)
+
+// ErrNotFound is returned when a session does not exist in the store
+var ErrNotFound = errors.New("session not found")
+
+// ErrExpired is returned when a session was idle for longer than the idle timeout
+var ErrExpired = errors.New("session expired")
+
+// CookieName is the cookie FromRequest reads the session ID from, when the header is absent
+const CookieName = "goai_session"
+
+// HeaderName is the header FromRequest reads the session ID from
+const HeaderName = "X-Session-ID"
+
+// Session is a user's chat session, holding one conversation
+type Session struct {
+	ID       string            `json:"id"`
+	User     string            `json:"user,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+
+	CreatedAt    time.Time `json:"created_at"`
+	LastActiveAt time.Time `json:"last_active_at"`
+}
+
+// Store persists sessions.
+// Idle returns the IDs of the sessions last active before the given time.
+type Store interface {
+	Save(ctx context.Context, session *Session) error
+	Get(ctx context.Context, id string) (*Session, error)
+	Delete(ctx context.Context, id string) error
+	Idle(ctx context.Context, before time.Time) ([]string, error)
+}
+
+// Options configures a Manager. All fields are optional.
+type Options struct {
+	// IdleTimeout expires sessions inactive for longer (defaults to 30m)
+	IdleTimeout time.Duration
+
+	// Messages stores the conversations of the sessions (defaults to an in-memory store)
+	Messages memory.Store
+
+	// Now returns the current time (defaults to time.Now)
+	Now func() time.Time
+}
+
+// Manager creates sessions, expires idle ones and runs agents on their conversations
+type Manager struct {
+	store       Store
+	messages    memory.Store
+	idleTimeout time.Duration
+	now         func() time.Time
+}
+
+// NewManager creates a session manager backed by the store
+func NewManager(store Store, opts Options) *Manager {
+	m := &Manager{
+		store:       store,
+		messages:    opts.Messages,
+		idleTimeout: opts.IdleTimeout,
+		now:         opts.Now,
+	}
+	if m.messages == nil {
+		m.messages = memory.NewInMemoryStore()
+	}
+	if m.idleTimeout <= 0 {
+		m.idleTimeout = 30 * time.Minute
+	}
+	if m.now == nil {
+		m.now = time.Now
+	}
+	return m
+}
+
+// Create starts a new session for the user
+func (m *Manager) Create(ctx context.Context, user string, metadata map[string]string) (*Session, error) {
+	now := m.now()
+	session := &Session{
+		ID:           uuid.New().String(),
+		User:         user,
+		Metadata:     metadata,
+		CreatedAt:    now,
+		LastActiveAt: now,
+	}
+	if err := m.store.Save(ctx, session); err != nil {
+		return nil, fmt.Errorf("failed to save session: %w", err)
+	}
+	return session, nil
+}
+
+// Get returns an active session. An idle session is deleted and ErrExpired returned.
+func (m *Manager) Get(ctx context.Context, id string) (*Session, error) {
+	session, err := m.store.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if m.now().Sub(session.LastActiveAt) > m.idleTimeout {
+		if err := m.Delete(ctx, id); err != nil {
+			return nil, err
+		}
+		return nil, ErrExpired
+	}
+	return session, nil
+}
+
+// FromRequest returns the active session identified by the X-Session-ID header or the
+// goai_session cookie
+func (m *Manager) FromRequest(r *http.Request) (*Session, error) {
+	id := r.Header.Get(HeaderName)
+	if id == "" {
+		cookie, err := r.Cookie(CookieName)
+		if err != nil {
+			return nil, ErrNotFound
+		}
+		id = cookie.Value
+	}
+	return m.Get(r.Context(), id)
+}
+
+// SetCookie sets the session cookie on the response
+func (m *Manager) SetCookie(w http.ResponseWriter, session *Session) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     CookieName,
+		Value:    session.ID,
+		Path:     "/",
+		MaxAge:   int(m.idleTimeout.Seconds()),
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// History returns the conversation of the session, oldest message first
+func (m *Manager) History(ctx context.Context, id string) ([]openai.ChatCompletionMessageParamUnion, error) {
+	return m.messages.Load(ctx, id)
+}
+
+// Delete removes the session and its conversation
+func (m *Manager) Delete(ctx context.Context, id string) error {
+	if err := m.messages.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete conversation of session %s: %w", id, err)
+	}
+	if err := m.store.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete session %s: %w", id, err)
+	}
+	return nil
+}
+
+// Sweep deletes every idle session and returns how many were removed. Call it periodically;
+// sessions are also expired lazily by Get.
+func (m *Manager) Sweep(ctx context.Context) (int, error) {
+	ids, err := m.store.Idle(ctx, m.now().Add(-m.idleTimeout))
+	if err != nil {
+		return 0, fmt.Errorf("failed to list idle sessions: %w", err)
+	}
+
+	for i, id := range ids {
+		if err := m.Delete(ctx, id); err != nil {
+			return i, err
+		}
+	}
+	return len(ids), nil
+}
+
+// Invoke runs the agent on the session's conversation and the new input of config (Prompt or
+// Messages), then appends the turn to the conversation. The run is attributed to the session's
+// user and carries its metadata.
+func Invoke[Output any](
+	ctx context.Context,
+	m *Manager,
+	agent *kit.Agent[Output],
+	id string,
+	config kit.InvokeConfig,
+) (*kit.InvokeResult[Output], error) {
+	session, err := m.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	history, err := m.messages.Load(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load conversation of session %s: %w", id, err)
+	}
+
+	// The files are part of the stored user turn, so the input message is built here
+	input := config.Messages
+	if config.Prompt != "" {
+		input = []openai.ChatCompletionMessageParamUnion{kit.PromptMessage(config.Prompt, config.Files)}
+	} else if len(config.Files) > 0 {
+		input = append(slices.Clone(input), kit.PromptMessage("", config.Files))
+	}
+	config.Prompt, config.Files = "", nil
+	config.Messages = append(slices.Clone(history), input...)
+
+	if config.User == "" {
+		config.User = session.User
+	}
+	metadata := make(map[string]string, len(session.Metadata)+len(config.Metadata)+1)
+	maps.Copy(metadata, session.Metadata)
+	maps.Copy(metadata, config.Metadata)
+	metadata["session_id"] = id
+	config.Metadata = metadata
+
+	result, err := agent.InvokeDetailed(ctx, config)
+	if err != nil {
+		return result, err
+	}
+
+	turn := append(slices.Clone(input), result.TurnMessages()...)
+	if err := m.messages.Append(ctx, id, turn...); err != nil {
+		return result, fmt.Errorf("failed to persist conversation of session %s: %w", id, err)
+	}
+
+	session.LastActiveAt = m.now()
+	if err := m.store.Save(ctx, session); err != nil {
+		return result, fmt.Errorf("failed to save session: %w", err)
+	}
+	return result, nil
+}
@@ -0,0 +1,90 @@
+package session
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mhrlife/goai-kit/internal/kit"
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManagerExpiresIdleSessions(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	manager := NewManager(NewMemoryStore(), Options{
+		IdleTimeout: time.Minute,
+		Now:         func() time.Time { return now },
+	})
+
+	active, err := manager.Create(ctx, "u1", nil)
+	require.NoError(t, err)
+	require.NoError(t, manager.messages.Append(ctx, active.ID, openai.UserMessage("hi")))
+
+	request := httptest.NewRequest("GET", "/", nil)
+	request.Header.Set(HeaderName, active.ID)
+	found, err := manager.FromRequest(request)
+	require.NoError(t, err)
+	require.Equal(t, "u1", found.User)
+
+	now = now.Add(2 * time.Minute)
+	fresh, err := manager.Create(ctx, "u2", nil)
+	require.NoError(t, err)
+
+	removed, err := manager.Sweep(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, removed)
+
+	_, err = manager.Get(ctx, active.ID)
+	require.ErrorIs(t, err, ErrNotFound)
+	history, err := manager.History(ctx, active.ID)
+	require.NoError(t, err)
+	require.Empty(t, history)
+
+	now = now.Add(2 * time.Minute)
+	_, err = manager.Get(ctx, fresh.ID)
+	require.ErrorIs(t, err, ErrExpired)
+}
+
+func TestInvokePersistsTurn(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		content := "a cat"
+		if calls == 1 {
+			content = ""
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"1","object":"chat.completion","choices":[` +
+			`{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":"` + content + `"}}]}`))
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	client := kit.NewClient(kit.WithAPIKey("test"), kit.WithBaseURL(server.URL), kit.WithRequestOptions(option.WithMaxRetries(0)))
+	agent := kit.CreateAgent(client).WithEmptyResponsePolicy(kit.EmptyResponsePolicy{Nudge: true})
+	manager := NewManager(NewMemoryStore(), Options{})
+	session, err := manager.Create(ctx, "u1", nil)
+	require.NoError(t, err)
+
+	result, err := Invoke(ctx, manager, agent, session.ID, kit.InvokeConfig{
+		Prompt: "What is in this picture?",
+		Files:  []kit.File{kit.FileImage("image/png", []byte("png"))},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "a cat", result.Output)
+
+	// The stored turn keeps the attached file and leaves out the nudge
+	history, err := manager.History(ctx, session.ID)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	require.Equal(t, "What is in this picture?", kit.MessageText(history[0]))
+	require.Len(t, history[0].OfUser.Content.OfArrayOfContentParts, 2)
+	require.NotNil(t, history[0].OfUser.Content.OfArrayOfContentParts[1].OfImageURL)
+	require.NotNil(t, history[1].OfAssistant)
+	require.Equal(t, "a cat", kit.MessageText(history[1]))
+}
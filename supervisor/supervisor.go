@@ -0,0 +1,211 @@
+package supervisor
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/mhrlife/goai-kit/internal/kit"
+	"github.com/openai/openai-go"
+)
+
+// Worker is an agent the supervisor can delegate subtasks to
+type Worker struct {
+	// Name is used as the tool name exposed to the supervisor
+	Name string
+
+	// Description tells the supervisor which subtasks this worker handles
+	Description string
+
+	// Agent executes the delegated subtasks
+	Agent *kit.Agent[string]
+}
+
+// Budget limits the resources the workers of a supervisor run may consume.
+// Once exhausted, the supervisor is told to stop delegating. Zero values mean unlimited.
+type Budget struct {
+	// MaxWorkerCalls is the total number of subtasks that may be delegated
+	MaxWorkerCalls int
+
+	// MaxIterations is the total number of generations across all workers
+	MaxIterations int
+
+	// MaxTokens is the total number of tokens consumed by all workers
+	MaxTokens int64
+}
+
+// WorkerResult records a single delegated subtask
+type WorkerResult struct {
+	Worker     string
+	Task       string
+	Output     string
+	Iterations int
+	Usage      openai.CompletionUsage
+	Error      string
+}
+
+// Result is the outcome of a supervisor run
+type Result[Output any] struct {
+	// Output is the supervisor's final output
+	Output Output
+
+	// Workers lists the delegated subtasks in order
+	Workers []WorkerResult
+
+	// Iterations is the total number of generations, supervisor and workers included
+	Iterations int
+
+	// Usage is the total token usage, supervisor and workers included
+	Usage openai.CompletionUsage
+}
+
+// Supervisor owns several worker agents and lets a coordinating agent route subtasks to them
+type Supervisor[Output any] struct {
+	agent  *kit.Agent[Output]
+	budget Budget
+}
+
+// New creates a supervisor. Every worker is exposed to the coordinating agent as a tool; the
+// tools are added to a clone, the agent itself is left untouched.
+func New[Output any](agent *kit.Agent[Output], workers ...Worker) *Supervisor[Output] {
+	agent = agent.Clone()
+	for i := range workers {
+		agent.WithTools(&WorkerTool{worker: &workers[i]})
+	}
+
+	return &Supervisor[Output]{
+		agent: agent,
+	}
+}
+
+// WithBudget sets the global budget enforced across the supervisor and its workers
+func (s *Supervisor[Output]) WithBudget(budget Budget) *Supervisor[Output] {
+	s.budget = budget
+	return s
+}
+
+// Run executes the coordinating agent, delegating to workers as it decides
+func (s *Supervisor[Output]) Run(ctx context.Context, config kit.InvokeConfig) (*Result[Output], error) {
+	state := &runState{budget: s.budget}
+
+	run, err := s.agent.InvokeDetailed(context.WithValue(ctx, runKey{}, state), config)
+	if err != nil {
+		return nil, err
+	}
+
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	state.iterations += run.Iterations
+	addUsage(&state.usage, run.Usage)
+
+	return &Result[Output]{
+		Output:     run.Output,
+		Workers:    state.results,
+		Iterations: state.iterations,
+		Usage:      state.usage,
+	}, nil
+}
+
+// runKey is the context key carrying the state of the active supervisor run
+type runKey struct{}
+
+// runState aggregates worker results and budget consumption of a single run
+type runState struct {
+	mu         sync.Mutex
+	budget     Budget
+	calls      int
+	iterations int
+	usage      openai.CompletionUsage
+	results    []WorkerResult
+}
+
+// exhausted returns the reason the budget no longer allows delegating, if any
+func (s *runState) exhausted() string {
+	switch {
+	case s.budget.MaxWorkerCalls > 0 && s.calls >= s.budget.MaxWorkerCalls:
+		return fmt.Sprintf("worker call budget (%d) exhausted", s.budget.MaxWorkerCalls)
+	case s.budget.MaxIterations > 0 && s.iterations >= s.budget.MaxIterations:
+		return fmt.Sprintf("iteration budget (%d) exhausted", s.budget.MaxIterations)
+	case s.budget.MaxTokens > 0 && s.usage.TotalTokens >= s.budget.MaxTokens:
+		return fmt.Sprintf("token budget (%d) exhausted", s.budget.MaxTokens)
+	default:
+		return ""
+	}
+}
+
+// WorkerTool delegates a subtask to a worker agent
+type WorkerTool struct {
+	Task string `json:"task" jsonschema:"description=Self-contained description of the subtask for the worker"`
+
+	worker *Worker
+}
+
+func (t *WorkerTool) AgentToolInfo() kit.AgentToolInfo {
+	return kit.AgentToolInfo{
+		Name:        t.worker.Name,
+		Description: t.worker.Description,
+	}
+}
+
+func (t *WorkerTool) Execute(ctx *kit.Context) (any, error) {
+	state, ok := ctx.Value(runKey{}).(*runState)
+	if !ok {
+		return nil, fmt.Errorf("worker tool %s used outside of a supervisor run", t.worker.Name)
+	}
+
+	state.mu.Lock()
+	if reason := state.exhausted(); reason != "" {
+		state.mu.Unlock()
+		return fmt.Sprintf("Budget exhausted: %s. Do not delegate further; give your final answer now.", reason), nil
+	}
+	state.calls++
+	remaining := state.remainingIterations()
+	state.mu.Unlock()
+
+	config := kit.InvokeConfig{Prompt: t.Task}
+	if remaining > 0 {
+		config.MaxIterations = &remaining
+	}
+
+	run, err := t.worker.Agent.InvokeDetailed(ctx.Context, config)
+
+	result := WorkerResult{
+		Worker: t.worker.Name,
+		Task:   t.Task,
+	}
+	if run != nil {
+		result.Output = run.Output
+		result.Iterations = run.Iterations
+		result.Usage = run.Usage
+	}
+	if err != nil {
+		result.Error = err.Error()
+	}
+
+	state.mu.Lock()
+	state.iterations += result.Iterations
+	addUsage(&state.usage, result.Usage)
+	state.results = append(state.results, result)
+	state.mu.Unlock()
+
+	if err != nil {
+		return fmt.Sprintf("Worker %s failed: %s", t.worker.Name, err), nil
+	}
+	return result.Output, nil
+}
+
+// remainingIterations returns the iterations left in the budget, or 0 when unlimited
+func (s *runState) remainingIterations() int {
+	if s.budget.MaxIterations <= 0 {
+		return 0
+	}
+	return s.budget.MaxIterations - s.iterations
+}
+
+// addUsage adds the token counts of delta to total
+func addUsage(total *openai.CompletionUsage, delta openai.CompletionUsage) {
+	total.PromptTokens += delta.PromptTokens
+	total.CompletionTokens += delta.CompletionTokens
+	total.TotalTokens += delta.TotalTokens
+}
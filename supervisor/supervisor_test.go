@@ -0,0 +1,112 @@
+package supervisor
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/mhrlife/goai-kit/internal/kit"
+	"github.com/openai/openai-go/option"
+	"github.com/stretchr/testify/require"
+)
+
+// coordinatorServer delegates two subtasks to the researcher, then answers with the tool results
+func coordinatorServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		if !strings.Contains(string(body), `"role":"tool"`) {
+			_, _ = w.Write([]byte(`{"id":"1","object":"chat.completion","choices":[{"index":0,"finish_reason":"tool_calls",` +
+				`"message":{"role":"assistant","tool_calls":[` +
+				`{"id":"call_1","type":"function","function":{"name":"researcher","arguments":"{\"task\":\"find A\"}"}},` +
+				`{"id":"call_2","type":"function","function":{"name":"researcher","arguments":"{\"task\":\"find B\"}"}}]}}],` +
+				`"usage":{"prompt_tokens":10,"completion_tokens":5,"total_tokens":15}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":"2","object":"chat.completion","choices":[{"index":0,"finish_reason":"stop",` +
+			`"message":{"role":"assistant","content":"summary"}}],` +
+			`"usage":{"prompt_tokens":20,"completion_tokens":5,"total_tokens":25}}`))
+	}))
+}
+
+// workerServer answers every subtask with its task
+func workerServer(calls *atomic.Int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		body, _ := io.ReadAll(r.Body)
+		answer := "found A"
+		if strings.Contains(string(body), "find B") {
+			answer = "found B"
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"1","object":"chat.completion","choices":[{"index":0,"finish_reason":"stop",` +
+			`"message":{"role":"assistant","content":"` + answer + `"}}],` +
+			`"usage":{"prompt_tokens":3,"completion_tokens":2,"total_tokens":5}}`))
+	}))
+}
+
+func newClient(server *httptest.Server) *kit.Client {
+	return kit.NewClient(kit.WithAPIKey("test"), kit.WithBaseURL(server.URL), kit.WithRequestOptions(option.WithMaxRetries(0)))
+}
+
+func TestSupervisorDelegates(t *testing.T) {
+	coordinator := coordinatorServer()
+	defer coordinator.Close()
+	var workerCalls atomic.Int32
+	workers := workerServer(&workerCalls)
+	defer workers.Close()
+
+	researcher := Worker{
+		Name:        "researcher",
+		Description: "Researches a topic",
+		Agent:       kit.CreateAgent(newClient(workers)),
+	}
+	agent := kit.CreateAgent(newClient(coordinator))
+	s := New(agent, researcher)
+
+	// Creating another supervisor from the same agent doesn't stack worker tools on it
+	New(agent, researcher)
+	require.Empty(t, agent.Describe().Tools)
+	require.Len(t, s.agent.Describe().Tools, 1)
+
+	result, err := s.Run(context.Background(), kit.InvokeConfig{Prompt: "research A and B"})
+	require.NoError(t, err)
+	require.Equal(t, "summary", result.Output)
+	require.Len(t, result.Workers, 2)
+	require.Equal(t, WorkerResult{Worker: "researcher", Task: "find A", Output: "found A", Iterations: 1, Usage: result.Workers[0].Usage}, result.Workers[0])
+	require.Equal(t, "found B", result.Workers[1].Output)
+
+	// Iterations and usage add up the coordinator's and the workers'
+	require.Equal(t, 4, result.Iterations)
+	require.Equal(t, int64(15+25+5+5), result.Usage.TotalTokens)
+}
+
+func TestSupervisorBudget(t *testing.T) {
+	coordinator := coordinatorServer()
+	defer coordinator.Close()
+	var workerCalls atomic.Int32
+	workers := workerServer(&workerCalls)
+	defer workers.Close()
+
+	s := New(kit.CreateAgent(newClient(coordinator)), Worker{
+		Name:  "researcher",
+		Agent: kit.CreateAgent(newClient(workers)),
+	}).WithBudget(Budget{MaxWorkerCalls: 1})
+
+	// The second subtask isn't delegated once the budget is used up
+	result, err := s.Run(context.Background(), kit.InvokeConfig{Prompt: "research A and B"})
+	require.NoError(t, err)
+	require.Equal(t, "summary", result.Output)
+	require.Len(t, result.Workers, 1)
+	require.Equal(t, int32(1), workerCalls.Load())
+}
+
+func TestWorkerToolOutsideRun(t *testing.T) {
+	tool := &WorkerTool{Task: "find A", worker: &Worker{Name: "researcher"}}
+	_, err := tool.Execute(&kit.Context{Context: context.Background()})
+	require.ErrorContains(t, err, "outside of a supervisor run")
+}
@@ -0,0 +1,260 @@
+package swarm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/mhrlife/goai-kit/internal/kit"
+	"github.com/openai/openai-go"
+)
+
+// HandoffToolName is the name of the tool generated for agents that can hand off
+const HandoffToolName = "transfer_to_agent"
+
+// Member is a named agent participating in a swarm
+type Member struct {
+	// Name identifies the member and is used as the handoff target
+	Name string
+
+	// Description tells other members when to hand off to this one
+	Description string
+
+	// Instructions is the system prompt used while this member is active (optional)
+	Instructions string
+
+	// Agent runs the member's turns
+	Agent *kit.Agent[string]
+
+	// Handoffs lists the members this one may transfer to (empty means all other members)
+	Handoffs []string
+}
+
+// Swarm runs a conversation across several agents that hand off control to each other
+type Swarm struct {
+	members     map[string]*Member
+	entry       string
+	maxHandoffs int
+}
+
+// Result is the outcome of a swarm run
+type Result struct {
+	// Output is the final answer of the last active member
+	Output string
+
+	// Agent is the name of the member that produced the output
+	Agent string
+
+	// Path lists the members that were active, in order
+	Path []string
+
+	// Messages is the shared history, without member instructions
+	Messages []openai.ChatCompletionMessageParamUnion
+}
+
+// New creates a swarm whose conversations start at the entry member.
+// Members that can hand off run a clone of their agent, given a generated handoff tool and
+// stopping its loop once it is called, so the agents passed in are left unchanged.
+func New(entry string, members ...Member) (*Swarm, error) {
+	s := &Swarm{
+		members:     make(map[string]*Member, len(members)),
+		entry:       entry,
+		maxHandoffs: 10,
+	}
+
+	for i := range members {
+		member := members[i]
+		if member.Name == "" || member.Agent == nil {
+			return nil, fmt.Errorf("swarm member must have a name and an agent")
+		}
+		if _, exists := s.members[member.Name]; exists {
+			return nil, fmt.Errorf("duplicate swarm member: %s", member.Name)
+		}
+		s.members[member.Name] = &member
+	}
+
+	if _, ok := s.members[entry]; !ok {
+		return nil, fmt.Errorf("entry member not found: %s", entry)
+	}
+
+	for _, member := range s.members {
+		targets, err := s.targets(member)
+		if err != nil {
+			return nil, err
+		}
+		if len(targets) == 0 {
+			continue
+		}
+
+		member.Agent = member.Agent.Clone().
+			WithTools(&HandoffTool{targets: targets}).
+			WithStopCondition(stopOnHandoff(targets))
+	}
+
+	return s, nil
+}
+
+// WithMaxHandoffs sets the maximum number of handoffs in a single run
+func (s *Swarm) WithMaxHandoffs(max int) *Swarm {
+	s.maxHandoffs = max
+	return s
+}
+
+// targets resolves the members a member may hand off to
+func (s *Swarm) targets(member *Member) ([]*Member, error) {
+	names := member.Handoffs
+	if len(names) == 0 {
+		for name := range s.members {
+			if name != member.Name {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+	}
+
+	targets := make([]*Member, 0, len(names))
+	for _, name := range names {
+		target, ok := s.members[name]
+		if !ok {
+			return nil, fmt.Errorf("member %s hands off to unknown member %s", member.Name, name)
+		}
+		targets = append(targets, target)
+	}
+	return targets, nil
+}
+
+// Run executes the swarm starting at the entry member. All members share the same history
+// and the invoke callbacks, so their runs are reported to the same tracing callbacks.
+func (s *Swarm) Run(ctx context.Context, config kit.InvokeConfig) (*Result, error) {
+	var history []openai.ChatCompletionMessageParamUnion
+	switch {
+	case config.Prompt != "" && len(config.Messages) > 0:
+		return nil, fmt.Errorf("cannot specify both Prompt and Messages")
+	case config.Prompt != "":
+		history = []openai.ChatCompletionMessageParamUnion{openai.UserMessage(config.Prompt)}
+	case len(config.Messages) > 0:
+		history = config.Messages
+	default:
+		return nil, fmt.Errorf("must specify either Prompt or Messages")
+	}
+
+	result := &Result{}
+	current := s.entry
+
+	// Every member's run is nested under the run that handed off to it
+	parentRunID := config.ParentRunID
+
+	for handoffs := 0; ; handoffs++ {
+		if handoffs > s.maxHandoffs {
+			return nil, fmt.Errorf("max handoffs (%d) reached without completion", s.maxHandoffs)
+		}
+
+		member := s.members[current]
+		result.Path = append(result.Path, member.Name)
+
+		state := &handoffState{}
+		memberConfig := config
+		memberConfig.Prompt = ""
+		memberConfig.Messages = history
+		memberConfig.SystemPrompt = member.Instructions
+		memberConfig.ParentRunID = parentRunID
+
+		run, err := member.Agent.InvokeDetailed(context.WithValue(ctx, handoffKey{}, state), memberConfig)
+		if err != nil {
+			return nil, fmt.Errorf("swarm member %s failed: %w", member.Name, err)
+		}
+
+		// Only the messages of the member's turn are shared, so the next member gets a neutral
+		// history without the system prompt, examples, guidance or any other messages of the
+		// member's agent
+		history = append(slices.Clone(history), run.TurnMessages()...)
+		parentRunID = &run.RunID
+
+		if state.target == "" {
+			result.Output = run.Output
+			result.Agent = member.Name
+			result.Messages = history
+			return result, nil
+		}
+
+		if _, ok := s.members[state.target]; !ok {
+			return nil, fmt.Errorf("swarm member %s handed off to unknown member %s", member.Name, state.target)
+		}
+		current = state.target
+	}
+}
+
+// handoffKey is the context key carrying the handoff state of the active member
+type handoffKey struct{}
+
+// handoffState records the handoff requested during a member's run
+type handoffState struct {
+	target string
+}
+
+// HandoffTool transfers the conversation to another swarm member
+type HandoffTool struct {
+	Agent  string `json:"agent" jsonschema:"description=Name of the agent to transfer the conversation to"`
+	Reason string `json:"reason" jsonschema:"description=Short reason for the transfer"`
+
+	targets []*Member
+}
+
+func (t *HandoffTool) AgentToolInfo() kit.AgentToolInfo {
+	var description strings.Builder
+	description.WriteString("Transfer the conversation to another agent that is better suited to handle it. ")
+	description.WriteString("Available agents:")
+	for _, target := range t.targets {
+		fmt.Fprintf(&description, "\n- %s: %s", target.Name, target.Description)
+	}
+
+	return kit.AgentToolInfo{
+		Name:        HandoffToolName,
+		Description: description.String(),
+	}
+}
+
+func (t *HandoffTool) Execute(ctx *kit.Context) (any, error) {
+	state, ok := ctx.Value(handoffKey{}).(*handoffState)
+	if !ok {
+		return nil, fmt.Errorf("handoff tool used outside of a swarm")
+	}
+
+	// The model is told which agents it may pick, the run goes on with its answer
+	if !isTarget(t.targets, t.Agent) {
+		names := make([]string, 0, len(t.targets))
+		for _, target := range t.targets {
+			names = append(names, target.Name)
+		}
+		return fmt.Sprintf("Error: cannot transfer to %q, the available agents are: %s. Transfer to one of them or answer yourself.",
+			t.Agent, strings.Join(names, ", ")), nil
+	}
+
+	state.target = t.Agent
+	return fmt.Sprintf("Transferred to %s.", t.Agent), nil
+}
+
+// isTarget reports whether name is one of the targets
+func isTarget(targets []*Member, name string) bool {
+	return slices.ContainsFunc(targets, func(target *Member) bool { return target.Name == name })
+}
+
+// stopOnHandoff stops a member's run after it handed off to one of its targets. Calls naming any
+// other member don't stop it, so the model can act on the error they return.
+func stopOnHandoff(targets []*Member) kit.StopCondition {
+	return func(state kit.LoopState) bool {
+		for _, call := range state.ToolCalls {
+			var handoff HandoffTool
+			if call.Function.Name != HandoffToolName || json.Unmarshal([]byte(call.Function.Arguments), &handoff) != nil {
+				continue
+			}
+			if isTarget(targets, handoff.Agent) {
+				return true
+			}
+		}
+		return false
+	}
+}
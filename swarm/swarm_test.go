@@ -0,0 +1,143 @@
+package swarm
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/mhrlife/goai-kit/internal/kit"
+	"github.com/mhrlife/goai-kit/internal/runs"
+	"github.com/openai/openai-go/option"
+	"github.com/stretchr/testify/require"
+)
+
+// swarmServer has the triage member hand off to billing, which answers
+func swarmServer(bodies *[]string, mu *sync.Mutex) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		body := string(data)
+		mu.Lock()
+		*bodies = append(*bodies, body)
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		if strings.Contains(body, "You are triage") {
+			_, _ = w.Write([]byte(`{"id":"1","object":"chat.completion","choices":[{"index":0,"finish_reason":"tool_calls",` +
+				`"message":{"role":"assistant","tool_calls":[{"id":"call_1","type":"function","function":` +
+				`{"name":"transfer_to_agent","arguments":"{\"agent\":\"billing\",\"reason\":\"refund\"}"}}]}}]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":"2","object":"chat.completion","choices":[{"index":0,"finish_reason":"stop",` +
+			`"message":{"role":"assistant","content":"refunded"}}]}`))
+	}))
+}
+
+func TestSwarmHandoff(t *testing.T) {
+	var (
+		bodies []string
+		mu     sync.Mutex
+	)
+	server := swarmServer(&bodies, &mu)
+	defer server.Close()
+
+	client := kit.NewClient(kit.WithAPIKey("test"), kit.WithBaseURL(server.URL), kit.WithRequestOptions(option.WithMaxRetries(0)))
+	triage := kit.CreateAgent(client).
+		WithToolExamples(kit.ToolExample{Prompt: "example question", Response: "example answer"})
+	billing := kit.CreateAgent(client)
+
+	members := []Member{
+		{Name: "triage", Instructions: "You are triage", Agent: triage},
+		{Name: "billing", Description: "Handles refunds", Instructions: "You are billing", Agent: billing},
+	}
+	s, err := New("triage", members...)
+	require.NoError(t, err)
+
+	// Creating another swarm from the same agents doesn't stack handoff tools on them
+	_, err = New("triage", members...)
+	require.NoError(t, err)
+	require.Empty(t, triage.Describe().Tools)
+	require.Empty(t, billing.Describe().Tools)
+	require.Len(t, s.members["triage"].Agent.Describe().Tools, 1)
+
+	result, err := s.Run(context.Background(), kit.InvokeConfig{Prompt: "I want a refund"})
+	require.NoError(t, err)
+	require.Equal(t, "refunded", result.Output)
+	require.Equal(t, "billing", result.Agent)
+	require.Equal(t, []string{"triage", "billing"}, result.Path)
+
+	// The shared history holds the user's message and the members' turns only
+	require.Len(t, result.Messages, 4)
+	require.Equal(t, "I want a refund", kit.MessageText(result.Messages[0]))
+	require.NotNil(t, result.Messages[1].OfAssistant)
+	require.NotNil(t, result.Messages[2].OfTool)
+	require.Equal(t, "refunded", kit.MessageText(result.Messages[3]))
+
+	// Billing neither sees the instructions nor the examples of triage
+	require.Len(t, bodies, 2)
+	require.NotContains(t, bodies[1], "You are triage")
+	require.NotContains(t, bodies[1], "example question")
+	require.Contains(t, bodies[1], "You are billing")
+}
+
+func TestSwarmHandoffTargets(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		body := string(data)
+
+		// Triage first picks a member it can't hand off to, then billing after the error
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.Contains(body, "You are triage") && !strings.Contains(body, "cannot transfer"):
+			_, _ = w.Write([]byte(`{"id":"1","object":"chat.completion","choices":[{"index":0,"finish_reason":"tool_calls",` +
+				`"message":{"role":"assistant","tool_calls":[{"id":"call_1","type":"function","function":` +
+				`{"name":"transfer_to_agent","arguments":"{\"agent\":\"admin\"}"}}]}}]}`))
+		case strings.Contains(body, "You are triage"):
+			_, _ = w.Write([]byte(`{"id":"2","object":"chat.completion","choices":[{"index":0,"finish_reason":"tool_calls",` +
+				`"message":{"role":"assistant","tool_calls":[{"id":"call_2","type":"function","function":` +
+				`{"name":"transfer_to_agent","arguments":"{\"agent\":\"billing\"}"}}]}}]}`))
+		default:
+			_, _ = w.Write([]byte(`{"id":"3","object":"chat.completion","choices":[{"index":0,"finish_reason":"stop",` +
+				`"message":{"role":"assistant","content":"refunded"}}]}`))
+		}
+	}))
+	defer server.Close()
+
+	client := kit.NewClient(kit.WithAPIKey("test"), kit.WithBaseURL(server.URL), kit.WithRequestOptions(option.WithMaxRetries(0)))
+	store := runs.NewMemoryStore()
+	s, err := New("triage",
+		Member{Name: "triage", Instructions: "You are triage", Agent: kit.CreateAgent(client).WithRunStore(store), Handoffs: []string{"billing"}},
+		Member{Name: "billing", Instructions: "You are billing", Agent: kit.CreateAgent(client).WithRunStore(store)},
+		Member{Name: "admin", Instructions: "You are admin", Agent: kit.CreateAgent(client).WithRunStore(store)},
+	)
+	require.NoError(t, err)
+
+	result, err := s.Run(context.Background(), kit.InvokeConfig{Prompt: "I want a refund"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"triage", "billing"}, result.Path)
+	require.Contains(t, kit.MessageText(result.Messages[2]), `cannot transfer to "admin"`)
+
+	// The billing run is nested under the triage run that handed off to it
+	recorded, err := store.Query(context.Background(), runs.Query{})
+	require.NoError(t, err)
+	require.Len(t, recorded, 2)
+	byParent := map[string]*runs.Run{}
+	for _, run := range recorded {
+		byParent[run.ParentID] = run
+	}
+	require.Contains(t, byParent, "")
+	require.Contains(t, byParent, byParent[""].ID)
+}
+
+func TestSwarmValidation(t *testing.T) {
+	client := kit.NewClient(kit.WithAPIKey("test"))
+
+	_, err := New("missing", Member{Name: "a", Agent: kit.CreateAgent(client)})
+	require.ErrorContains(t, err, "entry member not found")
+
+	_, err = New("a", Member{Name: "a", Agent: kit.CreateAgent(client), Handoffs: []string{"b"}})
+	require.ErrorContains(t, err, "unknown member b")
+}
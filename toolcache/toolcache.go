@@ -0,0 +1,54 @@
+// Package toolcache stores tool results so expensive idempotent tools, such as search or
+// retrieval, are not executed again for the same arguments across iterations and runs.
+package toolcache
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrNotFound is returned when a key is not cached or its entry expired
+var ErrNotFound = errors.New("tool result not cached")
+
+// Store holds cached tool results. Entries expire after their TTL; a zero TTL never expires.
+type Store interface {
+	Get(ctx context.Context, key string) ([]byte, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+}
+
+// Key returns the cache key of a tool call: the tool name and the hash of its canonicalized
+// JSON arguments, so argument order and formatting don't cause misses
+func Key(tool string, arguments string) (string, error) {
+	canonical, err := Canonicalize(arguments)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(canonical)
+	return tool + ":" + hex.EncodeToString(sum[:]), nil
+}
+
+// Canonicalize re-encodes JSON arguments with sorted object keys and no insignificant
+// whitespace. Numbers keep their original representation.
+func Canonicalize(arguments string) ([]byte, error) {
+	if arguments == "" {
+		return []byte("{}"), nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader([]byte(arguments)))
+	decoder.UseNumber()
+
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, fmt.Errorf("failed to canonicalize tool arguments: %w", err)
+	}
+
+	// encoding/json sorts map keys
+	return json.Marshal(value)
+}
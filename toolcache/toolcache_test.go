@@ -0,0 +1,41 @@
+package toolcache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyCanonicalizesArguments(t *testing.T) {
+	a, err := Key("search", `{"query": "go", "limit": 10, "filters": {"b": 1, "a": 2}}`)
+	require.NoError(t, err)
+	b, err := Key("search", `{"filters":{"a":2,"b":1},"limit":10,"query":"go"}`)
+	require.NoError(t, err)
+	require.Equal(t, a, b)
+
+	other, err := Key("lookup", `{"filters":{"a":2,"b":1},"limit":10,"query":"go"}`)
+	require.NoError(t, err)
+	require.NotEqual(t, a, other, "the tool name is part of the key")
+
+	_, err = Key("search", `{"query":`)
+	require.Error(t, err)
+}
+
+func TestMemoryStoreExpires(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+
+	require.NoError(t, store.Set(ctx, "key", []byte("result"), time.Minute))
+
+	value, err := store.Get(ctx, "key")
+	require.NoError(t, err)
+	require.Equal(t, "result", string(value))
+
+	now = now.Add(time.Minute)
+	_, err = store.Get(ctx, "key")
+	require.ErrorIs(t, err, ErrNotFound)
+}
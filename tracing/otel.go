@@ -0,0 +1,165 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// OTELOptions contains the optional settings of an OTLP tracer
+type OTELOptions struct {
+	// URLPath overrides the path traces are posted to (optional, defaults to /v1/traces)
+	URLPath string
+
+	// Insecure sends traces over plain HTTP (optional, e.g. for a local collector)
+	Insecure bool
+
+	// Environment is the deployment environment (e.g., "development", "production")
+	Environment string
+
+	// ServiceName is the name of the service (optional, defaults to "goaikit")
+	ServiceName string
+
+	// ServiceVersion is the version of the service (optional)
+	ServiceVersion string
+
+	// Sampler decides which traces are recorded (optional, defaults to the SDK default)
+	Sampler sdktrace.Sampler
+
+	// SkipGlobal keeps the provider from being set as the global tracer provider (optional)
+	SkipGlobal bool
+}
+
+// OTELTracer wraps an OpenTelemetry tracer provider exporting to any OTLP/HTTP collector,
+// such as Jaeger, Tempo or Honeycomb
+type OTELTracer struct {
+	provider *sdktrace.TracerProvider
+	tracer   trace.Tracer
+}
+
+// NewOTELTracer creates a tracer exporting to the OTLP/HTTP endpoint, given either as host:port
+// or as a URL. headers are sent with every export, e.g. for authentication.
+func NewOTELTracer(endpoint string, headers map[string]string, opts OTELOptions) (*OTELTracer, error) {
+	if endpoint == "" {
+		return nil, fmt.Errorf("endpoint is required when tracing is enabled")
+	}
+
+	// Set defaults
+	serviceName := opts.ServiceName
+	if serviceName == "" {
+		serviceName = "goaikit"
+	}
+
+	serviceVersion := opts.ServiceVersion
+	if serviceVersion == "" {
+		serviceVersion = "1.0.0"
+	}
+
+	// Create resource with service information
+	res, err := resource.New(
+		context.Background(),
+		resource.WithAttributes(
+			semconv.ServiceName(serviceName),
+			semconv.ServiceVersion(serviceVersion),
+			semconv.DeploymentEnvironment(opts.Environment),
+		),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create resource: %w", err)
+	}
+
+	// Create OTLP HTTP exporter
+	var exporterOpts []otlptracehttp.Option
+	if strings.Contains(endpoint, "://") {
+		exporterOpts = append(exporterOpts, otlptracehttp.WithEndpointURL(endpoint))
+	} else {
+		exporterOpts = append(exporterOpts, otlptracehttp.WithEndpoint(endpoint))
+	}
+	if len(headers) > 0 {
+		exporterOpts = append(exporterOpts, otlptracehttp.WithHeaders(headers))
+	}
+	if opts.URLPath != "" {
+		exporterOpts = append(exporterOpts, otlptracehttp.WithURLPath(opts.URLPath))
+	}
+	if opts.Insecure {
+		exporterOpts = append(exporterOpts, otlptracehttp.WithInsecure())
+	}
+
+	exporter, err := otlptracehttp.New(context.Background(), exporterOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+	}
+
+	// Create tracer provider
+	providerOpts := []sdktrace.TracerProviderOption{
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	}
+	if opts.Sampler != nil {
+		providerOpts = append(providerOpts, sdktrace.WithSampler(opts.Sampler))
+	}
+	provider := sdktrace.NewTracerProvider(providerOpts...)
+
+	// Set as global provider
+	if !opts.SkipGlobal {
+		otel.SetTracerProvider(provider)
+	}
+
+	// Create tracer
+	tracer := provider.Tracer(serviceName, trace.WithInstrumentationVersion(serviceVersion))
+
+	return &OTELTracer{
+		provider: provider,
+		tracer:   tracer,
+	}, nil
+}
+
+// Tracer returns the underlying OpenTelemetry tracer
+func (t *OTELTracer) Tracer() trace.Tracer {
+	return t.tracer
+}
+
+// Provider returns the underlying tracer provider
+func (t *OTELTracer) Provider() *sdktrace.TracerProvider {
+	return t.provider
+}
+
+// Flush ensures all spans are exported
+func (t *OTELTracer) Flush() error {
+	if t.provider == nil {
+		return nil
+	}
+
+	ctx := context.Background()
+	return t.provider.ForceFlush(ctx)
+}
+
+func (t *OTELTracer) FlushOrPanic() {
+	if err := t.Flush(); err != nil {
+		slog.Error("failed to flush tracer", "error", err)
+		panic(err)
+	}
+}
+
+// Shutdown shuts down the tracer provider
+func (t *OTELTracer) Shutdown() error {
+	if t.provider == nil {
+		return nil
+	}
+
+	ctx := context.Background()
+	return t.provider.Shutdown(ctx)
+}
+
+// IsEnabled returns whether tracing is enabled
+func (t *OTELTracer) IsEnabled() bool {
+	return t.provider != nil
+}
@@ -0,0 +1,66 @@
+package tracing
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOTELTracer(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		paths    []string
+		auth     []string
+		payloads []string
+	)
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		auth = append(auth, r.Header.Get("Authorization"))
+		payloads = append(payloads, string(body))
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer collector.Close()
+
+	_, err := NewOTELTracer("", nil, OTELOptions{})
+	require.ErrorContains(t, err, "endpoint is required")
+
+	// Endpoints given as URLs keep their scheme; the path defaults to /v1/traces
+	tracer, err := NewOTELTracer(collector.URL, map[string]string{"Authorization": "Bearer token"}, OTELOptions{
+		ServiceName: "support-bot",
+		SkipGlobal:  true,
+	})
+	require.NoError(t, err)
+	_, span := tracer.Tracer().Start(context.Background(), "agent-run")
+	span.End()
+	require.NoError(t, tracer.Flush())
+	require.NoError(t, tracer.Shutdown())
+
+	// host:port endpoints need Insecure for a plain HTTP collector
+	tracer, err = NewOTELTracer(collector.Listener.Addr().String(), nil, OTELOptions{
+		URLPath:    "/custom/traces",
+		Insecure:   true,
+		SkipGlobal: true,
+	})
+	require.NoError(t, err)
+	_, span = tracer.Tracer().Start(context.Background(), "other-run")
+	span.End()
+	require.NoError(t, tracer.Shutdown())
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, []string{"/v1/traces", "/custom/traces"}, paths)
+	require.Equal(t, []string{"Bearer token", ""}, auth)
+	require.Contains(t, payloads[0], "agent-run")
+	require.Contains(t, payloads[0], "support-bot")
+	require.Contains(t, payloads[1], "goaikit", "the service name defaults to goaikit")
+}